@@ -24,6 +24,9 @@ export function generateStandaloneHTML(tmpDir: string, type: 'recover' | 'create
 // Options for test project creation
 interface TestProjectOptions {
   noEmbedManifest?: boolean;
+  note?: string;
+  question?: string;
+  answer?: string; // Answer to question, given to seal
 }
 
 // Cache for test projects within the same worker process.
@@ -33,7 +36,10 @@ const projectCache = new Map<string, string>();
 const cachedPaths = new Set<string>();
 
 function cacheKey(options: TestProjectOptions): string {
-  return options.noEmbedManifest ? 'standard-no-embed' : 'standard';
+  let key = options.noEmbedManifest ? 'standard-no-embed' : 'standard';
+  if (options.note) key += `-note:${options.note}`;
+  if (options.question) key += `-question:${options.question}:${options.answer}`;
+  return key;
 }
 
 // Create a sealed test project with bundles (cached per config within a worker)
@@ -54,6 +60,13 @@ export function createTestProject(options: TestProjectOptions = {}): string {
     '--friend', 'Alice,alice@test.com', '--friend', 'Bob,bob@test.com', '--friend', 'Carol,carol@test.com',
   ], { stdio: 'inherit' });
 
+  if (options.note) {
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), `note: ${JSON.stringify(options.note)}\n`);
+  }
+  if (options.question) {
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), `question: ${JSON.stringify(options.question)}\n`);
+  }
+
   // Add secret content
   const manifestDir = path.join(projectDir, 'manifest');
   fs.writeFileSync(path.join(manifestDir, 'secret.txt'), 'The secret password is: correct-horse-battery-staple');
@@ -61,7 +74,8 @@ export function createTestProject(options: TestProjectOptions = {}): string {
 
   // Seal and generate bundles
   const extraFlags = options.noEmbedManifest ? ['--no-embed-manifest'] : [];
-  execFileSync(bin, ['seal', ...extraFlags], { cwd: projectDir, stdio: 'inherit' });
+  const sealEnv = options.answer ? { ...process.env, REMEMORY_ANSWER: options.answer } : process.env;
+  execFileSync(bin, ['seal', ...extraFlags], { cwd: projectDir, stdio: 'inherit', env: sealEnv });
   execFileSync(bin, ['bundle', ...extraFlags], { cwd: projectDir, stdio: 'inherit' });
 
   projectCache.set(key, projectDir);
@@ -70,8 +84,8 @@ export function createTestProject(options: TestProjectOptions = {}): string {
 }
 
 // Create a sealed anonymous test project with bundles (cached within a worker)
-export function createAnonymousTestProject(): string {
-  const key = 'anonymous';
+export function createAnonymousTestProject(shares: number = 3): string {
+  const key = `anonymous-${shares}`;
   const cached = projectCache.get(key);
   if (cached && fs.existsSync(cached)) {
     return cached;
@@ -81,9 +95,9 @@ export function createAnonymousTestProject(): string {
   const projectDir = path.join(tmpDir, 'test-anon-project');
   const bin = getRememoryBin();
 
-  // Create anonymous project with 3 shares, threshold 2
+  // Create anonymous project with the given number of shares, threshold 2
   execFileSync(bin, [
-    'init', projectDir, '--name', 'Anonymous E2E Test', '--anonymous', '--shares', '3', '--threshold', '2',
+    'init', projectDir, '--name', 'Anonymous E2E Test', '--anonymous', '--shares', String(shares), '--threshold', '2',
   ], { stdio: 'inherit' });
 
   // Add secret content
@@ -169,17 +183,18 @@ export function findReadmeFile(bundleDir: string, ext: string = '.txt'): string
   throw new Error(`No README${ext} file found in ${bundleDir}`);
 }
 
-// Extract the 25 recovery words from a README file as a space-separated string
+// Extract the 25 (or, for pieces above 15, 26) recovery words from a README
+// file as a space-separated string
 export function extractWordsFromReadme(readmePath: string): string {
   const readme = fs.readFileSync(readmePath, 'utf8');
   // Match word grid: look for "25 RECOVERY WORDS" (any language) or numbered word lines
-  const wordsMatch = readme.match(/\b25\b[^\n]*:\n\n([\s\S]*?)\n\n/);
+  const wordsMatch = readme.match(/\b(2[56])\b[^\n]*:\n\n([\s\S]*?)\n\n/);
   if (!wordsMatch) throw new Error('Could not find recovery words in ' + readmePath);
 
-  const wordLines = wordsMatch[1].trim().split('\n');
+  const wordLines = wordsMatch[2].trim().split('\n');
   const leftWords: string[] = [];
   const rightWords: string[] = [];
-  const half = 13; // 25 words: 13 left (1-13), 12 right (14-25)
+  const half = Math.ceil(parseInt(wordsMatch[1], 10) / 2); // 25 words: 13 left (1-13), 12 right (14-25)
   for (const line of wordLines) {
     const matches = line.match(/\d+\.\s+(\S+)/g);
     if (matches) {
@@ -247,6 +262,11 @@ export class RecoveryPage {
     await this.page.locator('#recover-btn').click();
   }
 
+  // Type the answer to the recovery question
+  async enterAnswer(answer: string): Promise<void> {
+    await this.page.locator('#answer-input').fill(answer);
+  }
+
   // Assertions
   async expectShareCount(count: number): Promise<void> {
     await expect(this.page.locator('.share-item')).toHaveCount(count);
@@ -271,6 +291,10 @@ export class RecoveryPage {
     await expect(this.page.locator('#manifest-status')).toHaveClass(/loaded/, { timeout: 15000 });
   }
 
+  async expectManifestChecksum(result: 'ok' | 'mismatch'): Promise<void> {
+    await expect(this.page.locator(`#manifest-status .manifest-checksum.${result}`)).toBeVisible({ timeout: 15000 });
+  }
+
   async expectManifestDropZoneVisible(): Promise<void> {
     await expect(this.page.locator('#manifest-drop-zone')).toBeVisible();
   }
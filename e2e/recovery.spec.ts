@@ -2,6 +2,7 @@ import { test, expect } from '@playwright/test';
 import * as fs from 'fs';
 import * as path from 'path';
 import * as os from 'os';
+import { execFileSync } from 'child_process';
 import {
   getRememoryBin,
   createTestProject,
@@ -59,6 +60,7 @@ test.describe('Browser Recovery Tool', () => {
 
     // Manifest is pre-loaded (embedded in recover.html for small manifests)
     await recovery.expectManifestLoaded();
+    await recovery.expectManifestChecksum('ok');
 
     // Still need 1 more share (threshold is 2)
     await recovery.expectNeedMoreShares(1);
@@ -225,6 +227,35 @@ test.describe('Browser Recovery Tool', () => {
     await recovery.expectShareCount(2);
   });
 
+  test('typing words shows suggestions and a word count', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    // Only the word lists this group uses are embedded
+    await expect(page.locator('script[data-wordlist="en"]')).toHaveCount(1);
+
+    const words = extractWordsFromReadme(findReadmeFile(bobDir)).split(' ');
+
+    await recovery.clickPasteButton();
+    await recovery.expectPasteAreaVisible();
+
+    // Type two full words and the start of the third
+    const input = page.locator('#paste-input');
+    await input.pressSequentially(`${words[0]} ${words[1]} ${words[2].slice(0, 3)}`);
+
+    const suggestions = page.locator('#word-suggestions');
+    await expect(suggestions).toBeVisible();
+    await expect(suggestions.locator('button', { hasText: new RegExp(`^${words[2]}$`) })).toHaveCount(1);
+
+    // Picking the suggestion completes the word
+    await suggestions.locator('button', { hasText: new RegExp(`^${words[2]}$`) }).click();
+    await expect(input).toHaveValue(`${words[0]} ${words[1]} ${words[2]} `);
+    await expect(suggestions.locator('.word-count')).toHaveText('3/25');
+  });
+
   test('paste area accepts numbered word grid directly', async ({ page }) => {
     const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
     const recovery = new RecoveryPage(page, aliceDir);
@@ -498,6 +529,113 @@ test.describe('Embedded Manifest Recovery', () => {
   });
 });
 
+test.describe('Project note', () => {
+  const note = 'Call my brother first.\n<script>window.__noteInjected = true</script>';
+  let projectDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    projectDir = createTestProject({ note });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    cleanupProject(projectDir);
+  });
+
+  test('note is shown as plain text above the steps', async ({ page }) => {
+    const aliceDir = extractBundle(bundlesDir, 'Alice');
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+
+    await expect(page.locator('#holder-note')).toBeVisible();
+    await expect(page.locator('#holder-note-text')).toHaveText(note);
+    await expect(page.locator('#holder-note-text script')).toHaveCount(0);
+    expect(await page.evaluate(() => (window as any).__noteInjected)).toBeUndefined();
+  });
+
+  test('note is hidden when the project has none', async ({ page }) => {
+    const aliceDir = extractBundle(path.join(createTestProject(), 'output', 'bundles'), 'Alice');
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+
+    await expect(page.locator('#holder-note')).toBeHidden();
+  });
+});
+
+test.describe('Recovery question', () => {
+  const question = 'Name of our first cat?';
+  let projectDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    projectDir = createTestProject({ question, answer: 'Mr. Whiskers' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    cleanupProject(projectDir);
+  });
+
+  test('asks the question and waits for an answer', async ({ page }) => {
+    const aliceDir = extractBundle(bundlesDir, 'Alice');
+    const bobDir = extractBundle(bundlesDir, 'Bob');
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await expect(page.locator('#answer-section')).toBeVisible();
+    await expect(page.locator('#answer-question')).toHaveText(question);
+
+    await recovery.addShares(bobDir);
+    await recovery.expectReadyToRecover();
+    await recovery.expectManifestLoaded();
+    await recovery.expectRecoverDisabled();
+  });
+
+  test('wrong answer keeps the pieces and can be corrected', async ({ page }) => {
+    const aliceDir = extractBundle(bundlesDir, 'Alice');
+    const bobDir = extractBundle(bundlesDir, 'Bob');
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectReadyToRecover();
+
+    await recovery.enterAnswer('Fluffy');
+    await recovery.recover();
+    await expect(page.locator('#status-message')).toHaveClass(/error/, { timeout: 60000 });
+    await recovery.expectShareCount(2);
+
+    await recovery.enterAnswer('  mr. WHISKERS ');
+    await recovery.recover();
+    await recovery.expectRecoveryComplete();
+    await recovery.expectFileCount(3);
+  });
+
+  test('answer section is hidden when the project has no question', async ({ page }) => {
+    const aliceDir = extractBundle(path.join(createTestProject(), 'output', 'bundles'), 'Alice');
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+
+    await expect(page.locator('#answer-section')).toBeHidden();
+  });
+});
+
 test.describe('--no-embed-manifest flag', () => {
   let noEmbedProjectDir: string;
   let noEmbedBundlesDir: string;
@@ -537,6 +675,7 @@ test.describe('--no-embed-manifest flag', () => {
     // Manifest must be loaded manually
     await recovery.addManifest();
     await recovery.expectManifestLoaded();
+    await recovery.expectManifestChecksum('ok');
 
     // Add Bob's share
     await recovery.addShares(bobDir);
@@ -545,4 +684,183 @@ test.describe('--no-embed-manifest flag', () => {
     await recovery.expectFileCount(3);
     await recovery.expectDownloadVisible();
   });
+
+  test('warns when MANIFEST.age is from a different project', async ({ page }) => {
+    const otherProjectDir = createTestProject({ noEmbedManifest: true });
+    try {
+      const otherDir = extractBundle(path.join(otherProjectDir, 'output', 'bundles'), 'Alice');
+      const bundleDir = extractBundle(noEmbedBundlesDir, 'Alice');
+      const recovery = new RecoveryPage(page, bundleDir);
+
+      await recovery.open();
+      await recovery.addManifest(otherDir);
+      await recovery.expectManifestLoaded();
+      await recovery.expectManifestChecksum('mismatch');
+    } finally {
+      cleanupProject(otherProjectDir);
+    }
+  });
+});
+
+test.describe('--no-cli-link flag', () => {
+  let tmpDir: string;
+
+  test.beforeAll(async () => {
+    if (!fs.existsSync(getRememoryBin())) {
+      test.skip();
+      return;
+    }
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-no-cli-e2e-'));
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir && fs.existsSync(tmpDir)) {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    }
+  });
+
+  test('footer links to the CLI by default', async ({ page }) => {
+    const htmlPath = generateStandaloneHTML(tmpDir, 'recover');
+    await new RecoveryPage(page, tmpDir).openFile(htmlPath);
+
+    await expect(page.locator('footer [data-i18n="download_cli"]')).toBeVisible();
+  });
+
+  test('footer leaves the CLI out with --no-cli-link', async ({ page }) => {
+    const htmlPath = path.join(tmpDir, 'recover-no-cli.html');
+    execFileSync(getRememoryBin(), ['html', 'recover', '--no-cli-link', '-o', htmlPath], { stdio: 'inherit' });
+    await new RecoveryPage(page, tmpDir).openFile(htmlPath);
+
+    await expect(page.locator('footer a', { hasText: 'Docs' })).toBeVisible();
+    await expect(page.locator('footer [data-i18n="download_cli"]')).toHaveCount(0);
+  });
+});
+
+test.describe('--langs flag', () => {
+  let tmpDir: string;
+
+  test.beforeAll(async () => {
+    if (!fs.existsSync(getRememoryBin())) {
+      test.skip();
+      return;
+    }
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-langs-e2e-'));
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir && fs.existsSync(tmpDir)) {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    }
+  });
+
+  test('only the chosen languages are offered', async ({ page }) => {
+    const htmlPath = path.join(tmpDir, 'recover-es.html');
+    execFileSync(getRememoryBin(), ['html', 'recover', '--langs', 'es', '-o', htmlPath], { stdio: 'inherit' });
+    await new RecoveryPage(page, tmpDir).openFile(htmlPath);
+
+    const options = page.locator('#lang-select option');
+    await expect(options).toHaveCount(2);
+    await expect(options.nth(0)).toHaveAttribute('value', 'en');
+    await expect(options.nth(1)).toHaveAttribute('value', 'es');
+    await page.locator('#lang-select').selectOption('es');
+    await expect(page).toHaveTitle('Recuperar Archivos');
+  });
+
+  test('a saved language that was left out falls back to English', async ({ page }) => {
+    const htmlPath = path.join(tmpDir, 'recover-es.html');
+    execFileSync(getRememoryBin(), ['html', 'recover', '--langs', 'es', '-o', htmlPath], { stdio: 'inherit' });
+    await page.addInitScript(() => localStorage.setItem('rememory-lang', 'fr'));
+    const errors: string[] = [];
+    page.on('pageerror', (err) => errors.push(err.message));
+    await new RecoveryPage(page, tmpDir).openFile(htmlPath);
+
+    await expect(page.locator('#lang-select')).toHaveValue('en');
+    expect(errors).toEqual([]);
+  });
+});
+
+test.describe('branding flags', () => {
+  let tmpDir: string;
+
+  test.beforeAll(async () => {
+    if (!fs.existsSync(getRememoryBin())) {
+      test.skip();
+      return;
+    }
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-branding-e2e-'));
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir && fs.existsSync(tmpDir)) {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    }
+  });
+
+  test('extra CSS and JavaScript are applied and recovery still loads', async ({ page }) => {
+    const cssPath = path.join(tmpDir, 'brand.css');
+    const jsPath = path.join(tmpDir, 'brand.js');
+    const htmlPath = path.join(tmpDir, 'recover-branded.html');
+    fs.writeFileSync(cssPath, 'body { background-color: rgb(18, 52, 86); }');
+    fs.writeFileSync(jsPath, 'document.body.dataset.brand = "acme";');
+    execFileSync(getRememoryBin(), ['html', 'recover', '--extra-css', cssPath, '--extra-js', jsPath, '-o', htmlPath], { stdio: 'inherit' });
+
+    const errors: string[] = [];
+    page.on('pageerror', (err) => errors.push(err.message));
+    await new RecoveryPage(page, tmpDir).openFile(htmlPath);
+
+    await expect(page.locator('body')).toHaveCSS('background-color', 'rgb(18, 52, 86)');
+    await expect(page.locator('body')).toHaveAttribute('data-brand', 'acme');
+    await expect(page.locator('#lang-select')).toBeVisible();
+    expect(errors).toEqual([]);
+  });
+});
+
+test.describe('Pieces numbered above 15', () => {
+  let projectDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    projectDir = createAnonymousTestProject(16);
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    cleanupProject(projectDir);
+  });
+
+  test('word count expects the 26th word and the piece is added', async ({ page }) => {
+    const [firstDir, lastDir] = extractAnonymousBundles(bundlesDir, [1, 16]);
+    const recovery = new RecoveryPage(page, firstDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    const words = extractWordsFromReadme(findReadmeFile(lastDir)).split(' ');
+    expect(words.length).toBe(26);
+
+    await recovery.clickPasteButton();
+    await recovery.expectPasteAreaVisible();
+
+    // Until the 25th word says otherwise, a piece has 25 words
+    const input = page.locator('#paste-input');
+    const count = page.locator('#word-suggestions .word-count');
+    await input.fill(words.slice(0, 24).join(' ') + ' ');
+    await expect(count).toHaveText('24/25');
+
+    // Piece 16's 25th word has no piece number, so a 26th is expected
+    await input.fill(words.slice(0, 25).join(' ') + ' ');
+    await expect(count).toHaveText('25/26');
+
+    await input.fill(words.join(' ') + ' ');
+    await expect(count).toHaveText('26/26');
+
+    await recovery.submitPaste();
+    await recovery.expectShareCount(2);
+  });
 });
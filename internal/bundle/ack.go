@@ -0,0 +1,69 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ackDomain keeps acknowledgment codes apart from every other hash of a
+// share, such as its checksum.
+const ackDomain = "rememory-ack-v1\x00"
+
+// ackAlphabet is Crockford's base32: no I, L, O or U, so a code read
+// aloud can't be mistaken for another.
+const ackAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// GenerateAckToken returns a short confirmation code for share, like
+// "K7QM-2XRD", printed at the bottom of the holder's README. When the
+// holder reads it back, it shows they received and opened their bundle.
+//
+// The code is 40 bits of a hash of the share's data, so it can't be
+// produced without the share and says nothing about it. It isn't secret.
+func GenerateAckToken(share *core.Share) string {
+	h := sha256.New()
+	h.Write([]byte(ackDomain))
+	h.Write(share.Data)
+	sum := h.Sum(nil)
+
+	// 8 characters of 5 bits each, from the first 5 bytes.
+	var bits uint64
+	for _, b := range sum[:5] {
+		bits = bits<<8 | uint64(b)
+	}
+	code := make([]byte, 8)
+	for i := len(code) - 1; i >= 0; i-- {
+		code[i] = ackAlphabet[bits&0x1f]
+		bits >>= 5
+	}
+	return string(code[:4]) + "-" + string(code[4:])
+}
+
+// VerifyAckToken reports whether token is share's confirmation code.
+// Case, spaces and dashes don't matter, and letters that look like digits
+// are read as those digits, so a code read over the phone can be checked
+// as it was typed.
+func VerifyAckToken(share *core.Share, token string) bool {
+	want := normalizeAckToken(GenerateAckToken(share))
+	got := normalizeAckToken(token)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// normalizeAckToken reduces a code to its bare characters.
+func normalizeAckToken(token string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(token) {
+		switch r {
+		case ' ', '-', '\t':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
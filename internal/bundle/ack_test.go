@@ -0,0 +1,38 @@
+package bundle
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+func TestAckToken(t *testing.T) {
+	alice := core.NewShare(2, 1, 3, 2, "Alice", []byte("alice-share-data"))
+	bob := core.NewShare(2, 2, 3, 2, "Bob", []byte("bob-share-data"))
+
+	token := GenerateAckToken(alice)
+	if !regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}$`).MatchString(token) {
+		t.Fatalf("unexpected token format %q", token)
+	}
+	if GenerateAckToken(alice.Clone()) != token {
+		t.Error("the same share should always give the same token")
+	}
+
+	for _, typed := range []string{token, strings.ToLower(token), strings.ReplaceAll(token, "-", " "), strings.ReplaceAll(token, "-", "")} {
+		if !VerifyAckToken(alice, typed) {
+			t.Errorf("VerifyAckToken(alice, %q) = false, want true", typed)
+		}
+	}
+
+	if VerifyAckToken(bob, token) {
+		t.Error("Alice's token should not verify against Bob's share")
+	}
+	if VerifyAckToken(alice, GenerateAckToken(bob)) {
+		t.Error("Bob's token should not verify against Alice's share")
+	}
+	if VerifyAckToken(alice, "") {
+		t.Error("an empty token should not verify")
+	}
+}
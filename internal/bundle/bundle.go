@@ -2,13 +2,16 @@ package bundle
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/eljojo/rememory/internal/core"
@@ -21,21 +24,28 @@ import (
 // Config holds configuration for bundle generation.
 type Config struct {
 	Version          string // Tool version (e.g., "v1.0.0")
-	GitHubReleaseURL string // URL to GitHub release for CLI download
+	GitHubReleaseURL string // URL to GitHub release for CLI download; empty leaves the CLI out
 	WASMBytes        []byte // Compiled recover.wasm binary
 	RecoveryURL      string // Optional: base URL for QR code (e.g. "https://example.com/recover.html")
 	NoEmbedManifest  bool   // If true, do not embed MANIFEST.age in recover.html even when small enough
+
+	// Workers is how many bundles GenerateAll builds at once (0 means
+	// runtime.NumCPU()).
+	Workers int
+	// Progress, if set, is called after each bundle is finished with the
+	// number done so far. Calls are never concurrent.
+	Progress func(done, total int)
 }
 
-// GenerateAll creates bundles for all friends in the project.
+// GenerateAll creates bundles for all friends in the project, several at a
+// time (see Config.Workers). Each bundle depends only on its friend, so the
+// files written are the same whatever order the workers finish in. If any
+// bundle fails, the error for the first such friend is returned. Once all
+// are written, SHA256SUMS is rewritten to list them.
 func GenerateAll(p *project.Project, cfg Config) error {
-	if p.Sealed == nil {
-		return fmt.Errorf("project must be sealed before generating bundles")
-	}
-
-	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
-	if err := os.MkdirAll(bundlesDir, 0755); err != nil {
-		return fmt.Errorf("creating bundles directory: %w", err)
+	c, err := newBundleContext(p, cfg)
+	if err != nil {
+		return err
 	}
 
 	// Load all shares
@@ -44,97 +54,232 @@ func GenerateAll(p *project.Project, cfg Config) error {
 		return fmt.Errorf("loading shares: %w", err)
 	}
 
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(p.Friends))
+
+	jobs := make(chan int)
+	errs := make([]error, len(p.Friends))
+	var mu sync.Mutex
+	done := 0
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, errs[i] = c.generate(p, cfg, i, shares[i])
+				if cfg.Progress != nil {
+					mu.Lock()
+					done++
+					cfg.Progress(done, len(p.Friends))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range p.Friends {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		return err
+	}
+	return WriteSHA256SUMS(c.bundlesDir)
+}
+
+// firstError returns the first non-nil error in errs.
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateFriendBundle creates the bundle for the friend at index i (0-based)
+// using the given share, and returns the path of the bundle ZIP.
+// Unlike GenerateAll it doesn't need the other friends' share files.
+func GenerateFriendBundle(p *project.Project, cfg Config, i int, share *core.Share) (string, error) {
+	if i < 0 || i >= len(p.Friends) {
+		return "", fmt.Errorf("friend index %d out of range", i)
+	}
+	c, err := newBundleContext(p, cfg)
+	if err != nil {
+		return "", err
+	}
+	path, err := c.generate(p, cfg, i, share)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteSHA256SUMS(c.bundlesDir); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// bundleContext holds what every bundle in a project shares. It is only read
+// once built, so bundles can be generated from it concurrently.
+type bundleContext struct {
+	bundlesDir       string
+	manifestData     []byte
+	manifestChecksum string
+	wasmChecksum     string
+	wordLangs        []string
+	recoverTemplate  *html.RecoverTemplate
+}
+
+func newBundleContext(p *project.Project, cfg Config) (*bundleContext, error) {
+	if p.Sealed == nil {
+		return nil, fmt.Errorf("project must be sealed before generating bundles")
+	}
+	if p.PerFileKeys() {
+		return nil, fmt.Errorf("generating bundles: %w", project.ErrPerFileKeys)
+	}
+
+	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
+	if err := os.MkdirAll(bundlesDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating bundles directory: %w", err)
+	}
+
 	// Read MANIFEST.age
-	manifestPath := p.ManifestAgePath()
-	manifestData, err := os.ReadFile(manifestPath)
+	manifestData, err := os.ReadFile(p.ManifestAgePath())
 	if err != nil {
-		return fmt.Errorf("reading manifest: %w", err)
+		return nil, fmt.Errorf("reading manifest: %w", err)
 	}
-	manifestChecksum := core.HashBytes(manifestData)
 
-	// Generate bundle for each friend
-	for i, friend := range p.Friends {
-		share := shares[i]
+	return newContext(p, cfg, bundlesDir, manifestData), nil
+}
 
-		// Resolve language: friend override > project default > "en"
-		lang := friend.Language
-		if lang == "" {
-			lang = p.Language
-		}
-		if lang == "" {
-			lang = "en"
-		}
+// newContext builds a bundleContext around manifestData, writing bundles to
+// bundlesDir.
+func newContext(p *project.Project, cfg Config, bundlesDir string, manifestData []byte) *bundleContext {
+	// Any holder may end up typing in someone else's words, so every
+	// recover.html carries the word lists for the whole group.
+	friendLangs := []string{p.Language}
+	for _, f := range p.Friends {
+		friendLangs = append(friendLangs, f.Language)
+	}
 
-		// Get other friends (excluding this one) - empty for anonymous mode
-		var otherFriends []project.Friend
-		var otherFriendsInfo []html.FriendInfo
-		if !p.Anonymous {
-			otherFriends = make([]project.Friend, 0, len(p.Friends)-1)
-			otherFriendsInfo = make([]html.FriendInfo, 0, len(p.Friends)-1)
-			for j, f := range p.Friends {
-				if j != i {
-					otherFriends = append(otherFriends, f)
-					otherFriendsInfo = append(otherFriendsInfo, html.FriendInfo{
-						Name:       f.Name,
-						Contact:    f.Contact,
-						ShareIndex: j + 1, // 1-based share index
-					})
-				}
-			}
-		}
+	return &bundleContext{
+		bundlesDir:       bundlesDir,
+		manifestData:     manifestData,
+		manifestChecksum: core.HashBytes(manifestData),
+		wasmChecksum:     core.HashBytes(cfg.WASMBytes),
+		wordLangs:        html.WordLanguages(friendLangs),
+		recoverTemplate:  html.NewRecoverTemplate(cfg.WASMBytes, cfg.Version, cfg.GitHubReleaseURL),
+	}
+}
 
-		// Generate personalized recover.html for this friend
-		personalization := &html.PersonalizationData{
-			Holder:       friend.Name,
-			HolderShare:  share.Encode(),
-			OtherFriends: otherFriendsInfo,
-			Threshold:    p.Threshold,
-			Total:        len(p.Friends),
-			Language:     lang,
-		}
+// generate creates and verifies the bundle for friend i.
+func (c *bundleContext) generate(p *project.Project, cfg Config, i int, share *core.Share) (string, error) {
+	friend := p.Friends[i]
+	params := c.params(p, cfg, i, share)
+	if err := GenerateBundle(params); err != nil {
+		return "", fmt.Errorf("generating bundle for %s: %w", friend.Name, err)
+	}
 
-		// Embed manifest in recover.html when small enough and not disabled
-		manifestEmbedded := !cfg.NoEmbedManifest && len(manifestData) <= html.MaxEmbeddedManifestSize
-		if manifestEmbedded {
-			personalization.ManifestB64 = base64.StdEncoding.EncodeToString(manifestData)
-		}
+	// Verify the bundle we just created
+	if err := verifyBundleContents(params.OutputPath); err != nil {
+		return "", fmt.Errorf("verifying bundle for %s: %w", friend.Name, err)
+	}
 
-		recoverHTML := html.GenerateRecoverHTML(cfg.WASMBytes, cfg.Version, cfg.GitHubReleaseURL, personalization)
-		recoverChecksum := core.HashString(recoverHTML)
-
-		bundlePath := filepath.Join(bundlesDir, fmt.Sprintf("bundle-%s.zip", core.SanitizeFilename(friend.Name)))
-
-		err := GenerateBundle(BundleParams{
-			OutputPath:       bundlePath,
-			ProjectName:      p.Name,
-			Friend:           friend,
-			Share:            share,
-			OtherFriends:     otherFriends,
-			Threshold:        p.Threshold,
-			Total:            len(p.Friends),
-			ManifestData:     manifestData,
-			ManifestChecksum: manifestChecksum,
-			ManifestEmbedded: manifestEmbedded,
-			RecoverHTML:      recoverHTML,
-			RecoverChecksum:  recoverChecksum,
-			Version:          cfg.Version,
-			GitHubReleaseURL: cfg.GitHubReleaseURL,
-			SealedAt:         p.Sealed.At,
-			Anonymous:        p.Anonymous,
-			RecoveryURL:      cfg.RecoveryURL,
-			Language:         lang,
-		})
-		if err != nil {
-			return fmt.Errorf("generating bundle for %s: %w", friend.Name, err)
-		}
+	return params.OutputPath, nil
+}
 
-		// Verify the bundle we just created
-		if err := VerifyBundle(bundlePath); err != nil {
-			return fmt.Errorf("verifying bundle for %s: %w", friend.Name, err)
+// params builds the BundleParams for friend i, including their personalized
+// recover.html.
+func (c *bundleContext) params(p *project.Project, cfg Config, i int, share *core.Share) BundleParams {
+	friend := p.Friends[i]
+	lang := friendLanguage(p, i)
+
+	// Get other friends (excluding this one) - empty for anonymous mode
+	var otherFriends []project.Friend
+	var otherFriendsInfo []html.FriendInfo
+	if !p.Anonymous {
+		otherFriends = make([]project.Friend, 0, len(p.Friends)-1)
+		otherFriendsInfo = make([]html.FriendInfo, 0, len(p.Friends)-1)
+		for j, f := range p.Friends {
+			if j != i {
+				otherFriends = append(otherFriends, f)
+				otherFriendsInfo = append(otherFriendsInfo, html.FriendInfo{
+					Name:       f.Name,
+					Contact:    f.Contact,
+					ShareIndex: j + 1, // 1-based share index
+				})
+			}
 		}
 	}
 
-	return nil
+	// Generate personalized recover.html for this friend
+	personalization := &html.PersonalizationData{
+		Holder:       friend.Name,
+		HolderShare:  share.Encode(),
+		OtherFriends: otherFriendsInfo,
+		Threshold:    p.Threshold,
+		Total:        len(p.Friends),
+		Language:     lang,
+		Note:         core.SanitizeNote(p.Note),
+		Question:     p.Question,
+		Instructions: core.SanitizeNote(p.Instructions),
+
+		ManifestChecksum: c.manifestChecksum,
+		WordLanguages:    c.wordLangs,
+	}
+
+	// Embed manifest in recover.html when small enough and not disabled
+	manifestEmbedded := !cfg.NoEmbedManifest && len(c.manifestData) <= html.MaxEmbeddedManifestSize
+	if manifestEmbedded {
+		personalization.ManifestB64 = base64.StdEncoding.EncodeToString(c.manifestData)
+	}
+
+	recoverHTML := c.recoverTemplate.Generate(personalization)
+	recoverChecksum := core.HashString(recoverHTML)
+
+	bundlePath := filepath.Join(c.bundlesDir, fmt.Sprintf("bundle-%s.zip", core.SanitizeFilename(friend.Name)))
+
+	return BundleParams{
+		OutputPath:       bundlePath,
+		ProjectName:      p.Name,
+		Friend:           friend,
+		Share:            share,
+		OtherFriends:     otherFriends,
+		Threshold:        p.Threshold,
+		Total:            len(p.Friends),
+		ManifestData:     c.manifestData,
+		ManifestChecksum: c.manifestChecksum,
+		ManifestEmbedded: manifestEmbedded,
+		RecoverHTML:      recoverHTML,
+		RecoverChecksum:  recoverChecksum,
+		WASMChecksum:     c.wasmChecksum,
+		Version:          cfg.Version,
+		GitHubReleaseURL: cfg.GitHubReleaseURL,
+		SealedAt:         p.Sealed.At,
+		Anonymous:        p.Anonymous,
+		RecoveryURL:      cfg.RecoveryURL,
+		Language:         lang,
+		Note:             p.Note,
+		Question:         p.Question,
+		Instructions:     p.Instructions,
+	}
+}
+
+// friendLanguage resolves the language of friend i's bundle: the friend's
+// own, then the project's, then English.
+func friendLanguage(p *project.Project, i int) string {
+	if lang := p.Friends[i].Language; lang != "" {
+		return lang
+	}
+	if p.Language != "" {
+		return p.Language
+	}
+	return "en"
 }
 
 // BundleParams contains all parameters for generating a single bundle.
@@ -151,16 +296,60 @@ type BundleParams struct {
 	ManifestEmbedded bool // true when manifest is base64-embedded in recover.html
 	RecoverHTML      string
 	RecoverChecksum  string
+	WASMChecksum     string // Checksum of the recovery tool embedded in recover.html
 	Version          string
 	GitHubReleaseURL string
 	SealedAt         time.Time
 	Anonymous        bool
 	RecoveryURL      string
 	Language         string // Bundle language for this friend
+	Note             string // Optional message from the project owner
+	Question         string // Optional recovery question
+	Instructions     string // Optional recovery steps from the project owner
 }
 
 // GenerateBundle creates a single bundle ZIP file for one friend.
 func GenerateBundle(params BundleParams) error {
+	files, err := bundleFiles(params)
+	if err != nil {
+		return err
+	}
+	return CreateZip(params.OutputPath, files)
+}
+
+// readmePDF renders the README.pdf of the bundle described by params.
+func readmePDF(params BundleParams) ([]byte, error) {
+	content, err := pdf.GenerateReadme(pdf.ReadmeData{
+		ProjectName:      params.ProjectName,
+		Holder:           params.Friend.Name,
+		Share:            params.Share,
+		OtherFriends:     params.OtherFriends,
+		Threshold:        params.Threshold,
+		Total:            params.Total,
+		Version:          params.Version,
+		GitHubReleaseURL: params.GitHubReleaseURL,
+		ManifestChecksum: params.ManifestChecksum,
+		RecoverChecksum:  params.RecoverChecksum,
+		WASMChecksum:     params.WASMChecksum,
+		AckToken:         GenerateAckToken(params.Share),
+		Created:          params.SealedAt,
+		Anonymous:        params.Anonymous,
+		RecoveryURL:      params.RecoveryURL,
+		Language:         params.Language,
+		ManifestEmbedded: params.ManifestEmbedded,
+		Note:             params.Note,
+		Question:         params.Question,
+		Instructions:     params.Instructions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generating PDF: %w", err)
+	}
+	return content, nil
+}
+
+// bundleFiles renders the files that go in a friend's bundle ZIP.
+// params.OutputPath is not used.
+func bundleFiles(params BundleParams) ([]ZipFile, error) {
 	// Common data for both README formats
 	readmeData := ReadmeData{
 		ProjectName:      params.ProjectName,
@@ -173,35 +362,23 @@ func GenerateBundle(params BundleParams) error {
 		GitHubReleaseURL: params.GitHubReleaseURL,
 		ManifestChecksum: params.ManifestChecksum,
 		RecoverChecksum:  params.RecoverChecksum,
+		WASMChecksum:     params.WASMChecksum,
 		Created:          params.SealedAt,
 		Anonymous:        params.Anonymous,
 		Language:         params.Language,
 		ManifestEmbedded: params.ManifestEmbedded,
+		Note:             params.Note,
+		Question:         params.Question,
+		Instructions:     params.Instructions,
 	}
 
 	// Generate README.txt
 	readmeContent := GenerateReadme(readmeData)
 
 	// Generate README.pdf
-	pdfContent, err := pdf.GenerateReadme(pdf.ReadmeData{
-		ProjectName:      readmeData.ProjectName,
-		Holder:           readmeData.Holder,
-		Share:            readmeData.Share,
-		OtherFriends:     readmeData.OtherFriends,
-		Threshold:        readmeData.Threshold,
-		Total:            params.Total,
-		Version:          readmeData.Version,
-		GitHubReleaseURL: readmeData.GitHubReleaseURL,
-		ManifestChecksum: readmeData.ManifestChecksum,
-		RecoverChecksum:  readmeData.RecoverChecksum,
-		Created:          readmeData.Created,
-		Anonymous:        readmeData.Anonymous,
-		RecoveryURL:      params.RecoveryURL,
-		Language:         params.Language,
-		ManifestEmbedded: params.ManifestEmbedded,
-	})
+	pdfContent, err := readmePDF(params)
 	if err != nil {
-		return fmt.Errorf("generating PDF: %w", err)
+		return nil, err
 	}
 
 	// Create ZIP with all files, using sealed date as modification time.
@@ -218,7 +395,7 @@ func GenerateBundle(params BundleParams) error {
 		files = append(files, ZipFile{Name: "MANIFEST.age", Content: params.ManifestData, ModTime: params.SealedAt})
 	}
 
-	return CreateZip(params.OutputPath, files)
+	return files, nil
 }
 
 // loadShares reads all share files from the project's shares directory.
@@ -247,88 +424,107 @@ func loadShares(p *project.Project) ([]*core.Share, error) {
 	return shares, nil
 }
 
-// VerifyBundle verifies the integrity of a bundle ZIP file.
+// VerifyBundle verifies the integrity of a bundle ZIP file. If a SHA256SUMS
+// file next to it lists the bundle, the whole ZIP is checked against it first.
 // Returns nil if valid, or an error describing the problem.
 func VerifyBundle(bundlePath string) error {
+	if _, err := checkSHA256SUMS(bundlePath); err != nil {
+		return err
+	}
+	return verifyBundleContents(bundlePath)
+}
+
+// verifyBundleContents checks the files inside a bundle against the
+// checksums in its README. SHA256SUMS isn't consulted, since while bundles
+// are being generated it still lists the previous ones.
+func verifyBundleContents(bundlePath string) error {
 	r, err := zip.OpenReader(bundlePath)
 	if err != nil {
 		return fmt.Errorf("opening bundle: %w", err)
 	}
 	defer r.Close()
 
-	// Read files from ZIP
-	var readmeContent string
-	var manifestData []byte
-	var recoverData []byte
-	var pdfData []byte
-
+	// Only the README text is read whole; it holds the checksums for the rest.
+	var readme, pdfFile, manifestFile, recoverFile *zip.File
 	for _, f := range r.File {
-		rc, err := f.Open()
-		if err != nil {
-			return fmt.Errorf("opening %s: %w", f.Name, err)
-		}
-
-		data, err := io.ReadAll(rc)
-		if closeErr := rc.Close(); closeErr != nil && err == nil {
-			err = closeErr
-		}
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", f.Name, err)
-		}
-
 		switch {
 		case translations.IsReadmeFile(f.Name, ".txt"):
-			readmeContent = string(data)
+			readme = f
 		case translations.IsReadmeFile(f.Name, ".pdf"):
-			pdfData = data
+			pdfFile = f
 		case f.Name == "MANIFEST.age":
-			manifestData = data
+			manifestFile = f
 		case f.Name == "recover.html":
-			recoverData = data
+			recoverFile = f
 		}
 	}
 
-	if readmeContent == "" {
+	if readme == nil || readme.UncompressedSize64 == 0 {
 		return fmt.Errorf("README file (.txt) not found in bundle")
 	}
-	if len(pdfData) == 0 {
+	if pdfFile == nil || pdfFile.UncompressedSize64 == 0 {
 		return fmt.Errorf("README file (.pdf) not found in bundle")
 	}
-	if len(recoverData) == 0 {
+	if recoverFile == nil || recoverFile.UncompressedSize64 == 0 {
 		return fmt.Errorf("recover.html not found in bundle")
 	}
 
-	// When MANIFEST.age is not in the ZIP, the manifest is embedded in recover.html.
-	// Extract it from there for checksum verification.
-	if len(manifestData) == 0 {
-		extracted, err := html.ExtractManifestFromHTML(recoverData)
-		if err != nil {
-			return fmt.Errorf("MANIFEST.age not in bundle and could not extract from recover.html: %w", err)
-		}
-		manifestData = extracted
+	readmeData, err := readZipFile(readme)
+	if err != nil {
+		return err
 	}
+	readmeContent := string(readmeData)
 
 	// Parse metadata from footer
 	metadata := parseMetadataFooter(readmeContent)
-
-	// Verify manifest checksum
-	actualManifestChecksum := core.HashBytes(manifestData)
 	expectedManifestChecksum := metadata["checksum-manifest"]
 	if expectedManifestChecksum == "" {
 		return fmt.Errorf("manifest checksum not found in README metadata")
 	}
-	if actualManifestChecksum != expectedManifestChecksum {
-		return fmt.Errorf("MANIFEST.age checksum mismatch")
-	}
-
-	// Verify recover.html checksum
-	actualRecoverChecksum := core.HashString(string(recoverData))
 	expectedRecoverChecksum := metadata["checksum-recover-html"]
 	if expectedRecoverChecksum == "" {
 		return fmt.Errorf("recover.html checksum not found in README metadata")
 	}
-	if actualRecoverChecksum != expectedRecoverChecksum {
-		return fmt.Errorf("recover.html checksum mismatch")
+
+	// Verify recover.html checksum. When MANIFEST.age is not in the ZIP, the
+	// manifest is embedded in recover.html, and newer bundles also record the
+	// checksum of the embedded recovery tool, so keep a copy to check those.
+	expectedWASMChecksum := metadata["checksum-recover-wasm"]
+	var recoverData bytes.Buffer
+	var recoverSink io.Writer = io.Discard
+	if manifestFile == nil || expectedWASMChecksum != "" {
+		recoverSink = &recoverData
+	}
+	if err := verifyZipFile(recoverFile, expectedRecoverChecksum, recoverSink); err != nil {
+		return err
+	}
+
+	// Verify the recovery tool inside recover.html on its own, so a page
+	// with a swapped-out tool is caught even if its overall checksum was
+	// rewritten to match.
+	if expectedWASMChecksum != "" {
+		wasmData, err := html.ExtractWASMFromHTML(recoverData.Bytes())
+		if err != nil {
+			return fmt.Errorf("reading the recovery tool in recover.html: %w", err)
+		}
+		if !core.VerifyHash(core.HashBytes(wasmData), expectedWASMChecksum) {
+			return fmt.Errorf("recover.html WASM checksum mismatch")
+		}
+	}
+
+	// Verify manifest checksum
+	if manifestFile != nil {
+		if err := verifyZipFile(manifestFile, expectedManifestChecksum, io.Discard); err != nil {
+			return err
+		}
+	} else {
+		manifestData, err := html.ExtractManifestFromHTML(recoverData.Bytes())
+		if err != nil {
+			return fmt.Errorf("MANIFEST.age not in bundle and could not extract from recover.html: %w", err)
+		}
+		if !core.VerifyHash(core.HashBytes(manifestData), expectedManifestChecksum) {
+			return fmt.Errorf("MANIFEST.age checksum mismatch")
+		}
 	}
 
 	// Verify embedded share
@@ -344,6 +540,27 @@ func VerifyBundle(bundlePath string) error {
 	return nil
 }
 
+// RecordedManifestChecksum returns the MANIFEST.age checksum written in the
+// README .txt file in dir, as in an unzipped bundle, or "" when dir has no
+// README or its footer has no checksum.
+func RecordedManifestChecksum(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() || !translations.IsReadmeFile(e.Name(), ".txt") {
+			continue
+		}
+		readme, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return ""
+		}
+		return parseMetadataFooter(string(readme))["checksum-manifest"]
+	}
+	return ""
+}
+
 // parseMetadataFooter extracts key-value pairs from the README.txt footer section.
 func parseMetadataFooter(content string) map[string]string {
 	metadata := make(map[string]string)
@@ -0,0 +1,97 @@
+package bundle
+
+import (
+	"sort"
+	"strconv"
+)
+
+// BundleDiff describes how two bundles differ, for reviewing regenerated
+// bundles. Share data is never compared: it changes on every seal by design.
+// Whether both pieces come from the same set is told by the fingerprint.
+type BundleDiff struct {
+	// Identical is true when nothing below differs.
+	Identical bool `json:"identical"`
+
+	FingerprintA    string `json:"fingerprint_a,omitempty"`
+	FingerprintB    string `json:"fingerprint_b,omitempty"`
+	SameFingerprint bool   `json:"same_fingerprint"`
+
+	Changes []FieldChange `json:"changes"`
+	Files   []FileChange  `json:"files"`
+}
+
+// FieldChange is one piece of bundle or share metadata that differs.
+type FieldChange struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// FileChange is a file that was added, removed or changed between bundles.
+type FileChange struct {
+	Name   string `json:"name"`
+	Change string `json:"change"` // "added", "removed" or "changed"
+	SizeA  int    `json:"size_a,omitempty"`
+	SizeB  int    `json:"size_b,omitempty"`
+}
+
+// DiffBundles compares two bundle descriptions from ExportBundle. Fields and
+// files are listed in a fixed order, so the result is stable.
+func DiffBundles(a, b *BundleExport) *BundleDiff {
+	d := &BundleDiff{
+		FingerprintA:    a.Share.Fingerprint,
+		FingerprintB:    b.Share.Fingerprint,
+		SameFingerprint: a.Share.Fingerprint != "" && a.Share.Fingerprint == b.Share.Fingerprint,
+		Changes:         []FieldChange{},
+		Files:           []FileChange{},
+	}
+
+	fields := []struct {
+		name string
+		a, b string
+	}{
+		{"holder", a.Holder, b.Holder},
+		{"project", a.Metadata.Project, b.Metadata.Project},
+		{"rememory_version", a.Metadata.RememoryVersion, b.Metadata.RememoryVersion},
+		{"created", a.Metadata.Created, b.Metadata.Created},
+		{"threshold", strconv.Itoa(a.Metadata.Threshold), strconv.Itoa(b.Metadata.Threshold)},
+		{"total", strconv.Itoa(a.Metadata.Total), strconv.Itoa(b.Metadata.Total)},
+		{"share_version", strconv.Itoa(a.Share.Version), strconv.Itoa(b.Share.Version)},
+		{"share_index", strconv.Itoa(a.Share.Index), strconv.Itoa(b.Share.Index)},
+		{"checksum_manifest", a.Metadata.ManifestChecksum, b.Metadata.ManifestChecksum},
+		{"checksum_recover_html", a.Metadata.RecoverHTMLChecksum, b.Metadata.RecoverHTMLChecksum},
+		{"checksum_recover_wasm", a.Metadata.RecoverWASMChecksum, b.Metadata.RecoverWASMChecksum},
+	}
+	for _, f := range fields {
+		if f.a != f.b {
+			d.Changes = append(d.Changes, FieldChange{Field: f.name, A: f.a, B: f.b})
+		}
+	}
+
+	filesA := make(map[string]ExportFile, len(a.Files))
+	for _, f := range a.Files {
+		filesA[f.Name] = f
+	}
+	filesB := make(map[string]ExportFile, len(b.Files))
+	for _, f := range b.Files {
+		filesB[f.Name] = f
+	}
+	for name, fa := range filesA {
+		fb, ok := filesB[name]
+		switch {
+		case !ok:
+			d.Files = append(d.Files, FileChange{Name: name, Change: "removed", SizeA: fa.Size})
+		case fa.Checksum != fb.Checksum:
+			d.Files = append(d.Files, FileChange{Name: name, Change: "changed", SizeA: fa.Size, SizeB: fb.Size})
+		}
+	}
+	for name, fb := range filesB {
+		if _, ok := filesA[name]; !ok {
+			d.Files = append(d.Files, FileChange{Name: name, Change: "added", SizeB: fb.Size})
+		}
+	}
+	sort.Slice(d.Files, func(i, j int) bool { return d.Files[i].Name < d.Files[j].Name })
+
+	d.Identical = len(d.Changes) == 0 && len(d.Files) == 0 && d.FingerprintA == d.FingerprintB
+	return d
+}
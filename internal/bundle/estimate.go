@@ -0,0 +1,160 @@
+package bundle
+
+import (
+	"compress/flate"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// SizeEstimate is the expected output of sealing a project, worked out
+// without encrypting or splitting anything.
+type SizeEstimate struct {
+	ManifestSize     int64            `json:"manifest_size"`     // MANIFEST.age
+	ManifestEmbedded bool             `json:"manifest_embedded"` // true when recover.html carries the manifest
+	Bundles          []BundleEstimate `json:"bundles"`
+	Total            int64            `json:"total"` // MANIFEST.age plus every bundle
+}
+
+// BundleEstimate is the expected size of one friend's bundle ZIP.
+type BundleEstimate struct {
+	Friend   string `json:"friend"`
+	Language string `json:"language"`
+	Size     int64  `json:"size"`
+}
+
+// EstimateSizes works out how big the bundles for p will be when its
+// manifest encrypts to manifestSize bytes (see core.EncryptedSize). Each
+// bundle is rendered for real, README.pdf and recover.html included, but
+// with a stand-in share and random bytes in place of the manifest, which
+// compress the same as ciphertext. p doesn't need to be sealed.
+//
+// A manifest too big to embed isn't rendered: its stored size is added to
+// each bundle instead, so estimating a large project stays cheap.
+func EstimateSizes(p *project.Project, cfg Config, manifestSize int64) (*SizeEstimate, error) {
+	if len(p.Friends) == 0 {
+		return nil, fmt.Errorf("project has no friends")
+	}
+
+	// Work on a copy so p's seal information is left alone.
+	sealed := *p
+	sealed.Sealed = &project.Sealed{At: time.Now().UTC()}
+
+	embedded := !cfg.NoEmbedManifest && manifestSize <= html.MaxEmbeddedManifestSize
+	// Settle it for params too, whose stand-in manifest may be empty.
+	cfg.NoEmbedManifest = !embedded
+	var manifestData []byte
+	if embedded {
+		manifestData = make([]byte, manifestSize)
+		if _, err := rand.Read(manifestData); err != nil {
+			return nil, fmt.Errorf("generating stand-in manifest: %w", err)
+		}
+	}
+
+	// A commitment to a stand-in secret is as long as the real one.
+	commitment, err := core.NewCommitment(make([]byte, 32))
+	if err != nil {
+		return nil, err
+	}
+
+	c := newContext(&sealed, cfg, "", manifestData)
+	est := &SizeEstimate{
+		ManifestSize:     manifestSize,
+		ManifestEmbedded: embedded,
+		Bundles:          make([]BundleEstimate, len(p.Friends)),
+		Total:            manifestSize,
+	}
+	standIn := func(i int) (*core.Share, error) {
+		shareData := make([]byte, 33) // a 32-byte passphrase plus Vault's x-coordinate
+		if _, err := rand.Read(shareData); err != nil {
+			return nil, fmt.Errorf("generating stand-in share: %w", err)
+		}
+		share := core.NewShare(2, i+1, len(p.Friends), p.Threshold, p.Friends[i].Name, shareData)
+		share.Created = sealed.Sealed.At
+		share.Headers = map[string]string{core.CommitmentHeader: commitment}
+		return share, nil
+	}
+	for i, friend := range p.Friends {
+		share, err := standIn(i)
+		if err != nil {
+			return nil, err
+		}
+
+		params := c.params(&sealed, cfg, i, share)
+		files, err := bundleFiles(params)
+		if err != nil {
+			return nil, fmt.Errorf("estimating bundle for %s: %w", friend.Name, err)
+		}
+		var n countingWriter
+		if err := writeZip(&n, files); err != nil {
+			return nil, fmt.Errorf("estimating bundle for %s: %w", friend.Name, err)
+		}
+		size := int64(n)
+
+		// README.pdf changes size with the piece: its words decide which
+		// glyphs are embedded, and its QR code compresses more or less
+		// well. Average it over a few stand-in pieces, so one unusual draw
+		// doesn't skew the estimate.
+		pdfName := translations.ReadmeFilename(params.Language, ".pdf")
+		var first, sum int64
+		for _, f := range files {
+			if f.Name == pdfName {
+				first = deflatedSize(f.Content)
+			}
+		}
+		sum = first
+		for range readmePDFSamples - 1 {
+			if params.Share, err = standIn(i); err != nil {
+				return nil, err
+			}
+			content, err := readmePDF(params)
+			if err != nil {
+				return nil, fmt.Errorf("estimating bundle for %s: %w", friend.Name, err)
+			}
+			sum += deflatedSize(content)
+		}
+		size += sum/readmePDFSamples - first
+
+		if !embedded {
+			size += storedSize(manifestSize)
+		}
+
+		est.Bundles[i] = BundleEstimate{Friend: friend.Name, Language: params.Language, Size: size}
+		est.Total += size
+	}
+	return est, nil
+}
+
+// readmePDFSamples is how many stand-in pieces EstimateSizes renders each
+// README.pdf with.
+const readmePDFSamples = 4
+
+// deflatedSize is how many bytes b takes up compressed in a bundle ZIP.
+func deflatedSize(b []byte) int64 {
+	var n countingWriter
+	w, _ := flate.NewWriter(&n, flate.DefaultCompression) // only fails for a bad level
+	w.Write(b)
+	w.Close()
+	return int64(n)
+}
+
+// storedSize is roughly how many bytes n bytes of ciphertext take up in a
+// ZIP. They don't compress, so deflate falls back to stored blocks of up to
+// 65535 bytes with a 5-byte header each.
+func storedSize(n int64) int64 {
+	const block = 65535
+	return n + (n/block+1)*5
+}
+
+// countingWriter counts the bytes written to it and discards them.
+type countingWriter int64
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	*w += countingWriter(len(b))
+	return len(b), nil
+}
@@ -0,0 +1,113 @@
+package bundle
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// BundleExport is a machine-readable description of a bundle ZIP.
+// It never contains decrypted secrets: MANIFEST.age is described only by its
+// checksum, and the only share included is the holder's own.
+type BundleExport struct {
+	Holder   string         `json:"holder"`
+	Metadata ExportMetadata `json:"metadata"`
+	Files    []ExportFile   `json:"files"`
+	Share    ExportShare    `json:"share"`
+}
+
+// ExportMetadata mirrors the README.txt metadata footer.
+type ExportMetadata struct {
+	RememoryVersion     string `json:"rememory_version"`
+	Created             string `json:"created"`
+	Project             string `json:"project"`
+	Threshold           int    `json:"threshold"`
+	Total               int    `json:"total"`
+	GitHubRelease       string `json:"github_release,omitempty"`
+	ManifestChecksum    string `json:"checksum_manifest"`
+	RecoverHTMLChecksum string `json:"checksum_recover_html"`
+	RecoverWASMChecksum string `json:"checksum_recover_wasm,omitempty"`
+}
+
+// ExportFile describes one file in the bundle.
+type ExportFile struct {
+	Name     string `json:"name"`
+	Size     int    `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// ExportShare is the holder's share in both machine-readable encodings.
+type ExportShare struct {
+	Version     int    `json:"version"`
+	Index       int    `json:"index"`
+	Total       int    `json:"total"`
+	Threshold   int    `json:"threshold"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Checksum    string `json:"checksum"`
+	Data        string `json:"data"` // standard base64
+	Compact     string `json:"compact"`
+}
+
+// ExportBundle reads a bundle ZIP and describes it without decrypting anything.
+func ExportBundle(path string) (*BundleExport, error) {
+	files, err := ReadZip(path)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &BundleExport{}
+	var readmeContent string
+	for _, f := range files {
+		export.Files = append(export.Files, ExportFile{
+			Name:     f.Name,
+			Size:     len(f.Content),
+			Checksum: core.HashBytes(f.Content),
+		})
+		if translations.IsReadmeFile(f.Name, ".txt") {
+			readmeContent = string(f.Content)
+		}
+	}
+	if readmeContent == "" {
+		return nil, fmt.Errorf("README file (.txt) not found in bundle")
+	}
+
+	metadata := parseMetadataFooter(readmeContent)
+	threshold, _ := strconv.Atoi(metadata["threshold"])
+	total, _ := strconv.Atoi(metadata["total"])
+	export.Metadata = ExportMetadata{
+		RememoryVersion:     metadata["rememory-version"],
+		Created:             metadata["created"],
+		Project:             metadata["project"],
+		Threshold:           threshold,
+		Total:               total,
+		GitHubRelease:       metadata["github-release"],
+		ManifestChecksum:    metadata["checksum-manifest"],
+		RecoverHTMLChecksum: metadata["checksum-recover-html"],
+		RecoverWASMChecksum: metadata["checksum-recover-wasm"],
+	}
+
+	share, err := core.ParseShare([]byte(readmeContent))
+	if err != nil {
+		return nil, fmt.Errorf("parsing share: %w", err)
+	}
+	if err := share.Verify(); err != nil {
+		return nil, fmt.Errorf("share verification failed: %w", err)
+	}
+
+	export.Holder = share.Holder
+	export.Share = ExportShare{
+		Version:     share.Version,
+		Index:       share.Index,
+		Total:       share.Total,
+		Threshold:   share.Threshold,
+		Fingerprint: share.Fingerprint(),
+		Checksum:    share.Checksum,
+		Data:        base64.StdEncoding.EncodeToString(share.Data),
+		Compact:     share.CompactEncode(),
+	}
+
+	return export, nil
+}
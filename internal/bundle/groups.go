@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"strings"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// RecoveryGroups lists every smallest group of friends that can recover
+// together: each combination of threshold names, in project order. There are
+// C(len(friends), threshold) of them. Returns nil if threshold is out of range.
+func RecoveryGroups(friends []project.Friend, threshold int) [][]string {
+	n := len(friends)
+	if threshold < 1 || threshold > n {
+		return nil
+	}
+
+	var groups [][]string
+	idx := make([]int, threshold)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		group := make([]string, threshold)
+		for i, j := range idx {
+			group[i] = friends[j].Name
+		}
+		groups = append(groups, group)
+
+		// Advance to the next combination in lexicographic order.
+		i := threshold - 1
+		for i >= 0 && idx[i] == n-threshold+i {
+			i--
+		}
+		if i < 0 {
+			return groups
+		}
+		idx[i]++
+		for j := i + 1; j < threshold; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// ContactOverlap is a contact that several holders in one group have in
+// common, such as a shared email domain or the same postal address. Those
+// holders may not be as independent as the threshold assumes: one household
+// or one employer could account for several pieces.
+type ContactOverlap struct {
+	Contact string   // The shared email domain or address
+	Names   []string // The holders in the group who share it
+}
+
+// SharedContacts reports the contacts that more than one member of group
+// shares. group holds friend names, as returned by RecoveryGroups. Emails are
+// compared by domain, ignoring large public providers; anything else is
+// compared as a whole, ignoring case and spacing.
+func SharedContacts(friends []project.Friend, group []string) []ContactOverlap {
+	byName := make(map[string]project.Friend, len(friends))
+	for _, f := range friends {
+		byName[f.Name] = f
+	}
+
+	var overlaps []ContactOverlap
+	index := make(map[string]int)
+	for _, name := range group {
+		key := contactKey(byName[name].Contact)
+		if key == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			overlaps[i].Names = append(overlaps[i].Names, name)
+			continue
+		}
+		index[key] = len(overlaps)
+		overlaps = append(overlaps, ContactOverlap{Contact: key, Names: []string{name}})
+	}
+
+	shared := overlaps[:0]
+	for _, o := range overlaps {
+		if len(o.Names) > 1 {
+			shared = append(shared, o)
+		}
+	}
+	return shared
+}
+
+// publicMailDomains are email providers shared by too many people to say
+// anything about whether two holders are connected.
+var publicMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true,
+	"outlook.com": true, "hotmail.com": true, "live.com": true,
+	"yahoo.com": true, "icloud.com": true, "me.com": true,
+	"proton.me": true, "protonmail.com": true,
+	"gmx.de": true, "gmx.net": true, "web.de": true,
+}
+
+// contactKey reduces a contact to what two connected holders would have in
+// common, or "" if it says nothing useful.
+func contactKey(contact string) string {
+	contact = strings.ToLower(strings.Join(strings.Fields(contact), " "))
+	if contact == "" {
+		return ""
+	}
+	if _, domain, ok := strings.Cut(contact, "@"); ok && !strings.Contains(contact, " ") {
+		if domain == "" || publicMailDomains[domain] {
+			return ""
+		}
+		return domain
+	}
+	return contact
+}
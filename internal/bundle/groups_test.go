@@ -0,0 +1,98 @@
+package bundle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+func friendsNamed(names ...string) []project.Friend {
+	friends := make([]project.Friend, len(names))
+	for i, n := range names {
+		friends[i] = project.Friend{Name: n}
+	}
+	return friends
+}
+
+func TestRecoveryGroups(t *testing.T) {
+	five := friendsNamed("Alice", "Bob", "Carol", "David", "Eve")
+
+	tests := []struct {
+		friends   []project.Friend
+		threshold int
+		want      int // C(n, k)
+	}{
+		{five, 3, 10},
+		{five, 1, 5},
+		{five, 5, 1},
+		{friendsNamed("A", "B", "C", "D", "E", "F"), 2, 15},
+		{friendsNamed("A", "B", "C", "D", "E", "F", "G", "H", "I", "J"), 5, 252},
+		{five, 0, 0},
+		{five, 6, 0},
+	}
+	for _, tt := range tests {
+		groups := RecoveryGroups(tt.friends, tt.threshold)
+		if len(groups) != tt.want {
+			t.Errorf("C(%d,%d): got %d groups, want %d", len(tt.friends), tt.threshold, len(groups), tt.want)
+		}
+
+		seen := make(map[string]bool)
+		for _, g := range groups {
+			if len(g) != tt.threshold {
+				t.Errorf("group %v has %d members, want %d", g, len(g), tt.threshold)
+			}
+			key := ""
+			for _, name := range g {
+				key += name + ","
+			}
+			if seen[key] {
+				t.Errorf("group %v listed twice", g)
+			}
+			seen[key] = true
+		}
+	}
+
+	got := RecoveryGroups(five, 4)
+	want := [][]string{
+		{"Alice", "Bob", "Carol", "David"},
+		{"Alice", "Bob", "Carol", "Eve"},
+		{"Alice", "Bob", "David", "Eve"},
+		{"Alice", "Carol", "David", "Eve"},
+		{"Bob", "Carol", "David", "Eve"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RecoveryGroups(5, 4) = %v, want %v", got, want)
+	}
+}
+
+func TestSharedContacts(t *testing.T) {
+	friends := []project.Friend{
+		{Name: "Alice", Contact: "alice@smith.family"},
+		{Name: "Bob", Contact: "Bob@Smith.Family"},
+		{Name: "Carol", Contact: "carol@gmail.com"},
+		{Name: "David", Contact: "david@gmail.com"},
+		{Name: "Eve", Contact: "12 Oak Street,  Springfield"},
+		{Name: "Frank", Contact: "12 oak street, springfield"},
+		{Name: "Grace"},
+	}
+
+	tests := []struct {
+		group []string
+		want  []ContactOverlap
+	}{
+		{[]string{"Alice", "Bob", "Carol"}, []ContactOverlap{{Contact: "smith.family", Names: []string{"Alice", "Bob"}}}},
+		{[]string{"Carol", "David", "Grace"}, nil},
+		{[]string{"Alice", "Eve", "Frank"}, []ContactOverlap{{Contact: "12 oak street, springfield", Names: []string{"Eve", "Frank"}}}},
+		{[]string{"Alice", "Carol", "Eve"}, nil},
+	}
+	for _, tt := range tests {
+		got := SharedContacts(friends, tt.group)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SharedContacts(%v) = %+v, want %+v", tt.group, got, tt.want)
+		}
+	}
+}
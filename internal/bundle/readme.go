@@ -1,9 +1,12 @@
 package bundle
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/text/unicode/norm"
 
@@ -24,10 +27,103 @@ type ReadmeData struct {
 	GitHubReleaseURL string
 	ManifestChecksum string
 	RecoverChecksum  string
+	WASMChecksum     string // Checksum of the recovery tool embedded in recover.html
 	Created          time.Time
 	Anonymous        bool
 	Language         string // Bundle language (e.g. "en", "es"); defaults to "en"
 	ManifestEmbedded bool   // true when manifest is embedded in recover.html
+	Note             string // Optional message from the project owner
+	Question         string // Optional recovery question whose answer unlocks along with the pieces
+	Instructions     string // Optional recovery steps from the project owner, wrapped to 80 columns
+	FriendsLayout    FriendsLayout
+}
+
+// FriendsLayout is how README.txt lists the other share holders.
+type FriendsLayout int
+
+const (
+	// FriendsAuto uses FriendsList, or FriendsTable from
+	// FriendsTableThreshold other holders up.
+	FriendsAuto FriendsLayout = iota
+	// FriendsList gives each holder a block: the name, then the contact
+	// on its own line, written out in full.
+	FriendsList
+	// FriendsTable gives each holder one line, with the contacts lined up
+	// in a column and cut short to keep the line within 80 columns.
+	FriendsTable
+)
+
+// FriendsTableThreshold is how many other holders FriendsAuto lists as a
+// table rather than as blocks.
+const FriendsTableThreshold = 10
+
+// maxNameColumn is the widest the name column of FriendsTable gets; longer
+// names are cut short.
+const maxNameColumn = 24
+
+// readmeWidth is the width of README.txt, the length of its rule lines.
+const readmeWidth = 80
+
+// wrapText wraps each line of text at width columns, breaking between
+// words. Line breaks already in the text are kept, and lines that wrap are
+// continued at the same indent, so lists stay lined up. A word longer than
+// the line is left whole.
+func wrapText(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+		words := strings.Fields(trimmed)
+		if len(words) == 0 {
+			lines[i] = ""
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(indent + words[0])
+		col := utf8.RuneCountInString(indent + words[0])
+		for _, w := range words[1:] {
+			n := utf8.RuneCountInString(w)
+			if col+1+n > width {
+				b.WriteString("\n" + indent + w)
+				col = utf8.RuneCountInString(indent) + n
+				continue
+			}
+			b.WriteString(" " + w)
+			col += 1 + n
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeFriendsTable writes one line per friend, name then contact, with the
+// contacts lined up and both cut short so no line passes readmeWidth.
+func writeFriendsTable(sb *strings.Builder, friends []project.Friend) {
+	nameWidth := 0
+	for _, f := range friends {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(f.Name))
+	}
+	nameWidth = min(nameWidth, maxNameColumn)
+	contactWidth := readmeWidth - nameWidth - 4
+	for _, f := range friends {
+		name := truncateText(f.Name, nameWidth)
+		line := "  " + name
+		if f.Contact != "" {
+			line += strings.Repeat(" ", nameWidth-utf8.RuneCountInString(name)+2) + truncateText(f.Contact, contactWidth)
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+}
+
+// truncateText cuts s to at most width characters, ending it with "…" when
+// anything was cut.
+func truncateText(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
 }
 
 // writeWordGrid writes a two-column word grid to the string builder.
@@ -64,13 +160,25 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString(fmt.Sprintf("                              %s\n", t("for", data.Holder)))
 	sb.WriteString("================================================================================\n\n")
 
+	// Note from the project owner
+	if note := core.SanitizeNote(data.Note); note != "" {
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("%s\n", t("note_title")))
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(note + "\n\n")
+	}
+
 	// What is this
 	sb.WriteString("--------------------------------------------------------------------------------\n")
 	sb.WriteString(fmt.Sprintf("%s\n", t("what_is_this")))
 	sb.WriteString("--------------------------------------------------------------------------------\n")
 	sb.WriteString(fmt.Sprintf("%s\n", t("what_bundle_for", data.ProjectName)))
 	sb.WriteString(fmt.Sprintf("%s\n", t("what_one_of", data.Total)))
-	sb.WriteString(fmt.Sprintf("%s\n\n", t("what_threshold", data.Threshold)))
+	sb.WriteString(fmt.Sprintf("%s\n", t("what_threshold", data.Threshold)))
+	if data.Question != "" {
+		sb.WriteString(fmt.Sprintf("%s\n", t("what_question", data.Question)))
+	}
+	sb.WriteString("\n")
 
 	// Warning
 	sb.WriteString(fmt.Sprintf("!!  %s\n", t("warning_title")))
@@ -80,17 +188,36 @@ func GenerateReadme(data ReadmeData) string {
 		sb.WriteString(fmt.Sprintf("    %s\n\n", t("warning_message_friends")))
 	}
 
+	// Recovery steps from the project owner
+	if instructions := core.SanitizeNote(data.Instructions); instructions != "" {
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("%s\n", t("instructions_title")))
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(wrapText(instructions, readmeWidth) + "\n\n")
+	}
+
 	// Other share holders (skip for anonymous mode)
 	if !data.Anonymous {
 		sb.WriteString("--------------------------------------------------------------------------------\n")
 		sb.WriteString(fmt.Sprintf("%s\n", t("other_holders")))
 		sb.WriteString("--------------------------------------------------------------------------------\n")
-		for _, friend := range data.OtherFriends {
-			sb.WriteString(fmt.Sprintf("%s\n", friend.Name))
-			if friend.Contact != "" {
-				sb.WriteString(fmt.Sprintf("  %s\n", t("contact_label", friend.Contact)))
+		layout := data.FriendsLayout
+		if layout == FriendsAuto {
+			layout = FriendsList
+			if len(data.OtherFriends) >= FriendsTableThreshold {
+				layout = FriendsTable
+			}
+		}
+		if layout == FriendsTable {
+			writeFriendsTable(&sb, data.OtherFriends)
+		} else {
+			for _, friend := range data.OtherFriends {
+				sb.WriteString(fmt.Sprintf("%s\n", friend.Name))
+				if friend.Contact != "" {
+					sb.WriteString(fmt.Sprintf("  %s\n", t("contact_label", friend.Contact)))
+				}
+				sb.WriteString("\n")
 			}
-			sb.WriteString("\n")
 		}
 	}
 
@@ -137,17 +264,23 @@ func GenerateReadme(data ReadmeData) string {
 	}
 	sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_offline")))
 
-	// Fallback method - CLI
-	sb.WriteString("--------------------------------------------------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("%s\n", t("recover_cli")))
-	sb.WriteString("--------------------------------------------------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("%s\n", t("recover_cli_hint")))
-	sb.WriteString(fmt.Sprintf("%s\n\n", data.GitHubReleaseURL))
-	sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_cli_usage")))
+	// Fallback method - CLI, when there is somewhere to download it
+	if data.GitHubReleaseURL != "" {
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("%s\n", t("recover_cli")))
+		sb.WriteString("--------------------------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("%s\n", t("recover_cli_hint")))
+		sb.WriteString(fmt.Sprintf("%s\n\n", data.GitHubReleaseURL))
+		sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_cli_usage")))
+	}
 
 	// Share block
 	sb.WriteString("--------------------------------------------------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("%s\n", t("your_share")))
+	if data.Share.Label != "" {
+		sb.WriteString(fmt.Sprintf("%s (%s)\n", t("your_share"), data.Share.Label))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s\n", t("your_share")))
+	}
 	sb.WriteString("--------------------------------------------------------------------------------\n")
 
 	// Word list (primary human-readable format)
@@ -183,14 +316,41 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString("METADATA FOOTER (machine-parseable)\n")
 	sb.WriteString("================================================================================\n")
 	sb.WriteString(fmt.Sprintf("rememory-version: %s\n", data.Version))
+	if requires := data.Share.Headers[core.RequiresVersionHeader]; requires != "" {
+		sb.WriteString(fmt.Sprintf("requires-version: %s\n", requires))
+	}
 	sb.WriteString(fmt.Sprintf("created: %s\n", data.Created.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("project: %s\n", data.ProjectName))
 	sb.WriteString(fmt.Sprintf("threshold: %d\n", data.Threshold))
 	sb.WriteString(fmt.Sprintf("total: %d\n", data.Total))
-	sb.WriteString(fmt.Sprintf("github-release: %s\n", data.GitHubReleaseURL))
+	if data.GitHubReleaseURL != "" {
+		sb.WriteString(fmt.Sprintf("github-release: %s\n", data.GitHubReleaseURL))
+	}
 	sb.WriteString(fmt.Sprintf("checksum-manifest: %s\n", data.ManifestChecksum))
 	sb.WriteString(fmt.Sprintf("checksum-recover-html: %s\n", data.RecoverChecksum))
+	if data.WASMChecksum != "" {
+		sb.WriteString(fmt.Sprintf("checksum-recover-wasm: %s\n", data.WASMChecksum))
+	}
+	sb.WriteString(fmt.Sprintf("confirmation-code: %s\n", GenerateAckToken(data.Share)))
 	sb.WriteString("================================================================================\n")
 
 	return sb.String()
 }
+
+// ReadmeShareMatches reports whether the share block in readme, the text of
+// a README.txt, is the same piece as share: a loose SHARE file kept next to
+// a bundle, say, or one typed in from words. Only the share data is
+// compared, so a copy with a different holder, label or extra headers
+// still matches, while a piece swapped with another friend's doesn't. It
+// returns an error if readme has no readable share block or share has no
+// data.
+func ReadmeShareMatches(readme string, share *core.Share) (bool, error) {
+	if share == nil || len(share.Data) == 0 {
+		return false, errors.New("share has no data")
+	}
+	inReadme, err := core.ParseShare([]byte(readme))
+	if err != nil {
+		return false, fmt.Errorf("reading share from README: %w", err)
+	}
+	return subtle.ConstantTimeCompare(inReadme.Data, share.Data) == 1, nil
+}
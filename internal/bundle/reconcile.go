@@ -0,0 +1,239 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// Reconciliation sorts the pieces found in a directory into the sets they
+// came from, for when a project was sealed more than once and pieces from
+// both seals are in circulation. Pieces are only read and counted, never
+// combined.
+type Reconciliation struct {
+	Groups []PieceGroup `json:"groups"`
+
+	// Keep is the index in Groups of the set to keep, or -1 when there are
+	// no groups. KeepReason says why it was picked.
+	Keep       int    `json:"keep"`
+	KeepReason string `json:"keep_reason,omitempty"`
+
+	// Unsorted lists files holding a piece that can't be placed in a set,
+	// such as compact strings or words, which carry no creation time.
+	Unsorted []string `json:"unsorted,omitempty"`
+
+	// Unreadable lists files that look like a piece or a bundle but could
+	// not be read, with the reason.
+	Unreadable []UnreadableFile `json:"unreadable,omitempty"`
+}
+
+// PieceGroup is the pieces found from one seal.
+type PieceGroup struct {
+	// Label names the set in reports, and is different for every set
+	// found. It is the fingerprint, with the start of a hash of the
+	// pieces' commitment added when another set has the same fingerprint.
+	Label       string    `json:"label"`
+	Fingerprint string    `json:"fingerprint"`
+	Created     time.Time `json:"created"`
+	Threshold   int       `json:"threshold"`
+	Total       int       `json:"total"`
+
+	// Holders are the distinct holders found, in piece order.
+	Holders []string `json:"holders"`
+	// Pieces counts distinct pieces; copies of the same piece count once.
+	Pieces int `json:"pieces"`
+	// Recoverable is true when Pieces reaches Threshold.
+	Recoverable bool `json:"recoverable"`
+
+	Files []string `json:"files"`
+
+	commitment string
+}
+
+// UnreadableFile is a file Reconcile couldn't read a piece from.
+type UnreadableFile struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// Reconcile reads every bundle ZIP and piece file under dir and groups the
+// pieces by the seal they came from: by fingerprint, and by commitment when
+// the pieces carry one, so two seals in the same minute still come apart.
+// It recommends keeping the newest set that can be recovered, or, when none
+// can, the one closest to its threshold.
+func Reconcile(dir string) (*Reconciliation, error) {
+	type groupKey struct{ fingerprint, commitment string }
+	r := &Reconciliation{Groups: []PieceGroup{}, Keep: -1}
+	groups := make(map[groupKey]*PieceGroup)
+	indices := make(map[groupKey]map[int]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		share, err := readPieceFile(path)
+		if err != nil {
+			r.Unreadable = append(r.Unreadable, UnreadableFile{File: path, Error: err.Error()})
+			return nil
+		}
+		if share == nil {
+			return nil
+		}
+		defer share.Zeroize()
+
+		fingerprint := share.Fingerprint()
+		if fingerprint == "" {
+			r.Unsorted = append(r.Unsorted, path)
+			return nil
+		}
+		key := groupKey{fingerprint, share.Headers[core.CommitmentHeader]}
+		g := groups[key]
+		if g == nil {
+			g = &PieceGroup{
+				Fingerprint: fingerprint,
+				Created:     share.Created,
+				Threshold:   share.Threshold,
+				Total:       share.Total,
+				commitment:  key.commitment,
+			}
+			groups[key] = g
+			indices[key] = make(map[int]string)
+		}
+		g.Files = append(g.Files, path)
+		if _, ok := indices[key][share.Index]; !ok {
+			indices[key][share.Index] = share.Holder
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	for key, g := range groups {
+		pieces := make([]int, 0, len(indices[key]))
+		for index := range indices[key] {
+			pieces = append(pieces, index)
+		}
+		sort.Ints(pieces)
+		g.Holders = []string{}
+		for _, index := range pieces {
+			if holder := indices[key][index]; holder != "" {
+				g.Holders = append(g.Holders, holder)
+			}
+		}
+		g.Pieces = len(pieces)
+		g.Recoverable = g.Pieces >= g.Threshold
+		r.Groups = append(r.Groups, *g)
+	}
+
+	// Newest first, so the recommended set is usually at the top.
+	sort.Slice(r.Groups, func(i, j int) bool {
+		if !r.Groups[i].Created.Equal(r.Groups[j].Created) {
+			return r.Groups[i].Created.After(r.Groups[j].Created)
+		}
+		return r.Groups[i].Fingerprint < r.Groups[j].Fingerprint
+	})
+	labelGroups(r.Groups)
+	r.Keep, r.KeepReason = recommendGroup(r.Groups)
+	return r, nil
+}
+
+// labelGroups sets each group's Label. Groups are keyed by fingerprint and
+// commitment, so groups that share a fingerprint have different
+// commitments, and the start of a hash of the commitment tells them apart.
+func labelGroups(groups []PieceGroup) {
+	count := make(map[string]int)
+	for _, g := range groups {
+		count[g.Fingerprint]++
+	}
+	for i := range groups {
+		g := &groups[i]
+		g.Label = g.Fingerprint
+		if count[g.Fingerprint] < 2 {
+			continue
+		}
+		if g.commitment == "" {
+			g.Label += " (no commitment)"
+		} else {
+			g.Label += " (commitment " + strings.TrimPrefix(core.HashString(g.commitment), "sha256:")[:8] + ")"
+		}
+	}
+}
+
+// recommendGroup picks the set to keep from groups sorted newest first.
+func recommendGroup(groups []PieceGroup) (int, string) {
+	for i, g := range groups {
+		if g.Recoverable {
+			if len(groups) == 1 {
+				return i, "it is the only set, and it has enough pieces to recover"
+			}
+			return i, "it is the newest set with enough pieces to recover"
+		}
+	}
+	best := -1
+	for i, g := range groups {
+		if best < 0 || g.Threshold-g.Pieces < groups[best].Threshold-groups[best].Pieces {
+			best = i
+		}
+	}
+	if best < 0 {
+		return -1, ""
+	}
+	g := groups[best]
+	return best, fmt.Sprintf("no set has enough pieces; this one is closest, %d short of %d", g.Threshold-g.Pieces, g.Threshold)
+}
+
+// readPieceFile reads the piece in a bundle ZIP or a text file holding a
+// PEM share block. It returns nil and no error for files that are neither.
+func readPieceFile(path string) (*core.Share, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		files, err := ReadZip(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if translations.IsReadmeFile(f.Name, ".txt") {
+				return checkedShare(f.Content)
+			}
+		}
+		return nil, nil
+	case ".txt":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Contains(content, []byte(core.ShareBegin)) {
+			return nil, nil
+		}
+		return checkedShare(content)
+	}
+	return nil, nil
+}
+
+func checkedShare(content []byte) (*core.Share, error) {
+	share, err := core.ParseShare(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := share.Verify(); err != nil {
+		share.Zeroize()
+		return nil, err
+	}
+	if err := share.Validate(); err != nil {
+		share.Zeroize()
+		return nil, err
+	}
+	return share, nil
+}
@@ -0,0 +1,169 @@
+package bundle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	holders := []string{"Alice", "Bob", "Carol", "David", "Eve"}
+
+	seal := func(created time.Time) []*core.Share {
+		t.Helper()
+		secret := bytes.Repeat([]byte{byte(created.Month())}, 32)
+		shares, err := core.SplitToShares(secret, 5, 3, holders, created)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitment, err := core.NewCommitment(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range shares {
+			s.Headers = map[string]string{core.CommitmentHeader: commitment}
+		}
+		return shares
+	}
+	write := func(name string, content []byte) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The first seal: three pieces, one of them twice, enough to recover.
+	older := seal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	write("alice/SHARE-alice.txt", []byte(older[0].Encode()))
+	write("alice/SHARE-alice (1).txt", []byte(older[0].Encode()))
+	write("SHARE-bob.txt", []byte(older[1].Encode()))
+	carolZip := filepath.Join(dir, "bundle-carol.zip")
+	if err := CreateZip(carolZip, []ZipFile{{Name: "README.txt", Content: []byte("Hi Carol\n\n" + older[2].Encode()), ModTime: older[2].Created}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second, accidental seal: newer, but only two pieces out there.
+	newer := seal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	write("new/SHARE-david.txt", []byte(newer[3].Encode()))
+	write("new/SHARE-eve.txt", []byte(newer[4].Encode()))
+
+	// A damaged piece and a file that isn't one.
+	damaged := []byte(older[4].Encode())
+	damaged = bytes.Replace(damaged, []byte("Checksum: sha256:"), []byte("Checksum: sha256:00"), 1)
+	damagedPath := write("SHARE-eve.txt", damaged)
+	write("notes.txt", []byte("call Bob first"))
+
+	r, err := Reconcile(dir)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	if len(r.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(r.Groups), r.Groups)
+	}
+	newGroup, oldGroup := r.Groups[0], r.Groups[1]
+	if newGroup.Fingerprint != newer[0].Fingerprint() || oldGroup.Fingerprint != older[0].Fingerprint() {
+		t.Fatalf("groups should be newest first, got %s then %s", newGroup.Fingerprint, oldGroup.Fingerprint)
+	}
+	if newGroup.Label != newGroup.Fingerprint || oldGroup.Label != oldGroup.Fingerprint {
+		t.Errorf("distinct fingerprints should be the labels, got %q and %q", newGroup.Label, oldGroup.Label)
+	}
+
+	if oldGroup.Pieces != 3 || !oldGroup.Recoverable || len(oldGroup.Files) != 4 {
+		t.Errorf("older set: %d pieces, recoverable %v, %d files; want 3, true, 4", oldGroup.Pieces, oldGroup.Recoverable, len(oldGroup.Files))
+	}
+	if !slices.Equal(oldGroup.Holders, []string{"Alice", "Bob", "Carol"}) {
+		t.Errorf("older set holders = %v", oldGroup.Holders)
+	}
+	if !slices.Contains(oldGroup.Files, carolZip) {
+		t.Errorf("older set should include the bundle ZIP, got %v", oldGroup.Files)
+	}
+
+	if newGroup.Pieces != 2 || newGroup.Recoverable {
+		t.Errorf("newer set: %d pieces, recoverable %v; want 2, false", newGroup.Pieces, newGroup.Recoverable)
+	}
+	if !slices.Equal(newGroup.Holders, []string{"David", "Eve"}) {
+		t.Errorf("newer set holders = %v", newGroup.Holders)
+	}
+
+	// Only the older set can be recovered, so it is the one to keep.
+	if r.Keep != 1 || !strings.Contains(r.KeepReason, "enough pieces") {
+		t.Errorf("Keep = %d (%q), want the older set", r.Keep, r.KeepReason)
+	}
+
+	if len(r.Unreadable) != 1 || r.Unreadable[0].File != damagedPath {
+		t.Errorf("Unreadable = %+v, want only %s", r.Unreadable, damagedPath)
+	}
+}
+
+func TestReconcileNoQuorum(t *testing.T) {
+	dir := t.TempDir()
+	for i, created := range []time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		shares, err := core.SplitToShares(bytes.Repeat([]byte{1}, 32), 5, 4, []string{"A", "B", "C", "D", "E"}, created)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Two pieces from the older seal, one from the newer.
+		for _, s := range shares[:2-i] {
+			name := filepath.Join(dir, created.Format("2006-01")+"-"+s.Filename())
+			if err := os.WriteFile(name, []byte(s.Encode()), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	r, err := Reconcile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(r.Groups))
+	}
+	if r.Keep != 1 || !strings.Contains(r.KeepReason, "closest, 2 short of 4") {
+		t.Errorf("Keep = %d (%q), want the older set, closest to its threshold", r.Keep, r.KeepReason)
+	}
+}
+
+func TestLabelGroups(t *testing.T) {
+	// Fingerprints are short, so two seals can share one; their labels
+	// must still differ.
+	groups := []PieceGroup{
+		{Fingerprint: "3f2a-91c0", commitment: "sha256:salt-one:aaaa"},
+		{Fingerprint: "3f2a-91c0", commitment: "sha256:salt-two:bbbb"},
+		{Fingerprint: "3f2a-91c0"},
+		{Fingerprint: "7b1e-0d44", commitment: "sha256:salt-three:cccc"},
+	}
+	labelGroups(groups)
+
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		if seen[g.Label] {
+			t.Errorf("label %q is used twice", g.Label)
+		}
+		seen[g.Label] = true
+		if !strings.HasPrefix(g.Label, g.Fingerprint) {
+			t.Errorf("label %q should start with the fingerprint %s", g.Label, g.Fingerprint)
+		}
+	}
+	if groups[2].Label != "3f2a-91c0 (no commitment)" {
+		t.Errorf("label without a commitment = %q", groups[2].Label)
+	}
+	if groups[3].Label != "7b1e-0d44" {
+		t.Errorf("a unique fingerprint should be the label on its own, got %q", groups[3].Label)
+	}
+}
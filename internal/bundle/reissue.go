@@ -0,0 +1,171 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// ErrOriginalShareLost is returned by ReissueShare when the share issued at
+// seal time can't be restored and a new one wasn't allowed.
+var ErrOriginalShareLost = errors.New("the original share can't be restored")
+
+// ReissueResult is a share rebuilt for a friend who lost theirs.
+type ReissueResult struct {
+	Index int         // 0-based friend index in the project
+	Share *core.Share // The rebuilt share
+	Exact bool        // True if it is byte-for-byte the share issued at seal time
+}
+
+// ReissueShare rebuilds the share for holder from at least threshold of the
+// other friends' shares. The passphrase is reconstructed and checked against
+// the project's verification hash, then the holder's share is derived from
+// the same polynomial so it combines with everyone else's.
+//
+// Vault picks each share's x-coordinate at random, so the original point
+// isn't stored anywhere. ReissueShare tries every unused x-coordinate and
+// keeps the one whose encoded share matches the checksum recorded at seal
+// time, which restores the exact original share. If no candidate matches
+// (for example, the project predates share checksums), it returns
+// ErrOriginalShareLost, unless allowNew is set. Then it issues a share at the
+// first x-coordinate the supplied shares don't use, with Exact=false. That
+// share combines with the supplied ones, but a friend who didn't supply
+// theirs may hold the same x-coordinate, and the two then won't combine.
+func ReissueShare(p *project.Project, holder string, shares []*core.Share, allowNew bool) (*ReissueResult, error) {
+	if p.Sealed == nil {
+		return nil, fmt.Errorf("project has not been sealed yet")
+	}
+	if p.PerFileKeys() {
+		return nil, fmt.Errorf("reissuing a share: %w", project.ErrPerFileKeys)
+	}
+
+	idx := -1
+	for i, f := range p.Friends {
+		if strings.EqualFold(f.Name, holder) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("no friend named %q in this project", holder)
+	}
+	friend := p.Friends[idx]
+
+	if len(shares) < p.Threshold {
+		return nil, fmt.Errorf("need at least %d other shares to reissue (got %d)", p.Threshold, len(shares))
+	}
+
+	version := shares[0].Version
+	data := make([][]byte, len(shares))
+	used := make(map[byte]bool)
+	for i, s := range shares {
+		if err := s.Verify(); err != nil {
+			return nil, fmt.Errorf("share %d: %w", i+1, err)
+		}
+		if s.Version != version {
+			return nil, fmt.Errorf("share %d has different version (v%d vs v%d)", i+1, s.Version, version)
+		}
+		data[i] = s.Data
+		used[s.XCoordinate()] = true
+	}
+
+	// Make sure the shares really belong to this project before deriving
+	// anything from them.
+	recovered, err := core.CombineChecked(data, p.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+	passphrase := core.RecoverPassphrase(recovered, version)
+	core.Zeroize(recovered)
+	if !core.VerifyHash(core.HashString(passphrase), p.Sealed.VerificationHash) {
+		return nil, fmt.Errorf("shares don't reconstruct this project's passphrase")
+	}
+
+	// Candidate creation times: whatever the supplied shares say, plus the
+	// seal time. Shares from one seal normally agree, but older versions
+	// stamped each share separately, so a minute either side is tried too.
+	var created []time.Time
+	seen := make(map[time.Time]bool)
+	for _, t := range append(shareTimes(shares), p.Sealed.At.UTC().Truncate(time.Minute)) {
+		for _, d := range []time.Duration{0, -time.Minute, time.Minute} {
+			c := t.Add(d)
+			if !t.IsZero() && !seen[c] {
+				seen[c] = true
+				created = append(created, c)
+			}
+		}
+	}
+
+	// The rebuilt share carries the same commitment as the others.
+	commitment, err := core.ShareCommitment(shares)
+	if err != nil {
+		return nil, err
+	}
+	if commitment == "" {
+		commitment = p.Sealed.Commitment
+	}
+	requires := core.RequiredVersion(shares)
+
+	var expected string
+	for _, si := range p.Sealed.Shares {
+		if strings.EqualFold(si.Friend, friend.Name) {
+			expected = si.Checksum
+		}
+	}
+
+	newShare := func(shareData []byte, created time.Time) *core.Share {
+		share := core.NewShare(version, idx+1, len(p.Friends), p.Threshold, friend.Name, shareData)
+		share.Label = friend.Label
+		share.Created = created
+		if commitment != "" {
+			share.Headers = map[string]string{core.CommitmentHeader: commitment}
+		}
+		if requires != "" {
+			if share.Headers == nil {
+				share.Headers = map[string]string{}
+			}
+			share.Headers[core.RequiresVersionHeader] = requires
+		}
+		return share
+	}
+
+	var fallback []byte
+	for x := 1; x <= 255; x++ {
+		if used[byte(x)] {
+			continue
+		}
+		shareData, err := core.ReconstructShare(data, p.Threshold, byte(x))
+		if err != nil {
+			return nil, err
+		}
+		if fallback == nil {
+			fallback = shareData
+		}
+		if expected == "" {
+			break
+		}
+		for _, t := range created {
+			share := newShare(shareData, t)
+			if core.VerifyHash(core.HashString(share.Encode()), expected) {
+				return &ReissueResult{Index: idx, Share: share, Exact: true}, nil
+			}
+		}
+	}
+
+	if !allowNew {
+		return nil, ErrOriginalShareLost
+	}
+	return &ReissueResult{Index: idx, Share: newShare(fallback, created[0]), Exact: false}, nil
+}
+
+func shareTimes(shares []*core.Share) []time.Time {
+	times := make([]time.Time, len(shares))
+	for i, s := range shares {
+		times[i] = s.Created.UTC()
+	}
+	return times
+}
@@ -0,0 +1,234 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// RelocalizeBundle rewrites the bundle ZIP at path for a friend who reads
+// lang. README.txt and README.pdf are rebuilt in that language (under their
+// translated names) from the README footer and the data recover.html was
+// personalized with, and recover.html opens in lang by default. Nothing else
+// changes: the share, MANIFEST.age and the recovery tool are kept byte for
+// byte, and the footer gets the new recover.html checksum.
+//
+// No project is needed, so a bundle can be relocalized wherever it is. If a
+// SHA256SUMS file next to it lists the bundle, its entry is updated too.
+// recoveryURL is the base URL for the QR code in the PDF, which the bundle
+// doesn't record.
+func RelocalizeBundle(path, lang, recoveryURL string) error {
+	if !slices.Contains(translations.Languages, lang) {
+		return fmt.Errorf("unsupported language %q (supported: %s)", lang, strings.Join(translations.Languages, ", "))
+	}
+	return rewriteReadmes(path, recoveryURL, func(data *ReadmeData, recoverHTML []byte) ([]byte, error) {
+		data.Language = lang
+		newHTML, err := html.SetPersonalizationLanguage(recoverHTML, lang)
+		if err != nil {
+			return nil, fmt.Errorf("updating recover.html: %w", err)
+		}
+		return newHTML, nil
+	})
+}
+
+// rewriteReadmes rebuilds README.txt and README.pdf in the bundle ZIP at
+// path. Their data is read back from the README footer and recover.html's
+// personalization, then edit may change it and returns the recover.html to
+// write, which is recover.html itself to keep it. The footer gets that
+// file's checksum. Every other file is kept byte for byte.
+//
+// The bundle is written next to the original and verified before it
+// replaces it, and its SHA256SUMS entry is updated if there is one.
+func rewriteReadmes(path, recoveryURL string, edit func(data *ReadmeData, recoverHTML []byte) ([]byte, error)) error {
+	files, err := ReadZip(path)
+	if err != nil {
+		return err
+	}
+	var readme, recoverHTML *ZipFile
+	for i := range files {
+		switch {
+		case translations.IsReadmeFile(files[i].Name, ".txt"):
+			readme = &files[i]
+		case files[i].Name == "recover.html":
+			recoverHTML = &files[i]
+		}
+	}
+	if readme == nil {
+		return fmt.Errorf("README file (.txt) not found in bundle")
+	}
+	if recoverHTML == nil {
+		return fmt.Errorf("recover.html not found in bundle")
+	}
+
+	share, err := core.ParseShare(readme.Content)
+	if err != nil {
+		return fmt.Errorf("parsing share: %w", err)
+	}
+	personalization, err := html.ExtractPersonalization(recoverHTML.Content)
+	if err != nil {
+		return fmt.Errorf("reading recover.html: %w", err)
+	}
+
+	metadata := parseMetadataFooter(string(readme.Content))
+	created, err := time.Parse(time.RFC3339, metadata["created"])
+	if err != nil {
+		return fmt.Errorf("reading creation time from README footer: %w", err)
+	}
+	threshold, _ := strconv.Atoi(metadata["threshold"])
+	total, _ := strconv.Atoi(metadata["total"])
+	if threshold == 0 || total == 0 || metadata["checksum-manifest"] == "" {
+		return fmt.Errorf("README footer is incomplete")
+	}
+
+	// Anonymous bundles list no other friends.
+	var otherFriends []project.Friend
+	for _, f := range personalization.OtherFriends {
+		otherFriends = append(otherFriends, project.Friend{Name: f.Name, Contact: f.Contact})
+	}
+
+	data := ReadmeData{
+		ProjectName:      metadata["project"],
+		Holder:           share.Holder,
+		Share:            share,
+		OtherFriends:     otherFriends,
+		Threshold:        threshold,
+		Total:            total,
+		Version:          metadata["rememory-version"],
+		GitHubReleaseURL: metadata["github-release"],
+		ManifestChecksum: metadata["checksum-manifest"],
+		WASMChecksum:     metadata["checksum-recover-wasm"],
+		Created:          created,
+		Anonymous:        len(otherFriends) == 0,
+		Language:         bundleLanguage(personalization.Language, readme.Name),
+		ManifestEmbedded: personalization.ManifestB64 != "",
+		Note:             personalization.Note,
+		Question:         personalization.Question,
+		Instructions:     personalization.Instructions,
+	}
+	if data.Holder == "" {
+		data.Holder = personalization.Holder
+	}
+
+	newHTML, err := edit(&data, recoverHTML.Content)
+	if err != nil {
+		return err
+	}
+	data.RecoverChecksum = core.HashBytes(newHTML)
+
+	readmeTxt := GenerateReadme(data)
+	readmePDF, err := pdf.GenerateReadme(pdf.ReadmeData{
+		ProjectName:      data.ProjectName,
+		Holder:           data.Holder,
+		Share:            data.Share,
+		OtherFriends:     data.OtherFriends,
+		Threshold:        data.Threshold,
+		Total:            data.Total,
+		Version:          data.Version,
+		GitHubReleaseURL: data.GitHubReleaseURL,
+		ManifestChecksum: data.ManifestChecksum,
+		RecoverChecksum:  data.RecoverChecksum,
+		WASMChecksum:     data.WASMChecksum,
+		AckToken:         GenerateAckToken(data.Share),
+		Created:          data.Created,
+		Anonymous:        data.Anonymous,
+		RecoveryURL:      recoveryURL,
+		Language:         data.Language,
+		ManifestEmbedded: data.ManifestEmbedded,
+		Note:             data.Note,
+		Question:         data.Question,
+		Instructions:     data.Instructions,
+	})
+	if err != nil {
+		return fmt.Errorf("generating PDF: %w", err)
+	}
+
+	// Keep the order bundleFiles uses: READMEs, recover.html, then the rest.
+	out := []ZipFile{
+		{Name: translations.ReadmeFilename(data.Language, ".txt"), Content: []byte(readmeTxt), ModTime: data.Created},
+		{Name: translations.ReadmeFilename(data.Language, ".pdf"), Content: readmePDF, ModTime: data.Created},
+		{Name: "recover.html", Content: newHTML, ModTime: recoverHTML.ModTime},
+	}
+	for _, f := range files {
+		if translations.IsReadmeFile(f.Name, ".txt") || translations.IsReadmeFile(f.Name, ".pdf") || f.Name == "recover.html" {
+			continue
+		}
+		out = append(out, f)
+	}
+
+	// Write next to the original and rename, so a failure leaves it intact.
+	tmp := path + ".tmp"
+	if err := CreateZip(tmp, out); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := verifyBundleContents(tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("verifying rewritten bundle: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("replacing bundle: %w", err)
+	}
+
+	return updateSHA256SUMS(path)
+}
+
+// bundleLanguage returns the language a bundle was made in: the one
+// recover.html was personalized with, or else the first whose README name
+// matches readmeName. Older bundles may not record it in recover.html.
+func bundleLanguage(personalized, readmeName string) string {
+	if personalized != "" {
+		return personalized
+	}
+	for _, lang := range translations.Languages {
+		if translations.ReadmeFilename(lang, ".txt") == readmeName {
+			return lang
+		}
+	}
+	return "en"
+}
+
+// updateSHA256SUMS rewrites path's entry in the SHA256SUMS file next to it,
+// if there is one and it lists path.
+func updateSHA256SUMS(path string) error {
+	sumsPath := filepath.Join(filepath.Dir(path), SHA256SUMSFile)
+	sums, err := readSHA256SUMS(sumsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	if _, ok := sums[name]; !ok {
+		return nil
+	}
+	if sums[name], err = hashFile(path); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(sums))
+	for n := range sums {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[n], n)
+	}
+	if err := os.WriteFile(sumsPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", SHA256SUMSFile, err)
+	}
+	return nil
+}
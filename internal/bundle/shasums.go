@@ -0,0 +1,129 @@
+package bundle
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// SHA256SUMSFile is the name of the checksum list written next to the
+// bundles. It uses the format of sha256sum, so anyone can check a bundle
+// they downloaded with "sha256sum -c --ignore-missing SHA256SUMS".
+const SHA256SUMSFile = "SHA256SUMS"
+
+// WriteSHA256SUMS writes SHA256SUMS in dir, listing the SHA-256 of every
+// other regular file there, sorted by name.
+func WriteSHA256SUMS(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && e.Name() != SHA256SUMSFile {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		sum, err := hashFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, name)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, SHA256SUMSFile), []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", SHA256SUMSFile, err)
+	}
+	return nil
+}
+
+// checkSHA256SUMS checks path against the SHA256SUMS file in the same
+// directory. It returns false without an error if there is no such file or
+// path isn't listed in it; a downloads folder may hold a list for
+// something else entirely.
+func checkSHA256SUMS(path string) (bool, error) {
+	sums, err := readSHA256SUMS(filepath.Join(filepath.Dir(path), SHA256SUMSFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	expected, ok := sums[filepath.Base(path)]
+	if !ok {
+		return false, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer f.Close()
+
+	r, check := core.NewVerifyingReader(f, "sha256:"+expected)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return false, fmt.Errorf("reading bundle: %w", err)
+	}
+	if err := check(); err != nil {
+		return true, fmt.Errorf("%s does not match %s", filepath.Base(path), SHA256SUMSFile)
+	}
+	return true, nil
+}
+
+// readSHA256SUMS parses a sha256sum listing into hex hashes by file name.
+// Both the text ("hash  name") and binary ("hash *name") forms are accepted.
+func readSHA256SUMS(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != sha256.Size*2 || (!strings.HasPrefix(name, " ") && !strings.HasPrefix(name, "*")) {
+			return nil, fmt.Errorf("%s line %d: not in sha256sum format", SHA256SUMSFile, n)
+		}
+		sums[name[1:]] = strings.ToLower(sum)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", SHA256SUMSFile, err)
+	}
+	return sums, nil
+}
+
+// hashFile returns the hex SHA-256 of the file at path, without a prefix.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// ShareSheetFilename returns the file name of a holder's printable share
+// sheet, alongside their SHARE-*.txt.
+func ShareSheetFilename(holder string) string {
+	return fmt.Sprintf("SHEET-%s.pdf", core.SanitizeFilename(holder))
+}
+
+// RenderShareSheetPDF renders the one-page share sheet PDF (see
+// pdf.GenerateShareSheet) for share. data gives the rest of the page, such
+// as the project name and holder; its Share field is replaced by share.
+func RenderShareSheetPDF(share *core.Share, data pdf.ReadmeData) ([]byte, error) {
+	if share == nil {
+		return nil, fmt.Errorf("no share to print")
+	}
+	data.Share = share
+	return pdf.GenerateShareSheet(data)
+}
+
+// WriteShareSheets writes a one-page share sheet PDF (see
+// pdf.GenerateShareSheet) for every friend of a sealed project into dir,
+// and returns the paths written. Each sheet holds a share, so the files
+// are only readable by their owner. recoveryURL is the base of the QR code
+// link, as for bundles; empty means core.DefaultRecoveryURL.
+func WriteShareSheets(p *project.Project, dir, recoveryURL string) ([]string, error) {
+	if p.Sealed == nil {
+		return nil, fmt.Errorf("project must be sealed before printing share sheets")
+	}
+	if p.PerFileKeys() {
+		return nil, fmt.Errorf("printing share sheets: %w", project.ErrPerFileKeys)
+	}
+	shares, err := loadShares(p)
+	if err != nil {
+		return nil, fmt.Errorf("loading shares: %w", err)
+	}
+	defer func() {
+		for _, s := range shares {
+			s.Zeroize()
+		}
+	}()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	paths := make([]string, len(p.Friends))
+	for i, friend := range p.Friends {
+		sheet, err := RenderShareSheetPDF(shares[i], pdf.ReadmeData{
+			ProjectName: p.Name,
+			Holder:      friend.Name,
+			Threshold:   p.Threshold,
+			Total:       len(p.Friends),
+			Created:     p.Sealed.At,
+			RecoveryURL: recoveryURL,
+			Language:    friendLanguage(p, i),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("share sheet for %s: %w", friend.Name, err)
+		}
+		paths[i] = filepath.Join(dir, ShareSheetFilename(friend.Name))
+		if err := os.WriteFile(paths[i], sheet, 0600); err != nil {
+			return nil, fmt.Errorf("writing share sheet for %s: %w", friend.Name, err)
+		}
+	}
+	return paths, nil
+}
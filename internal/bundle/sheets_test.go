@@ -0,0 +1,24 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/pdf"
+)
+
+func TestRenderShareSheetPDF(t *testing.T) {
+	share := core.NewShare(2, 1, 3, 2, "Alice", []byte("render-share-sheet-test-data-123"))
+	sheet, err := RenderShareSheetPDF(share, pdf.ReadmeData{ProjectName: "Test", Holder: "Alice", Threshold: 2, Total: 3})
+	if err != nil {
+		t.Fatalf("RenderShareSheetPDF: %v", err)
+	}
+	if !bytes.HasPrefix(sheet, []byte("%PDF-")) {
+		t.Error("output does not start with PDF header")
+	}
+
+	if _, err := RenderShareSheetPDF(nil, pdf.ReadmeData{Holder: "Alice"}); err == nil {
+		t.Error("expected error without a share")
+	}
+}
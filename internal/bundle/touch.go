@@ -0,0 +1,28 @@
+package bundle
+
+import (
+	"time"
+)
+
+// TouchBundle rewrites the README footer of the bundle ZIP at path with
+// cfg.Version and the created time, and rebuilds README.txt and README.pdf
+// to match. A bundle that links to a CLI release gets cfg.GitHubReleaseURL;
+// one made without the link stays without it. A zero created keeps the time
+// already in the footer.
+//
+// Nothing else changes: the share, MANIFEST.age and recover.html are kept
+// byte for byte, so the bundle still works with everyone else's. If a
+// SHA256SUMS file next to it lists the bundle, its entry is updated too.
+// cfg.RecoveryURL is the base URL for the QR code in the PDF.
+func TouchBundle(path string, cfg Config, created time.Time) error {
+	return rewriteReadmes(path, cfg.RecoveryURL, func(data *ReadmeData, recoverHTML []byte) ([]byte, error) {
+		data.Version = cfg.Version
+		if data.GitHubReleaseURL != "" {
+			data.GitHubReleaseURL = cfg.GitHubReleaseURL
+		}
+		if !created.IsZero() {
+			data.Created = created.UTC()
+		}
+		return recoverHTML, nil
+	})
+}
@@ -0,0 +1,113 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// vcardLineLimit is the longest a vCard line may be, in bytes, before it
+// is folded onto the next (RFC 2425).
+const vcardLineLimit = 75
+
+// contactSplitRe separates the parts of a contact written on one line, as
+// in "alice@example.com, +1 555 0100".
+var contactSplitRe = regexp.MustCompile(`\s*(?:[,;\n]|\s+or\s+)\s*`)
+
+// phoneRe matches a phone number: digits with the usual separators, and
+// enough digits to be one.
+var phoneRe = regexp.MustCompile(`^\+?[0-9 ().\-]{7,}$`)
+
+// WriteVCards writes a vCard 3.0 entry to w for each friend who has contact
+// info, so the owner can import every holder into their phone at once. The
+// free-form contact is split on commas, semicolons, line breaks and "or":
+// email addresses become EMAIL lines, phone numbers TEL lines, and anything
+// else, such as a postal address, goes into the NOTE along with the project
+// name and the friend's piece. Friends with no contact info are skipped and
+// their names returned.
+func WriteVCards(w io.Writer, p *project.Project) (skipped []string, err error) {
+	var sb strings.Builder
+	for i, friend := range p.Friends {
+		if strings.TrimSpace(friend.Contact) == "" {
+			skipped = append(skipped, friend.Name)
+			continue
+		}
+
+		var emails, phones, other []string
+		for _, part := range contactSplitRe.Split(strings.TrimSpace(friend.Contact), -1) {
+			switch {
+			case part == "":
+			case strings.Contains(part, "@") && !strings.ContainsAny(part, " \t"):
+				emails = append(emails, strings.TrimPrefix(part, "mailto:"))
+			case phoneRe.MatchString(part) && countDigits(part) >= 7:
+				phones = append(phones, part)
+			default:
+				other = append(other, part)
+			}
+		}
+
+		piece := fmt.Sprintf("piece %d", i+1)
+		if friend.Label != "" {
+			piece = fmt.Sprintf("piece %s", friend.Label)
+		}
+		note := fmt.Sprintf("Holds %s of the ReMemory project %q.", piece, p.Name)
+		if len(other) > 0 {
+			note += "\n" + strings.Join(other, "\n")
+		}
+
+		writeVCardLine(&sb, "BEGIN:VCARD")
+		writeVCardLine(&sb, "VERSION:3.0")
+		writeVCardLine(&sb, "FN:"+escapeVCard(friend.Name))
+		writeVCardLine(&sb, "N:;"+escapeVCard(friend.Name)+";;;")
+		for _, email := range emails {
+			writeVCardLine(&sb, "EMAIL;TYPE=INTERNET:"+escapeVCard(email))
+		}
+		for _, phone := range phones {
+			writeVCardLine(&sb, "TEL;TYPE=CELL:"+escapeVCard(phone))
+		}
+		writeVCardLine(&sb, "NOTE:"+escapeVCard(note))
+		writeVCardLine(&sb, "END:VCARD")
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// escapeVCard escapes a vCard text value.
+func escapeVCard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeVCardLine writes line with a CRLF ending, folded so no physical line
+// is longer than vcardLineLimit bytes. Folds fall between characters, and
+// each continuation line starts with a space.
+func writeVCardLine(sb *strings.Builder, line string) {
+	limit := vcardLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		limit = vcardLineLimit - 1 // the leading space counts
+	}
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
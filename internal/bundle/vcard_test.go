@@ -0,0 +1,93 @@
+package bundle
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+func TestWriteVCards(t *testing.T) {
+	p := &project.Project{
+		Name: "Family vault",
+		Friends: []project.Friend{
+			{Name: "Alice", Contact: "alice@example.com, +1 555 0100 1234"},
+			{Name: "Bob", Contact: "(030) 1234-5678 or bob@example.org"},
+			{Name: "Carol", Contact: "12 Oak Street; Springfield", Label: "C"},
+			{Name: "David"},
+			{Name: "Eve", Contact: "eve@example.net"},
+		},
+	}
+
+	var buf bytes.Buffer
+	skipped, err := WriteVCards(&buf, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(skipped, []string{"David"}) {
+		t.Errorf("skipped = %v, want [David]", skipped)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "BEGIN:VCARD\r\n"); n != 4 {
+		t.Errorf("got %d vCards, want 4 (one per friend with contact info)", n)
+	}
+	if n := strings.Count(out, "END:VCARD\r\n"); n != 4 {
+		t.Errorf("got %d vCard ends, want 4", n)
+	}
+	cards := strings.Split(strings.TrimSuffix(out, "END:VCARD\r\n"), "END:VCARD\r\n")
+	if len(cards) != 4 {
+		t.Fatalf("split into %d cards", len(cards))
+	}
+
+	lines := func(card, prefix string) []string {
+		var got []string
+		for _, line := range strings.Split(card, "\r\n") {
+			if strings.HasPrefix(line, prefix) {
+				got = append(got, line)
+			}
+		}
+		return got
+	}
+	tests := []struct {
+		card          int
+		name          string
+		emails, phone []string
+	}{
+		{0, "FN:Alice", []string{"EMAIL;TYPE=INTERNET:alice@example.com"}, []string{"TEL;TYPE=CELL:+1 555 0100 1234"}},
+		{1, "FN:Bob", []string{"EMAIL;TYPE=INTERNET:bob@example.org"}, []string{"TEL;TYPE=CELL:(030) 1234-5678"}},
+		{2, "FN:Carol", nil, nil},
+		{3, "FN:Eve", []string{"EMAIL;TYPE=INTERNET:eve@example.net"}, nil},
+	}
+	for _, tt := range tests {
+		card := cards[tt.card]
+		if got := lines(card, "FN:"); !reflect.DeepEqual(got, []string{tt.name}) {
+			t.Errorf("card %d: FN = %v, want %s", tt.card, got, tt.name)
+		}
+		if got := lines(card, "EMAIL"); !reflect.DeepEqual(got, tt.emails) {
+			t.Errorf("%s: EMAIL = %v, want %v", tt.name, got, tt.emails)
+		}
+		if got := lines(card, "TEL"); !reflect.DeepEqual(got, tt.phone) {
+			t.Errorf("%s: TEL = %v, want %v", tt.name, got, tt.phone)
+		}
+	}
+
+	// The address goes into the note, escaped, with the piece's label.
+	unfolded := strings.ReplaceAll(cards[2], "\r\n ", "")
+	if want := `NOTE:Holds piece C of the ReMemory project "Family vault".\n12 Oak Street\nSpringfield` + "\r\n"; !strings.Contains(unfolded, want) {
+		t.Errorf("Carol's note should be %q, got:\n%s", want, cards[2])
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > vcardLineLimit {
+			t.Errorf("line longer than %d bytes: %q", vcardLineLimit, line)
+		}
+	}
+}
+
+func TestEscapeVCard(t *testing.T) {
+	if got, want := escapeVCard("a,b;c\\d\ne"), `a\,b\;c\\d\ne`; got != want {
+		t.Errorf("escapeVCard = %q, want %q", got, want)
+	}
+}
@@ -3,8 +3,11 @@ package bundle
 import (
 	"archive/zip"
 	"fmt"
+	"io"
 	"os"
 	"time"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 // ZipFile represents a file to be added to a ZIP archive.
@@ -22,7 +25,12 @@ func CreateZip(path string, files []ZipFile) error {
 	}
 	defer f.Close()
 
-	w := zip.NewWriter(f)
+	return writeZip(f, files)
+}
+
+// writeZip writes a ZIP archive with the given files to dst.
+func writeZip(dst io.Writer, files []ZipFile) error {
+	w := zip.NewWriter(dst)
 	defer w.Close()
 
 	for _, file := range files {
@@ -44,3 +52,57 @@ func CreateZip(path string, files []ZipFile) error {
 
 	return nil
 }
+
+// ReadZip reads every file in the ZIP archive at path into memory.
+func ReadZip(path string) ([]ZipFile, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	files := make([]ZipFile, 0, len(r.File))
+	for _, f := range r.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, ZipFile{Name: f.Name, Content: data, ModTime: f.Modified})
+	}
+	return files, nil
+}
+
+// readZipFile reads one entry of an open ZIP in full.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// verifyZipFile streams one entry of an open ZIP into w, checking it against
+// expected ("sha256:...") on the way.
+func verifyZipFile(f *zip.File, expected string, w io.Writer) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	r, check := core.NewVerifyingReader(rc, expected)
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("reading %s: %w", f.Name, err)
+	}
+	if err := check(); err != nil {
+		return fmt.Errorf("%s checksum mismatch", f.Name)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// answerEnv names the environment variable seal and recover read the answer
+// to a project's recovery question from. Like the passphrase for encrypt and
+// decrypt, the answer never goes on the command line.
+const answerEnv = "REMEMORY_ANSWER"
+
+// recoveryAnswer returns the answer to a recovery question: the first line
+// of stdin when fromStdin is set, or else answerEnv. It is "" when there is
+// neither.
+func recoveryAnswer(stdin io.Reader, fromStdin bool) (string, error) {
+	if !fromStdin {
+		return os.Getenv(answerEnv), nil
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// requireAnswer returns the answer to question from recoveryAnswer, or an
+// error naming the question when there is none.
+func requireAnswer(stdin io.Reader, fromStdin bool, question string) (string, error) {
+	answer, err := recoveryAnswer(stdin, fromStdin)
+	if err != nil {
+		return "", err
+	}
+	if core.NormalizeAnswer(answer) == "" {
+		return "", fmt.Errorf("this project needs the answer to %q: use --answer-stdin or set %s", question, answerEnv)
+	}
+	return answer, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/spf13/cobra"
+)
+
+var buildWASMCmd = &cobra.Command{
+	Use:   "build-wasm --out recover.wasm",
+	Short: "Write out the recovery tool's WASM so bundle runs can reuse it",
+	Long: `Build-wasm writes the WebAssembly recovery module that goes into every
+recover.html to a file. The module is built into this binary, so the file
+is byte-for-byte what seal and bundle would embed.
+
+Pass the file back with --wasm to seal, bundle or reissue to embed it
+instead of the built-in one. This is useful in scripts that pin one
+recover.wasm across many projects, or that build it separately with:
+
+  GOOS=js GOARCH=wasm go build -o recover.wasm ./internal/wasm
+
+Example:
+  rememory build-wasm --out recover.wasm
+  rememory seal --wasm recover.wasm`,
+	Args: cobra.NoArgs,
+	RunE: runBuildWASM,
+}
+
+var buildWASMOut string
+
+func init() {
+	rootCmd.AddCommand(buildWASMCmd)
+	buildWASMCmd.Flags().StringVar(&buildWASMOut, "out", "recover.wasm", "File to write the WASM to")
+}
+
+func runBuildWASM(cmd *cobra.Command, args []string) error {
+	wasmBytes, err := embeddedRecoverWASM()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(buildWASMOut, wasmBytes, 0644); err != nil {
+		return fmt.Errorf("writing WASM: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", green("✓"), buildWASMOut, formatSize(int64(len(wasmBytes))))
+	return nil
+}
+
+// addWASMFlag adds --wasm to a command that generates bundles.
+func addWASMFlag(cmd *cobra.Command) {
+	cmd.Flags().String("wasm", "", "Embed this recover.wasm (from build-wasm) instead of the built-in one")
+}
+
+// recoverWASMBytes returns the recovery WASM to embed in bundles: the file
+// given with --wasm, checked to be a WebAssembly module, or else the one
+// built into this binary.
+func recoverWASMBytes(cmd *cobra.Command) ([]byte, error) {
+	path, _ := cmd.Flags().GetString("wasm")
+	if path == "" {
+		return embeddedRecoverWASM()
+	}
+	wasmBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading WASM: %w", err)
+	}
+	if err := html.ValidateWASM(wasmBytes); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return wasmBytes, nil
+}
+
+func embeddedRecoverWASM() ([]byte, error) {
+	wasmBytes := html.GetRecoverWASMBytes()
+	if len(wasmBytes) == 0 {
+		return nil, fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+	return wasmBytes, nil
+}
@@ -2,12 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
-	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
 )
@@ -33,6 +33,8 @@ Each bundle contains:
 func init() {
 	bundleCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
 	bundleCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	bundleCmd.Flags().Bool("no-cli-link", false, "Leave the CLI download instructions out of README and recover.html")
+	addWASMFlag(bundleCmd)
 	rootCmd.AddCommand(bundleCmd)
 }
 
@@ -59,29 +61,30 @@ func runBundle(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("project must be sealed before generating bundles (run 'rememory seal' first)")
 	}
 
-	// Get embedded recovery WASM binary (smaller, for bundles)
-	wasmBytes := html.GetRecoverWASMBytes()
-	if len(wasmBytes) == 0 {
-		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	wasmBytes, err := recoverWASMBytes(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Generate bundles
-	fmt.Printf("Generating bundles for %d friends...\n\n", len(p.Friends))
+	fmt.Printf("Generating bundles for %d friends...\n", len(p.Friends))
 
 	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
 	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
 
 	cfg := bundle.Config{
 		Version:          version,
-		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
+		GitHubReleaseURL: releaseURL(cmd),
 		WASMBytes:        wasmBytes,
 		RecoveryURL:      recoveryURL,
 		NoEmbedManifest:  noEmbedManifest,
+		Progress:         bundleProgress(os.Stdout),
 	}
 
 	if err := bundle.GenerateAll(p, cfg); err != nil {
 		return fmt.Errorf("generating bundles: %w", err)
 	}
+	fmt.Println()
 
 	// Print summary
 	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
@@ -100,3 +103,23 @@ func runBundle(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// bundleProgress returns a bundle.Config.Progress callback that keeps a
+// running count on one line of w and ends the line after the last bundle.
+func bundleProgress(w io.Writer) func(done, total int) {
+	return func(done, total int) {
+		fmt.Fprintf(w, "\r  %d of %d done", done, total)
+		if done == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// releaseURL is where bundles point friends to download the CLI, or empty
+// when cmd's --no-cli-link is set, for deployments that don't publish it.
+func releaseURL(cmd *cobra.Command) string {
+	if noCLILink, _ := cmd.Flags().GetBool("no-cli-link"); noCLILink {
+		return ""
+	}
+	return fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version)
+}
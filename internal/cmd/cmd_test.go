@@ -1,9 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestFormatSize(t *testing.T) {
@@ -66,3 +80,1927 @@ func TestFriendNames(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyShare(t *testing.T) {
+	for _, version := range []string{"v1", "v2"} {
+		for _, name := range []string{"alice", "bob", "carol", "david", "eve"} {
+			path := filepath.Join("..", "core", "testdata", version+"-bundle", "SHARE-"+name+".txt")
+			t.Run(version+"/"+name, func(t *testing.T) {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("reading golden share: %v", err)
+				}
+
+				var out bytes.Buffer
+				if err := verifyShare(&out, content, ""); err != nil {
+					t.Fatalf("golden share should verify: %v\n%s", err, out.String())
+				}
+				if !strings.Contains(out.String(), "OK") {
+					t.Errorf("expected OK in output:\n%s", out.String())
+				}
+				if !strings.Contains(out.String(), "Fingerprint:") {
+					t.Errorf("expected fingerprint in output:\n%s", out.String())
+				}
+			})
+		}
+	}
+
+	t.Run("byte flipped", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-alice.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Flip a character inside the base64 payload
+		end := bytes.Index(content, []byte("-----END"))
+		i := end - 5
+		if content[i] == 'A' {
+			content[i] = 'B'
+		} else {
+			content[i] = 'A'
+		}
+
+		var out bytes.Buffer
+		if err := verifyShare(&out, content, ""); err == nil {
+			t.Fatal("corrupted share should fail")
+		}
+		if !strings.Contains(out.String(), "CORRUPT") {
+			t.Errorf("expected CORRUPT in output:\n%s", out.String())
+		}
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-bob.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseShare(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		if err := verifyShare(&out, []byte(share.CompactEncode()+"\n"), ""); err != nil {
+			t.Fatalf("compact share should verify: %v", err)
+		}
+		if !strings.Contains(out.String(), "Share:       2 of 5") {
+			t.Errorf("unexpected output:\n%s", out.String())
+		}
+	})
+}
+
+func TestStaleSharesWarning(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-alice.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share, err := core.ParseShare(content) // created 2025-01-01
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := []*core.Share{share}
+
+	old := time.Date(2029, 3, 1, 0, 0, 0, 0, time.UTC)
+	want := "these shares were created 4 years ago; the underlying secret may be stale"
+	if got := staleSharesWarning(shares, old, 2); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	recent := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if got := staleSharesWarning(shares, recent, 2); got != "" {
+		t.Errorf("expected no warning for recent shares, got %q", got)
+	}
+
+	if got := staleSharesWarning(shares, old, 0); got != "" {
+		t.Errorf("expected no warning when disabled, got %q", got)
+	}
+}
+
+// resetFlags restores cmd's flags to their defaults between Execute calls in
+// one test binary, including the "changed" state cobra uses for mutually
+// exclusive flags.
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
+
+func TestRecoverStdoutFile(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	want, err := os.ReadFile(filepath.Join(dir, "expected-output", "manifest", "secret.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(name string) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(&stderr)
+		rootCmd.SetArgs([]string{"recover",
+			filepath.Join(dir, "SHARE-alice.txt"),
+			filepath.Join(dir, "SHARE-bob.txt"),
+			filepath.Join(dir, "SHARE-carol.txt"),
+			"--manifest", filepath.Join(dir, "MANIFEST.age"),
+			"--stdout-file", name,
+		})
+		t.Cleanup(func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		})
+		err := rootCmd.Execute()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("found", func(t *testing.T) {
+		stdout, stderr, err := run("manifest/secret.txt")
+		if err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+		if stdout != string(want) {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+		if !strings.Contains(stderr, "Decrypting manifest") {
+			t.Errorf("progress should go to stderr, got %q", stderr)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		stdout, _, err := run("manifest/nope.txt")
+		if err == nil {
+			t.Fatal("expected error for missing file")
+		}
+		if !strings.Contains(err.Error(), "manifest/secret.txt") || !strings.Contains(err.Error(), "manifest/README.md") {
+			t.Errorf("error should list available files: %v", err)
+		}
+		if strings.Contains(stdout, "correct-horse") {
+			t.Errorf("no file contents should be written, got %q", stdout)
+		}
+	})
+}
+
+func TestRecoverOutputTar(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+
+	run := func(target string) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(&stderr)
+		rootCmd.SetArgs([]string{"recover",
+			filepath.Join(dir, "SHARE-alice.txt"),
+			filepath.Join(dir, "SHARE-bob.txt"),
+			filepath.Join(dir, "SHARE-carol.txt"),
+			"--manifest", filepath.Join(dir, "MANIFEST.age"),
+			"--output-tar", target,
+		})
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), stderr.String(), err
+	}
+
+	checkGolden := func(t *testing.T, archive []byte) {
+		t.Helper()
+		files, err := core.ExtractTarGz(archive)
+		if err != nil {
+			t.Fatalf("ExtractTarGz: %v", err)
+		}
+		for _, name := range []string{"README.md", "secret.txt"} {
+			want, err := os.ReadFile(filepath.Join(dir, "expected-output", "manifest", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found bool
+			for _, f := range files {
+				if f.Name == "manifest/"+name {
+					found = true
+					if !bytes.Equal(f.Data, want) {
+						t.Errorf("%s differs from the golden file", name)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%s missing from the archive", name)
+			}
+		}
+	}
+
+	t.Run("stdout", func(t *testing.T) {
+		stdout, stderr, err := run("-")
+		if err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+		if !strings.Contains(stderr, "Decrypting manifest") {
+			t.Errorf("progress should go to stderr, got %q", stderr)
+		}
+		checkGolden(t, []byte(stdout))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "manifest.tar.gz")
+		if _, _, err := run(out); err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+		archive, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, archive)
+	})
+}
+
+func TestRecoverRequiresVersion(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	pinned := t.TempDir()
+	var paths []string
+	for _, name := range []string{"SHARE-alice.txt", "SHARE-bob.txt", "SHARE-carol.txt"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseShare(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if share.Headers == nil {
+			share.Headers = map[string]string{}
+		}
+		share.Headers[core.RequiresVersionHeader] = "v0.1.0"
+		path := filepath.Join(pinned, name)
+		if err := os.WriteFile(path, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	oldVersion := version
+	version = "v0.0.12"
+	t.Cleanup(func() { version = oldVersion })
+
+	run := func(extra ...string) error {
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(append([]string{"recover", "--manifest", filepath.Join(dir, "MANIFEST.age"), "--verify-only"}, paths...), extra...))
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	var tooOld *core.VersionTooOldError
+	if err := run(); !errors.As(err, &tooOld) {
+		t.Fatalf("expected VersionTooOldError, got %v", err)
+	}
+	if err := run("--ignore-version"); err != nil {
+		t.Errorf("recover --ignore-version: %v", err)
+	}
+
+	version = "v0.1.0"
+	if err := run(); err != nil {
+		t.Errorf("recover with the required version: %v", err)
+	}
+}
+
+func TestRecoverManifestFromStdin(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	manifestData, err := os.ReadFile(filepath.Join(dir, "MANIFEST.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "expected-output", "manifest", "secret.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(extra ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetIn(bytes.NewReader(manifestData))
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"recover",
+			filepath.Join(dir, "SHARE-alice.txt"),
+			filepath.Join(dir, "SHARE-bob.txt"),
+			filepath.Join(dir, "SHARE-carol.txt"),
+			"--manifest", "-",
+		}, extra...))
+		defer func() {
+			rootCmd.SetIn(nil)
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	outDir := filepath.Join(t.TempDir(), "recovered")
+	if _, err := run("-o", outDir); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "manifest", "secret.txt"))
+	if err != nil || string(got) != string(want) {
+		t.Errorf("secret.txt: got %q, %v; want %q", got, err, want)
+	}
+
+	stdout, err := run("--stdout-file", "manifest/secret.txt")
+	if err != nil {
+		t.Fatalf("recover --stdout-file: %v", err)
+	}
+	if stdout != string(want) {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+
+	// stdin can't carry both the manifest and the answer.
+	if _, err := run("--answer-stdin"); err == nil || !strings.Contains(err.Error(), "--answer-stdin") {
+		t.Errorf("expected --manifest - with --answer-stdin to be refused, got %v", err)
+	}
+}
+
+func TestRecoverVerifyOnly(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("..", "core", "testdata", "v2-bundle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	t.Chdir(work)
+
+	run := func(manifestPath string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"recover",
+			filepath.Join(dir, "SHARE-alice.txt"),
+			filepath.Join(dir, "SHARE-bob.txt"),
+			filepath.Join(dir, "SHARE-carol.txt"),
+			"--manifest", manifestPath,
+			"--verify-only",
+		})
+		t.Cleanup(func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		})
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+	assertNothingWritten := func(t *testing.T) {
+		t.Helper()
+		entries, err := os.ReadDir(work)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("nothing should be written, found %v", entries)
+		}
+	}
+
+	t.Run("valid quorum", func(t *testing.T) {
+		stdout, err := run(filepath.Join(dir, "MANIFEST.age"))
+		if err != nil {
+			t.Fatalf("recover --verify-only: %v", err)
+		}
+		if !strings.Contains(stdout, "These pieces unlock the manifest") {
+			t.Errorf("expected success message, got %q", stdout)
+		}
+		if strings.Contains(stdout, "correct-horse") || strings.Contains(stdout, "Recovered to") {
+			t.Errorf("nothing should be recovered, got %q", stdout)
+		}
+		assertNothingWritten(t)
+	})
+
+	t.Run("damaged manifest", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(dir, "MANIFEST.age"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		damaged := filepath.Join(t.TempDir(), "MANIFEST.age")
+		if err := os.WriteFile(damaged, data[:len(data)-1], 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run(damaged); err == nil || !strings.Contains(err.Error(), "doesn't decrypt") {
+			t.Errorf("expected the check to fail, got %v", err)
+		}
+		assertNothingWritten(t)
+	})
+}
+
+func TestRehearse(t *testing.T) {
+	golden, err := filepath.Abs(filepath.Join("..", "core", "testdata", "v2-bundle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestData, err := os.ReadFile(filepath.Join(golden, "MANIFEST.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := project.New(filepath.Join(t.TempDir(), "drill"), "drill", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Sealed = &project.Sealed{At: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(p.OutputPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	rehearse := func(manifest []byte) (string, error) {
+		t.Helper()
+		if err := os.WriteFile(p.ManifestAgePath(), manifest, 0644); err != nil {
+			t.Fatal(err)
+		}
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"rehearse",
+			filepath.Join(golden, "SHARE-alice.txt"),
+			filepath.Join(golden, "SHARE-bob.txt"),
+			filepath.Join(golden, "SHARE-carol.txt"),
+		})
+		t.Cleanup(func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(rehearseCmd)
+		})
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+	lastRehearsed := func() time.Time {
+		t.Helper()
+		loaded, err := project.Load(p.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loaded.Sealed.LastRehearsed
+	}
+
+	// A failed drill records nothing.
+	if _, err := rehearse(manifestData[:len(manifestData)-1]); err == nil {
+		t.Fatal("expected rehearsal with a damaged manifest to fail")
+	}
+	if got := lastRehearsed(); !got.IsZero() {
+		t.Errorf("failed rehearsal should not be recorded, got %v", got)
+	}
+
+	before := time.Now().UTC()
+	stdout, err := rehearse(manifestData)
+	if err != nil {
+		t.Fatalf("rehearse: %v", err)
+	}
+	if !strings.Contains(stdout, "These pieces unlock the manifest") {
+		t.Errorf("expected success message, got %q", stdout)
+	}
+	first := lastRehearsed()
+	if first.Before(before.Truncate(time.Second)) {
+		t.Errorf("LastRehearsed = %v, want at or after %v", first, before)
+	}
+
+	// Failing later leaves the last good rehearsal in place.
+	if _, err := rehearse(manifestData[:len(manifestData)-1]); err == nil {
+		t.Fatal("expected rehearsal with a damaged manifest to fail")
+	}
+	if got := lastRehearsed(); !got.Equal(first) {
+		t.Errorf("LastRehearsed changed on failure: %v, want %v", got, first)
+	}
+	if entries, _ := os.ReadDir(p.Path); len(entries) != 3 {
+		t.Errorf("rehearse should not write recovered files, project has %v", entries)
+	}
+}
+
+func TestCollectSharesInteractive(t *testing.T) {
+	v2 := filepath.Join("..", "core", "testdata", "v2-bundle")
+	v1 := filepath.Join("..", "core", "testdata", "v1-bundle")
+	bob, err := os.ReadFile(filepath.Join(v2, "SHARE-bob.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file path, a piece from another set, a pasted piece, a duplicate,
+	// then the last piece needed. Anything after that is never read.
+	script := strings.Join([]string{
+		filepath.Join(v2, "SHARE-alice.txt"),
+		filepath.Join(v1, "SHARE-bob.txt"),
+		string(bob),
+		filepath.Join(v2, "SHARE-alice.txt"),
+		filepath.Join(v2, "SHARE-carol.txt"),
+		filepath.Join(v2, "SHARE-dave.txt"),
+	}, "\n")
+
+	var out bytes.Buffer
+	shares, labels, err := collectSharesInteractive(strings.NewReader(script), &out, "")
+	if err != nil {
+		t.Fatalf("collectSharesInteractive: %v\n%s", err, out.String())
+	}
+	if len(shares) != 3 {
+		t.Fatalf("got %d shares, want 3\n%s", len(shares), out.String())
+	}
+	if labels[0] != filepath.Join(v2, "SHARE-alice.txt") || labels[1] != "piece 2" {
+		t.Errorf("labels = %q", labels)
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"1 of 3, fingerprint",
+		"from a different set",
+		"already have piece 1",
+		"Bob's piece, 2 of 3",
+		"3 of 3",
+		"Enough pieces collected.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Piece 4") {
+		t.Errorf("should stop at the threshold:\n%s", output)
+	}
+
+	data := make([][]byte, len(shares))
+	for i, s := range shares {
+		data[i] = s.Data
+	}
+	recovered, err := core.Combine(data)
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if len(recovered) == 0 {
+		t.Error("empty passphrase")
+	}
+
+	t.Run("no pieces", func(t *testing.T) {
+		if _, _, err := collectSharesInteractive(strings.NewReader("done\n"), io.Discard, ""); err == nil {
+			t.Error("expected error when nothing was entered")
+		}
+	})
+
+	t.Run("26th word", func(t *testing.T) {
+		// Piece 20's first 25 words parse on their own, so the 26th word
+		// on the next line must be waited for; a blank line ends a piece
+		// without one.
+		piece := core.NewShare(2, 20, 20, 3, "", shares[0].Data)
+		words, err := piece.Words()
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := core.NewShare(2, 19, 20, 3, "", shares[1].Data)
+		otherWords, _ := other.Words()
+		script := strings.Join(words[:25], " ") + "\n" + words[25] + "\n" +
+			strings.Join(otherWords[:25], " ") + "\n\ndone\n"
+
+		var out bytes.Buffer
+		got, _, err := collectSharesInteractive(strings.NewReader(script), &out, "")
+		if err != nil {
+			t.Fatalf("collectSharesInteractive: %v\n%s", err, out.String())
+		}
+		if len(got) != 2 || got[0].Index != 20 || got[1].Index != 0 {
+			t.Fatalf("got %d pieces, want pieces 20 and unnumbered\n%s", len(got), out.String())
+		}
+	})
+
+	t.Run("broken block", func(t *testing.T) {
+		// Bob's block without its END line: the blank line after the data
+		// ends it, it is reported, and the next piece is still read.
+		broken := strings.Replace(string(bob), "-----END REMEMORY SHARE-----", "", 1)
+		script := strings.TrimRight(broken, "\n") + "\n\n" +
+			filepath.Join(v2, "SHARE-carol.txt") + "\ndone\n"
+
+		var out bytes.Buffer
+		got, labels, err := collectSharesInteractive(strings.NewReader(script), &out, "")
+		if err != nil {
+			t.Fatalf("collectSharesInteractive: %v\n%s", err, out.String())
+		}
+		if !strings.Contains(out.String(), "no END line") {
+			t.Errorf("expected the broken block to be reported:\n%s", out.String())
+		}
+		if len(got) != 1 || labels[0] != filepath.Join(v2, "SHARE-carol.txt") {
+			t.Errorf("got %d pieces (%q), want only Carol's\n%s", len(got), labels, out.String())
+		}
+	})
+}
+
+func TestRecoverChecksManifestAgainstReadme(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("..", "core", "testdata", "v2-bundle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestData, err := os.ReadFile(filepath.Join(dir, "MANIFEST.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	manifestPath := filepath.Join(work, "MANIFEST.age")
+	if err := os.WriteFile(manifestPath, manifestData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(checksum string) (string, error) {
+		readme := "METADATA FOOTER\nchecksum-manifest: " + checksum + "\n"
+		if err := os.WriteFile(filepath.Join(work, "README.txt"), []byte(readme), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"recover",
+			filepath.Join(dir, "SHARE-alice.txt"),
+			filepath.Join(dir, "SHARE-bob.txt"),
+			filepath.Join(dir, "SHARE-carol.txt"),
+			"--manifest", manifestPath,
+			"--verify-only",
+		})
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	stdout, err := run(core.HashBytes(manifestData))
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if strings.Contains(stdout, "doesn't match the checksum") {
+		t.Errorf("matching checksum should not warn:\n%s", stdout)
+	}
+
+	// A mismatch is reported, but decryption still decides.
+	stdout, err = run(core.HashBytes([]byte("some other manifest")))
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if !strings.Contains(stdout, "doesn't match the checksum in the README next to it") {
+		t.Errorf("mismatching checksum should warn:\n%s", stdout)
+	}
+}
+
+func TestRecoverChecksCommitment(t *testing.T) {
+	dir := t.TempDir()
+	secret := bytes.Repeat([]byte{7}, 32)
+	data, err := core.Split(secret, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := core.NewCommitment(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := core.Split(bytes.Repeat([]byte{9}, 32), 3, 3)
+
+	write := func(name string, index int, d []byte) string {
+		share := core.NewShare(2, index, 3, 3, name, d)
+		share.Headers = map[string]string{core.CommitmentHeader: commitment}
+		path := filepath.Join(dir, share.Filename())
+		if err := os.WriteFile(path, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	alice := write("Alice", 1, data[0])
+	bob := write("Bob", 2, data[1])
+	carol := write("Carol", 3, data[2])
+	mallory := write("Mallory", 3, other[2])
+
+	run := func(shares ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(append([]string{"recover"}, shares...), "--passphrase-only"))
+		defer resetFlags(recoverCmd)
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	out, err := run(alice, bob, carol)
+	if err != nil {
+		t.Fatalf("correct pieces: %v", err)
+	}
+	if !strings.Contains(out, core.RecoverPassphrase(secret, 2)) {
+		t.Errorf("expected the passphrase in the output:\n%s", out)
+	}
+
+	// A piece from another split still combines, but not to the secret.
+	_, err = run(alice, bob, mallory)
+	if err == nil || !strings.Contains(err.Error(), "don't rebuild the passphrase they were made from") {
+		t.Errorf("scrambled pieces: got %v", err)
+	}
+}
+
+func TestRecoverTrySubsets(t *testing.T) {
+	dir := t.TempDir()
+	secret := bytes.Repeat([]byte{5}, 32)
+	data, err := core.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := core.NewCommitment(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Dave's piece is subtly wrong: damaged before its checksum was taken,
+	// so it reads fine on its own.
+	data[3][7] ^= 0x10
+
+	var paths []string
+	for i, name := range []string{"Alice", "Bob", "Carol", "Dave", "Eve"} {
+		share := core.NewShare(2, i+1, 5, 3, name, data[i])
+		share.Headers = map[string]string{core.CommitmentHeader: commitment}
+		path := filepath.Join(dir, share.Filename())
+		if err := os.WriteFile(path, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	run := func(extra ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(append(append([]string{"recover"}, paths...), "--passphrase-only"), extra...))
+		defer resetFlags(recoverCmd)
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	if _, err := run(); err == nil {
+		t.Fatal("recover without --try-subsets should refuse pieces that disagree")
+	}
+
+	out, err := run("--try-subsets")
+	if err != nil {
+		t.Fatalf("--try-subsets: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"4 of 10 sets rebuild the passphrase",
+		paths[3] + " is in no set that works",
+		core.RecoverPassphrase(secret, 2),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	for _, good := range []string{paths[0], paths[1], paths[2], paths[4]} {
+		if strings.Contains(out, good+" is in no set") {
+			t.Errorf("%s should not be named as a suspect:\n%s", good, out)
+		}
+	}
+}
+
+func TestRecoverDuplicatePiece(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	alice := filepath.Join(dir, "SHARE-alice.txt")
+
+	// A second copy of Alice's file, as if it had been downloaded twice.
+	content, err := os.ReadFile(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceCopy := filepath.Join(t.TempDir(), "SHARE-alice (1).txt")
+	if err := os.WriteFile(aliceCopy, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs([]string{"recover", alice, aliceCopy, filepath.Join(dir, "SHARE-bob.txt"),
+		"--manifest", filepath.Join(dir, "MANIFEST.age"),
+		"--output", filepath.Join(t.TempDir(), "out"),
+	})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		resetFlags(recoverCmd)
+	})
+
+	err = rootCmd.Execute()
+	if err == nil {
+		t.Fatal("recover should refuse the same piece twice")
+	}
+	for _, want := range []string{alice, aliceCopy, "piece 1 (Alice's)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestRecoverSkipsDamagedPiece(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+
+	// David's piece with a flipped character, so its checksum fails.
+	content, err := os.ReadFile(filepath.Join(dir, "SHARE-david.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	damaged := filepath.Join(t.TempDir(), "SHARE-david.txt")
+	content = bytes.Replace(content, []byte("UMwXZ6"), []byte("UMwXZ7"), 1)
+	if err := os.WriteFile(damaged, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(shares ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(append([]string{"recover"}, shares...),
+			"--manifest", filepath.Join(dir, "MANIFEST.age"), "--verify-only"))
+		defer resetFlags(recoverCmd)
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	alice := filepath.Join(dir, "SHARE-alice.txt")
+	bob := filepath.Join(dir, "SHARE-bob.txt")
+	carol := filepath.Join(dir, "SHARE-carol.txt")
+
+	t.Run("enough left", func(t *testing.T) {
+		stdout, err := run(alice, bob, damaged, carol)
+		if err != nil {
+			t.Fatalf("recover should carry on without the damaged piece: %v", err)
+		}
+		for _, want := range []string{damaged, "checksum verification failed", "skipping 1 piece(s)", "These pieces unlock the manifest"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("output should contain %q, got:\n%s", want, stdout)
+			}
+		}
+	})
+
+	t.Run("too few left", func(t *testing.T) {
+		_, err := run(alice, damaged, bob)
+		if err == nil {
+			t.Fatal("recover should stop when the usable pieces fall short of the threshold")
+		}
+		for _, want := range []string{"only 2 of the 3 pieces", damaged, "at least 3"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error should contain %q, got: %v", want, err)
+			}
+		}
+	})
+}
+
+func TestRecoverJSON(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+
+	run := func(t *testing.T, shares ...string) (recoverReport, error) {
+		var stdout, stderr bytes.Buffer
+		args := []string{"recover"}
+		for _, s := range shares {
+			args = append(args, filepath.Join(dir, "SHARE-"+s+".txt"))
+		}
+		args = append(args,
+			"--manifest", filepath.Join(dir, "MANIFEST.age"),
+			"--output", filepath.Join(t.TempDir(), "out"),
+			"--json",
+		)
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(&stderr)
+		rootCmd.SetArgs(args)
+		t.Cleanup(func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		})
+		err := rootCmd.Execute()
+
+		// On failure cobra appends usage to the same buffer; the report
+		// comes first.
+		var report recoverReport
+		if decErr := json.NewDecoder(&stdout).Decode(&report); decErr != nil {
+			t.Fatalf("decoding report: %v\nstdout: %s", decErr, stdout.String())
+		}
+		return report, err
+	}
+
+	t.Run("success", func(t *testing.T) {
+		report, err := run(t, "alice", "bob", "carol")
+		if err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+		if !report.Status.OK || report.Status.Error != "" {
+			t.Errorf("status = %+v, want ok", report.Status)
+		}
+		if report.Status.Shares != 3 || report.Status.Threshold != 3 {
+			t.Errorf("status = %+v, want 3 shares, threshold 3", report.Status)
+		}
+
+		expectedDir := filepath.Join(dir, "expected-output")
+		want := make(map[string]int)
+		err = filepath.Walk(expectedDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(expectedDir, path)
+			want[filepath.ToSlash(rel)] = int(info.Size())
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make(map[string]int)
+		for _, f := range report.Files {
+			got[f.Name] = f.Size
+			if !strings.HasPrefix(f.Checksum, "sha256:") {
+				t.Errorf("%s: checksum = %q", f.Name, f.Checksum)
+			}
+			if f.Name == core.ManifestHashesFile || strings.HasSuffix(f.Name, "/"+core.ManifestHashesFile) {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(report.Output, "..", f.Name))
+			if err != nil {
+				t.Errorf("%s not written: %v", f.Name, err)
+			} else if core.HashBytes(data) != f.Checksum {
+				t.Errorf("%s: checksum doesn't match the written file", f.Name)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("files = %v, want %v", got, want)
+		}
+		for name, size := range want {
+			if got[name] != size {
+				t.Errorf("%s: size = %d, want %d", name, got[name], size)
+			}
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		report, err := run(t, "alice", "bob")
+		if err == nil {
+			t.Fatal("expected error with too few shares")
+		}
+		if report.Status.OK || !strings.Contains(report.Status.Error, "need at least 3") {
+			t.Errorf("status = %+v, want failure naming the threshold", report.Status)
+		}
+		if len(report.Files) != 0 {
+			t.Errorf("files = %v, want none", report.Files)
+		}
+	})
+}
+
+func TestConvertShare(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-alice.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := core.ParseShare(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convert := func(t *testing.T, in []byte, to string) (string, string) {
+		t.Helper()
+		var out, warn bytes.Buffer
+		if err := convertShare(&out, &warn, in, to); err != nil {
+			t.Fatalf("convert to %s: %v", to, err)
+		}
+		return out.String(), warn.String()
+	}
+
+	// Every path between formats keeps the share data and index.
+	paths := [][]string{
+		{"pem", "compact", "pem"},
+		{"pem", "words", "words"},
+		{"compact", "words"},
+		{"pem", "uri", "compact", "uri", "pem"},
+		{"pem", "pem"},
+	}
+	for _, path := range paths {
+		t.Run(strings.Join(path, "-"), func(t *testing.T) {
+			cur := content
+			for _, to := range path {
+				out, _ := convert(t, cur, to)
+				cur = []byte(out)
+			}
+			got, err := core.ParseShareAny(cur)
+			if err != nil {
+				t.Fatalf("parsing result: %v\n%s", err, cur)
+			}
+			if !bytes.Equal(got.Data, orig.Data) || got.Index != orig.Index {
+				t.Errorf("got index %d data %x, want index %d data %x", got.Index, got.Data, orig.Index, orig.Data)
+			}
+			if got.Total != 0 && (got.Total != orig.Total || got.Threshold != orig.Threshold) {
+				t.Errorf("got %d of %d, want %d of %d", got.Threshold, got.Total, orig.Threshold, orig.Total)
+			}
+		})
+	}
+
+	t.Run("pem keeps everything", func(t *testing.T) {
+		out, warn := convert(t, content, "pem")
+		if out != orig.Encode() {
+			t.Errorf("pem to pem changed the share:\n%s", out)
+		}
+		if warn != "" {
+			t.Errorf("unexpected warning: %s", warn)
+		}
+	})
+
+	t.Run("compact warns about holder", func(t *testing.T) {
+		out, warn := convert(t, content, "compact")
+		if out != orig.CompactEncode()+"\n" {
+			t.Errorf("compact = %q, want %q", out, orig.CompactEncode())
+		}
+		if !strings.Contains(warn, "holder name (Alice)") || !strings.Contains(warn, orig.Fingerprint()) {
+			t.Errorf("warning should name what was lost: %q", warn)
+		}
+
+		// Back to PEM there's no creation time to write.
+		pem, _ := convert(t, []byte(out), "pem")
+		if strings.Contains(pem, "Created:") {
+			t.Errorf("pem from compact shouldn't invent a creation time:\n%s", pem)
+		}
+	})
+
+	t.Run("words warns about threshold", func(t *testing.T) {
+		_, warn := convert(t, content, "words")
+		if !strings.Contains(warn, "holder name") || !strings.Contains(warn, "total and threshold") {
+			t.Errorf("warning should name what was lost: %q", warn)
+		}
+	})
+
+	t.Run("words can't go back", func(t *testing.T) {
+		words, _ := convert(t, content, "words")
+		for _, to := range []string{"pem", "compact"} {
+			if err := convertShare(io.Discard, io.Discard, []byte(words), to); err == nil {
+				t.Errorf("words to %s: expected error", to)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := convertShare(io.Discard, io.Discard, content, "qr"); err == nil {
+			t.Error("expected error for unknown format")
+		}
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		t.Setenv(sharePasswordEnv, "laptop password")
+		out, warn := convert(t, content, "encrypted")
+		if warn != "" || strings.Contains(out, "Alice") {
+			t.Errorf("encrypted output should keep everything and show nothing:\n%s%s", warn, out)
+		}
+		pem, _ := convert(t, []byte(out), "pem")
+		if pem != orig.Encode() {
+			t.Errorf("encrypted to pem changed the share:\n%s", pem)
+		}
+		var report bytes.Buffer
+		if err := verifyShare(&report, []byte(out), ""); err != nil {
+			t.Errorf("verify-share on the encrypted file: %v\n%s", err, report.String())
+		}
+
+		t.Setenv(sharePasswordEnv, "wrong password")
+		if err := convertShare(io.Discard, io.Discard, []byte(out), "pem"); !errors.Is(err, core.ErrWrongSharePassword) {
+			t.Errorf("wrong password: got %v", err)
+		}
+		t.Setenv(sharePasswordEnv, "")
+		if err := convertShare(io.Discard, io.Discard, []byte(out), "pem"); err == nil || !strings.Contains(err.Error(), sharePasswordEnv) {
+			t.Errorf("no password: got %v", err)
+		}
+		if err := convertShare(io.Discard, io.Discard, content, "encrypted"); err == nil {
+			t.Error("expected error encrypting without a password")
+		}
+	})
+}
+
+func TestEncryptDecryptCommands(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "notes.txt")
+	content := []byte("the spare key is with the neighbours\n")
+	if err := os.WriteFile(plain, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, stdin string, args ...string) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		rootCmd.SetIn(strings.NewReader(stdin))
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(args)
+		defer func() {
+			rootCmd.SetIn(nil)
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(encryptCmd)
+			resetFlags(decryptCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	encrypted := filepath.Join(dir, "notes.txt.age")
+	if _, err := run(t, "correct horse\n", "encrypt", "--passphrase-stdin", plain, "-o", encrypted); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	data, err := os.ReadFile(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("age-encryption.org/v1\n-> scrypt")) {
+		t.Errorf("output is not an age scrypt file: %q", data[:min(len(data), 40)])
+	}
+
+	t.Run("passphrase on stdin", func(t *testing.T) {
+		decrypted := filepath.Join(dir, "stdin.txt")
+		if _, err := run(t, "correct horse\n", "decrypt", "--passphrase-stdin", encrypted, "-o", decrypted); err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		got, err := os.ReadFile(decrypted)
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("decrypted = %q, %v; want %q", got, err, content)
+		}
+	})
+
+	t.Run("passphrase in environment", func(t *testing.T) {
+		t.Setenv(passphraseEnv, "correct horse")
+		out, err := run(t, string(data), "decrypt")
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if out != string(content) {
+			t.Errorf("decrypted = %q, want %q", out, content)
+		}
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		decrypted := filepath.Join(dir, "wrong.txt")
+		if _, err := run(t, "battery staple\n", "decrypt", "--passphrase-stdin", encrypted, "-o", decrypted); err == nil {
+			t.Fatal("decrypt with the wrong passphrase should fail")
+		}
+		if _, err := os.Stat(decrypted); !os.IsNotExist(err) {
+			t.Error("a failed decrypt should not leave an output file")
+		}
+	})
+
+	t.Run("no passphrase", func(t *testing.T) {
+		t.Setenv(passphraseEnv, "")
+		if _, err := run(t, "", "encrypt", plain); err == nil || !strings.Contains(err.Error(), passphraseEnv) {
+			t.Errorf("expected an error naming %s, got %v", passphraseEnv, err)
+		}
+	})
+
+	t.Run("passphrase in keyring", func(t *testing.T) {
+		saved := core.SystemKeyring
+		core.SystemKeyring = fakeKeyring{"rememory/notes": "correct horse\n"}
+		t.Cleanup(func() { core.SystemKeyring = saved })
+		t.Setenv(passphraseEnv, "")
+
+		out, err := run(t, string(data), "decrypt", "--keyring", "rememory/notes")
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if out != string(content) {
+			t.Errorf("decrypted = %q, want %q", out, content)
+		}
+
+		// A missing entry falls back to the environment.
+		t.Setenv(passphraseEnv, "correct horse")
+		out, err = run(t, string(data), "decrypt", "--keyring", "rememory/other")
+		if err != nil || out != string(content) {
+			t.Errorf("fallback decrypt = %q, %v", out, err)
+		}
+
+		if _, err := run(t, string(data), "decrypt", "--keyring", "rememory"); err == nil || !strings.Contains(err.Error(), "SERVICE/ACCOUNT") {
+			t.Errorf("expected an error about the entry name, got %v", err)
+		}
+	})
+}
+
+// fakeKeyring is a core.Keyring holding secrets by "service/account".
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) Get(service, account string) (string, error) {
+	secret, ok := k[service+"/"+account]
+	if !ok {
+		return "", core.ErrKeyringNotFound
+	}
+	return secret, nil
+}
+
+func TestCheckThreshold(t *testing.T) {
+	var out bytes.Buffer
+	if err := checkThreshold(&out, 3, 5, false); err != nil || out.Len() > 0 {
+		t.Errorf("3 of 5: got %v, output %q", err, out.String())
+	}
+
+	err := checkThreshold(&out, 5, 5, false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("5 of 5 without --force: expected an error mentioning --force, got %v", err)
+	}
+
+	out.Reset()
+	if err := checkThreshold(&out, 5, 5, true); err != nil {
+		t.Errorf("5 of 5 with --force: %v", err)
+	}
+	if !strings.Contains(out.String(), "losing any one") {
+		t.Errorf("5 of 5 with --force should still warn, got %q", out.String())
+	}
+
+	// --force only overrides the warning, not thresholds that can't work.
+	for _, threshold := range []int{1, 6} {
+		if err := checkThreshold(io.Discard, threshold, 5, true); err == nil {
+			t.Errorf("%d of 5 with --force: expected an error", threshold)
+		}
+	}
+}
+
+func TestRecoverAnswer(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	sharePaths := []string{filepath.Join(dir, "SHARE-alice.txt"), filepath.Join(dir, "SHARE-bob.txt"), filepath.Join(dir, "SHARE-carol.txt")}
+
+	// Lock the golden archive again, this time behind a recovery question.
+	var data [][]byte
+	for _, path := range sharePaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseShare(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, share.Data)
+	}
+	recovered, err := core.Combine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := core.RecoverPassphrase(recovered, 2)
+	encrypted, err := os.ReadFile(filepath.Join(dir, "MANIFEST.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := core.DecryptBytes(encrypted, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locked bytes.Buffer
+	if err := core.EncryptWithAnswer(&locked, bytes.NewReader(archive), passphrase, "Mr. Whiskers"); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(t.TempDir(), "MANIFEST.age")
+	if err := os.WriteFile(manifestPath, locked.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, stdin string, extra ...string) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		rootCmd.SetIn(strings.NewReader(stdin))
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		args := append([]string{"recover"}, sharePaths...)
+		args = append(args, "--manifest", manifestPath, "--stdout-file", "manifest/secret.txt")
+		rootCmd.SetArgs(append(args, extra...))
+		defer func() {
+			rootCmd.SetIn(nil)
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(recoverCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	t.Setenv(answerEnv, "")
+
+	t.Run("no answer", func(t *testing.T) {
+		_, err := run(t, "")
+		if err == nil || !strings.Contains(err.Error(), answerEnv) {
+			t.Errorf("expected an error pointing at %s, got %v", answerEnv, err)
+		}
+	})
+
+	t.Run("wrong answer", func(t *testing.T) {
+		if _, err := run(t, "Fluffy\n", "--answer-stdin"); err == nil {
+			t.Error("expected an error for the wrong answer, with every piece given")
+		}
+	})
+
+	t.Run("right answer", func(t *testing.T) {
+		stdout, err := run(t, "  MR. whiskers\n", "--answer-stdin")
+		if err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+		if !strings.Contains(stdout, "correct-horse") {
+			t.Errorf("unexpected secret.txt: %q", stdout)
+		}
+	})
+
+	t.Run("answer from the environment", func(t *testing.T) {
+		t.Setenv(answerEnv, "mr. whiskers")
+		if _, err := run(t, ""); err != nil {
+			t.Fatalf("recover: %v", err)
+		}
+	})
+}
+
+func TestSealKeyring(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "pet"), "pet", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	saved := core.SystemKeyring
+	core.SystemKeyring = fakeKeyring{"rememory/pet": "Mr. Whiskers\n"}
+	t.Cleanup(func() { core.SystemKeyring = saved })
+	t.Setenv(answerEnv, "")
+
+	run := func(args ...string) error {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		defer func() {
+			rootCmd.SetArgs(nil)
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			resetFlags(sealCmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	if err := run("seal", "--keyring", "rememory/pet"); err == nil || !strings.Contains(err.Error(), "has none") {
+		t.Fatalf("expected an error for a project without a question, got %v", err)
+	}
+
+	p.Question = "Name of our first cat?"
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// Without the keyring there is no answer to seal with.
+	if err := run("seal"); err == nil || !strings.Contains(err.Error(), answerEnv) {
+		t.Fatalf("expected an error pointing at %s, got %v", answerEnv, err)
+	}
+	if err := run("seal", "--keyring", "rememory/pet"); err != nil {
+		t.Fatalf("seal --keyring: %v", err)
+	}
+	// A missing entry falls back to the environment.
+	t.Setenv(answerEnv, "mr. whiskers")
+	if err := run("seal", "--keyring", "rememory/other"); err != nil {
+		t.Errorf("fallback seal: %v", err)
+	}
+}
+
+func TestSealRefusesShareInManifest(t *testing.T) {
+	share, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-alice.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := project.New(filepath.Join(t.TempDir(), "oops"), "oops", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Renamed, so only the content gives it away.
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "notes.txt"), share, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	rootCmd.SetArgs([]string{"seal"})
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		resetFlags(sealCmd)
+	})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "notes.txt") || !strings.Contains(err.Error(), "--allow-shares") {
+		t.Fatalf("expected seal to refuse and name notes.txt, got %v", err)
+	}
+	if _, err := os.Stat(p.ManifestAgePath()); !os.IsNotExist(err) {
+		t.Errorf("nothing should be sealed, got %v", err)
+	}
+}
+
+func TestSealPerFileKeys(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "split"), "split", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"passwords.txt":    "bank: hunter2",
+		"photos/album.txt": "not for everyone",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(p.ManifestPath(), path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(p.Path)
+
+	run := func(cmd *cobra.Command, args ...string) error {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(io.Discard)
+		defer func() {
+			rootCmd.SetArgs(nil)
+			rootCmd.SetOut(nil)
+			resetFlags(cmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	if err := run(sealCmd, "seal", "--per-file-keys"); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	loaded, err := project.Load(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.PerFileKeys() || len(loaded.Sealed.Secrets) != 2 {
+		t.Fatalf("expected two secrets recorded, got %+v", loaded.Sealed)
+	}
+	if err := run(verifyCmd, "verify"); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+
+	// Recover only the passwords, from the output directory.
+	t.Chdir(p.OutputPath())
+	outDir := filepath.Join(t.TempDir(), "recovered")
+	if err := run(recoverCmd, "recover", "--secret", "passwords.txt", "-o", outDir,
+		filepath.Join("shares", "passwords-txt", "SHARE-alice.txt"),
+		filepath.Join("shares", "passwords-txt", "SHARE-carol.txt"),
+	); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "manifest", "passwords.txt"))
+	if err != nil || string(got) != files["passwords.txt"] {
+		t.Errorf("passwords.txt: got %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "manifest", "photos")); !os.IsNotExist(err) {
+		t.Errorf("photos should stay sealed, got %v", err)
+	}
+
+	// Pieces of the other secret are turned away by name.
+	err = run(recoverCmd, "recover", "--secret", "passwords.txt", "--verify-only",
+		filepath.Join("shares", "passwords-txt", "SHARE-alice.txt"),
+		filepath.Join("shares", "photos", "SHARE-bob.txt"),
+	)
+	if err == nil || !strings.Contains(err.Error(), `secret "photos"`) {
+		t.Errorf("expected mixed pieces to be refused, got %v", err)
+	}
+
+	// Without --secret, the pieces say which manifest to open.
+	if err := run(recoverCmd, "recover", "--verify-only",
+		filepath.Join("shares", "photos", "SHARE-alice.txt"),
+		filepath.Join("shares", "photos", "SHARE-bob.txt"),
+	); err != nil {
+		t.Errorf("recover photos: %v", err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"plan"}, args...))
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(planCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run("--friends", "5", "--threshold", "4")
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	for _, want := range []string{
+		"2 of 5       99.95%\n",
+		"3 of 5       99.14%  (suggested)\n",
+		"4 of 5       91.85%  (chosen)\n",
+		"5 of 5       59.05%\n",
+		"Suggested: 3 of 5",
+		"With 4 of 5, recovery falls short of 99%.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = run("--friends", "3", "--loss", "0.5")
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if !strings.Contains(out, "No threshold reaches 99%") {
+		t.Errorf("plan output should say no threshold is enough:\n%s", out)
+	}
+
+	for _, args := range [][]string{
+		{"--friends", "1"},
+		{"--friends", "5", "--threshold", "6"},
+		{"--friends", "5", "--loss", "1.5"},
+		{"--friends", "5", "--target", "0"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("plan %v should fail", args)
+		}
+	}
+}
+
+func TestVerifyShareReadme(t *testing.T) {
+	dir := filepath.Join("..", "core", "testdata", "v2-bundle")
+	run := func(share string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		// SHARE-alice.txt stands in for Alice's README.txt: both hold her
+		// share block.
+		rootCmd.SetArgs([]string{"verify-share", filepath.Join(dir, share), "--readme", filepath.Join(dir, "SHARE-alice.txt")})
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(verifyShareCmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run("SHARE-alice.txt")
+	if err != nil {
+		t.Fatalf("verify-share: %v", err)
+	}
+	if !strings.Contains(out, "same piece") {
+		t.Errorf("output should confirm the match:\n%s", out)
+	}
+
+	out, err = run("SHARE-bob.txt")
+	if err == nil {
+		t.Fatal("expected an error for Bob's share against Alice's README")
+	}
+	if !strings.Contains(out, "MISMATCH") {
+		t.Errorf("output should report the mismatch:\n%s", out)
+	}
+}
+
+func TestMigrateV1(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := strings.Repeat("Q", 42) + "A" // base64 of 32 bytes, as v1 seals made
+	parts, err := core.Split([]byte(passphrase), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for i, name := range []string{"Alice", "Bob"} {
+		share := core.NewShare(1, i+1, 3, 2, name, parts[i])
+		path := filepath.Join(dir, "old-"+share.Filename())
+		if err := os.WriteFile(path, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	outDir := filepath.Join(dir, "new")
+
+	run := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"migrate-v1-to-v2"}, args...))
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+			resetFlags(migrateV1Cmd)
+		}()
+		err := rootCmd.Execute()
+		return stdout.String(), err
+	}
+
+	out, err := run(append(paths, "-o", outDir)...)
+	if err != nil {
+		t.Fatalf("migrate-v1-to-v2: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "old v1 shares are now obsolete") {
+		t.Errorf("output should warn that the old shares are obsolete:\n%s", out)
+	}
+	var data [][]byte
+	for _, name := range []string{"SHARE-alice.txt", "SHARE-bob.txt", "SHARE-3.txt"} {
+		content, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		share, err := core.ParseShare(content)
+		if err != nil || share.Version != 2 {
+			t.Fatalf("%s: %v, version %d", name, err, share.Version)
+		}
+		data = append(data, share.Data)
+	}
+	recovered, err := core.Combine(data[1:])
+	if err != nil || core.RecoverPassphrase(recovered, 2) != passphrase {
+		t.Errorf("new shares don't give the same passphrase: %v", err)
+	}
+
+	// A second run would overwrite the new shares, so it stops instead.
+	if _, err := run(append(paths, "-o", outDir)...); err == nil {
+		t.Error("expected an error when the new shares already exist")
+	}
+}
+
+func TestBuildWASM(t *testing.T) {
+	embedded := html.GetRecoverWASMBytes()
+	if len(embedded) == 0 {
+		t.Skip("recover.wasm not embedded")
+	}
+	out := filepath.Join(t.TempDir(), "recover.wasm")
+	rootCmd.SetArgs([]string{"build-wasm", "--out", out})
+	rootCmd.SetOut(io.Discard)
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		resetFlags(buildWASMCmd)
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build-wasm: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, embedded) {
+		t.Error("written WASM differs from the embedded one")
+	}
+}
+
+func TestSealWithWASM(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "wasm"), "wasm", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	run := func(args ...string) error {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(io.Discard)
+		defer func() {
+			rootCmd.SetArgs(nil)
+			rootCmd.SetOut(nil)
+			resetFlags(sealCmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	// Not a WebAssembly module: refused before anything is sealed.
+	notWASM := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(notWASM, []byte("just some notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("seal", "--wasm", notWASM); err == nil || !strings.Contains(err.Error(), "not a WebAssembly module") {
+		t.Fatalf("expected a WASM error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.OutputPath(), "MANIFEST.age")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing sealed, stat gave %v", err)
+	}
+
+	// A given WASM is embedded verbatim.
+	wasm := append([]byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}, "custom build"...)
+	wasmPath := filepath.Join(t.TempDir(), "recover.wasm")
+	if err := os.WriteFile(wasmPath, wasm, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("seal", "--wasm", wasmPath); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	files, err := bundle.ReadZip(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range files {
+		if f.Name != "recover.html" {
+			continue
+		}
+		found = true
+		got, err := html.ExtractWASMFromHTML(f.Content)
+		if err != nil {
+			t.Fatalf("extracting WASM: %v", err)
+		}
+		if !bytes.Equal(got, wasm) {
+			t.Errorf("embedded WASM = %q, want %q", got, wasm)
+		}
+	}
+	if !found {
+		t.Fatal("no recover.html in bundle")
+	}
+}
+
+func TestSealNoCLILink(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "nocli"), "nocli", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	rootCmd.SetArgs([]string{"seal", "--no-cli-link"})
+	rootCmd.SetOut(io.Discard)
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		resetFlags(sealCmd)
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+
+	files, err := bundle.ReadZip(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := 0
+	for _, f := range files {
+		if f.Name != "README.txt" && f.Name != "recover.html" {
+			continue
+		}
+		checked++
+		if bytes.Contains(f.Content, []byte("github.com/eljojo/rememory/releases")) {
+			t.Errorf("%s links to the CLI download", f.Name)
+		}
+	}
+	if checked != 2 {
+		t.Fatalf("found %d of README.txt and recover.html in the bundle", checked)
+	}
+}
+
+func TestSealEscrow(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "escrow"), "escrow", 3, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}, {Name: "Dave"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	run := func(cmd *cobra.Command, args ...string) error {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(io.Discard)
+		defer func() {
+			rootCmd.SetArgs(nil)
+			rootCmd.SetOut(nil)
+			resetFlags(cmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	escrowPath := filepath.Join(t.TempDir(), "SHARE-escrow.txt")
+	if err := run(sealCmd, "seal", "--escrow", escrowPath); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	content, err := os.ReadFile(escrowPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	escrow, err := core.ParseShare(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !escrow.IsEscrow() || escrow.Index != 5 {
+		t.Errorf("escrow piece: escrow %v, index %d", escrow.IsEscrow(), escrow.Index)
+	}
+
+	// Sealing again onto the same escrow file fails before anything else
+	// is replaced.
+	before := map[string][]byte{}
+	for _, path := range []string{p.ManifestAgePath(), filepath.Join(p.SharesPath(), "SHARE-alice.txt"), filepath.Join(p.Path, "project.yml")} {
+		if before[path], err = os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := run(sealCmd, "seal", "--escrow", escrowPath); err == nil {
+		t.Fatal("sealing onto an existing escrow file should fail")
+	}
+	for path, want := range before {
+		if got, err := os.ReadFile(path); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s changed by the failed seal", filepath.Base(path))
+		}
+	}
+
+	// The escrow piece and two friends' pieces recover.
+	t.Chdir(p.OutputPath())
+	outDir := filepath.Join(t.TempDir(), "recovered")
+	if err := run(recoverCmd, "recover", "-o", outDir, escrowPath,
+		filepath.Join("shares", "SHARE-alice.txt"),
+		filepath.Join("shares", "SHARE-dave.txt"),
+	); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "manifest", "secret.txt"))
+	if err != nil || string(got) != "the secret" {
+		t.Errorf("secret.txt: got %q, %v", got, err)
+	}
+}
+
+func TestReissueNewShare(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "reissue"), "reissue", 2, []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(p.Path)
+
+	run := func(cmd *cobra.Command, args ...string) error {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(io.Discard)
+		defer func() {
+			rootCmd.SetArgs(nil)
+			rootCmd.SetOut(nil)
+			resetFlags(cmd)
+		}()
+		return rootCmd.Execute()
+	}
+
+	if err := run(sealCmd, "seal"); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+
+	// Carol's piece is lost, and so is its checksum, so it can't be
+	// restored exactly.
+	carolPath := filepath.Join(p.SharesPath(), "SHARE-carol.txt")
+	if err := os.Remove(carolPath); err != nil {
+		t.Fatal(err)
+	}
+	p, err = project.Load(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Sealed.Shares[2].Checksum = ""
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	pieces := []string{filepath.Join(p.SharesPath(), "SHARE-alice.txt"), filepath.Join(p.SharesPath(), "SHARE-bob.txt")}
+	if err := run(reissueCmd, append([]string{"reissue", "--holder", "Carol"}, pieces...)...); !errors.Is(err, bundle.ErrOriginalShareLost) {
+		t.Fatalf("reissue without --allow-new-share: got %v, want ErrOriginalShareLost", err)
+	}
+	if _, err := os.Stat(carolPath); !os.IsNotExist(err) {
+		t.Error("a refused reissue wrote Carol's piece")
+	}
+
+	if err := run(reissueCmd, append([]string{"reissue", "--holder", "Carol", "--allow-new-share"}, pieces...)...); err != nil {
+		t.Fatalf("reissue --allow-new-share: %v", err)
+	}
+	p, err = project.Load(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := crypto.HashFile(carolPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Sealed.Shares[2].Checksum; got != checksum {
+		t.Errorf("project.yml has checksum %q for Carol's reissued piece, want %q", got, checksum)
+	}
+	if err := run(verifyCmd, "verify"); err != nil {
+		t.Errorf("verify after reissue: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var confirmCmd = &cobra.Command{
+	Use:   "confirm --holder <name> <code>",
+	Short: "Check the confirmation code a friend read back from their bundle",
+	Long: `Confirm checks that a friend has received and opened their bundle.
+
+The bottom of each README.txt and README.pdf has a confirmation code, such
+as K7QM-2XRD. It is worked out from that friend's piece, so only someone
+holding the piece can read it back to you, and it reveals nothing about
+the piece itself. Case, spaces and dashes don't matter.
+
+Run this command inside the project directory.
+
+Example:
+  rememory confirm --holder Alice K7QM-2XRD`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfirm,
+}
+
+var confirmHolder string
+
+func init() {
+	rootCmd.AddCommand(confirmCmd)
+	confirmCmd.Flags().StringVar(&confirmHolder, "holder", "", "Name of the friend who read the code")
+	_ = confirmCmd.MarkFlagRequired("holder")
+}
+
+func runConfirm(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return err
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project is not sealed yet; run 'rememory seal' first")
+	}
+	if p.PerFileKeys() {
+		return fmt.Errorf("confirming: %w", project.ErrPerFileKeys)
+	}
+
+	var info *project.ShareInfo
+	for i, si := range p.Sealed.Shares {
+		if strings.EqualFold(si.Friend, confirmHolder) {
+			info = &p.Sealed.Shares[i]
+			break
+		}
+	}
+	if info == nil {
+		return fmt.Errorf("no friend named %q in this project", confirmHolder)
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.Path, info.File))
+	if err != nil {
+		return fmt.Errorf("reading share for %s: %w", info.Friend, err)
+	}
+	share, err := core.ParseShare(content)
+	if err != nil {
+		return fmt.Errorf("parsing share for %s: %w", info.Friend, err)
+	}
+	defer share.Zeroize()
+
+	if !bundle.VerifyAckToken(share, args[0]) {
+		return fmt.Errorf("%s doesn't match %s's confirmation code; check they read it from their own bundle", args[0], info.Friend)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s has their bundle and could open it.\n", green("✓"), info.Friend)
+	return nil
+}
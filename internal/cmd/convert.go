@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert --to compact|pem|uri|words|encrypted [file]",
+	Short: "Convert a share between the PEM, compact, URI and word formats",
+	Long: `Convert reads a share in any format and prints it in another: the full
+PEM block from SHARE-*.txt, a short compact string (RM2:...) that fits in a
+message, the same string as a rememory://share/ URI for NFC tags, QR codes
+and links, or the 25 recovery words.
+
+Use "-" or omit the file to read from stdin. The converted share goes to
+stdout; anything the target format can't hold is noted on stderr.
+
+The compact, URI and word formats carry less than the PEM block. Both drop the
+holder's name and creation time (and with it the fingerprint), and words
+also drop the total and threshold. Words can't be turned back into PEM or
+compact form for that reason.
+
+With --to encrypted, the PEM block is encrypted with a password of your
+own, read from the ` + sharePasswordEnv + ` environment variable, for keeping
+the share on a laptop. The result is an age file that convert, recover and
+verify-share open when ` + sharePasswordEnv + ` is set. This password only
+protects the file; it has nothing to do with recovery.
+
+Examples:
+  rememory convert --to compact SHARE-alice.txt
+  rememory convert --to words SHARE-alice.txt
+  rememory convert --to uri SHARE-alice.txt
+  ` + sharePasswordEnv + `=... rememory convert --to encrypted SHARE-alice.txt > SHARE-alice.txt.age
+  pbpaste | rememory convert --to pem > SHARE-alice.txt`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConvert,
+}
+
+var convertTo string
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Target format: compact, pem, uri, words or encrypted")
+	convertCmd.MarkFlagRequired("to")
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	var content []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		content, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		content, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("reading share: %w", err)
+	}
+
+	return convertShare(cmd.OutOrStdout(), cmd.ErrOrStderr(), content, convertTo)
+}
+
+// convertShare parses a share in any format and writes it to w in the format
+// named by to. Metadata the target format can't represent is listed on warn.
+func convertShare(w, warn io.Writer, content []byte, to string) error {
+	share, err := parseShareInput(content, "")
+	if err != nil {
+		return fmt.Errorf("share could not be read: %w", err)
+	}
+	defer share.Zeroize()
+	if err := share.Verify(); err != nil {
+		return err
+	}
+
+	var out string
+	var lost []string
+	switch to {
+	case "pem":
+		if share.Total == 0 {
+			return fmt.Errorf("words don't record the total and threshold, so they can't be converted to pem")
+		}
+		out = share.Encode()
+	case "compact":
+		if share.Total == 0 {
+			return fmt.Errorf("words don't record the total and threshold, so they can't be converted to compact")
+		}
+		out = share.CompactEncode() + "\n"
+		lost = lostMetadata(share)
+	case "uri":
+		if share.Total == 0 {
+			return fmt.Errorf("words don't record the total and threshold, so they can't be converted to a uri")
+		}
+		out = share.URI() + "\n"
+		lost = lostMetadata(share)
+	case "words":
+		words, err := share.Words()
+		if err != nil {
+			return err
+		}
+		var sb strings.Builder
+		for i, word := range words {
+			fmt.Fprintf(&sb, "%2d. %s\n", i+1, word)
+		}
+		out = sb.String()
+		lost = lostMetadata(share)
+		if share.Total > 0 {
+			lost = append(lost, fmt.Sprintf("total and threshold (%d of %d)", share.Threshold, share.Total))
+		}
+		if share.Index > 15 {
+			lost = append(lost, fmt.Sprintf("piece number (%d; words only hold 1 to 15)", share.Index))
+		}
+	case "encrypted":
+		if share.Total == 0 {
+			return fmt.Errorf("words don't record the total and threshold, so they can't be converted to encrypted")
+		}
+		password := os.Getenv(sharePasswordEnv)
+		if password == "" {
+			return fmt.Errorf("no password: set %s to the password to protect the share with", sharePasswordEnv)
+		}
+		encrypted, err := share.EncodeEncrypted(password)
+		if err != nil {
+			return err
+		}
+		out = string(encrypted)
+	default:
+		return fmt.Errorf("unknown format %q (use compact, pem, uri, words or encrypted)", to)
+	}
+
+	for _, what := range lost {
+		fmt.Fprintf(warn, "%s the %s format doesn't keep the %s\n", yellow("Note:"), to, what)
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// sharePasswordEnv names the environment variable holding the holder's own
+// password for a share saved with convert --to encrypted. Like
+// passphraseEnv, it is never taken as a flag.
+const sharePasswordEnv = "REMEMORY_SHARE_PASSWORD"
+
+// parseShareInput parses a share in any format, as ParseShareAnyLang does.
+// A share saved with convert --to encrypted is decrypted first, with the
+// password in sharePasswordEnv.
+func parseShareInput(content []byte, lang core.Lang) (*core.Share, error) {
+	if !core.IsEncryptedShare(content) {
+		return core.ParseShareAnyLang(content, lang)
+	}
+	password := os.Getenv(sharePasswordEnv)
+	if password == "" {
+		return nil, fmt.Errorf("this share is protected with a password: set %s to it", sharePasswordEnv)
+	}
+	return core.ParseEncryptedShare(content, password)
+}
+
+// lostMetadata lists the PEM-only fields set on share, which the compact, URI
+// and word formats all drop.
+func lostMetadata(share *core.Share) []string {
+	var lost []string
+	if share.Holder != "" {
+		lost = append(lost, fmt.Sprintf("holder name (%s)", share.Holder))
+	}
+	if fp := share.Fingerprint(); fp != "" {
+		lost = append(lost, fmt.Sprintf("creation time or fingerprint (%s)", fp))
+	}
+	return lost
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// passphraseEnv names the environment variable encrypt and decrypt read the
+// passphrase from when --passphrase-stdin isn't given. There is deliberately
+// no flag for the passphrase itself, which would show up in process lists.
+const passphraseEnv = "REMEMORY_PASSPHRASE"
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt [file] [-o out.age]",
+	Short: "Encrypt any file with a passphrase, as seal does for the manifest",
+	Long: `Encrypt encrypts a single file with age's passphrase (scrypt) mode, the
+same way seal encrypts MANIFEST.age. It works outside any project and is
+handy for one-off files and for testing.
+
+The passphrase is read from the first line of stdin with --passphrase-stdin,
+or else from the ` + passphraseEnv + ` environment variable. It is never
+taken as an argument, so it doesn't show up in process lists.
+
+With --keyring SERVICE/ACCOUNT it is read from the system keyring instead:
+the Keychain on macOS, or the Secret Service on Linux (with secret-tool
+installed). If there is no keyring or no such entry, it says so and falls
+back to stdin or the environment.
+
+The file is read from stdin if omitted or "-" (not with --passphrase-stdin),
+and the result goes to stdout unless -o is given. Any age tool can decrypt
+it with the same passphrase.
+
+Examples:
+  rememory encrypt --passphrase-stdin notes.txt -o notes.txt.age
+  ` + passphraseEnv + `=... rememory encrypt < notes.txt > notes.txt.age
+  rememory encrypt --keyring rememory/notes notes.txt -o notes.txt.age`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCrypt(cmd, args, core.Encrypt)
+	},
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt [file.age] [-o out]",
+	Short: "Decrypt a passphrase-encrypted age file",
+	Long: `Decrypt reverses encrypt, and also opens any age file encrypted with a
+passphrase, such as MANIFEST.age when you already know its passphrase.
+
+The passphrase and the input and output are handled as for encrypt.
+
+Examples:
+  rememory decrypt --passphrase-stdin notes.txt.age -o notes.txt
+  ` + passphraseEnv + `=... rememory decrypt < notes.txt.age
+  rememory decrypt --keyring rememory/notes notes.txt.age -o notes.txt`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCrypt(cmd, args, core.Decrypt)
+	},
+}
+
+var (
+	cryptOutput          string
+	cryptPassphraseStdin bool
+	cryptKeyring         string
+)
+
+func init() {
+	for _, c := range []*cobra.Command{encryptCmd, decryptCmd} {
+		rootCmd.AddCommand(c)
+		c.Flags().StringVarP(&cryptOutput, "output", "o", "", "Write to this file instead of stdout")
+		c.Flags().BoolVar(&cryptPassphraseStdin, "passphrase-stdin", false, "Read the passphrase from the first line of stdin")
+		c.Flags().StringVar(&cryptKeyring, "keyring", "", "Read the passphrase from the system keyring entry SERVICE/ACCOUNT")
+	}
+}
+
+// runCrypt sets up the passphrase, input and output for encrypt or decrypt
+// and runs crypt (core.Encrypt or core.Decrypt) on them.
+func runCrypt(cmd *cobra.Command, args []string, crypt func(io.Writer, io.Reader, string) error) error {
+	fromStdin := len(args) == 0 || args[0] == "-"
+	if fromStdin && cryptPassphraseStdin {
+		return fmt.Errorf("with --passphrase-stdin, give the input as a file")
+	}
+
+	passphrase, err := keyringSecret(cmd.ErrOrStderr(), cryptKeyring, "--passphrase-stdin or "+passphraseEnv)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		passphrase, err = cryptPassphrase(cmd.InOrStdin(), cryptPassphraseStdin)
+		if err != nil {
+			return err
+		}
+	}
+
+	var in io.Reader = cmd.InOrStdin()
+	if !fromStdin {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening input: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if cryptOutput == "" {
+		return crypt(cmd.OutOrStdout(), in, passphrase)
+	}
+
+	out, err := os.OpenFile(cryptOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating output: %w", err)
+	}
+	err = crypt(out, in, passphrase)
+	if closeErr := out.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Don't leave a partial file that looks like a result.
+		os.Remove(cryptOutput)
+		return err
+	}
+	return nil
+}
+
+// keyringSecret reads a passphrase or answer from the system keyring entry
+// named by entry ("service/account"). It returns "" with no error when entry
+// is empty, or when the keyring or the entry is missing, after saying on
+// status that fallback is used instead, so the caller can go on to it.
+func keyringSecret(status io.Writer, entry, fallback string) (string, error) {
+	if entry == "" {
+		return "", nil
+	}
+	service, account, ok := strings.Cut(entry, "/")
+	if !ok || service == "" || account == "" {
+		return "", fmt.Errorf("--keyring takes SERVICE/ACCOUNT, got %q", entry)
+	}
+	passphrase, err := core.PassphraseFromKeyring(service, account)
+	if errors.Is(err, core.ErrKeyringUnavailable) || errors.Is(err, core.ErrKeyringNotFound) {
+		fmt.Fprintf(status, "%s %v; using %s instead\n", yellow("Note:"), err, fallback)
+		return "", nil
+	}
+	return passphrase, err
+}
+
+// cryptPassphrase reads the passphrase from the first line of stdin, or from
+// the environment.
+func cryptPassphrase(stdin io.Reader, fromStdin bool) (string, error) {
+	if !fromStdin {
+		passphrase := os.Getenv(passphraseEnv)
+		if passphrase == "" {
+			return "", fmt.Errorf("no passphrase: use --passphrase-stdin or set %s", passphraseEnv)
+		}
+		return passphrase, nil
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	passphrase := strings.TrimRight(line, "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("no passphrase on stdin")
+	}
+	return passphrase, nil
+}
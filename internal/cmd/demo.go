@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
 )
@@ -120,7 +121,11 @@ Note: In a real project, these would be your actual sensitive credentials.
 	fmt.Printf("  %s manifest/passwords.txt\n", green("✓"))
 	fmt.Println()
 
-	if err := sealProject(p, "", false); err != nil {
+	wasmBytes, err := embeddedRecoverWASM()
+	if err != nil {
+		return err
+	}
+	if err := sealProject(p, "", releaseURL(cmd), false, manifest.ArchiveOptions{}, "", wasmBytes, "", ""); err != nil {
 		return err
 	}
 
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <a.zip> <b.zip>",
+	Short: "Show what changed between two bundles",
+	Long: `Diff compares two bundle ZIPs, for example a friend's bundle before and
+after running 'rememory bundle' or sealing again, and lists what differs:
+the holder, project, threshold, version and checksums in the README
+metadata, and which files were added, removed or changed.
+
+The pieces themselves are not compared byte for byte, since every seal makes
+new ones. Instead the fingerprints are shown: the same fingerprint means both
+pieces come from the same set and can be combined with each other.
+
+Nothing is decrypted. Use --json for a machine-readable report.
+
+Example:
+  rememory diff old/bundle-alice.zip output/bundles/bundle-alice.zip`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+var diffJSON bool
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	a, err := bundle.ExportBundle(args[0])
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[0], err)
+	}
+	b, err := bundle.ExportBundle(args[1])
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[1], err)
+	}
+
+	d := bundle.DiffBundles(a, b)
+	out := cmd.OutOrStdout()
+	if diffJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	printBundleDiff(out, d)
+	return nil
+}
+
+// printBundleDiff writes the human-readable form of a bundle diff.
+func printBundleDiff(w io.Writer, d *bundle.BundleDiff) {
+	if d.Identical {
+		fmt.Fprintln(w, "The bundles are identical.")
+		return
+	}
+
+	switch {
+	case d.SameFingerprint:
+		fmt.Fprintf(w, "Fingerprint: %s in both (pieces from the same set)\n", d.FingerprintA)
+	case d.FingerprintA == "" || d.FingerprintB == "":
+		fmt.Fprintln(w, "Fingerprint: unknown for at least one bundle")
+	default:
+		fmt.Fprintf(w, "Fingerprint: %s → %s (pieces from different sets, don't mix them)\n", d.FingerprintA, d.FingerprintB)
+	}
+
+	if len(d.Changes) > 0 {
+		fmt.Fprintln(w, "\nChanged:")
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "  %-22s %s → %s\n", c.Field, c.A, c.B)
+		}
+	}
+
+	if len(d.Files) > 0 {
+		fmt.Fprintln(w, "\nFiles:")
+		for _, f := range d.Files {
+			switch f.Change {
+			case "added":
+				fmt.Fprintf(w, "  %s %-24s %s\n", green("+"), f.Name, formatSize(int64(f.SizeB)))
+			case "removed":
+				fmt.Fprintf(w, "  %s %-24s %s\n", red("-"), f.Name, formatSize(int64(f.SizeA)))
+			default:
+				fmt.Fprintf(w, "  %s %-24s %s → %s\n", yellow("~"), f.Name, formatSize(int64(f.SizeA)), formatSize(int64(f.SizeB)))
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate bundle sizes before sealing",
+	Long: `Estimate works out roughly how big each friend's bundle and the whole
+output will be, without sealing: the manifest is archived and compressed
+to measure it, but nothing is encrypted and no pieces are made.
+
+Each bundle holds recover.html (with the recovery tool built in, and the
+manifest too when it is 5 MB or less), the README as text and PDF, and
+otherwise MANIFEST.age.
+
+Run it inside a project directory, or point it at any folder with
+--manifest and say how many friends with --friends.
+
+Examples:
+  rememory estimate
+  rememory estimate --manifest ~/secrets --friends 5`,
+	RunE: runEstimate,
+}
+
+var (
+	estimateManifest string
+	estimateFriends  int
+	estimateJSON     bool
+)
+
+func init() {
+	estimateCmd.Flags().StringVar(&estimateManifest, "manifest", "", "Folder to estimate for, instead of the project's manifest/")
+	estimateCmd.Flags().IntVar(&estimateFriends, "friends", 0, "Number of friends, when not in a project")
+	estimateCmd.Flags().String("compression", string(core.CompressionGzip), "Compression for the manifest archive: gzip or zstd")
+	estimateCmd.Flags().Bool("no-embed-manifest", false, "Estimate as if sealing with --no-embed-manifest")
+	estimateCmd.Flags().Bool("no-cli-link", false, "Estimate as if sealing with --no-cli-link")
+	estimateCmd.Flags().BoolVar(&estimateJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(estimateCmd)
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	compressionName, _ := cmd.Flags().GetString("compression")
+	compression, err := core.ParseCompression(compressionName)
+	if err != nil {
+		return err
+	}
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+
+	p, err := estimateProject()
+	if err != nil {
+		return err
+	}
+	manifestDir := p.ManifestPath()
+	if estimateManifest != "" {
+		manifestDir = estimateManifest
+	}
+
+	fileCount, err := manifest.CountFiles(manifestDir)
+	if err != nil {
+		return fmt.Errorf("checking manifest directory: %w", err)
+	}
+	if fileCount == 0 {
+		return fmt.Errorf("manifest directory is empty: %s", manifestDir)
+	}
+
+	var archiveBuf bytes.Buffer
+	if _, err := manifest.ArchiveCompressed(&archiveBuf, manifestDir, compression); err != nil {
+		return fmt.Errorf("archiving manifest: %w", err)
+	}
+
+	wasmBytes := html.GetRecoverWASMBytes()
+	if len(wasmBytes) == 0 {
+		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+
+	est, err := bundle.EstimateSizes(p, bundle.Config{
+		Version:          version,
+		GitHubReleaseURL: releaseURL(cmd),
+		WASMBytes:        wasmBytes,
+		RecoveryURL:      core.DefaultRecoveryURL,
+		NoEmbedManifest:  noEmbedManifest,
+	}, core.EncryptedSize(int64(archiveBuf.Len())))
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if estimateJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(est)
+	}
+	printEstimate(out, est)
+	return nil
+}
+
+// estimateProject returns the project to estimate for: the one in the
+// current directory, or with --friends a stand-in of that many friends.
+func estimateProject() (*project.Project, error) {
+	if estimateFriends != 0 {
+		if estimateManifest == "" {
+			return nil, fmt.Errorf("--friends needs --manifest")
+		}
+		if estimateFriends < 2 || estimateFriends > 255 {
+			return nil, fmt.Errorf("--friends must be between 2 and 255, got %d", estimateFriends)
+		}
+		p := &project.Project{Name: "estimate", Threshold: 2}
+		for i := range estimateFriends {
+			p.Friends = append(p.Friends, project.Friend{Name: fmt.Sprintf("Friend %d", i+1)})
+		}
+		return p, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("no rememory project found (use --manifest and --friends outside a project)")
+	}
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading project: %w", err)
+	}
+	return p, nil
+}
+
+// printEstimate writes the human-readable form of a size estimate.
+func printEstimate(w io.Writer, est *bundle.SizeEstimate) {
+	fmt.Fprintf(w, "MANIFEST.age: about %s", formatSize(est.ManifestSize))
+	if est.ManifestEmbedded {
+		fmt.Fprint(w, " (embedded in recover.html)")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "\nBundles:")
+	for _, b := range est.Bundles {
+		fmt.Fprintf(w, "  %-24s about %s\n", b.Friend, formatSize(b.Size))
+	}
+	fmt.Fprintf(w, "\nTotal: about %s\n", formatSize(est.Total))
+}
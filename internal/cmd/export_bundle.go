@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+var exportBundleCmd = &cobra.Command{
+	Use:   "export-bundle <bundle.zip>",
+	Short: "Describe a bundle in machine-readable form",
+	Long: `Export-bundle reads a bundle ZIP and prints its holder, metadata, file
+list with checksums, and the holder's share.
+
+Nothing is decrypted: the manifest is described only by its checksum, and the
+only share included is the one already in the bundle.
+
+Example:
+  rememory export-bundle bundle-alice.zip --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportBundle,
+}
+
+var exportBundleJSON bool
+
+func init() {
+	rootCmd.AddCommand(exportBundleCmd)
+	exportBundleCmd.Flags().BoolVar(&exportBundleJSON, "json", false, "Output as JSON")
+}
+
+func runExportBundle(cmd *cobra.Command, args []string) error {
+	export, err := bundle.ExportBundle(args[0])
+	if err != nil {
+		return fmt.Errorf("exporting bundle: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if exportBundleJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(export)
+	}
+
+	fmt.Fprintf(out, "Holder:      %s\n", export.Holder)
+	fmt.Fprintf(out, "Project:     %s\n", export.Metadata.Project)
+	fmt.Fprintf(out, "Share:       %d of %d (threshold %d)\n", export.Share.Index, export.Share.Total, export.Share.Threshold)
+	if export.Share.Fingerprint != "" {
+		fmt.Fprintf(out, "Fingerprint: %s\n", export.Share.Fingerprint)
+	}
+	fmt.Fprintln(out, "Files:")
+	for _, f := range export.Files {
+		fmt.Fprintf(out, "  %-24s %10s  %s\n", f.Name, formatSize(int64(f.Size)), truncateHash(f.Checksum))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var exportContactsCmd = &cobra.Command{
+	Use:   "export-contacts [-o contacts.vcf]",
+	Short: "Write every friend's contact info to one vCard file",
+	Long: `Export-contacts writes the friends in project.yml to a vCard file, one
+card each, to import into your phone or address book. Then everyone who
+holds a piece is easy to reach when it's time to recover.
+
+Each friend's contact is split on commas, semicolons and "or". Email
+addresses and phone numbers get their own fields; anything else, such as a
+postal address, goes into the card's note, which also names the project
+and the friend's piece. Friends without contact info are skipped with a
+warning.
+
+Run this command inside the project directory. Use -o - to write to stdout.
+
+Example:
+  rememory export-contacts -o contacts.vcf`,
+	Args: cobra.NoArgs,
+	RunE: runExportContacts,
+}
+
+var exportContactsOutput string
+
+func init() {
+	rootCmd.AddCommand(exportContactsCmd)
+	exportContactsCmd.Flags().StringVarP(&exportContactsOutput, "output", "o", "contacts.vcf", "File to write the vCards to, or - for stdout")
+}
+
+func runExportContacts(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return fmt.Errorf("no rememory project found (run 'rememory init' first)")
+	}
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	var buf bytes.Buffer
+	skipped, err := bundle.WriteVCards(&buf, p)
+	if err != nil {
+		return err
+	}
+	status := cmd.OutOrStdout()
+	if exportContactsOutput == "-" {
+		status = cmd.ErrOrStderr()
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(status, "  Warning: skipping %s (no contact info)\n", name)
+	}
+	written := len(p.Friends) - len(skipped)
+	if written == 0 {
+		return fmt.Errorf("no friend has contact info; add some to project.yml")
+	}
+
+	if exportContactsOutput == "-" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(exportContactsOutput, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("writing contacts: %w", err)
+	}
+	fmt.Fprintf(status, "%s %s (%d of %d friends)\n", green("✓"), exportContactsOutput, written, len(p.Friends))
+	return nil
+}
@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/translations"
 	"github.com/spf13/cobra"
 )
 
@@ -23,26 +25,49 @@ Commands:
 The create and recover HTML files are self-contained with embedded WASM binary,
 JavaScript, and CSS. They work fully offline.
 
+recover.html comes in every language. --langs limits it to some, for a
+smaller file: only their translations and word lists are embedded. English
+is always kept, as the fallback.
+
+recover.html can be re-skinned with a logo, colours or help text of your own:
+--styles replaces the built-in stylesheet, --extra-css adds to it, and
+--extra-js runs a script after the page has loaded. Recovery itself is
+unchanged.
+
 Examples:
   rememory html index > index.html
   rememory html create > maker.html
   rememory html docs > docs.html
-  rememory html recover > recover.html`,
+  rememory html recover > recover.html
+  rememory html recover --langs es -o recover.html
+  rememory html recover --extra-css brand.css -o recover.html`,
 	Args: cobra.ExactArgs(1),
 	RunE: runHTML,
 }
 
-var htmlOutputFile string
+var (
+	htmlOutputFile string
+	htmlNoCLILink  bool
+	htmlLangs      []string
+	htmlStyles     string
+	htmlExtraCSS   string
+	htmlExtraJS    string
+)
 
 func init() {
 	htmlCmd.Flags().StringVarP(&htmlOutputFile, "output", "o", "", "Output file path (default: stdout)")
+	htmlCmd.Flags().BoolVar(&htmlNoCLILink, "no-cli-link", false, "Leave the CLI download link out of recover.html")
+	htmlCmd.Flags().StringSliceVar(&htmlLangs, "langs", nil, "Languages to include in recover.html, comma-separated (default: all; English is always included)")
+	htmlCmd.Flags().StringVar(&htmlStyles, "styles", "", "CSS file to use in recover.html instead of the built-in styles")
+	htmlCmd.Flags().StringVar(&htmlExtraCSS, "extra-css", "", "CSS file to add to recover.html after the styles")
+	htmlCmd.Flags().StringVar(&htmlExtraJS, "extra-js", "", "JavaScript file to run in recover.html after it loads")
 	rootCmd.AddCommand(htmlCmd)
 }
 
 func runHTML(cmd *cobra.Command, args []string) error {
 	subcommand := args[0]
 
-	var content string
+	var content, note string
 	// Use specific release URL if version is a tag, otherwise use latest
 	var githubURL string
 	if strings.HasPrefix(version, "v") {
@@ -67,7 +92,29 @@ func runHTML(cmd *cobra.Command, args []string) error {
 		if len(recoverWASM) == 0 {
 			return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
 		}
-		content = html.GenerateRecoverHTML(recoverWASM, version, githubURL, nil)
+		if htmlNoCLILink {
+			githubURL = ""
+		}
+		for _, l := range htmlLangs {
+			if !slices.Contains(translations.Languages, l) {
+				return fmt.Errorf("unsupported language %q (supported: %s)", l, strings.Join(translations.Languages, ", "))
+			}
+		}
+		branding, err := readBranding()
+		if err != nil {
+			return err
+		}
+		content, err = html.GenerateRecoverHTMLWithBranding(recoverWASM, version, githubURL, nil, branding, htmlLangs...)
+		if err != nil {
+			return err
+		}
+		if len(htmlLangs) > 0 {
+			full, err := html.GenerateRecoverHTMLWithBranding(recoverWASM, version, githubURL, nil, branding)
+			if err != nil {
+				return err
+			}
+			note = fmt.Sprintf(", %s smaller than with every language", formatSize(int64(len(full)-len(content))))
+		}
 
 	case "create":
 		// Generate maker.html (bundle creation tool)
@@ -87,10 +134,37 @@ func runHTML(cmd *cobra.Command, args []string) error {
 		if err := os.WriteFile(htmlOutputFile, []byte(content), 0644); err != nil {
 			return fmt.Errorf("writing file: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "Generated %s (%s)\n", htmlOutputFile, formatSize(int64(len(content))))
+		fmt.Fprintf(os.Stderr, "Generated %s (%s%s)\n", htmlOutputFile, formatSize(int64(len(content))), note)
 	} else {
 		fmt.Print(content)
 	}
 
 	return nil
 }
+
+// readBranding reads the --styles, --extra-css and --extra-js files, or
+// returns nil when none was given.
+func readBranding() (*html.Branding, error) {
+	if htmlStyles == "" && htmlExtraCSS == "" && htmlExtraJS == "" {
+		return nil, nil
+	}
+	branding := &html.Branding{}
+	for _, f := range []struct {
+		flag, path string
+		dest       *string
+	}{
+		{"--styles", htmlStyles, &branding.StylesCSS},
+		{"--extra-css", htmlExtraCSS, &branding.ExtraCSS},
+		{"--extra-js", htmlExtraJS, &branding.ExtraJS},
+	} {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s file: %w", f.flag, err)
+		}
+		*f.dest = string(data)
+	}
+	return branding, nil
+}
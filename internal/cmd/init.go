@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/eljojo/rememory/internal/translations"
 	"github.com/spf13/cobra"
@@ -37,6 +40,7 @@ var (
 	initAnonymous bool
 	initShares    int
 	initLanguage  string
+	initForce     bool
 )
 
 const (
@@ -55,6 +59,7 @@ func init() {
 	initCmd.Flags().BoolVar(&initAnonymous, "anonymous", false, "Anonymous mode (no contact info for shareholders)")
 	initCmd.Flags().IntVar(&initShares, "shares", 0, "Number of shares (for anonymous mode)")
 	initCmd.Flags().StringVar(&initLanguage, "language", "", "Default bundle language (en, es, de, fr, sl)")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Allow a threshold equal to the number of friends")
 }
 
 // validLanguage returns true if the given language code is supported.
@@ -140,8 +145,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if threshold < 2 || threshold > numShares {
-			return fmt.Errorf("invalid threshold: must be between 2 and %d", numShares)
+		if err := checkThreshold(os.Stdout, threshold, numShares, initForce); err != nil {
+			return err
 		}
 
 		// Generate synthetic friends
@@ -166,8 +171,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if threshold < 2 || threshold > len(friends) {
-			return fmt.Errorf("invalid threshold: must be between 2 and %d", len(friends))
+		if err := checkThreshold(os.Stdout, threshold, len(friends), initForce); err != nil {
+			return err
 		}
 
 		fmt.Printf("Friends: %s\n", friendNames(friends))
@@ -185,6 +190,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 		friends = existing.Friends
 		threshold = existing.Threshold
+		if err := checkThreshold(os.Stdout, threshold, len(friends), initForce); err != nil {
+			return err
+		}
 		fmt.Printf("Copying configuration from: %s\n", initFrom)
 		fmt.Printf("  Friends: %s\n", friendNames(friends))
 		fmt.Printf("  Threshold: %d of %d\n\n", threshold, len(friends))
@@ -221,6 +229,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 			}
 			threshold = t
 		}
+		if err := checkThreshold(os.Stdout, threshold, numFriends, initForce); err != nil {
+			return err
+		}
 
 		fmt.Println()
 
@@ -286,11 +297,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 }
 
 func friendNames(friends []project.Friend) string {
+	return strings.Join(holderNames(friends), ", ")
+}
+
+// holderNames returns the friends' names in order, one per share.
+func holderNames(friends []project.Friend) []string {
 	names := make([]string, len(friends))
 	for i, f := range friends {
 		names[i] = f.Name
 	}
-	return strings.Join(names, ", ")
+	return names
 }
 
 // parseFriendFlags parses --friend flags in format "Name", "Name,contact", or "Name,contact,lang"
@@ -329,3 +345,19 @@ func parseFriendFlags(flags []string) ([]project.Friend, error) {
 	}
 	return friends, nil
 }
+
+// checkThreshold applies core.ValidateThreshold for init and seal. Errors
+// stop the command; a warning stops it too unless force is set, in which
+// case it is printed to w.
+func checkThreshold(w io.Writer, threshold, total int, force bool) error {
+	err := core.ValidateThreshold(threshold, total)
+	var warning *core.ThresholdWarning
+	if !errors.As(err, &warning) {
+		return err
+	}
+	if !force {
+		return fmt.Errorf("%w (use --force to go ahead anyway)", err)
+	}
+	fmt.Fprintf(w, "%s %v\n", yellow("Warning:"), warning)
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var migrateV1Cmd = &cobra.Command{
+	Use:   "migrate-v1-to-v2 share1.txt share2.txt ... [-o dir]",
+	Short: "Turn a quorum of old v1 shares into v2 shares that can be written as words",
+	Long: `Migrate-v1-to-v2 upgrades the shares of a project sealed with an old
+version of rememory. Version 1 shares can't be written as the 25 recovery
+words; version 2 shares can.
+
+It needs at least the threshold number of v1 shares. The passphrase is
+rebuilt from them and split again into the same number of v2 shares with
+the same threshold. Each new share keeps the number and holder of the old
+one, where that share was given. The passphrase doesn't change, so the new
+shares open the same MANIFEST.age and nothing needs to be sealed again.
+
+The new shares are written to the output directory (default: shares-v2).
+Give each friend their new share and ask them to destroy the old one. Old
+and new shares can't be combined with each other, but the old ones still
+open the manifest on their own, so they are only obsolete once they are
+gone.
+
+The project directory isn't needed. Bundles aren't rebuilt; after
+migrating, seal again with a current version to get new bundles.
+
+Example:
+  rememory migrate-v1-to-v2 SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt -o shares-v2`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMigrateV1,
+}
+
+var migrateV1Output string
+
+func init() {
+	rootCmd.AddCommand(migrateV1Cmd)
+	migrateV1Cmd.Flags().StringVarP(&migrateV1Output, "output", "o", "shares-v2", "Directory to write the new shares to")
+}
+
+func runMigrateV1(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	shares := make([]*core.Share, 0, len(args))
+	defer func() { zeroizeShares(shares) }()
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading share %s: %w", path, err)
+		}
+		share, err := core.ParseShare(content)
+		if err != nil {
+			return fmt.Errorf("parsing share %s: %w", path, err)
+		}
+		shares = append(shares, share)
+		if share.Version != 1 {
+			return fmt.Errorf("%s is already a version %d share; only v1 shares need migrating", path, share.Version)
+		}
+	}
+
+	fmt.Fprintf(out, "Rebuilding the passphrase from %d v1 shares...\n", len(shares))
+	migrated, err := core.MigrateV1Shares(shares, time.Now().UTC())
+	if errors.Is(err, core.ErrV1NotMigratable) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("migrating shares: %w", err)
+	}
+	defer zeroizeShares(migrated)
+
+	if err := writeMigratedShares(out, migrated, migrateV1Output); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "%s the old v1 shares are now obsolete. Give each friend their new share and\n", yellow("Warning:"))
+	fmt.Fprintln(out, "ask them to destroy the old one: old shares still open the manifest on their")
+	fmt.Fprintln(out, "own, and can't be combined with the new ones.")
+	return nil
+}
+
+// writeMigratedShares writes each share to dir as SHARE-<holder>.txt, and
+// lists them on out. Existing files are not overwritten.
+func writeMigratedShares(out io.Writer, shares []*core.Share, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	for _, share := range shares {
+		path := filepath.Join(dir, share.Filename())
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("writing share: %w", err)
+		}
+		_, err = io.WriteString(f, share.Encode())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing share %s: %w", path, err)
+		}
+		fmt.Fprintf(out, "  %s %s (piece %d of %d)\n", green("✓"), path, share.Index, share.Total)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Work out the chance of recovery for a threshold",
+	Long: `Plan helps choose how many pieces are needed to recover. Given how many
+friends hold a piece and how likely each one is to be unreachable when it
+matters (moved away, lost the piece, passed away), it shows the chance that
+enough of them can still be found, for every threshold.
+
+It suggests the highest threshold that keeps that chance at or above
+--target: a higher threshold makes collusion harder, but is more likely to
+leave too few friends. Each friend is assumed to be lost independently of
+the others; a couple or two coworkers may be lost together, so allow for
+some slack.
+
+Examples:
+  rememory plan --friends 5
+  rememory plan --friends 7 --loss 0.2 --target 0.999
+  rememory plan --friends 5 --threshold 4`,
+	RunE: runPlan,
+}
+
+var (
+	planFriends   int
+	planThreshold int
+	planLoss      float64
+	planTarget    float64
+)
+
+func init() {
+	planCmd.Flags().IntVar(&planFriends, "friends", 0, "Number of friends holding a piece")
+	planCmd.Flags().IntVar(&planThreshold, "threshold", 0, "Threshold you have in mind, to compare with the suggestion")
+	planCmd.Flags().Float64Var(&planLoss, "loss", 0.1, "Chance that any one friend can't be reached, from 0 to 1")
+	planCmd.Flags().Float64Var(&planTarget, "target", 0.99, "Chance of recovery to aim for, from 0 to 1")
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	if planFriends < 2 || planFriends > 255 {
+		return fmt.Errorf("--friends must be between 2 and 255, got %d", planFriends)
+	}
+	if planThreshold != 0 {
+		if err := core.ValidateShamirParams(planFriends, planThreshold); err != nil {
+			return err
+		}
+	}
+	if !(planLoss >= 0 && planLoss <= 1) {
+		return fmt.Errorf("--loss must be between 0 and 1, got %v", planLoss)
+	}
+	if !(planTarget > 0 && planTarget <= 1) {
+		return fmt.Errorf("--target must be above 0 and at most 1, got %v", planTarget)
+	}
+
+	printPlan(cmd.OutOrStdout(), planFriends, planThreshold, planLoss, planTarget)
+	return nil
+}
+
+// suggestThreshold returns the highest threshold below total whose chance
+// of recovery reaches target, or 0 if even a threshold of 2 falls short.
+// A threshold equal to total is never suggested: it leaves no room for a
+// lost piece.
+func suggestThreshold(total int, lossProb, target float64) int {
+	for k := total - 1; k >= 2; k-- {
+		if core.RecoveryProbability(total, k, lossProb) >= target {
+			return k
+		}
+	}
+	return 0
+}
+
+// printPlan writes the chance of recovery for every threshold, marking the
+// chosen and suggested ones.
+func printPlan(w io.Writer, total, threshold int, lossProb, target float64) {
+	suggested := suggestThreshold(total, lossProb, target)
+
+	fmt.Fprintf(w, "%d friends, each unreachable with a chance of %s\n\n", total, formatChance(lossProb))
+	fmt.Fprintln(w, "Threshold   Chance of recovery")
+	for k := 2; k <= total; k++ {
+		var note string
+		switch {
+		case k == threshold && k == suggested:
+			note = "  (chosen, suggested)"
+		case k == threshold:
+			note = "  (chosen)"
+		case k == suggested:
+			note = "  (suggested)"
+		}
+		label := fmt.Sprintf("%d of %d", k, total)
+		fmt.Fprintf(w, "  %-9s %9s%s\n", label, formatChance(core.RecoveryProbability(total, k, lossProb)), note)
+	}
+	fmt.Fprintln(w)
+
+	if suggested == 0 {
+		fmt.Fprintf(w, "No threshold reaches %s. Add friends, or lower --target.\n", formatChance(target))
+		return
+	}
+	fmt.Fprintf(w, "Suggested: %d of %d, the highest threshold with at least %s chance of recovery.\n",
+		suggested, total, formatChance(target))
+	if threshold > suggested {
+		fmt.Fprintf(w, "With %d of %d, recovery falls short of %s.\n", threshold, total, formatChance(target))
+	}
+}
+
+// formatChance formats a probability as a percentage. Chances that would
+// round to 0% or 100% without being exactly that are shown as <0.01% and
+// >99.99%, so a small risk doesn't disappear.
+func formatChance(p float64) string {
+	switch {
+	case p > 0 && p < 0.0001:
+		return "<0.01%"
+	case p < 1 && p > 0.9999:
+		return ">99.99%"
+	}
+	s := fmt.Sprintf("%.2f", p*100)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	return s + "%"
+}
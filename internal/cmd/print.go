@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var printCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Write a one-page printable sheet for each friend's piece",
+	Long: `Print writes a single-page PDF for each friend, made for keeping a piece
+on paper: the holder's name, the project, the fingerprint, the QR code and
+the recovery words in a numbered grid, in a monospaced font so letters
+can't be mistaken for each other.
+
+The sheet folds in half along the dashed line, printed side in, and has cut
+marks at the corners for trimming. It holds only the piece; README.pdf in
+the bundle still has the contacts and the full instructions.
+
+Sheets are written to output/sheets/SHEET-<name>.pdf unless -o is given.
+Each one contains a piece, so treat them like the share files.
+
+Run this command inside a sealed project directory.`,
+	Args: cobra.NoArgs,
+	RunE: runPrint,
+}
+
+var printOutput string
+
+func init() {
+	rootCmd.AddCommand(printCmd)
+	printCmd.Flags().StringVarP(&printOutput, "output", "o", "", "Directory for the sheets (default: output/sheets in the project)")
+	printCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for the QR code")
+}
+
+func runPrint(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return fmt.Errorf("no rememory project found (run 'rememory init' first)")
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed before printing (run 'rememory seal' first)")
+	}
+
+	dir := printOutput
+	if dir == "" {
+		dir = filepath.Join(p.OutputPath(), "sheets")
+	}
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+
+	paths, err := bundle.WriteShareSheets(p, dir, recoveryURL)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, "Created share sheets:")
+	for _, path := range paths {
+		fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(path))
+	}
+	fmt.Fprintf(out, "\nSheets saved to: %s\n", dir)
+	fmt.Fprintln(out, "Each sheet holds a piece. Print them, hand them over, then delete the files.")
+	return nil
+}
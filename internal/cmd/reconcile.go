@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile <dir>",
+	Short: "Sort pieces from more than one seal into their sets",
+	Long: `Reconcile helps when a project was sealed more than once and friends
+now hold pieces from different seals. Pieces from different seals can't be
+combined, even for the same project.
+
+Gather the bundles and piece files you can get hold of into one directory
+(subdirectories are fine) and run reconcile on it. It reads every bundle
+ZIP and SHARE-*.txt or README.txt file, groups the pieces by the seal they
+came from, and lists for each set who holds a piece and whether there are
+enough pieces to recover. It then recommends which set to keep: the newest
+one that can be recovered, or the one closest to it.
+
+Nothing is decrypted and no pieces are combined. Use --json for a
+machine-readable report.
+
+Example:
+  rememory reconcile collected/`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReconcile,
+}
+
+var reconcileJSON bool
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().BoolVar(&reconcileJSON, "json", false, "Output as JSON")
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	r, err := bundle.Reconcile(args[0])
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if reconcileJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	printReconciliation(out, args[0], r)
+	return nil
+}
+
+// printReconciliation writes the human-readable form of a reconciliation.
+func printReconciliation(w io.Writer, dir string, r *bundle.Reconciliation) {
+	switch len(r.Groups) {
+	case 0:
+		fmt.Fprintf(w, "No pieces found in %s.\n", dir)
+	case 1:
+		fmt.Fprintf(w, "Found 1 set of pieces in %s.\n", dir)
+	default:
+		fmt.Fprintf(w, "Found %d sets of pieces in %s. Pieces from different sets can't be combined.\n", len(r.Groups), dir)
+	}
+
+	for i, g := range r.Groups {
+		marker := ""
+		if i == r.Keep {
+			marker = " (keep)"
+		}
+		fmt.Fprintf(w, "\nSet %s%s\n", g.Label, marker)
+		fmt.Fprintf(w, "  Sealed:  %s\n", g.Created.Format("2006-01-02 15:04"))
+		fmt.Fprintf(w, "  Pieces:  %d of %d, %d needed\n", g.Pieces, g.Total, g.Threshold)
+		if len(g.Holders) > 0 {
+			fmt.Fprintf(w, "  Holders: %s\n", strings.Join(g.Holders, ", "))
+		}
+		if g.Recoverable {
+			fmt.Fprintf(w, "  Status:  %s enough pieces to recover\n", green("✓"))
+		} else {
+			fmt.Fprintf(w, "  Status:  %s %d more needed to recover\n", red("✗"), g.Threshold-g.Pieces)
+		}
+		for _, f := range g.Files {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+
+	if len(r.Unsorted) > 0 {
+		fmt.Fprintln(w, "\nPieces with no creation time, which can't be placed in a set:")
+		for _, f := range r.Unsorted {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+	if len(r.Unreadable) > 0 {
+		fmt.Fprintln(w, "\nFiles that could not be read:")
+		for _, u := range r.Unreadable {
+			fmt.Fprintf(w, "  %s %s: %s\n", red("✗"), u.File, u.Error)
+		}
+	}
+
+	if r.Keep < 0 || len(r.Groups) < 2 {
+		return
+	}
+	fmt.Fprintf(w, "\nRecommendation: keep set %s, since %s.\n", r.Groups[r.Keep].Fingerprint, r.KeepReason)
+	fmt.Fprintln(w, "Friends holding a piece from another set need a new bundle from this one.")
+}
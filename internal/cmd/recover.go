@@ -2,12 +2,21 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/manifest"
@@ -22,106 +31,301 @@ var recoverCmd = &cobra.Command{
 This command can be run from anywhere (doesn't need a project directory).
 You need at least the threshold number of shares to recover.
 
-Example:
-  rememory recover SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt -m MANIFEST.age`,
-	Args: cobra.MinimumNArgs(1),
+Each share file can be in any format: a SHARE-*.txt or README.txt file, a
+compact share string (RM2:...), or a text file with the 25 recovery words.
+Formats can be mixed in one run. The language of the words is detected;
+use --lang to name it when detection guesses wrong.
+
+With --interactive, pieces are entered one at a time instead (as file
+paths, pasted text, or typed words) and each is checked as it arrives.
+Recovery starts as soon as enough pieces are in.
+
+Use --stdout-file to print a single file from the manifest instead of
+writing everything to disk, for piping into another tool. With
+--manifest -, the MANIFEST.age (or recover.html) is read from stdin, so
+recovery can sit in the middle of a pipeline.
+
+Use --output-tar to write the decrypted archive exactly as sealed (a
+.tar.gz, or a .tar.zst if sealed with --compression zstd) to a file, or
+to stdout with --output-tar -, for your own tooling. Nothing is extracted
+or checked against the file hashes inside.
+
+For a project sealed with --per-file-keys, each secret has its own
+pieces and MANIFEST-<secret>.age. Pieces name the secret they unlock, and
+recover looks for that secret's manifest in the current directory. Use
+--secret to say which secret you mean; pieces of any other secret are
+turned away.
+
+If some pieces may be silently wrong (they read fine but don't combine
+with the others), --try-subsets combines every set of threshold pieces and
+checks each against the commitment the pieces carry, then recovers with
+one that matches and names the pieces that are in none.
+
+Pieces sealed with --pin-version name the oldest version of rememory that
+may recover them. An older version refuses, since it may not read the
+format; --ignore-version tries anyway.
+
+Use --verify-only for a recovery drill: it checks that the pieces unlock
+the manifest and stops there, without writing anything.
+
+If the project has a recovery question, give its answer on the first line
+of stdin with --answer-stdin, or in the ` + answerEnv + ` environment
+variable. A personalized recover.html names the question when the answer
+is missing.
+
+Use --json for a machine-readable report on stdout: a status object and the
+recovered files with their sizes and checksums (never their contents).
+Progress goes to stderr. The report is printed on failure too, with ok set
+to false, and the exit code is 0 only when every file was recovered.
+
+Examples:
+  rememory recover SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt -m MANIFEST.age
+  rememory recover SHARE-alice.txt SHARE-bob.txt --stdout-file manifest/key.pem | ssh-add -
+  curl -s https://example.com/MANIFEST.age | rememory recover SHARE-*.txt -m - --stdout-file manifest/notes.txt
+  rememory recover --interactive -m MANIFEST.age
+  rememory recover SHARE-*.txt -m MANIFEST.age -o recovered --json
+  rememory recover SHARE-alice.txt SHARE-bob.txt -m MANIFEST.age --verify-only
+  rememory recover SHARE-*.txt -m MANIFEST.age --try-subsets
+  rememory recover SHARE-*.txt -m MANIFEST.age --output-tar - | tar -tzf -
+  rememory recover shares/passwords/SHARE-*.txt --secret passwords`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if recoverInteractive {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runRecover,
 }
 
 var (
-	recoverManifest   string
-	recoverOutput     string
-	recoverPassphrase bool
+	recoverManifest      string
+	recoverOutput        string
+	recoverPassphrase    bool
+	recoverStaleYears    int
+	recoverStdoutFile    string
+	recoverInteractive   bool
+	recoverJSON          bool
+	recoverLang          string
+	recoverAnswerStdin   bool
+	recoverVerifyOnly    bool
+	recoverSecret        string
+	recoverTrySubsets    bool
+	recoverOutputTar     string
+	recoverIgnoreVersion bool
 )
 
 func init() {
 	rootCmd.AddCommand(recoverCmd)
-	recoverCmd.Flags().StringVarP(&recoverManifest, "manifest", "m", "", "Path to MANIFEST.age file")
+	recoverCmd.Flags().StringVarP(&recoverManifest, "manifest", "m", "", "Path to MANIFEST.age file, or - to read it from stdin")
 	recoverCmd.Flags().StringVarP(&recoverOutput, "output", "o", "", "Output directory (default: recovered-TIMESTAMP)")
 	recoverCmd.Flags().BoolVar(&recoverPassphrase, "passphrase-only", false, "Only output the passphrase, don't decrypt")
+	recoverCmd.Flags().IntVar(&recoverStaleYears, "stale-years", defaultStaleYears, "Warn when shares are older than this many years (0 to disable)")
+	recoverCmd.Flags().StringVar(&recoverStdoutFile, "stdout-file", "", "Write only this file from the manifest to stdout (e.g. manifest/secret.txt)")
+	recoverCmd.Flags().BoolVarP(&recoverInteractive, "interactive", "i", false, "Enter pieces one at a time, checking each as it arrives")
+	recoverCmd.Flags().BoolVar(&recoverJSON, "json", false, "Print a JSON report of the recovered files to stdout")
+	recoverCmd.Flags().StringVar(&recoverLang, "lang", "", "Word list language of shares given as words (default: detect)")
+	recoverCmd.Flags().BoolVar(&recoverAnswerStdin, "answer-stdin", false, "Read the answer to the project's question from stdin instead of $"+answerEnv)
+	recoverCmd.MarkFlagsMutuallyExclusive("stdout-file", "output")
+	recoverCmd.MarkFlagsMutuallyExclusive("stdout-file", "passphrase-only")
+	recoverCmd.MarkFlagsMutuallyExclusive("json", "stdout-file")
+	recoverCmd.MarkFlagsMutuallyExclusive("json", "passphrase-only")
+	recoverCmd.Flags().StringVar(&recoverSecret, "secret", "", "Secret to recover, for a project sealed with --per-file-keys")
+	recoverCmd.Flags().BoolVar(&recoverVerifyOnly, "verify-only", false, "Only check that the pieces unlock the manifest; write nothing")
+	recoverCmd.Flags().BoolVar(&recoverTrySubsets, "try-subsets", false, "Try every set of threshold pieces to find ones that agree, and name the odd ones out")
+	recoverCmd.MarkFlagsMutuallyExclusive("answer-stdin", "interactive")
+	recoverCmd.MarkFlagsMutuallyExclusive("try-subsets", "interactive")
+	recoverCmd.MarkFlagsMutuallyExclusive("verify-only", "output")
+	recoverCmd.MarkFlagsMutuallyExclusive("verify-only", "stdout-file")
+	recoverCmd.MarkFlagsMutuallyExclusive("verify-only", "passphrase-only")
+	recoverCmd.Flags().StringVar(&recoverOutputTar, "output-tar", "", "Write the decrypted archive as-is to this file, or - for stdout, without extracting it")
+	recoverCmd.Flags().BoolVar(&recoverIgnoreVersion, "ignore-version", false, "Recover even if the pieces ask for a newer version of rememory")
+	for _, other := range []string{"output", "stdout-file", "passphrase-only", "verify-only", "json"} {
+		recoverCmd.MarkFlagsMutuallyExclusive("output-tar", other)
+	}
 }
 
-func runRecover(cmd *cobra.Command, args []string) error {
-	// Parse all share files
-	fmt.Printf("Reading %d share files...\n", len(args))
+// defaultStaleYears is how old shares can be before recovery notes that
+// the secret they protect may be out of date.
+const defaultStaleYears = core.ShareStaleYears
 
-	shares := make([]*core.Share, len(args))
-	for i, path := range args {
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("reading share %s: %w", path, err)
-		}
+// recoverReport is the --json output of recover.
+type recoverReport struct {
+	Status recoverStatus `json:"status"`
+	Output string        `json:"output,omitempty"` // directory the files were written to
+	Files  []recoverFile `json:"files"`
+}
 
-		share, err := core.ParseShare(content)
-		if err != nil {
-			return fmt.Errorf("parsing share %s: %w", path, err)
-		}
+type recoverStatus struct {
+	OK            bool   `json:"ok"`
+	Error         string `json:"error,omitempty"`
+	Shares        int    `json:"shares"`
+	Threshold     int    `json:"threshold,omitempty"`
+	FilesVerified bool   `json:"files_verified"` // checked against MANIFEST-HASHES.txt
+}
 
-		// Verify checksum
-		if err := share.Verify(); err != nil {
-			return fmt.Errorf("share %s: %w", path, err)
-		}
+type recoverFile struct {
+	Name     string `json:"name"`
+	Size     int    `json:"size"`
+	Checksum string `json:"checksum"`
+}
 
-		shares[i] = share
+func runRecover(cmd *cobra.Command, args []string) error {
+	if !recoverJSON {
+		return recoverFromShares(cmd, args, nil)
 	}
 
-	// Validate shares are compatible
-	if len(shares) == 0 {
-		return fmt.Errorf("no shares provided")
+	report := &recoverReport{Files: []recoverFile{}}
+	err := recoverFromShares(cmd, args, report)
+	report.Status.OK = err == nil
+	if err != nil {
+		report.Status.Error = err.Error()
 	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(report); encErr != nil && err == nil {
+		return encErr
+	}
+	return err
+}
 
-	first := shares[0]
-	for i, share := range shares[1:] {
-		if share.Version != first.Version {
-			return fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+2, share.Version, first.Version)
-		}
-		if share.Total != first.Total {
-			return fmt.Errorf("share %d has different total (%d vs %d)", i+2, share.Total, first.Total)
+// wordLangFlag checks a --lang value. An empty value means detect.
+func wordLangFlag(value string) (core.Lang, error) {
+	if value == "" {
+		return "", nil
+	}
+	lang, err := core.ParseLang(value)
+	if err != nil {
+		return "", fmt.Errorf("--lang: %w", err)
+	}
+	return lang, nil
+}
+
+// duplicateShareError describes shares[i] and shares[j] being the same piece,
+// naming the holder when the share records one.
+func duplicateShareError(shares []*core.Share, labels []string, i, j int) error {
+	piece := "the same piece"
+	if shares[j].Index > 0 {
+		piece = fmt.Sprintf("piece %d", shares[j].Index)
+	}
+	if holder := shares[j].Holder; holder != "" {
+		piece += fmt.Sprintf(" (%s's)", holder)
+	}
+	return fmt.Errorf("%s and %s are both %s; each piece counts only once, so give a different friend's piece instead", labels[i], labels[j], piece)
+}
+
+// shareSecret returns the secret the shares unlock, for a project sealed
+// with per-file keys: want if given, or else the one named by the shares.
+// It is an error for a share to name a different secret, since its piece
+// belongs to another passphrase and can't help.
+func shareSecret(shares []*core.Share, labels []string, want string) (string, error) {
+	given := want != ""
+	for i, s := range shares {
+		got := s.Headers[core.SecretHeader]
+		switch {
+		case got == "" || got == want:
+		case want == "":
+			want = got
+		case given:
+			return "", fmt.Errorf("%s is a piece of the secret %q, not %q", labels[i], got, want)
+		default:
+			return "", fmt.Errorf("%s is a piece of the secret %q, but the pieces before it are for %q; recover one secret at a time", labels[i], got, want)
 		}
-		if share.Threshold != first.Threshold {
-			return fmt.Errorf("share %d has different threshold (%d vs %d)", i+2, share.Threshold, first.Threshold)
+	}
+	return want, nil
+}
+
+// looksLikeHTML reports whether data is a recover.html rather than an age
+// file, for a manifest read from stdin that has no file name to go by.
+func looksLikeHTML(data []byte) bool {
+	head := bytes.TrimSpace(data[:min(len(data), 512)])
+	return len(head) > 0 && head[0] == '<'
+}
+
+// pieceList names the pieces being combined in order, as " (pieces 1, 3,
+// 4)", or returns "" when some share doesn't know its number.
+func pieceList(shares []*core.Share) string {
+	sorted := slices.Clone(shares)
+	core.SortShares(sorted)
+	numbers := make([]string, len(sorted))
+	for i, share := range sorted {
+		if share.Index == 0 {
+			return ""
 		}
+		numbers[i] = strconv.Itoa(share.Index)
 	}
+	return fmt.Sprintf(" (pieces %s)", strings.Join(numbers, ", "))
+}
 
-	// Check we have enough shares
-	if len(shares) < first.Threshold {
-		return fmt.Errorf("need at least %d shares to recover (you provided %d)", first.Threshold, len(shares))
+// recoverFromShares does the work of recover. When report is non-nil it is
+// filled in as recovery goes, for --json.
+func recoverFromShares(cmd *cobra.Command, args []string, report *recoverReport) error {
+	// With --stdout-file, --output-tar - or --json, stdout carries only
+	// the requested output, so progress goes to stderr.
+	status := cmd.OutOrStdout()
+	if recoverStdoutFile != "" || recoverOutputTar == "-" || report != nil {
+		status = cmd.ErrOrStderr()
 	}
 
-	// Check for duplicate indices
-	seen := make(map[int]bool)
-	for _, share := range shares {
-		if seen[share.Index] {
-			return fmt.Errorf("duplicate share index %d", share.Index)
+	lang, err := wordLangFlag(recoverLang)
+	if err != nil {
+		return err
+	}
+	if recoverManifest == "-" && (recoverInteractive || recoverAnswerStdin) {
+		return fmt.Errorf("--manifest - reads the manifest from stdin, so it can't be combined with --interactive or --answer-stdin (give the answer in %s instead)", answerEnv)
+	}
+
+	// Collect shares: one at a time from the operator, or from files.
+	// labels name each share in messages about it.
+	var shares []*core.Share
+	labels := args
+	if recoverInteractive {
+		shares, labels, err = collectSharesInteractive(cmd.InOrStdin(), status, lang)
+		if err != nil {
+			return err
+		}
+	} else {
+		shares, labels, err = readShareFiles(status, args, lang)
+		if err != nil {
+			return err
 		}
-		seen[share.Index] = true
 	}
+	defer zeroizeShares(shares)
 
-	fmt.Printf("Combining %d shares...\n", len(shares))
+	if err := core.CheckVersionCompat(core.RequiredVersion(shares), version); err != nil {
+		if !recoverIgnoreVersion {
+			return fmt.Errorf("%w (use --ignore-version to try anyway)", err)
+		}
+		fmt.Fprintf(status, "%s %v\n", yellow("Warning:"), err)
+	}
 
-	// Extract raw share data
-	shareData := make([][]byte, len(shares))
-	for i, share := range shares {
-		shareData[i] = share.Data
+	secret, err := shareSecret(shares, labels, recoverSecret)
+	if err != nil {
+		return err
 	}
 
-	// Reconstruct passphrase
-	recovered, err := core.Combine(shareData)
+	recovered, shareVersion, err := combineShares(status, shares, labels, recoverStaleYears, recoverTrySubsets, report)
 	if err != nil {
-		return fmt.Errorf("combining shares: %w", err)
+		return err
 	}
+	defer core.Zeroize(recovered)
 
-	passphrase := core.RecoverPassphrase(recovered, first.Version)
+	passphrase := core.RecoverPassphrase(recovered, shareVersion)
 
 	if recoverPassphrase {
-		fmt.Println()
-		fmt.Println("Recovered passphrase:")
-		fmt.Println(passphrase)
+		fmt.Fprintln(status)
+		fmt.Fprintln(status, "Recovered passphrase:")
+		fmt.Fprintln(cmd.OutOrStdout(), passphrase)
 		return nil
 	}
 
 	// Find manifest file
 	manifestPath := recoverManifest
+	if manifestPath == "" && secret != "" {
+		manifestPath = secretManifestName(secret)
+		if _, err := os.Stat(manifestPath); err != nil {
+			return fmt.Errorf("%s not found in current directory; use --manifest to specify path", manifestPath)
+		}
+	}
 	if manifestPath == "" {
 		// Try to find MANIFEST.age in current directory, then recover.html
 		if _, err := os.Stat("MANIFEST.age"); err == nil {
@@ -133,30 +337,99 @@ func runRecover(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Println("Decrypting manifest...")
+	fmt.Fprintln(status, "Decrypting manifest...")
 
-	// Read manifest data — either directly from .age file or extracted from .html
-	var encryptedData []byte
-	if strings.HasSuffix(strings.ToLower(manifestPath), ".html") || strings.HasSuffix(strings.ToLower(manifestPath), ".htm") {
-		htmlContent, err := os.ReadFile(manifestPath)
+	// Read manifest data — either directly from .age file or extracted from
+	// .html, from a file or from stdin with --manifest -
+	var manifestData []byte
+	manifestName := manifestPath
+	isHTML := strings.HasSuffix(strings.ToLower(manifestPath), ".html") || strings.HasSuffix(strings.ToLower(manifestPath), ".htm")
+	if manifestPath == "-" {
+		manifestName = "stdin"
+		manifestData, err = io.ReadAll(cmd.InOrStdin())
 		if err != nil {
-			return fmt.Errorf("reading %s: %w", manifestPath, err)
+			return fmt.Errorf("reading manifest from stdin: %w", err)
 		}
-		encryptedData, err = html.ExtractManifestFromHTML(htmlContent)
-		if err != nil {
-			return fmt.Errorf("extracting manifest from %s: %w", manifestPath, err)
-		}
-		fmt.Printf("Extracted manifest from %s\n", manifestPath)
+		isHTML = looksLikeHTML(manifestData)
 	} else {
-		encryptedData, err = os.ReadFile(manifestPath)
+		manifestData, err = readManifestFile(status, manifestPath, isHTML)
 		if err != nil {
 			return fmt.Errorf("reading manifest: %w", err)
 		}
 	}
 
+	var encryptedData []byte
+	var question string
+	if isHTML {
+		encryptedData, err = html.ExtractManifestFromHTML(manifestData)
+		if err != nil {
+			return fmt.Errorf("extracting manifest from %s: %w", manifestName, err)
+		}
+		fmt.Fprintf(status, "Extracted manifest from %s\n", manifestName)
+		question = html.ExtractQuestionFromHTML(manifestData)
+	} else {
+		encryptedData = manifestData
+	}
+
+	var answer string
+	if question != "" {
+		answer, err = requireAnswer(cmd.InOrStdin(), recoverAnswerStdin, question)
+	} else {
+		answer, err = recoveryAnswer(cmd.InOrStdin(), recoverAnswerStdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C stops a slow decrypt cleanly.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	if recoverVerifyOnly {
+		return verifyDecrypts(ctx, status, encryptedData, passphrase, answer)
+	}
+
+	key, err := decryptKey(passphrase, answer)
+	if err != nil {
+		return err
+	}
 	var decryptedBuf bytes.Buffer
-	if err := core.Decrypt(&decryptedBuf, bytes.NewReader(encryptedData), passphrase); err != nil {
-		return fmt.Errorf("decryption failed (shares may be corrupted or from different operation): %w", err)
+	if err := decryptWithProgress(ctx, status, &decryptedBuf, encryptedData, key); err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			return fmt.Errorf("decryption cancelled")
+		case key != passphrase:
+			return fmt.Errorf("decryption failed (the answer may be wrong, or shares corrupted or from a different operation): %w", err)
+		default:
+			return fmt.Errorf("decryption failed (shares may be corrupted or from different operation, or the project has a recovery question: give its answer with --answer-stdin or %s): %w", answerEnv, err)
+		}
+	}
+
+	if recoverOutputTar != "" {
+		return writeDecryptedArchive(cmd.OutOrStdout(), status, decryptedBuf.Bytes(), recoverOutputTar)
+	}
+
+	if recoverStdoutFile != "" {
+		// Check each file against the hash list recorded at seal time, so
+		// a tampered file is named instead of silently written out.
+		files, err := core.ExtractTarGz(decryptedBuf.Bytes())
+		if err != nil {
+			return fmt.Errorf("reading manifest archive: %w", err)
+		}
+		hashes, err := core.FindManifestHashes(files)
+		if err != nil {
+			return fmt.Errorf("reading manifest archive: %w", err)
+		}
+		if hashes != nil {
+			if err := core.VerifyExtractedFiles(files, hashes); err != nil {
+				return fmt.Errorf("verifying recovered files: %w", err)
+			}
+			fmt.Fprintf(status, "Verified %d files against %s\n", len(hashes), core.ManifestHashesFile)
+			if report != nil {
+				report.Status.FilesVerified = true
+			}
+		}
+		return writeManifestFile(cmd.OutOrStdout(), files, recoverStdoutFile)
 	}
 
 	// Determine output directory
@@ -165,20 +438,41 @@ func runRecover(cmd *cobra.Command, args []string) error {
 		outputDir = fmt.Sprintf("recovered-%s", time.Now().Format("2006-01-02"))
 	}
 
-	// Extract archive
+	// Extract archive. Extract checks the files it writes against the hash
+	// list recorded at seal time, and removes them again if one differs.
 	extractResult, err := manifest.Extract(&decryptedBuf, outputDir)
+	if errors.Is(err, core.ErrFileHashMismatch) {
+		return fmt.Errorf("verifying recovered files: %w", err)
+	}
 	if err != nil {
 		return fmt.Errorf("extracting manifest: %w", err)
 	}
+	if extractResult.Verified > 0 {
+		fmt.Fprintf(status, "Verified %d files against %s\n", extractResult.Verified, core.ManifestHashesFile)
+		if report != nil {
+			report.Status.FilesVerified = true
+		}
+	}
 
 	// Warn about any skipped files (symlinks, etc.)
 	for _, warning := range extractResult.Warnings {
-		fmt.Printf("  Warning: %s\n", warning)
+		fmt.Fprintf(status, "  Warning: %s\n", warning)
+	}
+
+	if report != nil {
+		report.Output = extractResult.Path
+		for _, f := range extractResult.Files {
+			report.Files = append(report.Files, recoverFile{
+				Name:     f.Name,
+				Size:     int(f.Size),
+				Checksum: f.Hash,
+			})
+		}
 	}
 
 	// List recovered files
-	fmt.Println()
-	fmt.Printf("Recovered to: %s/\n", extractResult.Path)
+	fmt.Fprintln(status)
+	fmt.Fprintf(status, "Recovered to: %s/\n", extractResult.Path)
 
 	err = filepath.Walk(extractResult.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -189,9 +483,9 @@ func runRecover(cmd *cobra.Command, args []string) error {
 		}
 		relPath, _ := filepath.Rel(extractResult.Path, path)
 		if info.IsDir() {
-			fmt.Printf("  %s/\n", relPath)
+			fmt.Fprintf(status, "  %s/\n", relPath)
 		} else {
-			fmt.Printf("  %s\n", relPath)
+			fmt.Fprintf(status, "  %s\n", relPath)
 		}
 		return nil
 	})
@@ -201,3 +495,388 @@ func runRecover(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// writeDecryptedArchive writes archive, the decrypted manifest, to path, or
+// to stdout when path is "-".
+func writeDecryptedArchive(stdout, status io.Writer, archive []byte, path string) error {
+	if path == "-" {
+		if _, err := stdout.Write(archive); err != nil {
+			return fmt.Errorf("writing archive: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, archive, 0600); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	fmt.Fprintln(status)
+	fmt.Fprintf(status, "Decrypted archive written to: %s (%s)\n", path, formatSize(int64(len(archive))))
+	return nil
+}
+
+// readShareFiles reads and checks the share in each file in paths, and
+// returns the usable ones with the paths they came from. A piece that can't
+// be parsed or fails its checks is skipped with a warning, as long as the
+// rest still reach the threshold: one damaged file among extra pieces
+// shouldn't stop recovery. A file that can't be read at all is an error.
+func readShareFiles(status io.Writer, paths []string, lang core.Lang) ([]*core.Share, []string, error) {
+	fmt.Fprintf(status, "Reading %d share files...\n", len(paths))
+
+	var shares []*core.Share
+	var labels, skipped []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			zeroizeShares(shares)
+			return nil, nil, fmt.Errorf("reading share %s: %w", path, err)
+		}
+
+		share, err := parseShareInput(content, lang)
+		if err != nil {
+			err = fmt.Errorf("parsing share %s: %w", path, err)
+		} else if err = share.Verify(); err == nil {
+			err = share.Validate()
+		}
+		if err != nil {
+			if share != nil {
+				share.Zeroize()
+			}
+			if len(paths) == 1 {
+				return nil, nil, err
+			}
+			fmt.Fprintf(status, "  %s %s: %v\n", red("✗"), path, err)
+			skipped = append(skipped, path)
+			continue
+		}
+
+		shares = append(shares, share)
+		labels = append(labels, path)
+	}
+
+	if len(skipped) == 0 {
+		return shares, labels, nil
+	}
+	threshold := 0
+	for _, share := range shares {
+		threshold = max(threshold, share.Threshold)
+	}
+	if len(shares) == 0 {
+		return nil, nil, fmt.Errorf("none of the %d pieces given can be used", len(paths))
+	}
+	if len(shares) < threshold {
+		zeroizeShares(shares)
+		return nil, nil, fmt.Errorf("only %d of the %d pieces given can be used (%s could not be read), and at least %d are needed", len(shares), len(paths), strings.Join(skipped, ", "), threshold)
+	}
+	fmt.Fprintf(status, "%s skipping %d piece(s) that could not be read; carrying on with the other %d\n", yellow("Warning:"), len(skipped), len(shares))
+	return shares, labels, nil
+}
+
+// zeroizeShares wipes the data of every share in shares.
+func zeroizeShares(shares []*core.Share) {
+	for _, share := range shares {
+		if share != nil {
+			share.Zeroize()
+		}
+	}
+}
+
+// combineShares checks that shares belong together and combines them,
+// returning the recovered secret and the share format version to turn it
+// into a passphrase with. labels name each share in messages, and shares
+// older than staleYears get a note. With trySubsets, every quorum is tried
+// against the pieces' commitment instead, to get past pieces that are
+// silently wrong. When report is non-nil its share counts are filled in.
+// The caller should zeroize the secret once done with it.
+func combineShares(status io.Writer, shares []*core.Share, labels []string, staleYears int, trySubsets bool, report *recoverReport) ([]byte, int, error) {
+	// Validate shares are compatible
+	if len(shares) == 0 {
+		return nil, 0, fmt.Errorf("no shares provided")
+	}
+
+	// Shares typed in as words carry no total or threshold, so compare
+	// metadata against the first share that has it.
+	first := shares[0]
+	for _, share := range shares {
+		if share.Threshold > 0 {
+			first = share
+			break
+		}
+	}
+	for i, share := range shares {
+		if share.Version != first.Version {
+			return nil, 0, fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+1, share.Version, first.Version)
+		}
+		if share.Threshold == 0 {
+			continue
+		}
+		if share.Total != first.Total {
+			return nil, 0, fmt.Errorf("share %d has different total (%d vs %d)", i+1, share.Total, first.Total)
+		}
+		if share.Threshold != first.Threshold {
+			return nil, 0, fmt.Errorf("share %d has different threshold (%d vs %d)", i+1, share.Threshold, first.Threshold)
+		}
+	}
+
+	if report != nil {
+		report.Status.Shares = len(shares)
+		report.Status.Threshold = first.Threshold
+	}
+
+	if warning := staleSharesWarning(shares, time.Now(), staleYears); warning != "" {
+		fmt.Fprintf(status, "%s %s\n", yellow("Note:"), warning)
+	}
+
+	// The same piece given twice still combines, but on fewer distinct
+	// pieces than it looks like, so refuse rather than drop the copy. Words
+	// encode indices above 15 as 0; CombineChecked catches those.
+	seen := make(map[int]int)
+	for i, share := range shares {
+		if share.Index == 0 {
+			continue
+		}
+		if j, ok := seen[share.Index]; ok {
+			return nil, 0, duplicateShareError(shares, labels, j, i)
+		}
+		seen[share.Index] = i
+	}
+
+	// Check we have enough shares
+	if len(shares) < first.Threshold {
+		return nil, 0, fmt.Errorf("need at least %d shares to recover (you provided %d)", first.Threshold, len(shares))
+	}
+
+	// Pieces from a seal that recorded a commitment can be checked once
+	// combined. Pieces typed as words or compact strings don't carry it.
+	commitment, err := core.ShareCommitment(shares)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if trySubsets {
+		recovered, err := combineSubsets(status, shares, labels, first.Threshold, commitment)
+		if err != nil {
+			return nil, 0, err
+		}
+		return recovered, first.Version, nil
+	}
+
+	fmt.Fprintf(status, "Combining %d shares%s...\n", len(shares), pieceList(shares))
+
+	// Extract raw share data
+	shareData := make([][]byte, len(shares))
+	for i, share := range shares {
+		shareData[i] = share.Data
+	}
+
+	// Reconstruct passphrase. When the threshold is known, shares beyond it
+	// are used to check the others for corruption.
+	var recovered []byte
+	if first.Threshold > 0 {
+		recovered, err = core.CombineChecked(shareData, first.Threshold)
+	} else {
+		recovered, err = core.Combine(shareData)
+	}
+	var duplicate *core.DuplicateSharesError
+	if errors.As(err, &duplicate) {
+		return nil, 0, duplicateShareError(shares, labels, duplicate.First, duplicate.Second)
+	}
+	var inconsistent *core.InconsistentSharesError
+	if errors.As(err, &inconsistent) {
+		for _, i := range inconsistent.Suspects {
+			fmt.Fprintf(status, "  %s %s does not match the other shares\n", red("✗"), labels[i])
+		}
+		return nil, 0, fmt.Errorf("combining shares: %w", err)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("combining shares: %w", err)
+	}
+	if commitment != "" {
+		if err := core.VerifyReconstructedSecret(recovered, commitment); err != nil {
+			core.Zeroize(recovered)
+			if errors.Is(err, core.ErrSecretMismatch) {
+				return nil, 0, fmt.Errorf("these pieces don't rebuild the passphrase they were made from: some may be from a different seal or damaged, or more pieces may be needed")
+			}
+			return nil, 0, err
+		}
+	}
+	return recovered, first.Version, nil
+}
+
+// readManifestFile reads MANIFEST.age or recover.html. A MANIFEST.age
+// next to a README .txt, as in an unzipped bundle, is checked against the
+// checksum recorded there as it is read. A mismatch is only a warning: the
+// README may be from another seal, and decryption has the final say.
+func readManifestFile(status io.Writer, path string, isHTML bool) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	expected := ""
+	if !isHTML {
+		expected = bundle.RecordedManifestChecksum(filepath.Dir(path))
+	}
+	if expected == "" {
+		return io.ReadAll(f)
+	}
+	r, check := core.NewVerifyingReader(f, expected)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(); err != nil {
+		fmt.Fprintf(status, "%s %s doesn't match the checksum in the README next to it; it may be damaged or from a different seal\n", yellow("Warning:"), path)
+	}
+	return data, nil
+}
+
+// combineSubsets combines every threshold-sized subset of shares, for
+// --try-subsets, and returns the secret from one that matches commitment.
+// Pieces that are in no matching subset are named as the odd ones out.
+func combineSubsets(status io.Writer, shares []*core.Share, labels []string, threshold int, commitment string) ([]byte, error) {
+	if threshold == 0 {
+		return nil, fmt.Errorf("--try-subsets needs to know the threshold, and pieces given as words or compact strings don't say it; include a share file or README.txt")
+	}
+	if commitment == "" {
+		return nil, fmt.Errorf("--try-subsets needs a commitment to check each set of pieces against, and none of these pieces carry one (they may be from an older version)")
+	}
+	if len(shares) == threshold {
+		fmt.Fprintf(status, "%s with exactly %d pieces there is only one set to try\n", yellow("Note:"), threshold)
+	}
+
+	shareData := make([][]byte, len(shares))
+	for i, share := range shares {
+		shareData[i] = share.Data
+	}
+	fmt.Fprintf(status, "Trying every %d of the %d pieces...\n", threshold, len(shares))
+	search, err := core.TrySubsets(shareData, threshold, commitment)
+	var duplicate *core.DuplicateSharesError
+	if errors.As(err, &duplicate) {
+		return nil, duplicateShareError(shares, labels, duplicate.First, duplicate.Second)
+	}
+	if errors.Is(err, core.ErrSecretMismatch) {
+		return nil, fmt.Errorf("no %d of these pieces rebuild the passphrase they were made from: too many may be damaged or from a different seal", threshold)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+
+	fmt.Fprintf(status, "  %d of %d sets rebuild the passphrase\n", search.Matched, search.Tried)
+	for _, i := range search.Suspects {
+		fmt.Fprintf(status, "  %s %s is in no set that works: it is damaged or from a different seal\n", red("✗"), labels[i])
+	}
+	used := make([]string, len(search.Used))
+	for i, pos := range search.Used {
+		used[i] = labels[pos]
+	}
+	fmt.Fprintf(status, "%s recovering with %s\n", green("✓"), strings.Join(used, ", "))
+	return search.Secret, nil
+}
+
+// verifyDecrypts checks that encryptedData unlocks with passphrase (and
+// answer, if given) and reports it, for --verify-only. Nothing is written.
+func verifyDecrypts(ctx context.Context, status io.Writer, encryptedData []byte, passphrase, answer string) error {
+	key, err := decryptKey(passphrase, answer)
+	if err != nil {
+		return err
+	}
+	if err := decryptWithProgress(ctx, status, io.Discard, encryptedData, key); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("decryption cancelled")
+		}
+		return fmt.Errorf("the manifest doesn't decrypt with these pieces (shares may be corrupted or from a different operation, the manifest may be damaged, or the project has a recovery question): %w", err)
+	}
+	fmt.Fprintln(status)
+	fmt.Fprintf(status, "%s These pieces unlock the manifest. Nothing was written.\n", green("✓"))
+	return nil
+}
+
+// decryptKey returns the age passphrase for passphrase and the recovery
+// answer: passphrase itself when there is no answer, or the two combined as
+// core.EncryptWithAnswer does.
+func decryptKey(passphrase, answer string) (string, error) {
+	if core.NormalizeAnswer(answer) == "" {
+		return passphrase, nil
+	}
+	return core.AnswerPassphrase(passphrase, answer)
+}
+
+// decryptProgressDelay is how long decryptWithProgress waits before showing
+// anything, so a quick decrypt prints nothing.
+const decryptProgressDelay = time.Second
+
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// decryptWithProgress decrypts encryptedData with key into dst. With a high
+// scrypt work factor, deriving the key alone can take several seconds, so
+// once it has run for decryptProgressDelay a spinner and the time taken so
+// far are kept on one line of status. Cancelling ctx stops the decrypt.
+func decryptWithProgress(ctx context.Context, status, dst io.Writer, encryptedData []byte, key string) error {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		start := time.Now()
+		select {
+		case <-done:
+			return
+		case <-time.After(decryptProgressDelay):
+		}
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			fmt.Fprintf(status, "\r  Decrypting %s %.1fs", spinnerFrames[frame%len(spinnerFrames)], time.Since(start).Seconds())
+			select {
+			case <-done:
+				fmt.Fprintf(status, "\r  Decrypting took %.1fs\n", time.Since(start).Seconds())
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	err := core.DecryptContext(ctx, dst, bytes.NewReader(encryptedData), key)
+	close(done)
+	wg.Wait()
+	return err
+}
+
+// writeManifestFile writes the contents of the named file to w. The name can
+// be given with or without the archive's root directory ("manifest/").
+func writeManifestFile(w io.Writer, files []core.ExtractedFile, name string) error {
+	names := make([]string, len(files))
+	for i, f := range files {
+		_, rel, _ := strings.Cut(f.Name, "/")
+		if f.Name == name || rel == name {
+			_, err := w.Write(f.Data)
+			return err
+		}
+		names[i] = f.Name
+	}
+	return fmt.Errorf("%s not found in the manifest; available files:\n  %s", name, strings.Join(names, "\n  "))
+}
+
+// staleSharesWarning returns a note when the oldest share is more than
+// maxYears old, or "" if the shares are recent (or maxYears is 0).
+// It's informational only; old shares still recover.
+func staleSharesWarning(shares []*core.Share, now time.Time, maxYears int) string {
+	if maxYears <= 0 {
+		return ""
+	}
+	var oldest time.Duration
+	for _, s := range shares {
+		oldest = max(oldest, core.ShareAge(s, now))
+	}
+
+	const year = 365 * 24 * time.Hour
+	if oldest <= time.Duration(maxYears)*year {
+		return ""
+	}
+	years := int(oldest / year)
+	unit := "years"
+	if years == 1 {
+		unit = "year"
+	}
+	return fmt.Sprintf("these shares were created %d %s ago; the underlying secret may be stale", years, unit)
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// collectSharesInteractive prompts for pieces one at a time until enough have
+// been collected. Each piece can be a file path, a pasted share block, a
+// compact RM... string, or its words (across as many lines as needed).
+// Pieces are checked as they arrive, and one from a different set — by
+// fingerprint, version or total/threshold — is rejected without ending the
+// session. Typing "done" stops early, which is only needed when every piece
+// so far was typed as words and the threshold is unknown. Words are read in
+// lang's word list, or any list if lang is empty.
+func collectSharesInteractive(in io.Reader, out io.Writer, lang core.Lang) ([]*core.Share, []string, error) {
+	sc := bufio.NewScanner(in)
+	var shares []*core.Share
+	var labels []string
+	var ref *core.Share // first piece that knows its total and threshold
+
+	for {
+		fmt.Fprintf(out, "\nPiece %d: enter a file path, or paste the piece or its words (\"done\" to finish):\n", len(shares)+1)
+		content, label, done := readPiece(sc, lang)
+		if done {
+			break
+		}
+
+		share, err := parseShareInput(content, lang)
+		if err != nil && isBrokenBlock(content) {
+			err = fmt.Errorf("the pasted piece has no END line, or it is damaged; paste the whole piece, from its BEGIN line to its END line")
+		}
+		if err == nil {
+			err = share.Verify()
+		}
+		if err == nil {
+			err = checkSameSet(share, shares, ref)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "  %s %v\n", red("✗"), err)
+			continue
+		}
+
+		if label == "" {
+			label = fmt.Sprintf("piece %d", len(shares)+1)
+		}
+		shares = append(shares, share)
+		labels = append(labels, label)
+		if ref == nil && share.Threshold > 0 {
+			ref = share
+		}
+
+		who := "piece"
+		if share.Holder != "" {
+			who = share.Holder + "'s piece"
+		}
+		switch {
+		case ref == nil:
+			fmt.Fprintf(out, "  %s %s, %d so far (add another, or type done)\n", green("✓"), who, len(shares))
+		case ref.Fingerprint() != "":
+			fmt.Fprintf(out, "  %s %s, %d of %d, fingerprint %s OK\n", green("✓"), who, len(shares), ref.Threshold, ref.Fingerprint())
+		default:
+			fmt.Fprintf(out, "  %s %s, %d of %d\n", green("✓"), who, len(shares), ref.Threshold)
+		}
+
+		if ref != nil && len(shares) >= ref.Threshold {
+			fmt.Fprintln(out, "\nEnough pieces collected.")
+			return shares, labels, nil
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("reading input: %w", err)
+	}
+	if len(shares) == 0 {
+		return nil, nil, fmt.Errorf("no pieces entered")
+	}
+	return shares, labels, nil
+}
+
+// checkSameSet reports why share can't be combined with the ones already
+// collected, or nil if it can.
+func checkSameSet(share *core.Share, have []*core.Share, ref *core.Share) error {
+	if ref != nil {
+		if fp, want := share.Fingerprint(), ref.Fingerprint(); fp != "" && want != "" && fp != want {
+			return fmt.Errorf("this piece is from a different set (fingerprint %s, expected %s)", fp, want)
+		}
+	}
+	if len(have) > 0 && share.Version != have[0].Version {
+		return fmt.Errorf("this piece is from a different set (v%d, expected v%d)", share.Version, have[0].Version)
+	}
+	if ref != nil && share.Threshold > 0 {
+		if share.Total != ref.Total || share.Threshold != ref.Threshold {
+			return fmt.Errorf("this piece is from a different set (%d of %d, expected %d of %d)", share.Threshold, share.Total, ref.Threshold, ref.Total)
+		}
+	}
+	for _, s := range have {
+		if share.Index > 0 && s.Index == share.Index {
+			return fmt.Errorf("already have piece %d", share.Index)
+		}
+		if subtle.ConstantTimeCompare(s.Data, share.Data) == 1 {
+			return fmt.Errorf("already have this piece")
+		}
+	}
+	return nil
+}
+
+// readPiece reads one piece from sc. A line naming an existing file loads
+// that file and returns its path as the label; otherwise lines are gathered
+// until they parse as a share or a blank line ends the entry. A pasted
+// share block has a blank line of its own between its headers and its
+// data, so in a block only a blank line after the data ends the entry, as
+// when the END line is missing or damaged. Words that parse without a piece
+// number may still be followed by a 26th word with it, so those wait for it
+// or for a blank line. done is true at end of input or on "done".
+func readPiece(sc *bufio.Scanner, lang core.Lang) (content []byte, label string, done bool) {
+	var lines []string
+	waiting := false // words parsed, but a 26th word may follow
+	inData := false  // past a share block's headers
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(lines) == 0 {
+			switch {
+			case line == "":
+				continue
+			case strings.EqualFold(line, "done"):
+				return nil, "", true
+			}
+			if info, err := os.Stat(line); err == nil && info.Mode().IsRegular() {
+				if data, err := os.ReadFile(line); err == nil {
+					return data, line, false
+				}
+			}
+		}
+		inBlock := len(lines) > 0 && strings.Contains(lines[0], "-----BEGIN")
+		if line == "" && (!inBlock || inData) {
+			break
+		}
+		if inBlock && line != "" && !strings.Contains(line, ": ") {
+			inData = true
+		}
+		lines = append(lines, line)
+		text := strings.Join(lines, "\n")
+		if share, err := core.ParseShareAnyLang([]byte(text), lang); err == nil {
+			if share.Index == 0 && share.Threshold == 0 && !waiting {
+				waiting = true
+				continue
+			}
+			return []byte(text), "", false
+		}
+	}
+	if len(lines) == 0 {
+		return nil, "", true
+	}
+	return []byte(strings.Join(lines, "\n")), "", false
+}
+
+// isBrokenBlock reports whether content starts a share block but doesn't end
+// it.
+func isBrokenBlock(content []byte) bool {
+	text := string(content)
+	return strings.Contains(text, "-----BEGIN") && !strings.Contains(text, "-----END")
+}
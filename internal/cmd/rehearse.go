@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var rehearseCmd = &cobra.Command{
+	Use:   "rehearse share1.txt share2.txt ...",
+	Short: "Check that a quorum of pieces still unlocks the manifest",
+	Long: `Rehearse is a recovery drill. It combines the pieces you give it and
+checks that they unlock the project's MANIFEST.age, like
+'rememory recover --verify-only', without writing any recovered files.
+
+When the check passes, the time is saved in project.yml, and
+'rememory status' reminds you once a year has gone by without one.
+Nothing is saved when it fails.
+
+If the project has a recovery question, give its answer on the first
+line of stdin with --answer-stdin, or in the ` + answerEnv + ` environment
+variable.
+
+Run this command inside a sealed project directory.
+
+Example:
+  rememory rehearse SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRehearse,
+}
+
+var (
+	rehearseLang        string
+	rehearseAnswerStdin bool
+)
+
+func init() {
+	rootCmd.AddCommand(rehearseCmd)
+	rehearseCmd.Flags().StringVar(&rehearseLang, "lang", "", "Word list language of shares given as words (default: detect)")
+	rehearseCmd.Flags().BoolVar(&rehearseAnswerStdin, "answer-stdin", false, "Read the answer to the project's question from stdin instead of $"+answerEnv)
+}
+
+func runRehearse(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return err
+	}
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project is not sealed yet; run 'rememory seal' first")
+	}
+	if p.PerFileKeys() {
+		return fmt.Errorf("rehearsing: %w; use 'rememory recover --secret NAME --verify-only' for each secret instead", project.ErrPerFileKeys)
+	}
+
+	lang, err := wordLangFlag(rehearseLang)
+	if err != nil {
+		return err
+	}
+	var answer string
+	if p.Question != "" {
+		if answer, err = requireAnswer(cmd.InOrStdin(), rehearseAnswerStdin, p.Question); err != nil {
+			return err
+		}
+	}
+
+	status := cmd.OutOrStdout()
+	shares, labels, err := readShareFiles(status, args, lang)
+	if err != nil {
+		return err
+	}
+	defer zeroizeShares(shares)
+
+	recovered, shareVersion, err := combineShares(status, shares, labels, defaultStaleYears, false, nil)
+	if err != nil {
+		return err
+	}
+	defer core.Zeroize(recovered)
+
+	encryptedData, err := os.ReadFile(p.ManifestAgePath())
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	fmt.Fprintln(status, "Decrypting manifest...")
+	if err := verifyDecrypts(cmd.Context(), status, encryptedData, core.RecoverPassphrase(recovered, shareVersion), answer); err != nil {
+		return err
+	}
+
+	p.Sealed.LastRehearsed = time.Now().UTC()
+	if err := p.Save(); err != nil {
+		return fmt.Errorf("saving project: %w", err)
+	}
+	fmt.Fprintf(status, "Rehearsal recorded in %s. Next one due in a year.\n", project.ProjectFileName)
+	return nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var reissueCmd = &cobra.Command{
+	Use:   "reissue --holder <name> share1.txt share2.txt ...",
+	Short: "Rebuild a lost bundle from the other friends' shares",
+	Long: `Reissue rebuilds one friend's share and bundle when they have lost it,
+using at least the threshold number of the other friends' shares.
+
+The passphrase is reconstructed and checked against the project, then the
+friend's share is derived so it combines with everyone else's. When the
+project still records the original share checksum, the rebuilt share is
+byte-for-byte the one issued at seal time.
+
+If the original share can't be restored, reissue stops. With
+--allow-new-share it issues a different share instead, which combines with
+the shares you supplied but may clash with a share you didn't supply.
+Sealing again is safer.
+
+Run this command inside the project directory.
+
+Example:
+  rememory reissue --holder Carol SHARE-alice.txt SHARE-bob.txt`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReissue,
+}
+
+var reissueHolder string
+
+func init() {
+	rootCmd.AddCommand(reissueCmd)
+	reissueCmd.Flags().StringVar(&reissueHolder, "holder", "", "Name of the friend whose bundle to rebuild")
+	reissueCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	reissueCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	reissueCmd.Flags().Bool("no-cli-link", false, "Leave the CLI download instructions out of README and recover.html")
+	reissueCmd.Flags().Bool("allow-new-share", false, "Issue a different share when the original can't be restored")
+	addWASMFlag(reissueCmd)
+	_ = reissueCmd.MarkFlagRequired("holder")
+}
+
+func runReissue(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return err
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	shares := make([]*core.Share, len(args))
+	for i, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading share %s: %w", path, err)
+		}
+		shares[i], err = core.ParseShare(content)
+		if err != nil {
+			return fmt.Errorf("parsing share %s: %w", path, err)
+		}
+		if err := p.MatchesShare(shares[i]); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	defer func() {
+		for _, s := range shares {
+			if s != nil {
+				s.Zeroize()
+			}
+		}
+	}()
+
+	fmt.Printf("Rebuilding share for %s from %d shares...\n", reissueHolder, len(shares))
+
+	allowNew, _ := cmd.Flags().GetBool("allow-new-share")
+	result, err := bundle.ReissueShare(p, reissueHolder, shares, allowNew)
+	if errors.Is(err, bundle.ErrOriginalShareLost) {
+		return fmt.Errorf("%w: the project has no checksum for it, or none matched; seal again, or use --allow-new-share to issue a different one", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer result.Share.Zeroize()
+
+	if result.Exact {
+		fmt.Printf("  %s Matches the share issued at seal time\n", green("✓"))
+	} else {
+		fmt.Printf("  %s Could not match the original share; issued a new one that\n", yellow("!"))
+		fmt.Println("    combines with the shares you supplied, but may clash with a share")
+		fmt.Println("    you didn't supply. Consider re-sealing.")
+	}
+
+	// Restore the share file so `rememory bundle` keeps working
+	if err := os.MkdirAll(p.SharesPath(), 0755); err != nil {
+		return fmt.Errorf("creating shares directory: %w", err)
+	}
+	sharePath := filepath.Join(p.SharesPath(), result.Share.Filename())
+	if err := os.WriteFile(sharePath, []byte(result.Share.Encode()), 0600); err != nil {
+		return fmt.Errorf("writing share: %w", err)
+	}
+	if err := recordShareChecksum(p, result.Share.Holder, sharePath); err != nil {
+		return err
+	}
+
+	wasmBytes, err := recoverWASMBytes(cmd)
+	if err != nil {
+		return err
+	}
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+
+	cfg := bundle.Config{
+		Version:          version,
+		GitHubReleaseURL: releaseURL(cmd),
+		WASMBytes:        wasmBytes,
+		RecoveryURL:      recoveryURL,
+		NoEmbedManifest:  noEmbedManifest,
+	}
+
+	bundlePath, err := bundle.GenerateFriendBundle(p, cfg, result.Index, result.Share)
+	if err != nil {
+		return fmt.Errorf("generating bundle: %w", err)
+	}
+
+	relShare, _ := filepath.Rel(p.Path, sharePath)
+	relBundle, _ := filepath.Rel(p.Path, bundlePath)
+	fmt.Println()
+	fmt.Printf("  %s %s\n", green("✓"), relShare)
+	fmt.Printf("  %s %s\n", green("✓"), relBundle)
+	return nil
+}
+
+// recordShareChecksum saves the checksum of holder's share file at
+// sharePath in project.yml, so verify accepts a reissued share.
+func recordShareChecksum(p *project.Project, holder, sharePath string) error {
+	checksum, err := crypto.HashFile(sharePath)
+	if err != nil {
+		return fmt.Errorf("computing checksum: %w", err)
+	}
+	relPath, _ := filepath.Rel(p.Path, sharePath)
+	info := project.ShareInfo{Friend: holder, File: relPath, Checksum: checksum}
+	found := false
+	for i, si := range p.Sealed.Shares {
+		if strings.EqualFold(si.Friend, holder) {
+			p.Sealed.Shares[i] = info
+			found = true
+		}
+	}
+	if !found {
+		p.Sealed.Shares = append(p.Sealed.Shares, info)
+	}
+	if err := p.Save(); err != nil {
+		return fmt.Errorf("saving project: %w", err)
+	}
+	return nil
+}
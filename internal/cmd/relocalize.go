@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var relocalizeCmd = &cobra.Command{
+	Use:   "relocalize <bundle.zip> --lang <lang>",
+	Short: "Rewrite a bundle's instructions in another language",
+	Long: `Relocalize rebuilds README.txt and README.pdf in a bundle in another
+language, and makes recover.html open in that language.
+
+The share, MANIFEST.age and the recovery tool are kept as they are, so the
+bundle doesn't need re-sealing and still works with everyone else's. The
+checksums in the README footer are updated to match, and so is the bundle's
+line in a SHA256SUMS file next to it, if there is one.
+
+The project directory isn't needed.
+
+Example:
+  rememory relocalize bundle-alice.zip --lang fr`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRelocalize,
+}
+
+var relocalizeLang string
+
+func init() {
+	rootCmd.AddCommand(relocalizeCmd)
+	relocalizeCmd.Flags().StringVar(&relocalizeLang, "lang", "", "Language for the bundle (en, es, de, fr, sl, pt, zh-TW)")
+	relocalizeCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	_ = relocalizeCmd.MarkFlagRequired("lang")
+}
+
+func runRelocalize(cmd *cobra.Command, args []string) error {
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	if err := bundle.RelocalizeBundle(args[0], relocalizeLang, recoveryURL); err != nil {
+		return fmt.Errorf("relocalizing %s: %w", args[0], err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s is now in %s\n", green("✓"), args[0], relocalizeLang)
+	return nil
+}
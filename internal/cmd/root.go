@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +17,11 @@ using Shamir's Secret Sharing, and creates recovery bundles for trusted friends.
 Create a project:    rememory init my-recovery
 Seal the manifest:   rememory seal
 Recover from shares: rememory recover share1.txt share2.txt share3.txt`,
+	// Refuse to run with a damaged word list: pieces written as words would
+	// not match any other copy of rememory.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return core.VerifyWordLists()
+	},
 }
 
 func Execute(v string) error {
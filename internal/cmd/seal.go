@@ -1,17 +1,17 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/crypto"
-	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
@@ -31,6 +31,16 @@ This command:
   5. Generates ZIP bundles for distribution
   6. Writes checksums to project.yml
 
+It then prints the manifest hash, the start of MANIFEST.age's
+checksum in groups of four, to read to friends over the phone. Each
+bundle's README.txt has the full checksum to compare it against. Use
+--manifest-hash to write it to a file as well.
+
+When project.yml sets a question, its answer is needed along with the
+pieces to unlock the archive. Give it on the first line of stdin with
+--answer-stdin, or in the ` + answerEnv + ` environment variable.
+Capital letters and extra spaces don't matter.
+
 Run this command inside a project directory (created with 'rememory init').`,
 	RunE: runSeal,
 }
@@ -38,6 +48,18 @@ Run this command inside a project directory (created with 'rememory init').`,
 func init() {
 	sealCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
 	sealCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	sealCmd.Flags().Bool("no-cli-link", false, "Leave the CLI download instructions out of README and recover.html")
+	sealCmd.Flags().String("compression", string(core.CompressionGzip), "Compression for the manifest archive: gzip or zstd")
+	sealCmd.Flags().String("symlinks", string(manifest.SymlinkSkip), "What to do with symlinks in manifest/: follow, store, or skip")
+	sealCmd.Flags().Bool("per-file-keys", false, "Seal each top-level file or directory in manifest/ under its own passphrase and pieces")
+	sealCmd.Flags().String("manifest-hash", "", "Also write the manifest hash to this file")
+	sealCmd.Flags().Bool("allow-shares", false, "Seal even if manifest/ seems to contain a piece")
+	sealCmd.Flags().Bool("force", false, "Seal even if the threshold equals the number of friends")
+	sealCmd.Flags().Bool("answer-stdin", false, "Read the answer to the project's question from stdin instead of $"+answerEnv)
+	sealCmd.Flags().String("keyring", "", "Read the answer to the project's question from the system keyring entry SERVICE/ACCOUNT")
+	sealCmd.Flags().Bool("pin-version", false, "Record this version of rememory in every piece as the oldest that may recover")
+	sealCmd.Flags().String("escrow", "", "Also write an extra escrow piece, not given to any friend, to this file")
+	addWASMFlag(sealCmd)
 	rootCmd.AddCommand(sealCmd)
 }
 
@@ -61,11 +83,77 @@ func runSeal(cmd *cobra.Command, args []string) error {
 	if err := p.Validate(); err != nil {
 		return fmt.Errorf("invalid project: %w", err)
 	}
+	force, _ := cmd.Flags().GetBool("force")
+	if err := checkThreshold(os.Stdout, p.Threshold, len(p.Friends), force); err != nil {
+		return err
+	}
+
+	allowShares, _ := cmd.Flags().GetBool("allow-shares")
+	if !allowShares {
+		if err := checkNoShares(p.ManifestPath()); err != nil {
+			return err
+		}
+	}
 
 	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
 	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+	compressionName, _ := cmd.Flags().GetString("compression")
+	compression, err := core.ParseCompression(compressionName)
+	if err != nil {
+		return err
+	}
+	symlinksName, _ := cmd.Flags().GetString("symlinks")
+	symlinks, err := manifest.ParseSymlinkMode(symlinksName)
+	if err != nil {
+		return err
+	}
+	archiveOpts := manifest.ArchiveOptions{Compression: compression, Symlinks: symlinks}
+	wasmBytes, err := recoverWASMBytes(cmd)
+	if err != nil {
+		return err
+	}
+
+	var answer string
+	keyring, _ := cmd.Flags().GetString("keyring")
+	if keyring != "" && p.Question == "" {
+		return fmt.Errorf("--keyring reads the answer to the project's question, and this project has none")
+	}
+	if p.Question != "" {
+		answerStdin, _ := cmd.Flags().GetBool("answer-stdin")
+		if answer, err = keyringSecret(cmd.ErrOrStderr(), keyring, "--answer-stdin or "+answerEnv); err != nil {
+			return err
+		}
+		if answer == "" {
+			if answer, err = requireAnswer(cmd.InOrStdin(), answerStdin, p.Question); err != nil {
+				return err
+			}
+		}
+	}
+
+	manifestHashPath, _ := cmd.Flags().GetString("manifest-hash")
+	escrowPath, _ := cmd.Flags().GetString("escrow")
+	var requiresVersion string
+	if pin, _ := cmd.Flags().GetBool("pin-version"); pin {
+		if !core.IsReleaseVersion(version) {
+			return fmt.Errorf("can't pin version %q: only release builds have a version to pin", version)
+		}
+		requiresVersion = version
+	}
 
-	if err := sealProject(p, recoveryURL, noEmbedManifest); err != nil {
+	if perFileKeys, _ := cmd.Flags().GetBool("per-file-keys"); perFileKeys {
+		if escrowPath != "" {
+			return fmt.Errorf("--escrow can't be used with --per-file-keys")
+		}
+		if err := sealPerFileKeys(p, archiveOpts, answer, requiresVersion); err != nil {
+			return err
+		}
+		return writeManifestHash(manifestHashPath, p)
+	}
+
+	if err := sealProject(p, recoveryURL, releaseURL(cmd), noEmbedManifest, archiveOpts, answer, wasmBytes, escrowPath, requiresVersion); err != nil {
+		return err
+	}
+	if err := writeManifestHash(manifestHashPath, p); err != nil {
 		return err
 	}
 
@@ -75,11 +163,37 @@ func runSeal(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkNoShares refuses to seal a manifest directory that seems to hold a
+// piece, since anyone opening the manifest would then have that piece too.
+func checkNoShares(manifestDir string) error {
+	found, err := manifest.FindShares(manifestDir)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	const shown = 5
+	list := strings.Join(found[:min(len(found), shown)], ", ")
+	if len(found) > shown {
+		list += fmt.Sprintf(", and %d more", len(found)-shown)
+	}
+	return fmt.Errorf("manifest/ seems to contain a piece (%s); move it out before sealing, or use --allow-shares if this is on purpose", list)
+}
+
 // sealProject archives, encrypts, splits, verifies, saves, and generates bundles
 // for an already-loaded project. Both runSeal and runDemo share this logic.
 // recoveryURL is the base URL for QR codes in the PDF. If empty, the PDF defaults to the production URL.
+// githubReleaseURL is where bundles point friends for the CLI; empty leaves it out.
 // noEmbedManifest controls whether MANIFEST.age is embedded in recover.html.
-func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) error {
+// archiveOpts selects the archive's compression codec and symlink handling.
+// answer is the answer to p.Question, and is ignored when p has no question.
+// If escrowPath is set, an escrow piece is written there as well, before
+// anything else; it must not exist yet, and is removed again if sealing fails.
+// requiresVersion, if set, is recorded in every share as the oldest version
+// that may recover.
+func sealProject(p *project.Project, recoveryURL, githubReleaseURL string, noEmbedManifest bool, archiveOpts manifest.ArchiveOptions, answer string, wasmBytes []byte, escrowPath, requiresVersion string) (err error) {
 	// Check manifest directory exists and has content
 	manifestDir := p.ManifestPath()
 	fileCount, err := manifest.CountFiles(manifestDir)
@@ -97,30 +211,65 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 
 	fmt.Printf("Archiving manifest/ (%d files, %s)...\n", fileCount, formatSize(dirSize))
 
-	// Archive the manifest directory
-	var archiveBuf bytes.Buffer
-	archiveResult, err := manifest.Archive(&archiveBuf, manifestDir)
+	// Generate passphrase (v2: split raw bytes, not the base64 string)
+	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	if err != nil {
+		return fmt.Errorf("generating passphrase: %w", err)
+	}
+
+	fmt.Println("Encrypting with age...")
+
+	// Archive and encrypt the manifest directory into a temporary file,
+	// renamed to MANIFEST.age once the escrow piece is written.
+	sealedPath, archiveResult, err := sealManifest(p, manifestDir, passphrase, answer, archiveOpts)
 	if err != nil {
-		return fmt.Errorf("archiving manifest: %w", err)
+		return err
 	}
+	defer os.Remove(sealedPath)
 
 	for _, warning := range archiveResult.Warnings {
 		fmt.Printf("  Warning: %s\n", warning)
 	}
+	if err := reportNonText(manifestDir); err != nil {
+		return err
+	}
 
-	// Generate passphrase (v2: split raw bytes, not the base64 string)
-	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	fmt.Printf("Splitting into %d shares (threshold: %d)...\n", len(p.Friends), p.Threshold)
+
+	// Split the raw bytes (v2: 32 bytes instead of 43-byte base64 string).
+	// All shares get the same timestamp so they share a fingerprint.
+	sealedAt := time.Now().UTC()
+	shares, err := core.SplitToShares(raw, len(p.Friends), p.Threshold, holderNames(p.Friends), sealedAt)
 	if err != nil {
-		return fmt.Errorf("generating passphrase: %w", err)
+		return fmt.Errorf("splitting passphrase: %w", err)
 	}
 
-	fmt.Println("Encrypting with age...")
+	commitment, err := core.NewCommitment(raw)
+	if err != nil {
+		return fmt.Errorf("committing to passphrase: %w", err)
+	}
+
+	for i, share := range shares {
+		share.Headers = map[string]string{core.CommitmentHeader: commitment}
+		if requiresVersion != "" {
+			share.Headers[core.RequiresVersionHeader] = requiresVersion
+		}
+		share.Label = p.Friends[i].Label
+	}
 
-	// Encrypt the archive
-	var encryptedBuf bytes.Buffer
-	archiveReader := bytes.NewReader(archiveBuf.Bytes())
-	if err := core.Encrypt(&encryptedBuf, archiveReader, passphrase); err != nil {
-		return fmt.Errorf("encrypting: %w", err)
+	// Write the escrow piece before anything in output/ is replaced, so
+	// an existing escrow file, or any other failure writing it, leaves
+	// the previous seal as it was.
+	var escrow *core.Share
+	if escrowPath != "" {
+		if escrow, err = writeEscrowShare(escrowPath, shares); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				os.Remove(escrowPath)
+			}
+		}()
 	}
 
 	// Create output directories
@@ -131,24 +280,14 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 
 	// Write encrypted manifest
 	manifestAgePath := p.ManifestAgePath()
-	if err := os.WriteFile(manifestAgePath, encryptedBuf.Bytes(), 0644); err != nil {
+	if err := os.Rename(sealedPath, manifestAgePath); err != nil {
 		return fmt.Errorf("writing encrypted manifest: %w", err)
 	}
 
-	fmt.Printf("Splitting into %d shares (threshold: %d)...\n", len(p.Friends), p.Threshold)
-
-	// Split the raw bytes (v2: 32 bytes instead of 43-byte base64 string)
-	shares, err := core.Split(raw, len(p.Friends), p.Threshold)
-	if err != nil {
-		return fmt.Errorf("splitting passphrase: %w", err)
-	}
-
-	// Create share files
+	// Create share files.
 	shareInfos := make([]project.ShareInfo, len(shares))
-	for i, shareData := range shares {
+	for i, share := range shares {
 		friend := p.Friends[i]
-		share := core.NewShare(2, i+1, len(p.Friends), p.Threshold, friend.Name, shareData)
-
 		filename := share.Filename()
 		sharePath := filepath.Join(sharesDir, filename)
 
@@ -173,16 +312,23 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 	fmt.Print("Verifying reconstruction... ")
 	testShares := make([][]byte, p.Threshold)
 	for i := 0; i < p.Threshold; i++ {
-		testShares[i] = shares[i]
+		testShares[i] = shares[i].Data
 	}
-	recovered, err := core.Combine(testShares)
-	if err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("verification failed: %w", err)
+	quorums := [][][]byte{testShares}
+	if escrow != nil {
+		// The escrow piece stands in for one friend.
+		quorums = append(quorums, append([][]byte{escrow.Data}, testShares[1:]...))
 	}
-	if base64.RawURLEncoding.EncodeToString(recovered) != passphrase {
-		fmt.Println("FAILED")
-		return fmt.Errorf("verification failed: reconstructed passphrase doesn't match")
+	for _, quorum := range quorums {
+		recovered, err := core.Combine(quorum)
+		if err != nil {
+			fmt.Println("FAILED")
+			return fmt.Errorf("verification failed: %w", err)
+		}
+		if base64.RawURLEncoding.EncodeToString(recovered) != passphrase {
+			fmt.Println("FAILED")
+			return fmt.Errorf("verification failed: reconstructed passphrase doesn't match")
+		}
 	}
 	fmt.Println("OK")
 
@@ -193,10 +339,11 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 	}
 
 	p.Sealed = &project.Sealed{
-		At:               time.Now().UTC(),
+		At:               sealedAt,
 		ManifestChecksum: manifestChecksum,
 		VerificationHash: core.HashString(passphrase),
 		Shares:           shareInfos,
+		Commitment:       commitment,
 	}
 
 	if err := p.Save(); err != nil {
@@ -211,22 +358,27 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 	for _, si := range shareInfos {
 		fmt.Printf("  %s %s\n", green("✓"), si.File)
 	}
+	if escrow != nil {
+		fmt.Printf("  %s %s (escrow piece)\n", green("✓"), escrowPath)
+		fmt.Println()
+		fmt.Printf("%s the escrow piece counts as a full piece. Whoever holds it and %d of\n", yellow("Warning:"), p.Threshold-1)
+		fmt.Println("your friends can recover everything. Keep it sealed and somewhere you trust.")
+	}
+	fmt.Println()
+	fmt.Println("Manifest hash (read it to friends so they can check their copy):")
+	fmt.Printf("  %s\n", core.FormatChecksumGrouped(manifestChecksum))
 
 	// Generate bundles
 	fmt.Println()
 	fmt.Printf("Generating bundles for %d friends...\n", len(p.Friends))
 
-	wasmBytes := html.GetRecoverWASMBytes()
-	if len(wasmBytes) == 0 {
-		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
-	}
-
 	cfg := bundle.Config{
 		Version:          version,
-		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
+		GitHubReleaseURL: githubReleaseURL,
 		WASMBytes:        wasmBytes,
 		RecoveryURL:      recoveryURL,
 		NoEmbedManifest:  noEmbedManifest,
+		Progress:         bundleProgress(os.Stdout),
 	}
 
 	if err := bundle.GenerateAll(p, cfg); err != nil {
@@ -249,6 +401,108 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 	return nil
 }
 
+// maxNonTextListed is how many non-text files reportNonText names.
+const maxNonTextListed = 10
+
+// reportNonText prints how many files in manifestDir are UTF-8 text and
+// names the ones that aren't, so the owner knows which files friends will
+// need another program for, or may see garbled if the encoding is unusual.
+func reportNonText(manifestDir string) error {
+	nonText, total, err := manifest.FindNonText(manifestDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %d text files, %d binary or not UTF-8\n", total-len(nonText), len(nonText))
+	if len(nonText) == 0 {
+		return nil
+	}
+	fmt.Println("  These are kept byte for byte, but aren't plain text. Friends need the")
+	fmt.Println("  right program to open them, or the right encoding for non-UTF-8 text:")
+	for i, name := range nonText {
+		if i == maxNonTextListed {
+			fmt.Printf("    ...and %d more\n", len(nonText)-maxNonTextListed)
+			break
+		}
+		fmt.Printf("    %s\n", name)
+	}
+	return nil
+}
+
+// writeEscrowShare makes an escrow piece for shares, the full set, and
+// writes it to path. An existing file is not overwritten.
+func writeEscrowShare(path string, shares []*core.Share) (*core.Share, error) {
+	escrow, err := core.NewEscrowShare(shares)
+	if err != nil {
+		return nil, fmt.Errorf("making escrow piece: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("writing escrow piece: %w", err)
+	}
+	_, err = io.WriteString(f, escrow.Encode())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing escrow piece %s: %w", path, err)
+	}
+	return escrow, nil
+}
+
+// writeManifestHash writes the short hash of each sealed manifest to path,
+// one "<file>  <hash>" line per manifest. It does nothing when path
+// is empty.
+func writeManifestHash(path string, p *project.Project) error {
+	if path == "" {
+		return nil
+	}
+	var sb strings.Builder
+	if p.PerFileKeys() {
+		for _, secret := range p.Sealed.Secrets {
+			fmt.Fprintf(&sb, "%s  %s\n", secret.File, core.FormatChecksumGrouped(secret.ManifestChecksum))
+		}
+	} else {
+		relManifest, _ := filepath.Rel(p.Path, p.ManifestAgePath())
+		fmt.Fprintf(&sb, "%s  %s\n", relManifest, core.FormatChecksumGrouped(p.Sealed.ManifestChecksum))
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("writing manifest hash: %w", err)
+	}
+	return nil
+}
+
+// sealManifest archives and encrypts the manifest directory root with
+// passphrase, and with the answer to p.Question too when p has one. The
+// archive is never held in memory whole: it goes straight into a temporary
+// file in p's output directory, whose path is returned for the caller to
+// rename into place.
+func sealManifest(p *project.Project, root, passphrase, answer string, archiveOpts manifest.ArchiveOptions) (string, *manifest.ArchiveResult, error) {
+	if err := os.MkdirAll(p.OutputPath(), 0755); err != nil {
+		return "", nil, fmt.Errorf("creating output directories: %w", err)
+	}
+	f, err := os.CreateTemp(p.OutputPath(), ".MANIFEST.age-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+
+	opts := manifest.SealOptions{ArchiveOptions: archiveOpts}
+	if p.Question != "" {
+		opts.Answer = answer
+	}
+	result, err := manifest.SealDirectory(root, f, passphrase, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("writing encrypted manifest: %w", closeErr)
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", nil, fmt.Errorf("sealing manifest: %w", err)
+	}
+	return f.Name(), result, nil
+}
+
 func formatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// secretSlug turns a top-level manifest entry name into the part of
+// MANIFEST-<slug>.age and shares/<slug>/ that names it.
+func secretSlug(name string) string {
+	return core.SanitizeFilename(strings.ReplaceAll(name, ".", "-"))
+}
+
+// secretManifestName is the encrypted archive of one secret.
+func secretManifestName(name string) string {
+	return fmt.Sprintf("MANIFEST-%s.age", secretSlug(name))
+}
+
+// listSecrets returns the top-level entries of manifestDir that
+// --per-file-keys seals one by one, in name order.
+func listSecrets(manifestDir string) ([]string, error) {
+	entries, err := os.ReadDir(manifestDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest directory: %w", err)
+	}
+
+	var names []string
+	slugs := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			fmt.Printf("  Warning: skipping %s (only regular files and directories are sealed on their own)\n", entry.Name())
+			continue
+		}
+		if entry.Name() == core.ManifestHashesFile {
+			continue
+		}
+
+		slug := secretSlug(entry.Name())
+		if slug == "" {
+			return nil, fmt.Errorf("can't name a file after %q; rename it using letters or digits", entry.Name())
+		}
+		if other, ok := slugs[slug]; ok {
+			return nil, fmt.Errorf("%q and %q would both be sealed as %s; rename one of them", other, entry.Name(), secretManifestName(entry.Name()))
+		}
+		slugs[slug] = entry.Name()
+		names = append(names, entry.Name())
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("manifest directory is empty: %s", manifestDir)
+	}
+	return names, nil
+}
+
+// sealPerFileKeys is sealProject for --per-file-keys: each top-level file
+// or directory of manifest/ is archived and encrypted under its own
+// passphrase, and each passphrase is split into its own set of shares. A
+// quorum of one secret's shares then opens that secret and nothing else.
+//
+// Bundles hold a single manifest, so none are generated; the shares are
+// written to output/shares/<secret>/ for handing out directly.
+func sealPerFileKeys(p *project.Project, archiveOpts manifest.ArchiveOptions, answer, requiresVersion string) error {
+	names, err := listSecrets(p.ManifestPath())
+	if err != nil {
+		return err
+	}
+	if err := reportNonText(p.ManifestPath()); err != nil {
+		return err
+	}
+
+	// All shares get the same timestamp, as in sealProject.
+	sealedAt := time.Now().UTC()
+	secrets := make([]project.SealedSecret, 0, len(names))
+	for _, name := range names {
+		fmt.Printf("Sealing %s...\n", name)
+		secret, err := sealSecret(p, name, archiveOpts, answer, sealedAt, requiresVersion)
+		if err != nil {
+			return fmt.Errorf("sealing %s: %w", name, err)
+		}
+		secrets = append(secrets, *secret)
+	}
+
+	p.Sealed = &project.Sealed{
+		At:      sealedAt,
+		Secrets: secrets,
+	}
+	if err := p.Save(); err != nil {
+		return fmt.Errorf("saving project: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("Sealed:")
+	for _, secret := range secrets {
+		fmt.Printf("  %s %s (hash %s)\n", green("✓"), secret.File, core.FormatChecksumGrouped(secret.ManifestChecksum))
+		for _, si := range secret.Shares {
+			fmt.Printf("  %s %s\n", green("✓"), si.File)
+		}
+	}
+	fmt.Println()
+	fmt.Println("Each secret has its own pieces. Bundles aren't generated with --per-file-keys,")
+	fmt.Println("so give each friend their pieces and the MANIFEST-*.age files directly.")
+
+	return nil
+}
+
+// sealSecret seals the top-level entry name of p's manifest directory on
+// its own, writing its archive and shares under p's output directory.
+func sealSecret(p *project.Project, name string, archiveOpts manifest.ArchiveOptions, answer string, sealedAt time.Time, requiresVersion string) (*project.SealedSecret, error) {
+	archiveOpts.Only = []string{name}
+	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("generating passphrase: %w", err)
+	}
+
+	sealedPath, archiveResult, err := sealManifest(p, p.ManifestPath(), passphrase, answer, archiveOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(sealedPath)
+	for _, warning := range archiveResult.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+
+	sharesDir := filepath.Join(p.SharesPath(), secretSlug(name))
+	if err := os.MkdirAll(sharesDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating output directories: %w", err)
+	}
+
+	manifestAgePath := filepath.Join(p.OutputPath(), secretManifestName(name))
+	if err := os.Rename(sealedPath, manifestAgePath); err != nil {
+		return nil, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+	manifestChecksum, err := crypto.HashFile(manifestAgePath)
+	if err != nil {
+		return nil, fmt.Errorf("computing manifest checksum: %w", err)
+	}
+
+	shares, err := core.SplitToShares(raw, len(p.Friends), p.Threshold, holderNames(p.Friends), sealedAt)
+	if err != nil {
+		return nil, fmt.Errorf("splitting passphrase: %w", err)
+	}
+	commitment, err := core.NewCommitment(raw)
+	if err != nil {
+		return nil, fmt.Errorf("committing to passphrase: %w", err)
+	}
+
+	shareInfos := make([]project.ShareInfo, len(shares))
+	for i, share := range shares {
+		friend := p.Friends[i]
+		share.Headers = map[string]string{core.SecretHeader: name, core.CommitmentHeader: commitment}
+		if requiresVersion != "" {
+			share.Headers[core.RequiresVersionHeader] = requiresVersion
+		}
+		share.Label = friend.Label
+
+		sharePath := filepath.Join(sharesDir, share.Filename())
+		encoded := []byte(share.Encode())
+		if err := os.WriteFile(sharePath, encoded, 0600); err != nil {
+			return nil, fmt.Errorf("writing share for %s: %w", friend.Name, err)
+		}
+
+		relPath, _ := filepath.Rel(p.Path, sharePath)
+		shareInfos[i] = project.ShareInfo{
+			Friend:   friend.Name,
+			File:     relPath,
+			Checksum: core.HashBytes(encoded),
+		}
+	}
+
+	testShares := make([][]byte, p.Threshold)
+	for i := range testShares {
+		testShares[i] = shares[i].Data
+	}
+	recovered, err := core.Combine(testShares)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+	if base64.RawURLEncoding.EncodeToString(recovered) != passphrase {
+		return nil, fmt.Errorf("verification failed: reconstructed passphrase doesn't match")
+	}
+
+	relManifest, _ := filepath.Rel(p.Path, manifestAgePath)
+	return &project.SealedSecret{
+		Name:             name,
+		File:             relManifest,
+		ManifestChecksum: manifestChecksum,
+		VerificationHash: core.HashString(passphrase),
+		Shares:           shareInfos,
+		Commitment:       commitment,
+	}, nil
+}
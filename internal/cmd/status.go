@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
@@ -14,12 +17,23 @@ import (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show project status and summary",
-	Long:  `Displays the current state of the rememory project including seal status, friends, and bundle information.`,
-	RunE:  runStatus,
+	Long: `Displays the current state of the rememory project including seal status, friends, and bundle information.
+
+With --threshold-of, also lists every smallest group of friends that can
+recover together, and flags groups where holders share an email domain or
+address, since those pieces may not be as independent as they look.`,
+	RunE: runStatus,
 }
 
+var statusThresholdOf bool
+
+// maxGroupsShown caps the --threshold-of listing; large groups have
+// thousands of combinations.
+const maxGroupsShown = 20
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusThresholdOf, "threshold-of", false, "List every group of friends that can recover together")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -47,7 +61,14 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Sealed status
 	if p.Sealed != nil {
 		fmt.Printf("Sealed: %s (%s)\n", green("Yes"), p.Sealed.At.Format("2006-01-02 15:04:05 UTC"))
-		fmt.Printf("Manifest Checksum: %s\n", truncateHash(p.Sealed.ManifestChecksum))
+		if p.PerFileKeys() {
+			fmt.Printf("Secrets: %d, each with its own pieces\n", len(p.Sealed.Secrets))
+			for _, secret := range p.Sealed.Secrets {
+				fmt.Printf("  %s (%s)\n", secret.Name, truncateHash(secret.ManifestChecksum))
+			}
+		} else {
+			fmt.Printf("Manifest Checksum: %s\n", truncateHash(p.Sealed.ManifestChecksum))
+		}
 	} else {
 		fmt.Printf("Sealed: %s\n", yellow("No"))
 		fmt.Println("  Run 'rememory seal' to encrypt and split the passphrase")
@@ -69,6 +90,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			contactInfo = "no contact info"
 		}
 		fmt.Printf("  %d. %s %s (%s)\n", i+1, status, friend.Name, contactInfo)
+		if note := shareHealthNote(p, friend, time.Now()); note != "" {
+			fmt.Printf("     %s %s\n", yellow("!"), note)
+		}
+	}
+
+	if statusThresholdOf {
+		printRecoveryGroups(os.Stdout, p)
 	}
 
 	// Bundles status
@@ -77,6 +105,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	if bundleCount > 0 {
 		fmt.Printf("Bundles: %s (%d bundles in %s)\n", green("Generated"), bundleCount, bundlesDir)
+	} else if p.PerFileKeys() {
+		fmt.Println("Bundles: Not available (sealed with --per-file-keys)")
 	} else if p.Sealed != nil {
 		fmt.Printf("Bundles: %s\n", yellow("Not yet generated"))
 		fmt.Println("  Run 'rememory bundle' to create distribution bundles")
@@ -95,18 +125,102 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Printf("Rotation: Last sealed %s ago (consider rotating every 2-3 years)\n", formatDuration(age))
 		}
+
+		switch last := p.Sealed.LastRehearsed; {
+		case p.PerFileKeys():
+			// rehearse works on a single manifest.
+		case p.RehearsalDue(time.Now()):
+			fmt.Printf("Rehearsal: %s\n", yellow("Not rehearsed in over a year - run 'rememory rehearse' with a quorum of pieces"))
+		case last.IsZero():
+			fmt.Println("Rehearsal: Never (run 'rememory rehearse' to check the pieces still work)")
+		default:
+			fmt.Printf("Rehearsal: Last rehearsed %s ago\n", formatDuration(time.Since(last)))
+		}
 	}
 
 	return nil
 }
 
+// printRecoveryGroups lists the groups of friends that can recover together,
+// noting holders in a group who share a contact.
+func printRecoveryGroups(w io.Writer, p *project.Project) {
+	groups := bundle.RecoveryGroups(p.Friends, p.Threshold)
+	fmt.Fprintf(w, "\nRecovery groups (any %d of %d, %d groups):\n", p.Threshold, len(p.Friends), len(groups))
+
+	flagged := 0
+	for i, group := range groups {
+		overlaps := bundle.SharedContacts(p.Friends, group)
+		if len(overlaps) > 0 {
+			flagged++
+		}
+		if i >= maxGroupsShown {
+			continue
+		}
+		line := "  " + strings.Join(group, ", ")
+		for _, o := range overlaps {
+			line += fmt.Sprintf("  %s %s share %s", yellow("!"), strings.Join(o.Names, " and "), o.Contact)
+		}
+		fmt.Fprintln(w, line)
+	}
+	if len(groups) > maxGroupsShown {
+		fmt.Fprintf(w, "  ...and %d more\n", len(groups)-maxGroupsShown)
+	}
+	if flagged > 0 {
+		fmt.Fprintf(w, "%s %d of %d groups include holders who share a contact; those pieces may not be independent.\n", yellow("Note:"), flagged, len(groups))
+	}
+}
+
 func checkShareExists(p *project.Project, friend project.Friend) bool {
 	sharesDir := p.SharesPath()
 	filename := fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(friend.Name))
+	if p.PerFileKeys() {
+		// The friend needs a share for every secret.
+		for _, secret := range p.Sealed.Secrets {
+			if _, err := os.Stat(filepath.Join(sharesDir, secretSlug(secret.Name), filename)); err != nil {
+				return false
+			}
+		}
+		return true
+	}
 	_, err := os.Stat(filepath.Join(sharesDir, filename))
 	return err == nil
 }
 
+// shareHealthNote describes what's wrong with friend's share in the
+// project, or returns "" when it's healthy or can't be read. A share from
+// another seal is reported first; otherwise its checksum and age are
+// checked.
+func shareHealthNote(p *project.Project, friend project.Friend, now time.Time) string {
+	if p.PerFileKeys() {
+		return ""
+	}
+	content, err := os.ReadFile(filepath.Join(p.SharesPath(), fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(friend.Name))))
+	if err != nil {
+		return ""
+	}
+	share, err := core.ParseShare(content)
+	if err != nil {
+		return fmt.Sprintf("piece can't be read: %v", err)
+	}
+	if p.Sealed != nil {
+		if err := p.MatchesShare(share); err != nil {
+			return err.Error()
+		}
+	}
+	h := core.CheckShareHealth(share, now)
+	var problems []string
+	if !h.ChecksumOK {
+		problems = append(problems, "checksum doesn't match")
+	}
+	if !h.Fresh {
+		problems = append(problems, fmt.Sprintf("over %d years old", core.ShareStaleYears))
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("piece health %d/100: %s", h.Score, strings.Join(problems, ", "))
+}
+
 func countBundles(dir string) int {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
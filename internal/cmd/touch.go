@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var touchCmd = &cobra.Command{
+	Use:   "touch <bundle.zip>...",
+	Short: "Refresh the version and date in a bundle's README footer",
+	Long: `Touch rewrites the version and creation time in each bundle's README
+footer, and rebuilds README.txt and README.pdf to match. It's for keeping
+records straight after upgrading rememory, without sealing again.
+
+The share, MANIFEST.age and recover.html are kept byte for byte, so the
+bundle still works with everyone else's, and the recovery tool inside is
+still the one it was sealed with (use 'rememory bundle' to update that).
+The bundle's line in a SHA256SUMS file next to it is updated, if there is
+one.
+
+The creation time becomes now, or the time given with --created (as
+RFC 3339, like 2025-06-01T10:30:00Z), so audits can reproduce the result.
+
+The project directory isn't needed.
+
+Examples:
+  rememory touch output/bundles/*.zip
+  rememory touch bundle-alice.zip --created 2025-06-01T10:30:00Z`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runTouch,
+}
+
+func init() {
+	rootCmd.AddCommand(touchCmd)
+	touchCmd.Flags().String("created", "", "Creation time for the footer, as RFC 3339 (default: now)")
+	touchCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+}
+
+func runTouch(cmd *cobra.Command, args []string) error {
+	created := time.Now().UTC().Truncate(time.Second)
+	if s, _ := cmd.Flags().GetString("created"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("invalid --created: %w", err)
+		}
+		created = t
+	}
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+
+	cfg := bundle.Config{
+		Version:          version,
+		GitHubReleaseURL: releaseURL(cmd),
+		RecoveryURL:      recoveryURL,
+	}
+	for _, path := range args {
+		if err := bundle.TouchBundle(path, cfg, created); err != nil {
+			return fmt.Errorf("touching %s: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s: footer now says %s, created %s\n", green("✓"), path, version, created.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
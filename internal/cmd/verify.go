@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
 )
@@ -51,53 +53,29 @@ func runVerify(cmd *cobra.Command, args []string) error {
 
 	allOK := true
 
-	// Verify manifest file
-	manifestPath := p.ManifestAgePath()
-	fmt.Printf("Checking %s... ", filepath.Base(manifestPath))
-
-	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
-		fmt.Println("MISSING")
-		allOK = false
-	} else {
-		checksum, err := crypto.HashFile(manifestPath)
-		if err != nil {
-			fmt.Printf("ERROR: %v\n", err)
-			allOK = false
-		} else if checksum != p.Sealed.ManifestChecksum {
-			fmt.Println("CHECKSUM MISMATCH")
-			fmt.Printf("  Expected: %s\n", p.Sealed.ManifestChecksum)
-			fmt.Printf("  Got:      %s\n", checksum)
-			allOK = false
-		} else {
-			fmt.Println("OK")
+	if p.PerFileKeys() {
+		// Each secret has its own manifest and shares.
+		for _, secret := range p.Sealed.Secrets {
+			if !checkSealedFile(filepath.Join(p.Path, secret.File), secret.ManifestChecksum) {
+				allOK = false
+			}
+			for _, shareInfo := range secret.Shares {
+				if !checkSealedFile(filepath.Join(p.Path, shareInfo.File), shareInfo.Checksum) {
+					allOK = false
+				}
+			}
 		}
-	}
-
-	// Verify share files
-	for _, shareInfo := range p.Sealed.Shares {
-		sharePath := filepath.Join(p.Path, shareInfo.File)
-		fmt.Printf("Checking %s... ", filepath.Base(sharePath))
-
-		if _, err := os.Stat(sharePath); os.IsNotExist(err) {
-			fmt.Println("MISSING")
-			allOK = false
-			continue
-		}
-
-		checksum, err := crypto.HashFile(sharePath)
-		if err != nil {
-			fmt.Printf("ERROR: %v\n", err)
+	} else {
+		// Verify manifest file
+		if !checkSealedFile(p.ManifestAgePath(), p.Sealed.ManifestChecksum) {
 			allOK = false
-			continue
 		}
 
-		if checksum != shareInfo.Checksum {
-			fmt.Println("CHECKSUM MISMATCH")
-			fmt.Printf("  Expected: %s\n", shareInfo.Checksum)
-			fmt.Printf("  Got:      %s\n", checksum)
-			allOK = false
-		} else {
-			fmt.Println("OK")
+		// Verify share files
+		for _, shareInfo := range p.Sealed.Shares {
+			if !checkSealedFile(filepath.Join(p.Path, shareInfo.File), shareInfo.Checksum) {
+				allOK = false
+			}
 		}
 	}
 
@@ -109,3 +87,42 @@ func runVerify(cmd *cobra.Command, args []string) error {
 
 	return fmt.Errorf("verification failed")
 }
+
+// checkSealedFile prints one line of verify's report for path and returns
+// whether it matched its recorded checksum.
+func checkSealedFile(path, expected string) bool {
+	fmt.Printf("Checking %s... ", filepath.Base(path))
+
+	err := verifyFileChecksum(path, expected)
+	var mismatch *core.ChecksumError
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Println("MISSING")
+	case errors.As(err, &mismatch):
+		fmt.Println("CHECKSUM MISMATCH")
+		fmt.Printf("  Expected: %s\n", mismatch.Expected)
+		fmt.Printf("  Got:      %s\n", mismatch.Got)
+	case err != nil:
+		fmt.Printf("ERROR: %v\n", err)
+	default:
+		fmt.Println("OK")
+		return true
+	}
+	return false
+}
+
+// verifyFileChecksum hashes the file at path as it streams past, without
+// reading it into memory, and compares it with expected.
+func verifyFileChecksum(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, check := core.NewVerifyingReader(f, expected)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+	return check()
+}
@@ -13,6 +13,7 @@ var verifyBundleCmd = &cobra.Command{
 	Long: `Verify-bundle checks that a distribution bundle is valid and intact.
 
 This command verifies:
+  - The ZIP matches its entry in SHA256SUMS, if that file is next to it
   - All required files are present (README.txt, README.pdf, MANIFEST.age, recover.html)
   - Checksums match the values embedded in README.txt
   - The embedded share is valid and parseable
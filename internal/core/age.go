@@ -2,9 +2,11 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"filippo.io/age"
 )
@@ -39,12 +41,13 @@ func Encrypt(dst io.Writer, src io.Reader, passphrase string) error {
 	return nil
 }
 
-// Decrypt decrypts age-encrypted data using a passphrase.
+// Decrypt decrypts age-encrypted data using a passphrase. It reads files
+// from both Encrypt and EncryptArgon2, picking the KDF from the header.
 func Decrypt(dst io.Writer, src io.Reader, passphrase string) error {
 	if passphrase == "" {
 		return ErrEmptyPassphrase
 	}
-	identity, err := age.NewScryptIdentity(passphrase)
+	identity, err := newPassphraseIdentity(passphrase)
 	if err != nil {
 		return fmt.Errorf("creating identity: %w", err)
 	}
@@ -61,12 +64,93 @@ func Decrypt(dst io.Writer, src io.Reader, passphrase string) error {
 	return nil
 }
 
+// DecryptContext is Decrypt that gives up when ctx is done. age derives the
+// key from the passphrase inside Decrypt, where it can't be interrupted, and
+// with a high scrypt work factor that alone can take seconds. So the work
+// runs in a goroutine, and DecryptContext returns ctx.Err() as soon as ctx
+// is done, even if a read from src is blocked. From then on no new read of
+// src starts and nothing more is written to dst; what was written before may
+// be partial and should be dropped. A read already in progress is left to
+// finish on its own, and whatever it returns is dropped.
+func DecryptContext(ctx context.Context, dst io.Writer, src io.Reader, passphrase string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g := &ioGate{}
+	done := make(chan error, 1)
+	go func() {
+		done <- Decrypt(gatedWriter{g, dst}, gatedReader{g, src}, passphrase)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		g.close()
+		return ctx.Err()
+	}
+}
+
+// errGateClosed is what a gated reader or writer returns once its gate is
+// closed. DecryptContext has returned by then, so no caller sees it.
+var errGateClosed = errors.New("decryption cancelled")
+
+// ioGate lets DecryptContext cut a goroutine it can't stop off from the
+// caller's reader and writer.
+type ioGate struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+func (g *ioGate) close() {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+}
+
+func (g *ioGate) isClosed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.closed
+}
+
+type gatedWriter struct {
+	g *ioGate
+	w io.Writer
+}
+
+func (w gatedWriter) Write(p []byte) (int, error) {
+	w.g.mu.Lock()
+	defer w.g.mu.Unlock()
+	if w.g.closed {
+		return 0, errGateClosed
+	}
+	return w.w.Write(p)
+}
+
+type gatedReader struct {
+	g *ioGate
+	r io.Reader
+}
+
+// Read doesn't hold the gate's lock while reading, so closing the gate
+// doesn't wait for a read that blocks.
+func (r gatedReader) Read(p []byte) (int, error) {
+	if r.g.isClosed() {
+		return 0, errGateClosed
+	}
+	n, err := r.r.Read(p)
+	if r.g.isClosed() {
+		return 0, errGateClosed
+	}
+	return n, err
+}
+
 // DecryptBytes is a convenience function that decrypts data and returns bytes.
 func DecryptBytes(encryptedData []byte, passphrase string) ([]byte, error) {
 	if passphrase == "" {
 		return nil, ErrEmptyPassphrase
 	}
-	identity, err := age.NewScryptIdentity(passphrase)
+	identity, err := newPassphraseIdentity(passphrase)
 	if err != nil {
 		return nil, fmt.Errorf("creating identity: %w", err)
 	}
@@ -83,3 +167,29 @@ func DecryptBytes(encryptedData []byte, passphrase string) ([]byte, error) {
 
 	return decrypted, nil
 }
+
+// CanDecrypt checks that src decrypts with passphrase, without keeping any
+// of the plaintext. It reads src to the end, so a damaged payload is caught
+// as well as a wrong passphrase, and returns nil when everything decrypts.
+func CanDecrypt(src io.Reader, passphrase string) error {
+	return Decrypt(io.Discard, src, passphrase)
+}
+
+// age's framing for a single scrypt recipient: the text header, then a
+// 16-byte nonce, then the payload in 64 KiB chunks with a 16-byte tag each.
+const (
+	ageScryptHeaderSize = 150
+	ageNonceSize        = 16
+	ageChunkSize        = 64 * 1024
+	ageTagSize          = 16
+)
+
+// EncryptedSize returns the size of plainSize bytes once Encrypt has
+// encrypted them, for estimating output before sealing.
+func EncryptedSize(plainSize int64) int64 {
+	chunks := (plainSize + ageChunkSize - 1) / ageChunkSize
+	if chunks == 0 {
+		chunks = 1 // an empty payload still has one (empty) chunk
+	}
+	return ageScryptHeaderSize + ageNonceSize + plainSize + chunks*ageTagSize
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ageScryptWorkFactor is the scrypt cost age uses by default, as log2(N).
+// EncryptDeterministic uses the same, so its files are framed exactly like
+// Encrypt's.
+const ageScryptWorkFactor = 18
+
+// ageScryptSaltSize is the size of the salt in an age scrypt stanza.
+const ageScryptSaltSize = 16
+
+// EncryptDeterministic writes the same age scrypt file as Encrypt, but with
+// the file key and scrypt salt given instead of random, and the payload
+// nonce derived from the file key. The same input therefore always gives
+// the same bytes, so test fixtures can pin a MANIFEST.age exactly.
+//
+// It lives in a test file so it never reaches the CLI or the WASM: a fixed
+// file key or salt must never protect real data. fileKey and salt are 16
+// bytes each. The output decrypts with Decrypt, and with stock age.
+func EncryptDeterministic(dst io.Writer, src io.Reader, passphrase string, fileKey, salt []byte) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	if len(fileKey) != ageFileKeySize {
+		return fmt.Errorf("file key must be %d bytes, got %d", ageFileKeySize, len(fileKey))
+	}
+	if len(salt) != ageScryptSaltSize {
+		return fmt.Errorf("salt must be %d bytes, got %d", ageScryptSaltSize, len(salt))
+	}
+
+	header, err := ageScryptHeader(passphrase, fileKey, salt)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(dst, header); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	nonce := deterministicPayloadNonce(fileKey)
+	if _, err := dst.Write(nonce); err != nil {
+		return fmt.Errorf("writing nonce: %w", err)
+	}
+	streamKey, err := hkdf.Key(sha256.New, fileKey, nonce, "payload", chacha20poly1305.KeySize)
+	if err != nil {
+		return err
+	}
+	if err := writeAgePayload(dst, src, streamKey); err != nil {
+		return fmt.Errorf("encrypting: %w", err)
+	}
+	return nil
+}
+
+// ageScryptHeader builds an age v1 header with a single scrypt stanza
+// wrapping fileKey, and its MAC.
+func ageScryptHeader(passphrase string, fileKey, salt []byte) (string, error) {
+	label := "age-encryption.org/v1/scrypt"
+	key, err := scrypt.Key([]byte(passphrase), append([]byte(label), salt...), 1<<ageScryptWorkFactor, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return "", fmt.Errorf("deriving key: %w", err)
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return "", err
+	}
+	body := aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil)
+
+	var b strings.Builder
+	b.WriteString("age-encryption.org/v1\n")
+	fmt.Fprintf(&b, "-> scrypt %s %d\n", base64.RawStdEncoding.EncodeToString(salt), ageScryptWorkFactor)
+	// The body is wrapped at 64 columns and always ends with a short line.
+	encoded := base64.RawStdEncoding.EncodeToString(body)
+	for len(encoded) >= 64 {
+		b.WriteString(encoded[:64] + "\n")
+		encoded = encoded[64:]
+	}
+	b.WriteString(encoded + "\n")
+	b.WriteString("---")
+
+	macKey, err := hkdf.Key(sha256.New, fileKey, nil, "header", sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write([]byte(b.String()))
+	fmt.Fprintf(&b, " %s\n", base64.RawStdEncoding.EncodeToString(mac.Sum(nil)))
+	return b.String(), nil
+}
+
+// deterministicPayloadNonce derives the payload nonce from the file key,
+// where age would pick it at random.
+func deterministicPayloadNonce(fileKey []byte) []byte {
+	mac := hmac.New(sha256.New, fileKey)
+	mac.Write([]byte("rememory deterministic payload nonce"))
+	return mac.Sum(nil)[:ageNonceSize]
+}
+
+// writeAgePayload encrypts src in age's STREAM format: 64 KiB chunks, each
+// sealed with a nonce of an 11-byte counter and a last-chunk flag. Only an
+// empty payload has an empty chunk.
+func writeAgePayload(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, ageChunkSize)
+	next := make([]byte, 1)
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	var counter uint64
+	n, err := io.ReadFull(src, buf)
+	for {
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		last := err != nil
+		if !last {
+			// A full chunk is the last one only if nothing follows it.
+			m, peekErr := io.ReadFull(src, next)
+			if peekErr != nil && peekErr != io.EOF {
+				return peekErr
+			}
+			last = m == 0
+		}
+
+		binary.BigEndian.PutUint64(nonce[3:11], counter)
+		nonce[11] = 0
+		if last {
+			nonce[11] = 1
+		}
+		if _, err := dst.Write(aead.Seal(nil, nonce, buf[:n], nil)); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		counter++
+
+		buf[0] = next[0]
+		n, err = io.ReadFull(src, buf[1:])
+		n++
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !js
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+)
+
+// ErrNoRecipients is returned when EncryptToPluginRecipients is given no
+// recipients.
+var ErrNoRecipients = errors.New("at least one recipient is required")
+
+// EncryptToPluginRecipients encrypts src to age plugin recipients such as
+// "age1yubikey1...", for hardware-backed recovery: the file key is wrapped by
+// the token instead of a passphrase. Each recipient is handled by its plugin
+// binary (age-plugin-yubikey, ...) found on PATH, which may ask for a PIN or
+// a touch through ui. ui may be nil if no interaction is expected.
+//
+// Shamir then splits whatever unlocks the token, such as its PIN or a backup
+// plugin identity, rather than the age passphrase. This isn't available in
+// the browser, which can't run plugin binaries.
+func EncryptToPluginRecipients(dst io.Writer, src io.Reader, recipients []string, ui *plugin.ClientUI) error {
+	if len(recipients) == 0 {
+		return ErrNoRecipients
+	}
+	if ui == nil {
+		ui = &plugin.ClientUI{}
+	}
+
+	rs := make([]age.Recipient, len(recipients))
+	for i, s := range recipients {
+		r, err := plugin.NewRecipient(s, ui)
+		if err != nil {
+			return fmt.Errorf("recipient %d: %w", i+1, err)
+		}
+		rs[i] = r
+	}
+
+	writer, err := age.Encrypt(dst, rs...)
+	if err != nil {
+		return fmt.Errorf("creating encryptor: %w", err)
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		return fmt.Errorf("encrypting: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finalizing encryption: %w", err)
+	}
+
+	return nil
+}
+
+// DecryptWithPluginIdentities decrypts data written by
+// EncryptToPluginRecipients, using plugin identities such as
+// "AGE-PLUGIN-YUBIKEY-1...". Any one identity matching a recipient is enough.
+func DecryptWithPluginIdentities(dst io.Writer, src io.Reader, identities []string, ui *plugin.ClientUI) error {
+	if len(identities) == 0 {
+		return fmt.Errorf("at least one identity is required")
+	}
+	if ui == nil {
+		ui = &plugin.ClientUI{}
+	}
+
+	ids := make([]age.Identity, len(identities))
+	for i, s := range identities {
+		id, err := plugin.NewIdentity(s, ui)
+		if err != nil {
+			return fmt.Errorf("identity %d: %w", i+1, err)
+		}
+		ids[i] = id
+	}
+
+	reader, err := age.Decrypt(src, ids...)
+	if err != nil {
+		return fmt.Errorf("decrypting: %w", err)
+	}
+
+	if _, err := io.Copy(dst, reader); err != nil {
+		return fmt.Errorf("reading decrypted data: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+//go:build !js
+
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+)
+
+const mockPluginName = "rememorytest"
+
+// TestMain lets the test binary double as a mock age plugin: when run as
+// age-plugin-rememorytest it speaks the plugin protocol instead of testing.
+func TestMain(m *testing.M) {
+	if filepath.Base(os.Args[0]) == "age-plugin-"+mockPluginName {
+		p, _ := plugin.New(mockPluginName)
+		p.HandleRecipient(func(data []byte) (age.Recipient, error) {
+			return mockRecipient{id: hex.EncodeToString(data)}, nil
+		})
+		p.HandleIdentity(func(data []byte) (age.Identity, error) {
+			return mockIdentity{id: hex.EncodeToString(data)}, nil
+		})
+		os.Exit(p.Main())
+	}
+	os.Exit(m.Run())
+}
+
+// mockRecipient stands in for a hardware token. It stores the file key in
+// the clear, tagged with the token's id; only a real plugin protects it.
+type mockRecipient struct{ id string }
+
+func (r mockRecipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	return []*age.Stanza{{Type: mockPluginName, Args: []string{r.id}, Body: fileKey}}, nil
+}
+
+type mockIdentity struct{ id string }
+
+func (i mockIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type == mockPluginName && len(s.Args) == 1 && s.Args[0] == i.id {
+			return s.Body, nil
+		}
+	}
+	return nil, age.ErrIncorrectIdentity
+}
+
+// installMockPlugin puts a copy of the test binary on PATH as the plugin.
+func installMockPlugin(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin binaries are looked up by a different name on Windows")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "age-plugin-"+mockPluginName), data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPluginRecipientRoundTrip(t *testing.T) {
+	installMockPlugin(t)
+
+	recipient := plugin.EncodeRecipient(mockPluginName, []byte("token-1"))
+	plaintext := []byte("manifest protected by a hardware token")
+
+	var encrypted bytes.Buffer
+	if err := EncryptToPluginRecipients(&encrypted, bytes.NewReader(plaintext), []string{recipient}, nil); err != nil {
+		t.Fatalf("EncryptToPluginRecipients: %v", err)
+	}
+
+	// The header names the plugin's stanza, not scrypt.
+	header := encrypted.String()
+	wantStanza := "\n-> " + mockPluginName + " " + hex.EncodeToString([]byte("token-1")) + "\n"
+	if !strings.Contains(header, wantStanza) {
+		t.Errorf("header missing recipient stanza %q", wantStanza)
+	}
+	if strings.Contains(header, "-> scrypt") {
+		t.Error("plugin-encrypted file should not have a scrypt stanza")
+	}
+
+	var decrypted bytes.Buffer
+	identity := plugin.EncodeIdentity(mockPluginName, []byte("token-1"))
+	if err := DecryptWithPluginIdentities(&decrypted, bytes.NewReader(encrypted.Bytes()), []string{identity}, nil); err != nil {
+		t.Fatalf("DecryptWithPluginIdentities: %v", err)
+	}
+	if !bytes.Equal(decrypted.Bytes(), plaintext) {
+		t.Errorf("decrypted = %q, want %q", decrypted.Bytes(), plaintext)
+	}
+
+	t.Run("wrong token", func(t *testing.T) {
+		other := plugin.EncodeIdentity(mockPluginName, []byte("token-2"))
+		err := DecryptWithPluginIdentities(&bytes.Buffer{}, bytes.NewReader(encrypted.Bytes()), []string{other}, nil)
+		var noMatch *age.NoIdentityMatchError
+		if !errors.As(err, &noMatch) {
+			t.Errorf("expected NoIdentityMatchError, got %v", err)
+		}
+	})
+}
+
+func TestPluginRecipientErrors(t *testing.T) {
+	if err := EncryptToPluginRecipients(&bytes.Buffer{}, strings.NewReader("x"), nil, nil); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("no recipients: got %v, want ErrNoRecipients", err)
+	}
+
+	native, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptToPluginRecipients(&bytes.Buffer{}, strings.NewReader("x"), []string{native.Recipient().String()}, nil); err == nil {
+		t.Error("a native X25519 recipient is not a plugin recipient")
+	}
+
+	missing := plugin.EncodeRecipient("rememorymissing", nil)
+	err = EncryptToPluginRecipients(&bytes.Buffer{}, strings.NewReader("x"), []string{missing}, nil)
+	var notFound *plugin.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("missing plugin: expected NotFoundError, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrEmptyAnswer is returned when a recovery answer is empty after
+// normalization.
+var ErrEmptyAnswer = errors.New("answer cannot be empty")
+
+// A project can require the answer to a recovery question on top of a quorum
+// of pieces. age only allows a scrypt recipient on its own, so the answer
+// can't be a second recipient next to the passphrase. Instead both go into
+// one scrypt passphrase, and neither the pieces nor the answer alone can
+// derive the key.
+
+// NormalizeAnswer prepares a recovery answer for use as key material, so
+// small differences in how it is typed don't matter: it puts it in Unicode
+// NFC, so an accented letter typed as one character or as a letter and a
+// combining accent is the same, then lowercases it, trims it and collapses
+// runs of whitespace into single spaces.
+func NormalizeAnswer(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFC.String(s))), " ")
+}
+
+// AnswerPassphrase combines the passphrase rebuilt from the pieces with the
+// normalized answer into the passphrase the archive is encrypted with.
+func AnswerPassphrase(passphrase, answer string) (string, error) {
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+	normalized := NormalizeAnswer(answer)
+	if normalized == "" {
+		return "", ErrEmptyAnswer
+	}
+	return passphrase + "\n" + normalized, nil
+}
+
+// EncryptWithAnswer encrypts src to dst so that decrypting needs both the
+// passphrase and the answer (see AnswerPassphrase). Decrypt with
+// DecryptWithAnswer.
+func EncryptWithAnswer(dst io.Writer, src io.Reader, passphrase, answer string) error {
+	combined, err := AnswerPassphrase(passphrase, answer)
+	if err != nil {
+		return err
+	}
+	return Encrypt(dst, src, combined)
+}
+
+// DecryptWithAnswer decrypts data made by EncryptWithAnswer.
+func DecryptWithAnswer(dst io.Writer, src io.Reader, passphrase, answer string) error {
+	combined, err := AnswerPassphrase(passphrase, answer)
+	if err != nil {
+		return err
+	}
+	return Decrypt(dst, src, combined)
+}
+
+// DecryptBytesWithAnswer is DecryptWithAnswer returning bytes.
+func DecryptBytesWithAnswer(encryptedData []byte, passphrase, answer string) ([]byte, error) {
+	combined, err := AnswerPassphrase(passphrase, answer)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptBytes(encryptedData, combined)
+}
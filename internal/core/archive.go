@@ -2,11 +2,14 @@ package core
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
-	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
+	"strings"
 )
 
 const (
@@ -14,15 +17,43 @@ const (
 	MaxFileSize = 100 * 1024 * 1024
 	// MaxTotalSize is the maximum total size of all extracted files (1 GB).
 	MaxTotalSize = 1024 * 1024 * 1024
+	// MaxEntries is the maximum number of entries (files, directories, links)
+	// read from an archive during extraction.
+	MaxEntries = 10000
+
+	// maxTrailingData is how much may follow the tar end marker, for the
+	// padding to a full record (10 KiB) that tar writers may add.
+	maxTrailingData = 64 * 1024
+)
+
+var (
+	// ErrCorruptGzip means the compressed stream is damaged: a bad gzip header,
+	// truncated deflate data, or a CRC mismatch. Usually the ciphertext or the
+	// decryption is at fault.
+	ErrCorruptGzip = errors.New("corrupt gzip stream")
+	// ErrCorruptZstd is ErrCorruptGzip for manifests compressed with zstd.
+	ErrCorruptZstd = errors.New("corrupt zstd stream")
+	// ErrCorruptTar means the compression layer decoded cleanly but the tar
+	// structure inside it is damaged or truncated.
+	ErrCorruptTar = errors.New("corrupt tar archive")
+	// ErrFileHashMismatch means an extracted file doesn't match the hash
+	// recorded for it in MANIFEST-HASHES.txt.
+	ErrFileHashMismatch = errors.New("file does not match its recorded hash")
 )
 
+// ManifestHashesFile is the per-file hash table stored in the manifest
+// archive's root directory. It uses sha256sum format with paths relative to
+// that directory, so "sha256sum -c MANIFEST-HASHES.txt" works after extraction.
+const ManifestHashesFile = "MANIFEST-HASHES.txt"
+
 // ExtractedFile represents a file extracted from a tar.gz archive.
 type ExtractedFile struct {
 	Name string
 	Data []byte
 }
 
-// ExtractTarGz extracts files from tar.gz data in memory.
+// ExtractTarGz extracts files from tar.gz data in memory. Despite the name,
+// zstd-compressed archives are accepted too (see Compression).
 // This is used by both CLI and WASM for in-memory extraction.
 // For file-based extraction, use the manifest package.
 func ExtractTarGz(tarGzData []byte) ([]ExtractedFile, error) {
@@ -31,26 +62,73 @@ func ExtractTarGz(tarGzData []byte) ([]ExtractedFile, error) {
 
 // ExtractTarGzReader extracts files from a tar.gz reader.
 func ExtractTarGzReader(r io.Reader) ([]ExtractedFile, error) {
-	gzr, err := gzip.NewReader(r)
+	return extractTarGz(r, MaxTotalSize, MaxEntries)
+}
+
+// ExtractTarGzLimited extracts files from tar.gz data, aborting as soon as the
+// decompressed output exceeds maxTotal bytes or the archive has more than
+// maxEntries entries. Sizes are counted as data is actually read, so a header
+// that understates its size can't sneak past the cap.
+func ExtractTarGzLimited(tarGzData []byte, maxTotal int64, maxEntries int) ([]ExtractedFile, error) {
+	return extractTarGz(bytes.NewReader(tarGzData), maxTotal, maxEntries)
+}
+
+func extractTarGz(r io.Reader, maxTotal int64, maxEntries int) ([]ExtractedFile, error) {
+	// Pick the codec from the magic bytes. Anything unrecognized goes to
+	// gzip, the original format, which reports it as a bad header.
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	codec, err := DetectCompression(magic)
 	if err != nil {
-		return nil, fmt.Errorf("creating gzip reader: %w", err)
+		codec = CompressionGzip
+	}
+	errCorrupt := ErrCorruptGzip
+	if codec == CompressionZstd {
+		errCorrupt = ErrCorruptZstd
 	}
-	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	// Track how far into each layer we got, so corruption can be reported
+	// by offset. The compressed counter implements io.ByteReader, which stops
+	// gzip from buffering ahead and keeps its count exact.
+	compressed := &countingReader{r: br}
+	dec, err := newDecompressor(compressed, codec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad header at byte %d: %v", errCorrupt, compressed.n, err)
+	}
+	defer dec.Close()
+
+	plain := &countingReader{r: dec}
+	tr := tar.NewReader(plain)
 	var files []ExtractedFile
 	var totalSize int64
+	entries := 0
+
+	// corrupt classifies a read failure: if the compression layer itself
+	// failed, the damage is in the compressed stream; otherwise the tar
+	// structure is bad.
+	corrupt := func(entryStart int64, what string, err error) error {
+		if plain.err != nil && plain.err != io.EOF {
+			return fmt.Errorf("%w at compressed byte %d (%s): %v", errCorrupt, compressed.n, what, plain.err)
+		}
+		return fmt.Errorf("%w at byte %d (%s): %v", ErrCorruptTar, entryStart, what, err)
+	}
 
 	// Regex to detect path traversal
 	pathTraversal := regexp.MustCompile(`(^|/)\.\.(/|$)`)
 
 	for {
+		entryStart := plain.n
 		header, err := tr.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("reading tar: %w", err)
+			return nil, corrupt(entryStart, fmt.Sprintf("header of entry %d", entries+1), err)
+		}
+
+		entries++
+		if entries > maxEntries {
+			return nil, fmt.Errorf("archive has too many entries (limit %d)", maxEntries)
 		}
 
 		// Security: reject path traversal
@@ -67,17 +145,21 @@ func ExtractTarGzReader(r io.Reader) ([]ExtractedFile, error) {
 		if header.Size > MaxFileSize {
 			return nil, fmt.Errorf("file %s exceeds maximum allowed size (%d bytes)", header.Name, MaxFileSize)
 		}
-		totalSize += header.Size
-		if totalSize > MaxTotalSize {
-			return nil, fmt.Errorf("archive exceeds maximum total size (%d bytes)", MaxTotalSize)
+		if totalSize+header.Size > maxTotal {
+			return nil, fmt.Errorf("archive exceeds maximum total size (%d bytes)", maxTotal)
 		}
 
-		// Use LimitReader for additional safety
-		limitedReader := io.LimitReader(tr, MaxFileSize)
-		data, err := io.ReadAll(limitedReader)
+		// Read at most one byte past what's allowed, so overruns are detected
+		// without decompressing the rest of the stream.
+		limit := min(MaxFileSize, maxTotal-totalSize)
+		data, err := io.ReadAll(io.LimitReader(tr, limit+1))
 		if err != nil {
-			return nil, fmt.Errorf("reading file %s from archive: %w", header.Name, err)
+			return nil, corrupt(entryStart, "file "+header.Name, err)
 		}
+		if int64(len(data)) > limit {
+			return nil, fmt.Errorf("archive exceeds maximum total size (%d bytes)", maxTotal)
+		}
+		totalSize += int64(len(data))
 
 		files = append(files, ExtractedFile{
 			Name: header.Name,
@@ -85,9 +167,149 @@ func ExtractTarGzReader(r io.Reader) ([]ExtractedFile, error) {
 		})
 	}
 
+	// Drain the compressed stream so a damaged trailer (CRC, size or
+	// checksum mismatch) is still caught after the tar end marker. Only
+	// the padding some tar writers add may follow; anything longer could
+	// be a decompression bomb.
+	trailing, err := io.Copy(io.Discard, io.LimitReader(plain, maxTrailingData+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w at compressed byte %d (trailer): %v", errCorrupt, compressed.n, err)
+	}
+	if trailing > maxTrailingData {
+		return nil, fmt.Errorf("archive has more than %d bytes of data after its end", maxTrailingData)
+	}
+
 	if len(files) == 0 {
 		return nil, fmt.Errorf("empty archive")
 	}
 
 	return files, nil
 }
+
+// countingReader counts bytes read and remembers the first error returned
+// by the underlying reader.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// ReadByte is only used on the compressed side, where the underlying
+// reader is always a *bufio.Reader.
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.(io.ByteReader).ReadByte()
+	if err != nil {
+		if c.err == nil {
+			c.err = err
+		}
+		return 0, err
+	}
+	c.n++
+	return b, nil
+}
+
+// FormatManifestHashes renders a path → hash table (hashes as returned by
+// HashBytes) as the contents of MANIFEST-HASHES.txt, sorted by path.
+func FormatManifestHashes(hashes map[string]string) []byte {
+	paths := make([]string, 0, len(hashes))
+	for p := range hashes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, p := range paths {
+		fmt.Fprintf(&buf, "%s  %s\n", strings.TrimPrefix(hashes[p], "sha256:"), p)
+	}
+	return buf.Bytes()
+}
+
+// ParseManifestHashes parses MANIFEST-HASHES.txt. Hashes are returned in
+// HashBytes form ("sha256:..."), keyed by path relative to the archive root.
+func ParseManifestHashes(data []byte) (map[string]string, error) {
+	hashes := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		sum, path, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != 64 || strings.Trim(sum, "0123456789abcdef") != "" || path == "" {
+			return nil, fmt.Errorf("%s line %d: malformed entry", ManifestHashesFile, i+1)
+		}
+		if _, dup := hashes[path]; dup {
+			return nil, fmt.Errorf("%s line %d: duplicate entry for %s", ManifestHashesFile, i+1, path)
+		}
+		hashes[path] = "sha256:" + sum
+	}
+	return hashes, nil
+}
+
+// FindManifestHashes returns the parsed MANIFEST-HASHES.txt from an extracted
+// archive, or nil if the archive predates per-file hashes.
+func FindManifestHashes(files []ExtractedFile) (map[string]string, error) {
+	for _, f := range files {
+		if _, rel, ok := strings.Cut(f.Name, "/"); ok && rel == ManifestHashesFile {
+			return ParseManifestHashes(f.Data)
+		}
+	}
+	return nil, nil
+}
+
+// VerifyExtractedFiles checks every extracted file against hashes, as parsed
+// from MANIFEST-HASHES.txt. File names are matched with the archive's root
+// directory removed. A file whose contents differ, a listed file that is
+// missing, or an extra file that isn't listed are all reported by name.
+func VerifyExtractedFiles(files []ExtractedFile, hashes map[string]string) error {
+	hashed := make([]FileHash, len(files))
+	for i, f := range files {
+		hashed[i] = FileHash{Name: f.Name, Hash: HashBytes(f.Data)}
+	}
+	return VerifyFileHashes(hashed, hashes)
+}
+
+// FileHash is the HashBytes hash of a file in an archive, by its name there.
+type FileHash struct {
+	Name string
+	Hash string
+}
+
+// VerifyFileHashes is VerifyExtractedFiles for files hashed as they were
+// read, so their contents needn't be kept.
+func VerifyFileHashes(files []FileHash, hashes map[string]string) error {
+	seen := make(map[string]bool, len(hashes))
+	for _, f := range files {
+		_, rel, _ := strings.Cut(f.Name, "/")
+		if rel == ManifestHashesFile {
+			continue
+		}
+		want, ok := hashes[rel]
+		if !ok {
+			return fmt.Errorf("%w: %s is not listed in %s", ErrFileHashMismatch, f.Name, ManifestHashesFile)
+		}
+		if !VerifyHash(f.Hash, want) {
+			return fmt.Errorf("%w: %s", ErrFileHashMismatch, f.Name)
+		}
+		seen[rel] = true
+	}
+
+	var missing []string
+	for rel := range hashes {
+		if !seen[rel] {
+			missing = append(missing, rel)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s is listed in %s but missing from the archive", ErrFileHashMismatch, missing[0], ManifestHashesFile)
+	}
+	return nil
+}
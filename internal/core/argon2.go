@@ -0,0 +1,218 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Argon2Params are the Argon2id cost parameters for EncryptArgon2. They are
+// stored in the file, so decrypting needs only the passphrase.
+type Argon2Params struct {
+	Time    uint32 // Passes over memory
+	Memory  uint32 // Memory in KiB
+	Threads uint8  // Degree of parallelism
+}
+
+// DefaultArgon2Params are the second recommended settings of RFC 9106:
+// 3 passes over 64 MiB with 4 lanes.
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// Limits on the parameters accepted when decrypting, so a crafted file can't
+// make Decrypt run for hours or exhaust memory. Encrypting checks them too,
+// so everything EncryptArgon2 writes can be read back. The memory limit is
+// what the recovery tool can count on in a browser, phones included: Go's
+// WebAssembly heap has to hold the Argon2id memory on top of the manifest.
+const (
+	maxArgon2Time   = 16
+	maxArgon2Memory = 256 * 1024 // 256 MiB in KiB
+)
+
+// argon2StanzaType is the age recipient stanza EncryptArgon2 writes:
+//
+//	-> rememory-argon2id <salt> <time> <memory> <threads>
+//	<wrapped file key>
+//
+// Stock age doesn't know it, so it can't decrypt these files.
+const argon2StanzaType = "rememory-argon2id"
+
+// argon2Label separates the derived key from any other use of the
+// passphrase, as age does for scrypt.
+const argon2Label = "rememory.eljojo.net/v1/argon2id"
+
+const argon2SaltSize = 16
+
+// ageFileKeySize is the size of the key age wraps in each stanza.
+const ageFileKeySize = 16
+
+var argon2DigitsRe = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+func (p Argon2Params) validate() error {
+	if p.Time < 1 || p.Time > maxArgon2Time {
+		return fmt.Errorf("argon2id time must be between 1 and %d, got %d", maxArgon2Time, p.Time)
+	}
+	if p.Threads < 1 {
+		return fmt.Errorf("argon2id threads must be at least 1")
+	}
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxArgon2Memory {
+		return fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d", 8*uint32(p.Threads), maxArgon2Memory, p.Memory)
+	}
+	return nil
+}
+
+func (p Argon2Params) key(passphrase, salt []byte) []byte {
+	labeled := append([]byte(argon2Label), salt...)
+	return argon2.IDKey(passphrase, labeled, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+// EncryptArgon2 is Encrypt with Argon2id in place of scrypt to derive the
+// key from passphrase. Decrypt reads either kind of file.
+//
+// The result is still an age file, but its recipient stanza is specific to
+// ReMemory: stock age and other age implementations can't decrypt it, and
+// report that no identity matched. Encrypt with scrypt (the default) when
+// the archive must open with plain age.
+func EncryptArgon2(dst io.Writer, src io.Reader, passphrase string, params Argon2Params) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	writer, err := age.Encrypt(dst, &argon2Recipient{passphrase: []byte(passphrase), params: params})
+	if err != nil {
+		return fmt.Errorf("creating encryptor: %w", err)
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		return fmt.Errorf("encrypting: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finalizing encryption: %w", err)
+	}
+
+	return nil
+}
+
+type argon2Recipient struct {
+	passphrase []byte
+	params     Argon2Params
+}
+
+func (r *argon2Recipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	salt := make([]byte, argon2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.New(r.params.key(r.passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+
+	return []*age.Stanza{{
+		Type: argon2StanzaType,
+		Args: []string{
+			base64.RawStdEncoding.EncodeToString(salt),
+			strconv.FormatUint(uint64(r.params.Time), 10),
+			strconv.FormatUint(uint64(r.params.Memory), 10),
+			strconv.FormatUint(uint64(r.params.Threads), 10),
+		},
+		Body: aead.Seal(nil, nonce, fileKey, nil),
+	}}, nil
+}
+
+// WrapWithLabels gives the stanza a random label, as age does for scrypt,
+// so a passphrase file can't also be encrypted to other recipients.
+func (r *argon2Recipient) WrapWithLabels(fileKey []byte) ([]*age.Stanza, []string, error) {
+	stanzas, err := r.Wrap(fileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, nil, err
+	}
+	return stanzas, []string{hex.EncodeToString(random)}, nil
+}
+
+// passphraseIdentity unwraps a passphrase-encrypted file whichever KDF it
+// was written with: Argon2id for EncryptArgon2, otherwise age's scrypt.
+// Being a single identity, wrong passphrases get age's usual error.
+type passphraseIdentity struct {
+	passphrase []byte
+	scrypt     *age.ScryptIdentity
+}
+
+func newPassphraseIdentity(passphrase string) (*passphraseIdentity, error) {
+	scrypt, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &passphraseIdentity{passphrase: []byte(passphrase), scrypt: scrypt}, nil
+}
+
+func (i *passphraseIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != argon2StanzaType {
+			continue
+		}
+		if len(stanzas) != 1 {
+			return nil, errors.New("an argon2id recipient must be the only one")
+		}
+		return i.unwrapArgon2(s)
+	}
+	return i.scrypt.Unwrap(stanzas)
+}
+
+func (i *passphraseIdentity) unwrapArgon2(s *age.Stanza) ([]byte, error) {
+	if len(s.Args) != 4 {
+		return nil, errors.New("invalid argon2id recipient block")
+	}
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(s.Args[0])
+	if err != nil || len(salt) != argon2SaltSize {
+		return nil, errors.New("invalid argon2id salt")
+	}
+	var nums [3]uint64
+	for j, arg := range s.Args[1:] {
+		if !argon2DigitsRe.MatchString(arg) {
+			return nil, fmt.Errorf("invalid argon2id parameter: %q", arg)
+		}
+		if nums[j], err = strconv.ParseUint(arg, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid argon2id parameter: %q", arg)
+		}
+	}
+	if nums[2] > 255 {
+		return nil, fmt.Errorf("invalid argon2id parameter: %q", s.Args[3])
+	}
+	params := Argon2Params{Time: uint32(nums[0]), Memory: uint32(nums[1]), Threads: uint8(nums[2])}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	if len(s.Body) != ageFileKeySize+chacha20poly1305.Overhead {
+		return nil, errors.New("invalid argon2id recipient block")
+	}
+
+	aead, err := chacha20poly1305.New(params.key(i.passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	fileKey, err := aead.Open(nil, nonce, s.Body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: incorrect passphrase", age.ErrIncorrectIdentity)
+	}
+	return fileKey, nil
+}
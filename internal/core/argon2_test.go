@@ -0,0 +1,105 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestEncryptArgon2(t *testing.T) {
+	data := bytes.Repeat([]byte("manifest "), 20000)
+	passphrase := "test-passphrase-12345"
+
+	tests := []struct {
+		name   string
+		params Argon2Params
+	}{
+		{"light", Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}},
+		{"heavier", Argon2Params{Time: 2, Memory: 32 * 1024, Threads: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encrypted bytes.Buffer
+			if err := EncryptArgon2(&encrypted, bytes.NewReader(data), passphrase, tt.params); err != nil {
+				t.Fatalf("encrypt: %v", err)
+			}
+			if !strings.Contains(encrypted.String(), "-> "+argon2StanzaType+" ") {
+				t.Fatal("header should hold an argon2id stanza")
+			}
+
+			decrypted, err := DecryptBytes(encrypted.Bytes(), passphrase)
+			if err != nil {
+				t.Fatalf("decrypt: %v", err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Error("decrypted data doesn't match")
+			}
+
+			if _, err := DecryptBytes(encrypted.Bytes(), "wrong-passphrase"); err == nil {
+				t.Error("expected error with wrong passphrase")
+			}
+		})
+	}
+}
+
+func TestEncryptArgon2StockAge(t *testing.T) {
+	passphrase := "test-passphrase"
+	var encrypted bytes.Buffer
+	params := Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+	if err := EncryptArgon2(&encrypted, strings.NewReader("secret"), passphrase, params); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = age.Decrypt(bytes.NewReader(encrypted.Bytes()), identity)
+	var noMatch *age.NoIdentityMatchError
+	if !errors.As(err, &noMatch) {
+		t.Errorf("stock age should find no matching identity, got %v", err)
+	}
+}
+
+func TestEncryptArgon2Params(t *testing.T) {
+	bad := []Argon2Params{
+		{Time: 0, Memory: 8 * 1024, Threads: 1},
+		{Time: maxArgon2Time + 1, Memory: 8 * 1024, Threads: 1},
+		{Time: 1, Memory: 8 * 1024, Threads: 0},
+		{Time: 1, Memory: 16, Threads: 4},
+		{Time: 1, Memory: maxArgon2Memory + 1, Threads: 1},
+	}
+	for _, params := range bad {
+		if err := EncryptArgon2(&bytes.Buffer{}, strings.NewReader("x"), "pass", params); err == nil {
+			t.Errorf("EncryptArgon2 with %+v should fail", params)
+		}
+	}
+
+	if err := EncryptArgon2(&bytes.Buffer{}, strings.NewReader("x"), "", DefaultArgon2Params); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("empty passphrase: got %v, want ErrEmptyPassphrase", err)
+	}
+}
+
+func TestDecryptArgon2RejectsCostlyHeader(t *testing.T) {
+	passphrase := "test-passphrase"
+	var encrypted bytes.Buffer
+	params := Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+	if err := EncryptArgon2(&encrypted, strings.NewReader("secret"), passphrase, params); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	// Raise the memory cost to 1 GiB, past what a browser can be relied on
+	// for; Decrypt must refuse before running Argon2id.
+	tampered := bytes.Replace(encrypted.Bytes(), []byte(" 1 8192 1\n"), []byte(" 1 1048576 1\n"), 1)
+	if bytes.Equal(tampered, encrypted.Bytes()) {
+		t.Fatal("didn't find the parameters in the header")
+	}
+	_, err := DecryptBytes(tampered, passphrase)
+	if err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("expected memory limit error, got %v", err)
+	}
+}
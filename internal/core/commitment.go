@@ -0,0 +1,90 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CommitmentHeader is the extra share header holding the commitment to the
+// secret the shares were split from (see NewCommitment). Every share from
+// one seal carries the same value.
+const CommitmentHeader = "Commitment"
+
+// ErrSecretMismatch is returned by VerifyReconstructedSecret when the
+// combined shares don't give back the secret they were split from.
+var ErrSecretMismatch = errors.New("reconstructed secret doesn't match the one the pieces were made from")
+
+// commitmentDomain keeps commitments apart from every other hash of the
+// secret, such as the project's verification hash.
+const commitmentDomain = "rememory-commitment-v1\x00"
+
+const commitmentSaltSize = 16
+
+// NewCommitment returns a salted hash of secret, the raw bytes given to
+// Split, in the form "v1:<salt>:<hash>" (both hex). It can be stored next
+// to the shares without revealing anything: the secret is a random 32-byte
+// passphrase, and the salt keeps two seals of the same secret apart.
+func NewCommitment(secret []byte) (string, error) {
+	salt := make([]byte, commitmentSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generating salt: %w", err)
+	}
+	return "v1:" + hex.EncodeToString(salt) + ":" + hex.EncodeToString(commitmentHash(salt, secret)), nil
+}
+
+// VerifyReconstructedSecret checks secret, the output of Combine, against a
+// commitment from NewCommitment. Combine gives a plausible result even for
+// pieces from different seals or too few pieces, so this tells those cases
+// apart from a damaged archive before anything is decrypted. It returns
+// ErrSecretMismatch if the secret is wrong, and another error if the
+// commitment can't be read.
+func VerifyReconstructedSecret(secret []byte, commitment string) error {
+	parts := strings.Split(commitment, ":")
+	if len(parts) != 3 || parts[0] != "v1" {
+		return fmt.Errorf("unrecognized commitment %q", commitment)
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil || len(salt) != commitmentSaltSize {
+		return fmt.Errorf("unrecognized commitment %q", commitment)
+	}
+	want, err := hex.DecodeString(parts[2])
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("unrecognized commitment %q", commitment)
+	}
+	if subtle.ConstantTimeCompare(commitmentHash(salt, secret), want) != 1 {
+		return ErrSecretMismatch
+	}
+	return nil
+}
+
+func commitmentHash(salt, secret []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(commitmentDomain))
+	h.Write(salt)
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+// ShareCommitment returns the commitment carried by shares, or "" if none
+// of them has one. It is an error for two shares to carry different ones:
+// they come from different seals.
+func ShareCommitment(shares []*Share) (string, error) {
+	var commitment string
+	var from *Share
+	for _, s := range shares {
+		c := s.Headers[CommitmentHeader]
+		if c == "" {
+			continue
+		}
+		if from != nil && c != commitment {
+			return "", fmt.Errorf("pieces %d and %d are from different seals", from.Index, s.Index)
+		}
+		commitment, from = c, s
+	}
+	return commitment, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the codec wrapped around the manifest tar archive.
+// The codec isn't recorded separately: each format starts with its own magic
+// bytes, so extraction detects it from the stream itself and older gzip
+// manifests keep working unchanged.
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zstdMaxWindow bounds the memory a zstd stream can ask the decoder for.
+// Streams written by NewCompressor use far smaller windows.
+const zstdMaxWindow = 64 << 20
+
+// ParseCompression validates a codec name as given on the command line.
+func ParseCompression(name string) (Compression, error) {
+	switch c := Compression(name); c {
+	case CompressionGzip, CompressionZstd:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q (use gzip or zstd)", name)
+	}
+}
+
+// DetectCompression identifies the codec from the first bytes of a stream.
+func DetectCompression(header []byte) (Compression, error) {
+	switch {
+	case bytes.HasPrefix(header, zstdMagic):
+		return CompressionZstd, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return CompressionGzip, nil
+	default:
+		return "", fmt.Errorf("unrecognized compression format (header % x)", header)
+	}
+}
+
+// NewCompressor returns a writer that compresses to w with the given codec.
+// The caller must Close it to flush the stream.
+func NewCompressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip, "":
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBetterCompression), zstd.WithEncoderConcurrency(1))
+	default:
+		return nil, fmt.Errorf("unknown compression %q", c)
+	}
+}
+
+// NewDecompressor detects the codec from the first bytes of r and returns a
+// reader for the decompressed data.
+func NewDecompressor(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	c, err := DetectCompression(magic)
+	if err != nil {
+		return nil, err
+	}
+	return newDecompressor(br, c)
+}
+
+// newDecompressor opens a decompressing reader for codec c. Decoding is kept
+// single-threaded so it behaves the same under WASM.
+func newDecompressor(r io.Reader, c Compression) (io.ReadCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(zstdMaxWindow))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q", c)
+	}
+}
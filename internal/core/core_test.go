@@ -4,8 +4,17 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHashString(t *testing.T) {
@@ -56,6 +65,62 @@ func TestVerifyHash(t *testing.T) {
 	}
 }
 
+func TestFormatChecksumGrouped(t *testing.T) {
+	sum := HashString("hello")
+	got := FormatChecksumGrouped(sum)
+	want := "2cf2 4dba 5fb0 a30e 26e8 3b2a c5b9 e29e"
+	if got != want {
+		t.Errorf("FormatChecksumGrouped(%s) = %q, want %q", sum, got, want)
+	}
+	if bare := FormatChecksumGrouped(strings.TrimPrefix(sum, "sha256:")); bare != want {
+		t.Errorf("bare hex = %q, want %q", bare, want)
+	}
+	if upper := FormatChecksumGrouped(strings.ToUpper(sum[7:])); upper != want {
+		t.Errorf("upper-case hex = %q, want %q", upper, want)
+	}
+	if short := FormatChecksumGrouped("sha256:abcdef"); short != "abcd ef" {
+		t.Errorf("short checksum = %q, want %q", short, "abcd ef")
+	}
+}
+
+func TestNewVerifyingReader(t *testing.T) {
+	data := bytes.Repeat([]byte("manifest "), 10000)
+	want := HashBytes(data)
+
+	r, check := NewVerifyingReader(bytes.NewReader(data), want)
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Error("data should pass through unchanged")
+	}
+	if err := check(); err != nil {
+		t.Errorf("matching hash: %v", err)
+	}
+
+	r, check = NewVerifyingReader(bytes.NewReader(data), HashString("something else"))
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	var mismatch *ChecksumError
+	if err := check(); !errors.As(err, &mismatch) {
+		t.Fatalf("mismatching hash: expected ChecksumError, got %v", err)
+	}
+	if mismatch.Got != want {
+		t.Errorf("Got = %s, want %s", mismatch.Got, want)
+	}
+
+	// A partial read has not been verified, even if the hash would match.
+	r, check = NewVerifyingReader(bytes.NewReader(data), want)
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(); err == nil {
+		t.Error("check before EOF should fail")
+	}
+}
+
 func TestEncryptDecrypt(t *testing.T) {
 	tests := []struct {
 		name string
@@ -165,6 +230,61 @@ func TestSplitCombine(t *testing.T) {
 	}
 }
 
+func TestSplitToShares(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	holders := []string{"Alice", "Bob", "Carol", "Dave", "Eve"}
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	shares, err := SplitToShares(secret, 5, 3, holders, created)
+	if err != nil {
+		t.Fatalf("SplitToShares: %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares, want 5", len(shares))
+	}
+
+	for i, share := range shares {
+		// Round trip through the PEM encoding, as a friend's file would.
+		parsed, err := ParseShare([]byte(share.Encode()))
+		if err != nil {
+			t.Fatalf("share %d: parse: %v", i+1, err)
+		}
+		if err := parsed.Verify(); err != nil {
+			t.Errorf("share %d: verify: %v", i+1, err)
+		}
+		if parsed.Version != 2 || parsed.Index != i+1 || parsed.Total != 5 || parsed.Threshold != 3 {
+			t.Errorf("share %d: got version %d, index %d, %d-of-%d", i+1, parsed.Version, parsed.Index, parsed.Threshold, parsed.Total)
+		}
+		if parsed.Holder != holders[i] {
+			t.Errorf("share %d: holder = %q, want %q", i+1, parsed.Holder, holders[i])
+		}
+		if !parsed.Created.Equal(created) {
+			t.Errorf("share %d: created = %v, want %v", i+1, parsed.Created, created)
+		}
+	}
+	if shares[0].Fingerprint() != shares[4].Fingerprint() {
+		t.Error("shares from one split should share a fingerprint")
+	}
+
+	recovered, err := Combine([][]byte{shares[4].Data, shares[1].Data, shares[2].Data})
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Error("combined shares don't give back the secret")
+	}
+
+	if _, err := SplitToShares(secret, 5, 3, holders[:4], created); err == nil {
+		t.Error("expected error with fewer holders than shares")
+	}
+	if _, err := SplitToShares(secret, 5, 6, holders, created); err == nil {
+		t.Error("expected error with threshold above total")
+	}
+}
+
 func TestValidateShamirParams(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -191,6 +311,82 @@ func TestValidateShamirParams(t *testing.T) {
 	}
 }
 
+func TestValidateThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   int
+		total       int
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"threshold 1", 1, 5, true, false},
+		{"threshold 0", 0, 5, true, false},
+		{"threshold 2", 2, 5, false, false},
+		{"one below total", 4, 5, false, false},
+		{"threshold equals total", 5, 5, true, true},
+		{"2 of 2", 2, 2, true, true},
+		{"above total", 6, 5, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateThreshold(tt.threshold, tt.total)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateThreshold(%d, %d) = %v, want error: %v", tt.threshold, tt.total, err, tt.wantErr)
+			}
+			var warning *ThresholdWarning
+			if errors.As(err, &warning) != tt.wantWarning {
+				t.Errorf("ValidateThreshold(%d, %d) = %v, want warning: %v", tt.threshold, tt.total, err, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestRecoveryProbability(t *testing.T) {
+	tests := []struct {
+		total, threshold int
+		lossProb         float64
+		want             float64
+	}{
+		// 3*0.9²*0.1 + 0.9³
+		{3, 2, 0.1, 0.972},
+		// 0.9³
+		{3, 3, 0.1, 0.729},
+		// 10*0.9³*0.1² + 5*0.9⁴*0.1 + 0.9⁵
+		{5, 3, 0.1, 0.99144},
+		// (6 + 4 + 1) / 16
+		{4, 2, 0.5, 0.6875},
+		// 1 - 0.5²
+		{2, 1, 0.5, 0.75},
+		{5, 3, 0, 1},
+		{5, 3, 1, 0},
+		{5, 0, 0.1, 0},
+		{5, 6, 0.1, 0},
+	}
+	for _, tt := range tests {
+		got := RecoveryProbability(tt.total, tt.threshold, tt.lossProb)
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("RecoveryProbability(%d, %d, %v) = %v, want %v", tt.total, tt.threshold, tt.lossProb, got, tt.want)
+		}
+	}
+
+	for _, p := range []float64{-0.1, 1.5, math.NaN()} {
+		if got := RecoveryProbability(5, 3, p); !math.IsNaN(got) {
+			t.Errorf("RecoveryProbability(5, 3, %v) = %v, want NaN", p, got)
+		}
+	}
+
+	// Large groups stay within 0..1 and fall as the threshold rises.
+	prev := 1.0
+	for k := 1; k <= 255; k++ {
+		got := RecoveryProbability(255, k, 0.2)
+		if got < 0 || got > prev {
+			t.Fatalf("RecoveryProbability(255, %d, 0.2) = %v, previous %v", k, got, prev)
+		}
+		prev = got
+	}
+}
+
 func TestShareEncodeDecode(t *testing.T) {
 	original := NewShare(1, 1, 5, 3, "Alice", []byte("test-share-data"))
 
@@ -224,212 +420,887 @@ func TestShareEncodeDecode(t *testing.T) {
 	}
 }
 
-func TestShareVerify(t *testing.T) {
-	share := NewShare(1, 1, 5, 3, "Alice", []byte("test-data"))
+func TestShareEncodeWithType(t *testing.T) {
+	original := NewShare(2, 2, 5, 3, "Bob", []byte("test-share-data"))
+	encoded := original.EncodeWithType("VAULT KEY SHARE")
 
-	// Valid checksum
-	if err := share.Verify(); err != nil {
-		t.Errorf("valid share failed verify: %v", err)
+	if !strings.HasPrefix(encoded, "-----BEGIN VAULT KEY SHARE-----\n") || !strings.HasSuffix(encoded, "-----END VAULT KEY SHARE-----\n") {
+		t.Fatalf("unexpected markers:\n%s", encoded)
 	}
-
-	// Corrupted checksum
-	share.Checksum = "sha256:wrong"
-	if err := share.Verify(); err == nil {
-		t.Error("corrupted share should fail verify")
+	if original.EncodeWithType("") != original.Encode() {
+		t.Error("an empty block type should give the default encoding")
 	}
-}
 
-func TestShareFilename(t *testing.T) {
-	tests := []struct {
-		holder   string
-		expected string
-	}{
-		{"Alice", "SHARE-alice.txt"},
-		{"Bob Smith", "SHARE-bob-smith.txt"},
-		{"Carol!", "SHARE-carol.txt"},
-		{"", "SHARE-1.txt"},
+	// Next to other PEM data, only the share block is read.
+	content := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n" + encoded
+	decoded, err := ParseShareWithTypes([]byte(content), DefaultShareBlockType, "VAULT KEY SHARE")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if decoded.Holder != "Bob" || decoded.Index != 2 || string(decoded.Data) != "test-share-data" {
+		t.Errorf("got holder %q, index %d, data %q", decoded.Holder, decoded.Index, decoded.Data)
+	}
+	if err := decoded.Verify(); err != nil {
+		t.Errorf("verify: %v", err)
 	}
 
-	for _, tt := range tests {
-		share := NewShare(1, 1, 3, 2, tt.holder, []byte("data"))
-		got := share.Filename()
-		if got != tt.expected {
-			t.Errorf("holder %q: got %q, want %q", tt.holder, got, tt.expected)
-		}
+	// A type not allowed isn't read, and ParseShare only reads the default.
+	if _, err := ParseShareWithTypes([]byte(content), "OTHER SHARE"); err == nil {
+		t.Error("expected error for a block type not in the list")
+	}
+	if _, err := ParseShare([]byte(encoded)); err == nil {
+		t.Error("ParseShare should only read the default block type")
 	}
 }
 
-func TestCompactEncodeRoundTrip(t *testing.T) {
-	original := NewShare(1, 1, 5, 3, "Alice", []byte("test-share-data-1234567890"))
-
-	compact := original.CompactEncode()
+func TestShareCreatedUTC(t *testing.T) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	created := time.Date(2025, 3, 1, 19, 30, 0, 0, zone) // 2025-03-02 00:30 UTC
 
-	decoded, err := ParseCompact(compact)
-	if err != nil {
-		t.Fatalf("ParseCompact: %v", err)
+	v1 := NewShare(1, 1, 5, 3, "Alice", []byte("test-share-data"))
+	v1.Created = created
+	encoded := v1.Encode()
+	if !strings.Contains(encoded, "Created: 2025-03-02T00:30:00Z\n") {
+		t.Errorf("v1 created time should be in UTC ending in Z:\n%s", encoded)
 	}
 
-	if decoded.Version != original.Version {
-		t.Errorf("version: got %d, want %d", decoded.Version, original.Version)
+	v2 := NewShare(2, 1, 5, 3, "Alice", []byte("test-share-data"))
+	v2.Created = created
+	encoded = v2.Encode()
+	if !strings.Contains(encoded, "Created: 2025-03-02 00:30\n") {
+		t.Errorf("v2 created time should be in UTC:\n%s", encoded)
 	}
-	if decoded.Index != original.Index {
-		t.Errorf("index: got %d, want %d", decoded.Index, original.Index)
-	}
-	if decoded.Total != original.Total {
-		t.Errorf("total: got %d, want %d", decoded.Total, original.Total)
+	inUTC := *v2
+	inUTC.Created = created.UTC()
+	if inUTC.Encode() != encoded {
+		t.Error("the same moment in another zone should encode the same")
 	}
-	if decoded.Threshold != original.Threshold {
-		t.Errorf("threshold: got %d, want %d", decoded.Threshold, original.Threshold)
+
+	// An offset written by another tool is read back as UTC.
+	offset := strings.Replace(v1.Encode(), "2025-03-02T00:30:00Z", "2025-03-01T19:30:00-05:00", 1)
+	decoded, err := ParseShare([]byte(offset))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
 	}
-	if !bytes.Equal(decoded.Data, original.Data) {
-		t.Errorf("data mismatch")
+	if decoded.Created.Location() != time.UTC || !decoded.Created.Equal(created) {
+		t.Errorf("created = %v, want %v in UTC", decoded.Created, created.UTC())
 	}
-	if decoded.Checksum != original.Checksum {
-		t.Errorf("checksum: got %q, want %q", decoded.Checksum, original.Checksum)
+	if !strings.Contains(decoded.Encode(), "Created: 2025-03-02T00:30:00Z\n") {
+		t.Error("re-encoding a parsed offset time should give UTC")
 	}
 }
 
-func TestCompactEncodeWithRealShares(t *testing.T) {
-	secret := []byte("a]Zp9kR-mN2xB7qL_YwF4vC8hD6sE0jT")
-	shares, err := Split(secret, 5, 3)
+func TestShareLabel(t *testing.T) {
+	secret := []byte("the boat key is in the blue jar")
+	shares, err := SplitToShares(secret, 3, 2, []string{"Alice", "Bob", "Carol"}, time.Now())
 	if err != nil {
-		t.Fatalf("Split: %v", err)
+		t.Fatal(err)
 	}
 
-	for i, shareData := range shares {
-		share := NewShare(1, i+1, 5, 3, "", shareData)
-		compact := share.CompactEncode()
+	// Without a label, the index stands in and no header is written.
+	if got := shares[0].DisplayLabel(); got != "1" {
+		t.Errorf("DisplayLabel without a label = %q, want %q", got, "1")
+	}
+	if strings.Contains(shares[0].Encode(), "Label:") {
+		t.Error("a share without a label should have no Label header")
+	}
 
-		decoded, err := ParseCompact(compact)
+	for i, label := range []string{"A", "B", "C"} {
+		shares[i].Label = label
+	}
+	var parsed []*Share
+	for _, s := range shares {
+		p, err := ParseShare([]byte(s.Encode()))
 		if err != nil {
-			t.Fatalf("share %d: ParseCompact(%q): %v", i+1, compact, err)
+			t.Fatalf("parse: %v", err)
 		}
-		if !bytes.Equal(decoded.Data, shareData) {
-			t.Errorf("share %d: data mismatch after round-trip", i+1)
+		if p.Label != s.Label || p.DisplayLabel() != s.Label || p.Index != s.Index {
+			t.Errorf("got label %q index %d, want %q index %d", p.Label, p.Index, s.Label, s.Index)
 		}
+		if _, ok := p.Headers["Label"]; ok {
+			t.Error("Label should not be in Headers")
+		}
+		if err := p.Verify(); err != nil {
+			t.Errorf("labeled share should verify: %v", err)
+		}
+		parsed = append(parsed, p)
+	}
+
+	// Labels are cosmetic: swapping them leaves Combine unaffected.
+	parsed[0].Label, parsed[2].Label = parsed[2].Label, parsed[0].Label
+	got, err := Combine([][]byte{parsed[0].Data, parsed[2].Data})
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("combine with labels = %q, want %q", got, secret)
+	}
+
+	if got := (&Share{}).DisplayLabel(); got != "" {
+		t.Errorf("DisplayLabel of an empty share = %q", got)
 	}
 }
 
-func TestCompactEncodeFormat(t *testing.T) {
-	share := NewShare(1, 2, 5, 3, "Bob", []byte{0xDE, 0xAD, 0xBE, 0xEF})
-	compact := share.CompactEncode()
+func TestShareHeadersRoundTrip(t *testing.T) {
+	original := NewShare(2, 3, 5, 3, "Carol", []byte("test-share-data"))
+	encoded := strings.Replace(original.Encode(), "Checksum: ", "x-note: given to Carol 2024-03, backup in safe\nChecksum: ", 1)
 
-	if !strings.HasPrefix(compact, "RM1:") {
-		t.Errorf("should start with RM1:, got %q", compact)
+	decoded, err := ParseShare([]byte(encoded))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := decoded.Headers["x-note"]; got != "given to Carol 2024-03, backup in safe" {
+		t.Errorf("x-note: got %q", got)
+	}
+	if decoded.Holder != "Carol" || decoded.Checksum != original.Checksum {
+		t.Errorf("known headers changed: holder %q, checksum %q", decoded.Holder, decoded.Checksum)
+	}
+	if _, ok := decoded.Headers["Holder"]; ok {
+		t.Error("known headers should not be in Headers")
 	}
 
-	parts := strings.Split(compact, ":")
-	if len(parts) != 6 {
-		t.Fatalf("expected 6 parts, got %d: %q", len(parts), compact)
+	// Encoding again keeps the note, and so does a second round trip.
+	again, err := ParseShare([]byte(decoded.Encode()))
+	if err != nil {
+		t.Fatalf("parse re-encoded: %v", err)
 	}
-	if parts[0] != "RM1" {
-		t.Errorf("version prefix: got %q, want RM1", parts[0])
+	if !reflect.DeepEqual(again.Headers, decoded.Headers) {
+		t.Errorf("headers after round trip: got %v, want %v", again.Headers, decoded.Headers)
 	}
-	if parts[1] != "2" {
-		t.Errorf("index: got %q, want 2", parts[1])
+	if err := again.Verify(); err != nil {
+		t.Errorf("annotated share should verify: %v", err)
 	}
-	if parts[2] != "5" {
-		t.Errorf("total: got %q, want 5", parts[2])
+
+	// Headers that wouldn't parse back are left out, and values stay on
+	// one line.
+	decoded.Headers["Index"] = "9"
+	decoded.Headers["bad: key"] = "x"
+	decoded.Headers["x-multi"] = "line one\nline two"
+	again, err = ParseShare([]byte(decoded.Encode()))
+	if err != nil {
+		t.Fatalf("parse with odd headers: %v", err)
 	}
-	if parts[3] != "3" {
-		t.Errorf("threshold: got %q, want 3", parts[3])
+	if again.Index != 3 {
+		t.Errorf("Index header overrode the index: got %d", again.Index)
 	}
-	// base64url of 0xDEADBEEF with no padding
-	if parts[4] != "3q2-7w" {
-		t.Errorf("data: got %q, want 3q2-7w", parts[4])
+	want := map[string]string{"x-note": "given to Carol 2024-03, backup in safe", "x-multi": "line one line two"}
+	if !reflect.DeepEqual(again.Headers, want) {
+		t.Errorf("headers: got %v, want %v", again.Headers, want)
 	}
-	// checksum should be 4 hex chars
-	if len(parts[5]) != 4 {
-		t.Errorf("checksum length: got %d, want 4", len(parts[5]))
+
+	// Clone copies the headers.
+	clone := again.Clone()
+	clone.Headers["x-note"] = "changed"
+	if again.Headers["x-note"] == "changed" {
+		t.Error("Clone should copy Headers")
 	}
 }
 
-func TestParseCompactRejectsBadInput(t *testing.T) {
-	// Build a valid compact string to use as a base
-	share := NewShare(1, 1, 5, 3, "Alice", []byte("valid-data"))
-	valid := share.CompactEncode()
+func TestShareVerify(t *testing.T) {
+	share := NewShare(1, 1, 5, 3, "Alice", []byte("test-data"))
 
-	tests := []struct {
-		name  string
-		input string
-	}{
-		{"empty string", ""},
-		{"too few fields", "RM1:1:5:3:data"},
-		{"too many fields", "RM1:1:5:3:data:check:extra"},
-		{"wrong prefix", "XX1:1:5:3:AAAA:0000"},
-		{"bad version", "RMx:1:5:3:AAAA:0000"},
-		{"zero version", "RM0:1:5:3:AAAA:0000"},
-		{"negative index", "RM1:-1:5:3:AAAA:0000"},
-		{"zero index", "RM1:0:5:3:AAAA:0000"},
-		{"zero total", "RM1:1:0:3:AAAA:0000"},
-		{"zero threshold", "RM1:1:5:0:AAAA:0000"},
-		{"bad base64", "RM1:1:5:3:!!!invalid!!!:0000"},
-		{"wrong checksum", valid[:len(valid)-4] + "ffff"},
-		{"truncated", valid[:len(valid)/2]},
+	// Valid checksum
+	if err := share.Verify(); err != nil {
+		t.Errorf("valid share failed verify: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := ParseCompact(tt.input)
-			if err == nil {
-				t.Errorf("expected error for %q, got nil", tt.input)
-			}
-		})
+	// Corrupted checksum
+	share.Checksum = "sha256:wrong"
+	if err := share.Verify(); err == nil {
+		t.Error("corrupted share should fail verify")
 	}
 }
 
-func TestCompactEncodeNoHolderOrCreated(t *testing.T) {
-	// Compact format intentionally omits Holder and Created metadata
-	// to keep the string short for QR codes
-	share := NewShare(1, 3, 7, 4, "Carol with spaces", []byte("some-share-data"))
-	compact := share.CompactEncode()
-	decoded, err := ParseCompact(compact)
-	if err != nil {
-		t.Fatalf("ParseCompact: %v", err)
-	}
-	if decoded.Holder != "" {
-		t.Errorf("holder should be empty in compact format, got %q", decoded.Holder)
+func TestShareZeroize(t *testing.T) {
+	share := NewShare(2, 1, 5, 3, "Alice", []byte("test-share-data"))
+	share.Zeroize()
+
+	for i, b := range share.Data {
+		if b != 0 {
+			t.Fatalf("byte %d not zeroed: %#x", i, b)
+		}
 	}
-	if !decoded.Created.IsZero() {
-		t.Errorf("created should be zero in compact format, got %v", decoded.Created)
+	if err := share.Verify(); err == nil {
+		t.Error("zeroized share should fail verify")
 	}
 }
 
-// createTarGz builds a tar.gz archive in memory with arbitrary entry names.
-// This allows crafting malicious archives for security testing.
-func createTarGz(t *testing.T, entries map[string]string) []byte {
-	t.Helper()
-	var buf bytes.Buffer
-	gzw := gzip.NewWriter(&buf)
-	tw := tar.NewWriter(gzw)
+func TestShareClone(t *testing.T) {
+	original := NewShare(2, 1, 5, 3, "Alice", []byte("test-share-data"))
+	clone := original.Clone()
 
-	for name, content := range entries {
-		if err := tw.WriteHeader(&tar.Header{
-			Name:     name,
-			Size:     int64(len(content)),
-			Mode:     0644,
-			Typeflag: tar.TypeReg,
-		}); err != nil {
-			t.Fatalf("writing tar header for %q: %v", name, err)
-		}
-		if _, err := tw.Write([]byte(content)); err != nil {
-			t.Fatalf("writing tar content for %q: %v", name, err)
-		}
+	if clone == original {
+		t.Fatal("clone should be a different pointer")
 	}
-
-	// Close tar then gzip explicitly (not defer) to ensure full flush.
-	if err := tw.Close(); err != nil {
-		t.Fatalf("closing tar writer: %v", err)
+	if !bytes.Equal(clone.Data, original.Data) || clone.Checksum != original.Checksum || clone.Holder != original.Holder {
+		t.Fatal("clone should have identical contents")
 	}
-	if err := gzw.Close(); err != nil {
-		t.Fatalf("closing gzip writer: %v", err)
+
+	// Wiping the original must not touch the copy
+	original.Zeroize()
+	if err := clone.Verify(); err != nil {
+		t.Errorf("clone should still verify after original is zeroized: %v", err)
 	}
-	return buf.Bytes()
 }
 
-func TestExtractTarGzPathTraversal(t *testing.T) {
-	t.Run("rejected paths", func(t *testing.T) {
-		tests := []struct {
+func TestSortShares(t *testing.T) {
+	shares := []*Share{
+		NewShare(2, 3, 5, 3, "Carol", []byte("c")),
+		NewShare(2, 1, 5, 3, "Alice", []byte("a")),
+		NewShare(2, 5, 5, 3, "Eve", []byte("e")),
+		NewShare(2, 2, 5, 3, "Bob", []byte("b")),
+	}
+	if !shares[1].Less(shares[0]) || shares[0].Less(shares[1]) {
+		t.Error("Less should order by index")
+	}
+
+	SortShares(shares)
+	var got []string
+	for _, s := range shares {
+		got = append(got, s.Holder)
+	}
+	if strings.Join(got, ",") != "Alice,Bob,Carol,Eve" {
+		t.Errorf("sorted order = %v", got)
+	}
+}
+
+func TestShareEncodeEncrypted(t *testing.T) {
+	share := NewShare(2, 3, 5, 3, "Carol", bytes.Repeat([]byte{0x42}, 33))
+	share.Label = "C"
+
+	encrypted, err := share.EncodeEncrypted("laptop password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedShare(encrypted) {
+		t.Error("IsEncryptedShare should recognize the output")
+	}
+	if IsEncryptedShare([]byte(share.Encode())) {
+		t.Error("IsEncryptedShare should not match a plain share")
+	}
+	if bytes.Contains(encrypted, []byte("Carol")) || bytes.Contains(encrypted, []byte(ShareBegin)) {
+		t.Error("the encrypted share should not show its contents")
+	}
+
+	parsed, err := ParseEncryptedShare(encrypted, "laptop password")
+	if err != nil {
+		t.Fatalf("ParseEncryptedShare: %v", err)
+	}
+	if parsed.Encode() != share.Encode() {
+		t.Errorf("round trip changed the share:\n%s\nwant:\n%s", parsed.Encode(), share.Encode())
+	}
+
+	if _, err := ParseEncryptedShare(encrypted, "wrong password"); !errors.Is(err, ErrWrongSharePassword) {
+		t.Errorf("wrong password: got %v, want ErrWrongSharePassword", err)
+	}
+	if _, err := share.EncodeEncrypted(""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("empty password: got %v", err)
+	}
+
+	// An unarmored age file of the PEM works as well.
+	var binary bytes.Buffer
+	if err := Encrypt(&binary, strings.NewReader(share.Encode()), "pw"); err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseEncryptedShare(binary.Bytes(), "pw"); err != nil || parsed.Index != 3 {
+		t.Errorf("binary age file: %v", err)
+	}
+}
+
+func TestDedupeShares(t *testing.T) {
+	alice := NewShare(2, 1, 5, 3, "Alice", []byte("alice-data"))
+	bob := NewShare(2, 2, 5, 3, "Bob", []byte("bob-data"))
+	words := NewShare(2, 0, 0, 0, "", []byte("typed-in"))
+
+	deduped, err := DedupeShares([]*Share{alice, bob, alice.Clone(), words, words.Clone()})
+	if err != nil {
+		t.Fatalf("DedupeShares: %v", err)
+	}
+	if len(deduped) != 4 || deduped[0] != alice || deduped[1] != bob {
+		t.Errorf("expected alice, bob and both unnumbered shares, got %v", deduped)
+	}
+
+	impostor := NewShare(2, 1, 5, 3, "Mallory", []byte("other-data"))
+	if _, err := DedupeShares([]*Share{alice, bob, impostor}); err == nil || !strings.Contains(err.Error(), "numbered 1") {
+		t.Errorf("expected a conflict on index 1, got %v", err)
+	}
+
+	// Data that differs only in its last byte, or only in length, is a
+	// conflict too.
+	lastByte := alice.Clone()
+	lastByte.Data[len(lastByte.Data)-1] ^= 1
+	longer := NewShare(2, 1, 5, 3, "Alice", []byte("alice-data!"))
+	for name, other := range map[string]*Share{"last byte": lastByte, "length": longer} {
+		if _, err := DedupeShares([]*Share{alice, other}); err == nil || !strings.Contains(err.Error(), "numbered 1") {
+			t.Errorf("%s: expected a conflict on index 1, got %v", name, err)
+		}
+	}
+}
+
+func TestCombineWordsWithoutIndex(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x3c}, 32)
+	holders := make([]string, 25)
+	for i := range holders {
+		holders[i] = fmt.Sprintf("Friend %d", i+1)
+	}
+	shares, err := SplitToShares(secret, 25, 3, holders, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Piece 20's holder only remembers the first 25 words, so its number
+	// is lost, but not its x-coordinate.
+	words, err := shares[19].Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed, err := ParseShareAny([]byte(strings.Join(words[:25], " ")))
+	if err != nil {
+		t.Fatalf("parsing 25 words: %v", err)
+	}
+	if typed.Index != 0 {
+		t.Errorf("index = %d, want 0 without the 26th word", typed.Index)
+	}
+	if typed.XCoordinate() != shares[19].XCoordinate() {
+		t.Errorf("x-coordinate = %d, want %d", typed.XCoordinate(), shares[19].XCoordinate())
+	}
+
+	for _, others := range [][]*Share{{shares[0], shares[1]}, {shares[17], shares[24]}} {
+		recovered, err := CombineChecked([][]byte{others[0].Data, typed.Data, others[1].Data}, 3)
+		if err != nil {
+			t.Fatalf("combining with pieces %d and %d: %v", others[0].Index, others[1].Index, err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("pieces %d and %d with the typed piece gave the wrong secret", others[0].Index, others[1].Index)
+		}
+	}
+}
+
+func TestVerifyReconstructedSecret(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a}, 32)
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := NewCommitment(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := NewCommitment(secret); other == commitment {
+		t.Error("two commitments to the same secret should differ by salt")
+	}
+
+	recovered, err := Combine(shares[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyReconstructedSecret(recovered, commitment); err != nil {
+		t.Errorf("correct pieces: %v", err)
+	}
+
+	// Too few pieces, and a piece from another split in place of one of
+	// them, both combine to something that isn't the secret.
+	tooFew, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherShares, _ := Split(bytes.Repeat([]byte{0xa5}, 32), 5, 3)
+	scrambled, err := Combine([][]byte{shares[0], shares[1], otherShares[4]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string][]byte{"too few": tooFew, "scrambled": scrambled} {
+		if err := VerifyReconstructedSecret(got, commitment); !errors.Is(err, ErrSecretMismatch) {
+			t.Errorf("%s: got %v, want ErrSecretMismatch", name, err)
+		}
+	}
+
+	for _, bad := range []string{"", "v1:zz:00", "v2:" + commitment[3:], commitment[:len(commitment)-2]} {
+		err := VerifyReconstructedSecret(recovered, bad)
+		if err == nil || errors.Is(err, ErrSecretMismatch) {
+			t.Errorf("commitment %q: got %v, want a format error", bad, err)
+		}
+	}
+}
+
+func TestShareCommitment(t *testing.T) {
+	withCommitment := func(index int, c string) *Share {
+		s := NewShare(2, index, 3, 2, "", []byte{byte(index)})
+		if c != "" {
+			s.Headers = map[string]string{CommitmentHeader: c}
+		}
+		return s
+	}
+
+	got, err := ShareCommitment([]*Share{withCommitment(1, ""), withCommitment(2, "v1:a:b"), withCommitment(3, "v1:a:b")})
+	if err != nil || got != "v1:a:b" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if got, err := ShareCommitment([]*Share{withCommitment(1, "")}); err != nil || got != "" {
+		t.Errorf("no commitment: got %q, %v", got, err)
+	}
+	if _, err := ShareCommitment([]*Share{withCommitment(1, "v1:a:b"), withCommitment(2, "v1:c:d")}); err == nil || !strings.Contains(err.Error(), "pieces 1 and 2") {
+		t.Errorf("different commitments: got %v", err)
+	}
+}
+
+func TestTrySubsets(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x3c}, 32)
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := NewCommitment(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Share 3 is subtly wrong: one byte off, at the same x-coordinate, so it
+	// still looks like a piece of this set.
+	shares[3] = bytes.Clone(shares[3])
+	shares[3][10] ^= 0x01
+
+	search, err := TrySubsets(shares, 3, commitment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(search.Secret, secret) {
+		t.Error("wrong secret recovered")
+	}
+	if slices.Contains(search.Used, 3) {
+		t.Errorf("the quorum used includes the wrong share: %v", search.Used)
+	}
+	// C(5,3) = 10 sets; the C(4,3) = 4 without share 3 match.
+	if search.Tried != 10 || search.Matched != 4 {
+		t.Errorf("tried %d, matched %d; want 10 and 4", search.Tried, search.Matched)
+	}
+	if !slices.Equal(search.Suspects, []int{3}) {
+		t.Errorf("suspects = %v, want [3]", search.Suspects)
+	}
+
+	// With two wrong shares of four, no three agree.
+	shares[1] = bytes.Clone(shares[1])
+	shares[1][0] ^= 0x80
+	if _, err := TrySubsets(shares[:4], 3, commitment); !errors.Is(err, ErrSecretMismatch) {
+		t.Errorf("two wrong of four: got %v, want ErrSecretMismatch", err)
+	}
+
+	if _, err := TrySubsets([][]byte{shares[0], shares[2], shares[0]}, 2, commitment); err == nil {
+		t.Error("expected error for the same share twice")
+	}
+	many := make([][]byte, 40)
+	for i := range many {
+		many[i] = []byte{1, byte(i + 1)}
+	}
+	if _, err := TrySubsets(many, 20, commitment); err == nil || !strings.Contains(err.Error(), "combinations") {
+		t.Errorf("expected error for too many combinations, got %v", err)
+	}
+}
+
+func TestShareStringRedactsData(t *testing.T) {
+	data := []byte("super secret share bytes, 33 long")
+	share := NewShare(2, 3, 5, 3, "Carol", data)
+
+	want := "Share{v2 3/5 holder=Carol idx=3 data=<redacted 33B> chk=" + strings.TrimPrefix(share.Checksum, "sha256:")[:4] + "…}"
+	if got := share.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	secrets := []string{
+		string(data),
+		fmt.Sprint([]byte(data)),
+		fmt.Sprintf("%x", data),
+		base64.StdEncoding.EncodeToString(data),
+	}
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []any{share, *share, []*Share{share}} {
+			out := fmt.Sprintf(verb, v)
+			for _, secret := range secrets {
+				if strings.Contains(out, secret) {
+					t.Errorf("fmt %s of %T leaks share data: %s", verb, v, out)
+				}
+			}
+			if !strings.Contains(out, "<redacted 33B>") {
+				t.Errorf("fmt %s of %T = %s, want redacted form", verb, v, out)
+			}
+		}
+	}
+
+	if !bytes.Equal(share.SecretData(), data) {
+		t.Error("SecretData should return the share bytes")
+	}
+}
+
+func TestShareFilename(t *testing.T) {
+	tests := []struct {
+		holder   string
+		expected string
+	}{
+		{"Alice", "SHARE-alice.txt"},
+		{"Bob Smith", "SHARE-bob-smith.txt"},
+		{"Carol!", "SHARE-carol.txt"},
+		{"", "SHARE-1.txt"},
+	}
+
+	for _, tt := range tests {
+		share := NewShare(1, 1, 3, 2, tt.holder, []byte("data"))
+		got := share.Filename()
+		if got != tt.expected {
+			t.Errorf("holder %q: got %q, want %q", tt.holder, got, tt.expected)
+		}
+	}
+}
+
+func TestCompactEncodeRoundTrip(t *testing.T) {
+	original := NewShare(1, 1, 5, 3, "Alice", []byte("test-share-data-1234567890"))
+
+	compact := original.CompactEncode()
+
+	decoded, err := ParseCompact(compact)
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+
+	if decoded.Version != original.Version {
+		t.Errorf("version: got %d, want %d", decoded.Version, original.Version)
+	}
+	if decoded.Index != original.Index {
+		t.Errorf("index: got %d, want %d", decoded.Index, original.Index)
+	}
+	if decoded.Total != original.Total {
+		t.Errorf("total: got %d, want %d", decoded.Total, original.Total)
+	}
+	if decoded.Threshold != original.Threshold {
+		t.Errorf("threshold: got %d, want %d", decoded.Threshold, original.Threshold)
+	}
+	if !bytes.Equal(decoded.Data, original.Data) {
+		t.Errorf("data mismatch")
+	}
+	if decoded.Checksum != original.Checksum {
+		t.Errorf("checksum: got %q, want %q", decoded.Checksum, original.Checksum)
+	}
+}
+
+func TestCompactEncodeWithRealShares(t *testing.T) {
+	secret := []byte("a]Zp9kR-mN2xB7qL_YwF4vC8hD6sE0jT")
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+
+	for i, shareData := range shares {
+		share := NewShare(1, i+1, 5, 3, "", shareData)
+		compact := share.CompactEncode()
+
+		decoded, err := ParseCompact(compact)
+		if err != nil {
+			t.Fatalf("share %d: ParseCompact(%q): %v", i+1, compact, err)
+		}
+		if !bytes.Equal(decoded.Data, shareData) {
+			t.Errorf("share %d: data mismatch after round-trip", i+1)
+		}
+	}
+}
+
+func TestShareURIRoundTrip(t *testing.T) {
+	original := NewShare(2, 3, 5, 3, "Carol", []byte("test-share-data-1234567890"))
+
+	uri := original.URI()
+	if want := "rememory://share/v2/" + original.CompactEncode(); uri != want {
+		t.Errorf("URI: got %q, want %q", uri, want)
+	}
+
+	decoded, err := ParseURI(uri)
+	if err != nil {
+		t.Fatalf("ParseURI: %v", err)
+	}
+	if decoded.Version != 2 || decoded.Index != 3 || decoded.Total != 5 || decoded.Threshold != 3 {
+		t.Errorf("got v%d %d/%d threshold %d", decoded.Version, decoded.Index, decoded.Total, decoded.Threshold)
+	}
+	if !bytes.Equal(decoded.Data, original.Data) {
+		t.Error("data mismatch")
+	}
+	if decoded.Checksum != original.Checksum {
+		t.Errorf("checksum: got %q, want %q", decoded.Checksum, original.Checksum)
+	}
+}
+
+func TestParseURIErrors(t *testing.T) {
+	share := NewShare(2, 1, 5, 3, "", []byte("test-share-data"))
+	compact := share.CompactEncode()
+
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{"unknown scheme", "https://share/v2/" + compact, "must start with"},
+		{"wrong host", "rememory://piece/v2/" + compact, "must start with"},
+		{"no version", "rememory://share/" + compact, "expected"},
+		{"bad version", "rememory://share/two/" + compact, "bad version"},
+		{"zero version", "rememory://share/v0/" + compact, "bad version"},
+		{"missing share", "rememory://share/v2/", "expected"},
+		{"version mismatch", "rememory://share/v1/" + compact, "path says v1"},
+		{"bad compact", "rememory://share/v2/RM2:1:5:3:AAAA:0000", "checksum mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseURI(tt.uri)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCompactEncodeFormat(t *testing.T) {
+	share := NewShare(1, 2, 5, 3, "Bob", []byte{0xDE, 0xAD, 0xBE, 0xEF})
+	compact := share.CompactEncode()
+
+	if !strings.HasPrefix(compact, "RM1:") {
+		t.Errorf("should start with RM1:, got %q", compact)
+	}
+
+	parts := strings.Split(compact, ":")
+	if len(parts) != 6 {
+		t.Fatalf("expected 6 parts, got %d: %q", len(parts), compact)
+	}
+	if parts[0] != "RM1" {
+		t.Errorf("version prefix: got %q, want RM1", parts[0])
+	}
+	if parts[1] != "2" {
+		t.Errorf("index: got %q, want 2", parts[1])
+	}
+	if parts[2] != "5" {
+		t.Errorf("total: got %q, want 5", parts[2])
+	}
+	if parts[3] != "3" {
+		t.Errorf("threshold: got %q, want 3", parts[3])
+	}
+	// base64url of 0xDEADBEEF with no padding
+	if parts[4] != "3q2-7w" {
+		t.Errorf("data: got %q, want 3q2-7w", parts[4])
+	}
+	// checksum should be 4 hex chars
+	if len(parts[5]) != 4 {
+		t.Errorf("checksum length: got %d, want 4", len(parts[5]))
+	}
+}
+
+func TestParseCompactRejectsBadInput(t *testing.T) {
+	// Build a valid compact string to use as a base
+	share := NewShare(1, 1, 5, 3, "Alice", []byte("valid-data"))
+	valid := share.CompactEncode()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty string", ""},
+		{"too few fields", "RM1:1:5:3:data"},
+		{"too many fields", "RM1:1:5:3:data:check:extra"},
+		{"wrong prefix", "XX1:1:5:3:AAAA:0000"},
+		{"bad version", "RMx:1:5:3:AAAA:0000"},
+		{"zero version", "RM0:1:5:3:AAAA:0000"},
+		{"negative index", "RM1:-1:5:3:AAAA:0000"},
+		{"zero index", "RM1:0:5:3:AAAA:0000"},
+		{"zero total", "RM1:1:0:3:AAAA:0000"},
+		{"zero threshold", "RM1:1:5:0:AAAA:0000"},
+		{"bad base64", "RM1:1:5:3:!!!invalid!!!:0000"},
+		{"wrong checksum", valid[:len(valid)-4] + "ffff"},
+		{"truncated", valid[:len(valid)/2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCompact(tt.input)
+			if err == nil {
+				t.Errorf("expected error for %q, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestCompactEncodeHMAC(t *testing.T) {
+	key := []byte("sha256:manifest-checksum")
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 11)
+	}
+	share := NewShare(2, 4, 5, 3, "David", data)
+
+	tagged := share.CompactEncodeHMAC(key)
+	if !strings.HasPrefix(tagged, share.CompactEncode()+":") {
+		t.Fatalf("tagged form should extend the compact one: %s", tagged)
+	}
+	decoded, err := ParseCompactHMAC(tagged, key)
+	if err != nil {
+		t.Fatalf("ParseCompactHMAC: %v", err)
+	}
+	if decoded.Index != 4 || decoded.Total != 5 || decoded.Threshold != 3 || !bytes.Equal(decoded.Data, data) {
+		t.Errorf("round trip: got %+v", decoded)
+	}
+	// Tools without the key still read it.
+	if _, err := ParseCompact(tagged); err != nil {
+		t.Errorf("ParseCompact of a tagged share: %v", err)
+	}
+	if _, err := ParseShareAny([]byte(tagged)); err != nil {
+		t.Errorf("ParseShareAny of a tagged share: %v", err)
+	}
+
+	// Flip each bit of the data and recompute the short checksum, as a
+	// deliberate change would. The checksum can't tell; the tag always does.
+	tag := tagged[strings.LastIndex(tagged, ":"):]
+	for bit := 0; bit < len(data)*8; bit++ {
+		tampered := share.Clone()
+		tampered.Data[bit/8] ^= 1 << (bit % 8)
+		forged := tampered.CompactEncode() + tag
+		if _, err := ParseCompact(forged); err != nil {
+			t.Fatalf("bit %d: the short checksum should be fooled, got %v", bit, err)
+		}
+		if _, err := ParseCompactHMAC(forged, key); err == nil {
+			t.Fatalf("bit %d: flipped data passed the integrity check", bit)
+		}
+	}
+	renumbered := share.Clone()
+	renumbered.Index = 2
+	if _, err := ParseCompactHMAC(renumbered.CompactEncode()+tag, key); err == nil {
+		t.Error("a changed index passed the integrity check")
+	}
+
+	errTests := []struct {
+		name  string
+		input string
+		key   []byte
+	}{
+		{"wrong key", tagged, []byte("another project")},
+		{"empty key", tagged, nil},
+		{"no tag", share.CompactEncode(), key},
+	}
+	for _, tt := range errTests {
+		if _, err := ParseCompactHMAC(tt.input, tt.key); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestCompactEncodeNoHolderOrCreated(t *testing.T) {
+	// Compact format intentionally omits Holder and Created metadata
+	// to keep the string short for QR codes
+	share := NewShare(1, 3, 7, 4, "Carol with spaces", []byte("some-share-data"))
+	compact := share.CompactEncode()
+	decoded, err := ParseCompact(compact)
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+	if decoded.Holder != "" {
+		t.Errorf("holder should be empty in compact format, got %q", decoded.Holder)
+	}
+	if !decoded.Created.IsZero() {
+		t.Errorf("created should be zero in compact format, got %v", decoded.Created)
+	}
+}
+
+// createTarGz builds a tar.gz archive in memory with arbitrary entry names.
+// This allows crafting malicious archives for security testing.
+func createTarGz(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	return createTarCompressed(t, entries, CompressionGzip)
+}
+
+func createTarCompressed(t *testing.T, entries map[string]string, c Compression) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw, err := NewCompressor(&buf, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(gzw)
+
+	for name, content := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Size:     int64(len(content)),
+			Mode:     0644,
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatalf("writing tar header for %q: %v", name, err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("writing tar content for %q: %v", name, err)
+		}
+	}
+
+	// Close tar then the compressor explicitly (not defer) to ensure full flush.
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("closing %s writer: %v", c, err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	files := map[string]string{
+		"manifest/a.txt": strings.Repeat("alpha ", 2000),
+		"manifest/b.txt": "bravo",
+	}
+
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			data := createTarCompressed(t, files, c)
+
+			got, err := DetectCompression(data)
+			if err != nil || got != c {
+				t.Fatalf("DetectCompression = %q, %v; want %q", got, err, c)
+			}
+
+			extracted, err := ExtractTarGz(data)
+			if err != nil {
+				t.Fatalf("ExtractTarGz: %v", err)
+			}
+			if len(extracted) != len(files) {
+				t.Fatalf("got %d files, want %d", len(extracted), len(files))
+			}
+			for _, f := range extracted {
+				if string(f.Data) != files[f.Name] {
+					t.Errorf("%s: content mismatch", f.Name)
+				}
+			}
+
+			r, err := NewDecompressor(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("NewDecompressor: %v", err)
+			}
+			defer r.Close()
+			if _, err := tar.NewReader(r).Next(); err != nil {
+				t.Errorf("reading first tar entry: %v", err)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := DetectCompression([]byte("plain")); err == nil {
+			t.Error("expected error for unknown format")
+		}
+		if _, err := ParseCompression("brotli"); err == nil {
+			t.Error("expected error for unknown codec name")
+		}
+	})
+
+	t.Run("truncated zstd", func(t *testing.T) {
+		data := createTarCompressed(t, files, CompressionZstd)
+		_, err := ExtractTarGz(data[:len(data)/2])
+		if !errors.Is(err, ErrCorruptZstd) {
+			t.Fatalf("expected ErrCorruptZstd, got %v", err)
+		}
+	})
+}
+
+func TestExtractTarGzPathTraversal(t *testing.T) {
+	t.Run("rejected paths", func(t *testing.T) {
+		tests := []struct {
 			name  string
 			entry string
 		}{
@@ -446,88 +1317,1240 @@ func TestExtractTarGzPathTraversal(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				data := createTarGz(t, map[string]string{tt.entry: "malicious"})
-				_, err := ExtractTarGz(data)
-				if err == nil {
-					t.Errorf("expected error for path %q, got nil", tt.entry)
+				data := createTarGz(t, map[string]string{tt.entry: "malicious"})
+				_, err := ExtractTarGz(data)
+				if err == nil {
+					t.Errorf("expected error for path %q, got nil", tt.entry)
+				}
+				if err != nil && !strings.Contains(err.Error(), "invalid path") {
+					t.Errorf("expected 'invalid path' error for %q, got: %v", tt.entry, err)
+				}
+			})
+		}
+	})
+
+	t.Run("accepted paths", func(t *testing.T) {
+		entries := map[string]string{
+			"safe/file.txt":        "hello",
+			"safe/nested/deep.txt": "world",
+		}
+		data := createTarGz(t, entries)
+		files, err := ExtractTarGz(data)
+		if err != nil {
+			t.Fatalf("unexpected error for safe paths: %v", err)
+		}
+
+		extracted := make(map[string]string)
+		for _, f := range files {
+			extracted[f.Name] = string(f.Data)
+		}
+
+		for name, want := range entries {
+			got, ok := extracted[name]
+			if !ok {
+				t.Errorf("missing extracted file %q", name)
+				continue
+			}
+			if got != want {
+				t.Errorf("file %q: got %q, want %q", name, got, want)
+			}
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		_, err := ExtractTarGz([]byte{})
+		if err == nil {
+			t.Error("expected error for empty input")
+		}
+	})
+}
+
+func TestVerifyExtractedFiles(t *testing.T) {
+	files := []ExtractedFile{
+		{Name: "manifest/a.txt", Data: []byte("alpha")},
+		{Name: "manifest/sub/b.txt", Data: []byte("bravo")},
+	}
+	hashes := map[string]string{
+		"a.txt":     HashString("alpha"),
+		"sub/b.txt": HashString("bravo"),
+	}
+
+	parsed, err := ParseManifestHashes(FormatManifestHashes(hashes))
+	if err != nil {
+		t.Fatalf("ParseManifestHashes: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, hashes) {
+		t.Fatalf("round trip = %v, want %v", parsed, hashes)
+	}
+
+	withList := append(files, ExtractedFile{Name: "manifest/" + ManifestHashesFile, Data: FormatManifestHashes(hashes)})
+	if err := VerifyExtractedFiles(withList, hashes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		files []ExtractedFile
+		want  string
+	}{
+		{"changed", []ExtractedFile{files[0], {Name: "manifest/sub/b.txt", Data: []byte("brave")}}, "manifest/sub/b.txt"},
+		{"missing", files[:1], "sub/b.txt is listed"},
+		{"extra", append(files[:2:2], ExtractedFile{Name: "manifest/c.txt", Data: nil}), "manifest/c.txt is not listed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyExtractedFiles(tt.files, hashes)
+			if !errors.Is(err, ErrFileHashMismatch) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want ErrFileHashMismatch mentioning %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseManifestHashes([]byte("not-a-hash  a.txt\n")); err == nil {
+		t.Error("expected error for malformed hash list")
+	}
+}
+
+func TestExtractTarGzLimited(t *testing.T) {
+	t.Run("size cap", func(t *testing.T) {
+		// 8 MB of zeros compresses to a few KB — a miniature zip bomb
+		data := createTarGz(t, map[string]string{"bomb/zeros.bin": strings.Repeat("\x00", 8<<20)})
+		if len(data) > 64<<10 {
+			t.Fatalf("test archive unexpectedly large: %d bytes", len(data))
+		}
+
+		_, err := ExtractTarGzLimited(data, 1<<20, 100)
+		if err == nil {
+			t.Fatal("expected size cap to trip")
+		}
+		if !strings.Contains(err.Error(), "maximum total size") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("entry cap", func(t *testing.T) {
+		entries := make(map[string]string)
+		for i := 0; i < 20; i++ {
+			entries[fmt.Sprintf("many/file-%d.txt", i)] = "x"
+		}
+		data := createTarGz(t, entries)
+
+		_, err := ExtractTarGzLimited(data, 1<<20, 10)
+		if err == nil || !strings.Contains(err.Error(), "too many entries") {
+			t.Fatalf("expected entry cap error, got %v", err)
+		}
+	})
+
+	t.Run("data after the end", func(t *testing.T) {
+		// A tar stream followed by padding, gzipped together.
+		withTrailing := func(n int) []byte {
+			var tarBuf bytes.Buffer
+			tw := tar.NewWriter(&tarBuf)
+			if err := tw.WriteHeader(&tar.Header{Name: "ok/a.txt", Mode: 0644, Size: 5}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			tarBuf.Write(make([]byte, n))
+			var gz bytes.Buffer
+			zw := gzip.NewWriter(&gz)
+			if _, err := zw.Write(tarBuf.Bytes()); err != nil {
+				t.Fatal(err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			return gz.Bytes()
+		}
+
+		// Padding to a 10 KiB record, as GNU tar writes, is fine.
+		if _, err := ExtractTarGzLimited(withTrailing(10<<10), 1<<20, 10); err != nil {
+			t.Errorf("record padding: %v", err)
+		}
+		// 8 MB of zeros after the end is not read to the end.
+		_, err := ExtractTarGzLimited(withTrailing(8<<20), 1<<20, 10)
+		if err == nil || !strings.Contains(err.Error(), "after its end") {
+			t.Errorf("expected an error about trailing data, got %v", err)
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		data := createTarGz(t, map[string]string{"ok/a.txt": "hello", "ok/b.txt": "world"})
+		files, err := ExtractTarGzLimited(data, 10, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 2 {
+			t.Errorf("got %d files, want 2", len(files))
+		}
+	})
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Alice", "alice"},
+		{"Bob Smith", "bob-smith"},
+		{"Carol!", "carol"},
+		{"test@user.com", "testusercom"},
+		{"file/path", "filepath"},
+		// NFD transliteration: accented chars → ASCII base
+		{"José", "jose"},
+		{"Ñoño", "nono"},
+		{"Müller", "muller"},
+		// Underscore → hyphen
+		{"bob_smith", "bob-smith"},
+		// Hyphen trimming and collapsing
+		{"---hyphens---", "hyphens"},
+		{"a--b", "a-b"},
+		// Leading/trailing spaces
+		{"  Alice  ", "alice"},
+		// Path traversal chars stripped
+		{"../etc/passwd", "etcpasswd"},
+		// Non-ASCII input that sanitizes to empty
+		{"日本語", ""},
+		{"!!!???", ""},
+		// Empty input
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := SanitizeFilename(tt.input)
+		if got != tt.expected {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSanitizeNote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Call my brother first.", "Call my brother first."},
+		{"  line one\r\nline two\rline three\n\n", "line one\nline two\nline three"},
+		{"tab\tkept, bell\x07 and escape\x1b[31m dropped", "tab\tkept, bell and escape[31m dropped"},
+		{"<script>alert(1)</script>", "<script>alert(1)</script>"},
+		{"Jose\u0301", "José"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := SanitizeNote(tt.input)
+		if got != tt.expected {
+			t.Errorf("SanitizeNote(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestExtractTarGzCorruption(t *testing.T) {
+	files := map[string]string{
+		"manifest/a.txt": strings.Repeat("alpha ", 2000),
+		"manifest/b.txt": strings.Repeat("bravo ", 2000),
+	}
+	valid := createTarGz(t, files)
+
+	t.Run("truncated gzip", func(t *testing.T) {
+		_, err := ExtractTarGz(valid[:len(valid)/2])
+		if !errors.Is(err, ErrCorruptGzip) {
+			t.Fatalf("expected ErrCorruptGzip, got %v", err)
+		}
+		if errors.Is(err, ErrCorruptTar) {
+			t.Error("gzip corruption should not be reported as tar corruption")
+		}
+		if !strings.Contains(err.Error(), "compressed byte") {
+			t.Errorf("error should report an offset: %v", err)
+		}
+	})
+
+	t.Run("bad gzip header", func(t *testing.T) {
+		_, err := ExtractTarGz([]byte("not a gzip stream"))
+		if !errors.Is(err, ErrCorruptGzip) {
+			t.Fatalf("expected ErrCorruptGzip, got %v", err)
+		}
+	})
+
+	t.Run("truncated tar", func(t *testing.T) {
+		// Build the tar stream, cut it mid-file, then gzip it cleanly.
+		var tarBuf bytes.Buffer
+		tw := tar.NewWriter(&tarBuf)
+		for _, name := range []string{"manifest/a.txt", "manifest/b.txt"} {
+			content := files[name]
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tw.Close()
+		// a.txt is 12000 bytes, padded to 12288; cut 100 bytes into b.txt's data
+		truncated := tarBuf.Bytes()[:512+12288+512+100]
+
+		var gzBuf bytes.Buffer
+		gzw := gzip.NewWriter(&gzBuf)
+		gzw.Write(truncated)
+		gzw.Close()
+
+		_, err := ExtractTarGz(gzBuf.Bytes())
+		if !errors.Is(err, ErrCorruptTar) {
+			t.Fatalf("expected ErrCorruptTar, got %v", err)
+		}
+		if errors.Is(err, ErrCorruptGzip) {
+			t.Error("tar corruption should not be reported as gzip corruption")
+		}
+		if !strings.Contains(err.Error(), "manifest/b.txt") {
+			t.Errorf("error should name the damaged entry: %v", err)
+		}
+	})
+}
+
+func TestShareValidate(t *testing.T) {
+	valid := func() *Share {
+		return NewShare(2, 1, 5, 3, "Alice", bytes.Repeat([]byte{7}, 33))
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid share failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *Share)
+	}{
+		{"unknown version", func(s *Share) { s.Version = 3 }},
+		{"threshold 1", func(s *Share) { s.Threshold = 1 }},
+		{"threshold above total", func(s *Share) { s.Threshold = 6 }},
+		{"index zero", func(s *Share) { s.Index = 0 }},
+		{"index above total", func(s *Share) { s.Index = 6 }},
+		{"v2 wrong length", func(s *Share) { s.Data = s.Data[:20] }},
+		{"too short", func(s *Share) { s.Version = 1; s.Data = s.Data[:1] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.mutate(s)
+			if err := s.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestShareFingerprint(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 30, 15, 0, time.UTC)
+	a := NewShare(2, 1, 5, 3, "Alice", []byte("a"))
+	b := NewShare(2, 2, 5, 3, "Bob", []byte("b"))
+	a.Created, b.Created = created, created.Add(20*time.Second)
+
+	if a.Fingerprint() == "" || a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("shares from the same seal should share a fingerprint: %q vs %q", a.Fingerprint(), b.Fingerprint())
+	}
+
+	other := NewShare(2, 1, 5, 3, "Alice", []byte("a"))
+	other.Created = created.Add(24 * time.Hour)
+	if other.Fingerprint() == a.Fingerprint() {
+		t.Error("shares from different seals should have different fingerprints")
+	}
+
+	// Two seals with the same settings in the same minute differ by their
+	// commitments.
+	for _, s := range []*Share{a, b} {
+		s.Headers = map[string]string{CommitmentHeader: "salt1:hash1"}
+	}
+	sameMinute := NewShare(2, 1, 5, 3, "Alice", []byte("c"))
+	sameMinute.Created = created
+	sameMinute.Headers = map[string]string{CommitmentHeader: "salt2:hash2"}
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("shares with the same commitment should share a fingerprint")
+	}
+	if sameMinute.Fingerprint() == a.Fingerprint() {
+		t.Error("seals in the same minute with different commitments should have different fingerprints")
+	}
+
+	compact, err := ParseCompact(a.CompactEncode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact.Fingerprint() != "" {
+		t.Error("compact shares have no creation time and should have no fingerprint")
+	}
+}
+
+func TestParseShareAny(t *testing.T) {
+	original := NewShare(2, 3, 5, 3, "Carol", bytes.Repeat([]byte{0x5a, 0xc3, 0x01}, 11))
+	words, err := original.Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	esWords, err := original.WordsForLang(LangES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbered strings.Builder
+	for i, w := range words {
+		fmt.Fprintf(&numbered, "%d. %s\n", i+1, w)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		wantTotal int
+	}{
+		{"pem", original.Encode(), 5},
+		{"pem in readme", "Hello Carol,\n\n" + original.Encode() + "\nThanks", 5},
+		{"compact", "  " + original.CompactEncode() + "\n", 5},
+		{"uri", original.URI() + "\n", 5},
+		{"words", strings.Join(words, " "), 0},
+		{"words numbered", numbered.String(), 0},
+		{"words spanish", strings.Join(esWords, "\n"), 0},
+		{"words with bom and crlf", "\uFEFF" + strings.Join(words, "\r\n") + "\r\n", 0},
+		{"words with tabs and nbsp", strings.Join(words[:12], "\t") + "\u00a0\u00a0" + strings.Join(words[12:], "\u00a0"), 0},
+		{"numbered words with crlf", "\uFEFF" + strings.ReplaceAll(numbered.String(), "\n", "\r\n"), 0},
+		{"compact with bom", "\uFEFF" + original.CompactEncode() + "\r\n", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			share, err := ParseShareAny([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !bytes.Equal(share.Data, original.Data) {
+				t.Error("data mismatch")
+			}
+			if share.Index != original.Index || share.Version != 2 {
+				t.Errorf("got index %d version %d", share.Index, share.Version)
+			}
+			if share.Total != tt.wantTotal {
+				t.Errorf("total: got %d, want %d", share.Total, tt.wantTotal)
+			}
+			if err := share.Verify(); err != nil {
+				t.Errorf("verify: %v", err)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"two share blocks", original.Encode() + original.Encode(), "ambiguous"},
+		{"prose", "please find my share attached", "unrecognized share format"},
+		{"empty", "  \n", "empty"},
+		{"only a bom", "\uFEFF\r\n", "empty"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseShareAny([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCombineChecked(t *testing.T) {
+	secret := []byte("my-super-secret-passphrase")
+	shares, err := Split(secret, 6, 3)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	t.Run("consistent extra shares", func(t *testing.T) {
+		recovered, err := CombineChecked(shares[:5], 3)
+		if err != nil {
+			t.Fatalf("combine: %v", err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("got %q, want %q", recovered, secret)
+		}
+	})
+
+	corrupt := func(n int, bad int) [][]byte {
+		out := make([][]byte, n)
+		for i := range out {
+			out[i] = append([]byte(nil), shares[i]...)
+		}
+		out[bad][4] ^= 0x5A
+		return out
+	}
+
+	t.Run("quorum plus one detects", func(t *testing.T) {
+		for bad := 0; bad < 4; bad++ {
+			recovered, err := CombineChecked(corrupt(4, bad), 3)
+			var inconsistent *InconsistentSharesError
+			if !errors.As(err, &inconsistent) {
+				t.Fatalf("bad share %d: expected InconsistentSharesError, got %q, %v", bad, recovered, err)
+			}
+			if recovered != nil {
+				t.Errorf("bad share %d: got a secret along with the error", bad)
+			}
+			if len(inconsistent.Suspects) != 0 {
+				t.Errorf("threshold+1 shares can't locate the bad one, got suspects %v", inconsistent.Suspects)
+			}
+			if !strings.Contains(err.Error(), "disagree") {
+				t.Errorf("error should say the shares disagree: %v", err)
+			}
+		}
+	})
+
+	t.Run("quorum plus two locates", func(t *testing.T) {
+		for bad := 0; bad < 5; bad++ {
+			_, err := CombineChecked(corrupt(5, bad), 3)
+			var inconsistent *InconsistentSharesError
+			if !errors.As(err, &inconsistent) {
+				t.Fatalf("expected InconsistentSharesError, got %v", err)
+			}
+			if len(inconsistent.Suspects) != 1 || inconsistent.Suspects[0] != bad {
+				t.Errorf("bad share %d: got suspects %v", bad, inconsistent.Suspects)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("share %d", bad+1)) {
+				t.Errorf("error should name share %d: %v", bad+1, err)
+			}
+		}
+	})
+
+	t.Run("exactly threshold", func(t *testing.T) {
+		recovered, err := CombineChecked(shares[:3], 3)
+		if err != nil || !bytes.Equal(recovered, secret) {
+			t.Fatalf("got %q, %v", recovered, err)
+		}
+	})
+
+	t.Run("too few", func(t *testing.T) {
+		if _, err := CombineChecked(shares[:2], 3); err == nil {
+			t.Error("expected error with fewer than threshold shares")
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := CombineChecked([][]byte{shares[0], shares[1], shares[0]}, 3)
+		if err == nil {
+			t.Fatal("expected error with duplicate shares")
+		}
+		var dup *DuplicateSharesError
+		if !errors.As(err, &dup) {
+			t.Fatalf("expected DuplicateSharesError, got %v", err)
+		}
+		if dup.First != 0 || dup.Second != 2 {
+			t.Errorf("duplicate positions = %d, %d, want 0, 2", dup.First, dup.Second)
+		}
+
+		if _, err := Combine([][]byte{shares[1], shares[1]}); !errors.As(err, &dup) {
+			t.Errorf("Combine: expected DuplicateSharesError, got %v", err)
+		}
+	})
+}
+
+func TestEvaluateAt(t *testing.T) {
+	// Evaluating at 0 is plain Combine, and evaluating a quorum at another
+	// share's x-coordinate must give back that share.
+	secret := []byte{0x00, 0x01, 0x7f, 0x80, 0xff}
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quorum := [][]byte{shares[0], shares[2], shares[4]}
+	if got, err := evaluateAt(quorum, 0); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("at 0: got %x, %v, want %x", got, err, secret)
+	}
+	for _, s := range shares {
+		if got, err := evaluateAt(quorum, s[5]); err != nil || !bytes.Equal(got, s[:5]) {
+			t.Errorf("at x=%d: got %x, %v, want %x", s[5], got, err, s[:5])
+		}
+	}
+}
+
+func TestReconstructShare(t *testing.T) {
+	secret := []byte("reconstruct-me")
+	shares, err := Split(secret, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rebuilding share 4 from shares 1-3 at its own x must reproduce it
+	x := shares[3][len(shares[3])-1]
+	rebuilt, err := ReconstructShare(shares[:3], 3, x)
+	if err != nil {
+		t.Fatalf("reconstruct: %v", err)
+	}
+	if !bytes.Equal(rebuilt, shares[3]) {
+		t.Error("reconstructed share differs from the original")
+	}
+
+	// A rebuilt share combines with the others
+	recovered, err := Combine([][]byte{shares[0], shares[1], rebuilt})
+	if err != nil || !bytes.Equal(recovered, secret) {
+		t.Errorf("combine with rebuilt share: %q, %v", recovered, err)
+	}
+
+	if _, err := ReconstructShare(shares[:2], 3, x); err == nil {
+		t.Error("expected error with fewer than threshold shares")
+	}
+	if _, err := ReconstructShare(shares[:3], 3, 0); err == nil {
+		t.Error("expected error for x=0")
+	}
+}
+
+func TestEvaluateShareAt(t *testing.T) {
+	secret := []byte("evaluate-me-at-seven")
+	all, err := Split(secret, 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vault's x-coordinates are random, so keep 7 free for the new share.
+	var shares [][]byte
+	for _, s := range all {
+		if s[len(s)-1] != 7 {
+			shares = append(shares, s)
+		}
+	}
+
+	extra, err := EvaluateShareAt(shares[:3], 7)
+	if err != nil {
+		t.Fatalf("EvaluateShareAt: %v", err)
+	}
+	if x := extra[len(extra)-1]; x != 7 {
+		t.Errorf("new share is at x=%d, want 7", x)
+	}
+	recovered, err := Combine([][]byte{shares[3], shares[4], extra})
+	if err != nil || !bytes.Equal(recovered, secret) {
+		t.Errorf("combine with the new share: %q, %v", recovered, err)
+	}
+	if _, err := CombineChecked([][]byte{shares[0], extra, shares[3], shares[4]}, 3); err != nil {
+		t.Errorf("new share should be consistent with the others: %v", err)
+	}
+
+	taken := int(shares[0][len(shares[0])-1])
+	if _, err := EvaluateShareAt(shares[:3], taken); err == nil {
+		t.Errorf("expected error for x=%d, already used by share 1", taken)
+	}
+	for _, x := range []int{0, 256} {
+		if _, err := EvaluateShareAt(shares[:3], x); err == nil {
+			t.Errorf("expected error for x=%d", x)
+		}
+	}
+	if _, err := EvaluateShareAt(shares[:1], 7); err == nil {
+		t.Error("expected error with a single share")
+	}
+}
+
+func TestShareAge(t *testing.T) {
+	share := NewShare(2, 1, 3, 2, "Alice", []byte("data"))
+	share.Created = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	now := share.Created.Add(48 * time.Hour)
+	if got := ShareAge(share, now); got != 48*time.Hour {
+		t.Errorf("got %v, want 48h", got)
+	}
+	if got := ShareAge(share, share.Created.Add(-time.Hour)); got != 0 {
+		t.Errorf("future creation time should give 0, got %v", got)
+	}
+	share.Created = time.Time{}
+	if got := ShareAge(share, now); got != 0 {
+		t.Errorf("unknown creation time should give 0, got %v", got)
+	}
+}
+
+func TestCheckShareHealth(t *testing.T) {
+	shares, err := Split([]byte("the boat key is in the blue jar"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newShare := func() *Share {
+		s := NewShare(2, 1, 3, 2, "Alice", shares[0])
+		s.Created = created
+		return s
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		got := CheckShareHealth(newShare(), created.AddDate(0, 6, 0))
+		want := ShareHealth{ChecksumOK: true, Fresh: true, Score: 100}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		got := CheckShareHealth(newShare(), created.AddDate(3, 0, 0))
+		want := ShareHealth{ChecksumOK: true, Fresh: false, Score: HealthScoreStale}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("unknown creation time", func(t *testing.T) {
+		s := newShare()
+		s.Created = time.Time{}
+		if got := CheckShareHealth(s, created.AddDate(10, 0, 0)); !got.Fresh || got.Score != 100 {
+			t.Errorf("got %+v, want fresh with score 100", got)
+		}
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		s := newShare()
+		s.Data = append([]byte(nil), s.Data...)
+		s.Data[0] ^= 0xff
+		got := CheckShareHealth(s, created)
+		// Fresh, but it won't combine.
+		want := ShareHealth{ChecksumOK: false, Fresh: true, Score: HealthScoreBad}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Fluffy", "fluffy"},
+		{"  Mr.   Whiskers \n", "mr. whiskers"},
+		{"MAPLE\tStreet", "maple street"},
+		{"Ñandú", "ñandú"},
+		{"N\u0303andu\u0301", "ñandú"}, // decomposed (NFD), as some keyboards type it
+		{"Ame\u0301lie  Poulain", "amélie poulain"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.in); got != tt.want {
+			t.Errorf("NormalizeAnswer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncryptDeterministic(t *testing.T) {
+	passphrase := "test-passphrase-12345"
+	fileKey := bytes.Repeat([]byte{0x11}, 16)
+	salt := bytes.Repeat([]byte{0x22}, 16)
+	encrypt := func(data []byte, fileKey, salt []byte) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := EncryptDeterministic(&buf, bytes.NewReader(data), passphrase, fileKey, salt); err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	data := []byte("the boat key is in the blue jar")
+	first := encrypt(data, fileKey, salt)
+	if second := encrypt(data, fileKey, salt); !bytes.Equal(first, second) {
+		t.Fatal("two encryptions of the same input differ")
+	}
+	// Pinned, so a change to the framing shows up here.
+	if got := HashBytes(first); got != "sha256:cb0e599effccb9033cb3996dda6457878803b713cf18654b2fff4ad0becdda17" {
+		t.Errorf("ciphertext hash = %s", got)
+	}
+
+	// Around the 64 KiB chunk boundary, where the last-chunk flag moves.
+	for _, size := range []int{0, len(data), ageChunkSize, ageChunkSize + 1, 2*ageChunkSize + 7} {
+		plain := bytes.Repeat([]byte("x"), size)
+		encrypted := encrypt(plain, fileKey, salt)
+		if int64(len(encrypted)) != EncryptedSize(int64(size)) {
+			t.Errorf("size %d: %d bytes, EncryptedSize says %d", size, len(encrypted), EncryptedSize(int64(size)))
+		}
+		var decrypted bytes.Buffer
+		if err := Decrypt(&decrypted, bytes.NewReader(encrypted), passphrase); err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if !bytes.Equal(decrypted.Bytes(), plain) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+
+	if bytes.Equal(encrypt(data, bytes.Repeat([]byte{0x33}, 16), salt), first) {
+		t.Error("a different file key gave the same ciphertext")
+	}
+
+	var buf bytes.Buffer
+	if err := EncryptDeterministic(&buf, bytes.NewReader(data), passphrase, fileKey[:8], salt); err == nil {
+		t.Error("expected error for a short file key")
+	}
+	if err := EncryptDeterministic(&buf, bytes.NewReader(data), passphrase, fileKey, salt[:8]); err == nil {
+		t.Error("expected error for a short salt")
+	}
+	if err := EncryptDeterministic(&buf, bytes.NewReader(data), "", fileKey, salt); err != ErrEmptyPassphrase {
+		t.Errorf("empty passphrase: got %v", err)
+	}
+}
+
+func TestEncryptWithAnswer(t *testing.T) {
+	data := []byte("secret data")
+	secret := []byte("random-passphrase-bytes")
+	passphrase := RecoverPassphrase(secret, 2)
+
+	var encrypted bytes.Buffer
+	if err := EncryptWithAnswer(&encrypted, bytes.NewReader(data), passphrase, "Mr. Whiskers"); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	// Every piece, so the quorum is never what's missing.
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	recovered, err := Combine(shares)
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	rebuilt := RecoverPassphrase(recovered, 2)
+
+	t.Run("right answer, typed differently", func(t *testing.T) {
+		got, err := DecryptBytesWithAnswer(encrypted.Bytes(), rebuilt, "  mr.  WHISKERS ")
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("got %q, want %q", got, data)
+		}
+	})
+
+	t.Run("wrong answer", func(t *testing.T) {
+		if _, err := DecryptBytesWithAnswer(encrypted.Bytes(), rebuilt, "Fluffy"); err == nil {
+			t.Error("expected error with wrong answer")
+		}
+	})
+
+	t.Run("no answer", func(t *testing.T) {
+		if err := DecryptWithAnswer(io.Discard, bytes.NewReader(encrypted.Bytes()), rebuilt, " "); !errors.Is(err, ErrEmptyAnswer) {
+			t.Errorf("got %v, want ErrEmptyAnswer", err)
+		}
+		if _, err := DecryptBytes(encrypted.Bytes(), rebuilt); err == nil {
+			t.Error("expected error decrypting without the answer")
+		}
+	})
+
+	t.Run("answer without pieces", func(t *testing.T) {
+		if _, err := DecryptBytes(encrypted.Bytes(), NormalizeAnswer("Mr. Whiskers")); err == nil {
+			t.Error("expected error decrypting with the answer alone")
+		}
+	})
+}
+
+func TestRemapIndices(t *testing.T) {
+	secret := []byte("raw-passphrase-bytes-for-remap!!")
+	newShares := func(t *testing.T) []*Share {
+		t.Helper()
+		data, err := Split(secret, 4, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares := make([]*Share, len(data))
+		for i, d := range data {
+			shares[i] = NewShare(2, i+1, 4, 2, fmt.Sprintf("holder%d", i+1), d)
+		}
+		return shares
+	}
+
+	t.Run("swap", func(t *testing.T) {
+		shares := newShares(t)
+		before, err := shares[0].Words()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RemapIndices(shares, map[int]int{1: 2, 2: 1}); err != nil {
+			t.Fatalf("RemapIndices: %v", err)
+		}
+		if shares[0].Index != 2 || shares[1].Index != 1 || shares[2].Index != 3 {
+			t.Errorf("indices = %d, %d, %d; want 2, 1, 3", shares[0].Index, shares[1].Index, shares[2].Index)
+		}
+
+		// The 25th word follows the new index; the data words don't change.
+		after, err := shares[0].Words()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(before[:24], after[:24]) || before[24] == after[24] {
+			t.Error("only the 25th word should change")
+		}
+		if _, idx, err := DecodeShareWords(after); err != nil || idx != 2 {
+			t.Errorf("words decode to index %d (%v), want 2", idx, err)
+		}
+		parsed, err := ParseCompact(shares[0].CompactEncode())
+		if err != nil || parsed.Index != 2 {
+			t.Errorf("compact encoding gives index %v (%v), want 2", parsed, err)
+		}
+
+		got, err := Combine([][]byte{shares[0].Data, shares[1].Data})
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Errorf("remapped shares should still combine, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		shares := newShares(t)
+		err := RemapIndices(shares, map[int]int{1: 3})
+		if err == nil || !strings.Contains(err.Error(), "index 3") {
+			t.Fatalf("expected a collision on index 3, got %v", err)
+		}
+		for i, s := range shares {
+			if s.Index != i+1 {
+				t.Errorf("share %d changed to index %d despite the error", i+1, s.Index)
+			}
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			mapping map[int]int
+			mutate  func([]*Share)
+		}{
+			{"missing share", map[int]int{9: 1}, nil},
+			{"out of range", map[int]int{1: 5}, nil},
+			{"two onto one", map[int]int{1: 4, 2: 4}, nil},
+			{"index tied to data", map[int]int{1: 4}, func(s []*Share) { s[0].Version = 3 }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				shares := newShares(t)
+				if tt.mutate != nil {
+					tt.mutate(shares)
 				}
-				if err != nil && !strings.Contains(err.Error(), "invalid path") {
-					t.Errorf("expected 'invalid path' error for %q, got: %v", tt.entry, err)
+				if err := RemapIndices(shares, tt.mapping); err == nil {
+					t.Error("expected an error")
 				}
 			})
 		}
 	})
+}
 
-	t.Run("accepted paths", func(t *testing.T) {
-		entries := map[string]string{
-			"safe/file.txt":        "hello",
-			"safe/nested/deep.txt": "world",
+func TestEncryptedSize(t *testing.T) {
+	for _, n := range []int{0, 1, 64 * 1024, 64*1024 + 1, 200_000} {
+		var buf bytes.Buffer
+		if err := Encrypt(&buf, bytes.NewReader(make([]byte, n)), "passphrase"); err != nil {
+			t.Fatalf("Encrypt(%d bytes): %v", n, err)
 		}
-		data := createTarGz(t, entries)
-		files, err := ExtractTarGz(data)
-		if err != nil {
-			t.Fatalf("unexpected error for safe paths: %v", err)
+		if got, want := EncryptedSize(int64(n)), int64(buf.Len()); got != want {
+			t.Errorf("EncryptedSize(%d) = %d, want %d", n, got, want)
 		}
+	}
+}
 
-		extracted := make(map[string]string)
-		for _, f := range files {
-			extracted[f.Name] = string(f.Data)
+func TestSplitWeighted(t *testing.T) {
+	secret := []byte("correct-horse-battery-staple")
+	weights := map[string]int{"Lawyer": 2, "Alice": 1, "Bob": 1, "Carol": 1}
+
+	shares, err := SplitWeighted(secret, weights, 3)
+	if err != nil {
+		t.Fatalf("SplitWeighted: %v", err)
+	}
+	for holder, w := range weights {
+		if len(shares[holder]) != w {
+			t.Errorf("%s: got %d points, want %d", holder, len(shares[holder]), w)
 		}
+	}
 
-		for name, want := range entries {
-			got, ok := extracted[name]
-			if !ok {
-				t.Errorf("missing extracted file %q", name)
-				continue
-			}
-			if got != want {
-				t.Errorf("file %q: got %q, want %q", name, got, want)
-			}
+	// The lawyer (2) and Alice (1) meet the threshold of 3.
+	points := append(append([][]byte{}, shares["Lawyer"]...), shares["Alice"]...)
+	recovered, err := Combine(points)
+	if err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Errorf("lawyer + Alice: got %q, want %q", recovered, secret)
+	}
+
+	// So do three weight-1 friends.
+	points = [][]byte{shares["Alice"][0], shares["Bob"][0], shares["Carol"][0]}
+	if recovered, err := Combine(points); err != nil || !bytes.Equal(recovered, secret) {
+		t.Errorf("Alice + Bob + Carol: got %q, %v", recovered, err)
+	}
+
+	// The lawyer alone falls short.
+	if recovered, err := Combine(shares["Lawyer"]); err == nil && bytes.Equal(recovered, secret) {
+		t.Error("lawyer alone should not recover the secret")
+	}
+
+	// Extra points are checked like any others.
+	points = append(append([][]byte{}, shares["Lawyer"]...), shares["Alice"][0], shares["Bob"][0])
+	if recovered, err := CombineChecked(points, 3); err != nil || !bytes.Equal(recovered, secret) {
+		t.Errorf("CombineChecked: got %q, %v", recovered, err)
+	}
+
+	bad := []struct {
+		name      string
+		weights   map[string]int
+		threshold int
+	}{
+		{"one holder", map[string]int{"Lawyer": 3}, 2},
+		{"zero weight", map[string]int{"Lawyer": 0, "Alice": 1}, 2},
+		{"threshold above total", map[string]int{"Lawyer": 2, "Alice": 1}, 4},
+		{"holder reaches threshold alone", map[string]int{"Lawyer": 3, "Alice": 1, "Bob": 1}, 3},
+		{"too many points", map[string]int{"Lawyer": 200, "Alice": 100}, 250},
+	}
+	for _, tt := range bad {
+		if _, err := SplitWeighted(secret, tt.weights, tt.threshold); err == nil {
+			t.Errorf("%s: expected error", tt.name)
 		}
-	})
+	}
+}
 
-	t.Run("empty input", func(t *testing.T) {
-		_, err := ExtractTarGz([]byte{})
-		if err == nil {
-			t.Error("expected error for empty input")
+func TestCanDecrypt(t *testing.T) {
+	var encrypted bytes.Buffer
+	if err := Encrypt(&encrypted, strings.NewReader("the boat key is in the blue jar"), "passphrase"); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	data := encrypted.Bytes()
+
+	if err := CanDecrypt(bytes.NewReader(data), "passphrase"); err != nil {
+		t.Errorf("right passphrase: %v", err)
+	}
+	if err := CanDecrypt(bytes.NewReader(data), "wrong"); err == nil {
+		t.Error("wrong passphrase should fail")
+	}
+	if err := CanDecrypt(bytes.NewReader(data[:len(data)-1]), "passphrase"); err == nil {
+		t.Error("truncated payload should fail")
+	}
+}
+
+func TestDecryptContext(t *testing.T) {
+	var encrypted bytes.Buffer
+	if err := Encrypt(&encrypted, strings.NewReader("the boat key is in the blue jar"), "passphrase"); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	data := encrypted.Bytes()
+
+	var out bytes.Buffer
+	if err := DecryptContext(context.Background(), &out, bytes.NewReader(data), "passphrase"); err != nil {
+		t.Fatalf("DecryptContext: %v", err)
+	}
+	if out.String() != "the boat key is in the blue jar" {
+		t.Errorf("decrypted %q", out.String())
+	}
+
+	// A context cancelled while src is stuck returns at once, without
+	// waiting for the read.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stuck := &blockingReader{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(stuck.release)
+	out.Reset()
+	result := make(chan error, 1)
+	go func() {
+		result <- DecryptContext(ctx, &out, stuck, "passphrase")
+	}()
+	<-stuck.started
+	cancel()
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("cancelled: got %v, want context.Canceled", err)
 		}
-	})
+	case <-time.After(10 * time.Second):
+		t.Fatal("DecryptContext didn't return after cancel while src was blocked")
+	}
+	if out.Len() != 0 {
+		t.Errorf("cancelled decrypt wrote %d bytes", out.Len())
+	}
+
+	// An already cancelled context doesn't start at all.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	src := bytes.NewReader(data)
+	if err := DecryptContext(ctx, &out, src, "passphrase"); !errors.Is(err, context.Canceled) {
+		t.Errorf("already cancelled: got %v, want context.Canceled", err)
+	}
+	if src.Len() != len(data) {
+		t.Error("already cancelled decrypt read its input")
+	}
 }
 
-func TestSanitizeFilename(t *testing.T) {
+// blockingReader is a src whose first Read blocks until release is closed.
+// started is closed once that Read is under way.
+type blockingReader struct {
+	started, release chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	close(r.started)
+	<-r.release
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestNewEscrowShare(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a}, 32)
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	shares, err := SplitToShares(secret, 5, 3, []string{"Alice", "Bob", "Carol", "Dave", "Eve"}, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := NewCommitment(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range shares {
+		s.Headers = map[string]string{CommitmentHeader: commitment}
+	}
+
+	escrow, err := NewEscrowShare(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if escrow.Index != 6 || escrow.Holder != EscrowHolder || !escrow.IsEscrow() {
+		t.Errorf("escrow share = index %d, holder %q, escrow %v", escrow.Index, escrow.Holder, escrow.IsEscrow())
+	}
+	if escrow.Fingerprint() != shares[0].Fingerprint() || escrow.Headers[CommitmentHeader] != commitment {
+		t.Error("escrow share isn't marked as part of the set")
+	}
+	for _, s := range shares {
+		if s.XCoordinate() == escrow.XCoordinate() {
+			t.Fatalf("escrow share reuses x-coordinate %d", s.XCoordinate())
+		}
+	}
+
+	// It survives encoding, and still validates past the friends' indices.
+	parsed, err := ParseShare([]byte(escrow.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("escrow share doesn't validate: %v", err)
+	}
+	if !parsed.IsEscrow() {
+		t.Error("escrow header lost in encoding")
+	}
+	plain := parsed.Clone()
+	delete(plain.Headers, EscrowHeader)
+	if err := plain.Validate(); err == nil {
+		t.Error("index past total validated without the escrow header")
+	}
+
+	// Escrow and any threshold-1 of the others recover the secret.
+	for _, combo := range combinations(len(shares), 2) {
+		quorum := [][]byte{parsed.Data, shares[combo[0]].Data, shares[combo[1]].Data}
+		got, err := Combine(quorum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyReconstructedSecret(got, commitment); err != nil {
+			t.Errorf("escrow with shares %v: %v", combo, err)
+		}
+	}
+
+	if _, err := NewEscrowShare(shares[:3]); err == nil {
+		t.Error("expected an error with only a quorum of the set")
+	}
+}
+
+func TestIsLikelyText(t *testing.T) {
+	// A multi-byte character cut off at the end of the sample.
+	longText := append(bytes.Repeat([]byte("a"), TextSampleSize-1), "é"...)
+
 	tests := []struct {
-		input    string
-		expected string
+		name string
+		data []byte
+		want bool
 	}{
-		{"Alice", "alice"},
-		{"Bob Smith", "bob-smith"},
-		{"Carol!", "carol"},
-		{"test@user.com", "testusercom"},
-		{"file/path", "filepath"},
-		// NFD transliteration: accented chars → ASCII base
-		{"José", "jose"},
-		{"Ñoño", "nono"},
-		{"Müller", "muller"},
-		// Underscore → hyphen
-		{"bob_smith", "bob-smith"},
-		// Hyphen trimming and collapsing
-		{"---hyphens---", "hyphens"},
-		{"a--b", "a-b"},
-		// Leading/trailing spaces
-		{"  Alice  ", "alice"},
-		// Path traversal chars stripped
-		{"../etc/passwd", "etcpasswd"},
-		// Non-ASCII input that sanitizes to empty
-		{"日本語", ""},
-		{"!!!???", ""},
-		// Empty input
-		{"", ""},
+		{"empty", nil, true},
+		{"ascii", []byte("bank: hunter2\nemail: me@example.com\n"), true},
+		{"utf-8", []byte("contraseña: ñandú 🔑\r\n\tnotes\f"), true},
+		{"cut at sample", longText, true},
+		{"one stray control", append(bytes.Repeat([]byte("text "), 40), 0x07), true},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), false},
+		{"nul", []byte("looks like text\x00but isn't"), false},
+		{"latin-1", []byte("contrase\xf1a"), false},
+		{"utf-16", []byte("\xff\xfeh\x00i\x00"), false},
+		{"controls", bytes.Repeat([]byte{0x01, 0x02, 'a'}, 20), false},
+	}
+	for _, tt := range tests {
+		if got := IsLikelyText(tt.data); got != tt.want {
+			t.Errorf("%s: IsLikelyText = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCombineVerbose(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x77}, 32)
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, residuals, err := CombineVerbose(shares, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Error("wrong secret from good shares")
+	}
+	if !bytes.Equal(residuals, make([]byte, 5)) {
+		t.Errorf("residuals = %v, want all zero", residuals)
+	}
+
+	// Share 1 is off the curve in two bytes. It is among the first three,
+	// so a naive interpolation through those would blame the others.
+	shares[1] = bytes.Clone(shares[1])
+	shares[1][4] ^= 0x10
+	shares[1][20] ^= 0x01
+	got, residuals, err = CombineVerbose(shares, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Error("wrong secret with one corrupt share")
+	}
+	if !bytes.Equal(residuals, []byte{0, 2, 0, 0, 0}) {
+		t.Errorf("residuals = %v, want [0 2 0 0 0]", residuals)
 	}
 
+	// With exactly the threshold there is nothing to check against.
+	if _, residuals, err = CombineVerbose(shares[:3], 3); err != nil || !bytes.Equal(residuals, make([]byte, 3)) {
+		t.Errorf("threshold shares: residuals %v, err %v", residuals, err)
+	}
+
+	// One extra share, and it disagrees: no polynomial has more support
+	// than another, so the bad share can't be told apart.
+	var inconsistent *InconsistentSharesError
+	if _, _, err := CombineVerbose(shares[:4], 3); !errors.As(err, &inconsistent) {
+		t.Errorf("expected InconsistentSharesError, got %v", err)
+	}
+}
+
+func TestCheckVersionCompat(t *testing.T) {
+	tests := []struct {
+		required, current string
+		wantTooOld        bool
+	}{
+		{"v0.0.12", "v0.0.12", false},            // equal
+		{"v0.0.12", "v0.0.13", false},            // newer patch
+		{"v0.0.12", "v1.0.0", false},             // newer major
+		{"v0.1.0", "v0.0.99", true},              // older minor
+		{"v0.0.12", "v0.0.11", true},             // older patch
+		{"0.0.12", "v0.0.12", false},             // "v" is optional
+		{"v0.0.12", "v0.0.12-3-gabc1234", false}, // build between releases
+		{"v0.0.13", "v0.0.12-3-gabc1234", true},
+		{"v0.0.12", "v0.0.10", true}, // compared as numbers, not text
+		{"v0.0.12", "dev", false},    // can't tell, so allowed
+		{"", "v0.0.1", false},        // nothing required
+	}
 	for _, tt := range tests {
-		got := SanitizeFilename(tt.input)
-		if got != tt.expected {
-			t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, got, tt.expected)
+		err := CheckVersionCompat(tt.required, tt.current)
+		var tooOld *VersionTooOldError
+		if got := errors.As(err, &tooOld); got != tt.wantTooOld || (!got && err != nil) {
+			t.Errorf("CheckVersionCompat(%q, %q) = %v, want too old: %v", tt.required, tt.current, err, tt.wantTooOld)
 		}
 	}
+
+	if err := CheckVersionCompat("latest", "v0.0.12"); err == nil {
+		t.Error("expected an error for an unreadable required version")
+	}
+
+	shares := []*Share{
+		{Headers: map[string]string{RequiresVersionHeader: "v0.0.9"}},
+		{},
+		{Headers: map[string]string{RequiresVersionHeader: "v0.0.12"}},
+	}
+	if got := RequiredVersion(shares); got != "v0.0.12" {
+		t.Errorf("RequiredVersion = %q, want the newest, v0.0.12", got)
+	}
 }
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// EscrowHeader marks an escrow share (see NewEscrowShare). Its value is
+// always "yes".
+const EscrowHeader = "Escrow"
+
+// EscrowHolder is the holder name of an escrow share.
+const EscrowHolder = "Escrow"
+
+// NewEscrowShare returns one more share of the set that shares were split
+// into, for the owner to keep in escrow (with a lawyer, say) instead of
+// giving it to a friend. It is a full piece: Combine treats it like any
+// other point, so the escrow share and threshold-1 of the friends' shares
+// recover the secret.
+//
+// The share gets index Total+1, past the friends' shares, holder
+// EscrowHolder and the EscrowHeader. Its version, total, threshold, created
+// time, commitment and required version are those of the set. It lies at
+// an x-coordinate no share of the set uses, so every share of the set must
+// be given, not just a quorum.
+func NewEscrowShare(shares []*Share) (*Share, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares provided")
+	}
+	first := shares[0]
+	if len(shares) != first.Total {
+		return nil, fmt.Errorf("need all %d shares of the set, got %d", first.Total, len(shares))
+	}
+	if first.Total >= 255 {
+		return nil, fmt.Errorf("a set of %d shares has no room for another", first.Total)
+	}
+
+	data := make([][]byte, len(shares))
+	used := make(map[byte]bool, len(shares))
+	for i, s := range shares {
+		if s.Total != first.Total || s.Threshold != first.Threshold || s.Version != first.Version {
+			return nil, fmt.Errorf("share %d is from a different set", i+1)
+		}
+		data[i] = s.Data
+		used[s.XCoordinate()] = true
+	}
+
+	free := make([]int, 0, 255-len(used))
+	for x := 1; x <= 255; x++ {
+		if !used[byte(x)] {
+			free = append(free, x)
+		}
+	}
+	pick, err := rand.Int(rand.Reader, big.NewInt(int64(len(free))))
+	if err != nil {
+		return nil, fmt.Errorf("picking x-coordinate: %w", err)
+	}
+	point, err := EvaluateShareAt(data[:first.Threshold], free[pick.Int64()])
+	if err != nil {
+		return nil, err
+	}
+
+	escrow := NewShare(first.Version, first.Total+1, first.Total, first.Threshold, EscrowHolder, point)
+	escrow.Created = first.Created
+	escrow.Headers = map[string]string{EscrowHeader: "yes"}
+	for _, key := range []string{CommitmentHeader, RequiresVersionHeader} {
+		if v := first.Headers[key]; v != "" {
+			escrow.Headers[key] = v
+		}
+	}
+	return escrow, nil
+}
+
+// IsEscrow reports whether s is an escrow share, from NewEscrowShare.
+func (s *Share) IsEscrow() bool {
+	return s.Headers[EscrowHeader] == "yes"
+}
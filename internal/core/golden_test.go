@@ -104,27 +104,6 @@ func parseCreatedTime(t *testing.T, s string) time.Time {
 	return time.Time{}
 }
 
-// combinations returns all k-element subsets of {0, 1, ..., n-1}.
-func combinations(n, k int) [][]int {
-	var result [][]int
-	combo := make([]int, k)
-	var gen func(start, depth int)
-	gen = func(start, depth int) {
-		if depth == k {
-			dup := make([]int, k)
-			copy(dup, combo)
-			result = append(result, dup)
-			return
-		}
-		for i := start; i < n; i++ {
-			combo[depth] = i
-			gen(i+1, depth+1)
-		}
-	}
-	gen(0, 0)
-	return result
-}
-
 // --- Generator ---
 
 // TestGenerateGoldenFixtures generates v2 golden test fixtures.
@@ -354,6 +333,66 @@ func TestGoldenShareParsing(t *testing.T) {
 	}
 }
 
+// TestGoldenShareParsingWhitespace parses the golden PEM shares after
+// spaces have been added to them, as a messaging app reflowing a pasted
+// block might.
+func TestGoldenShareParsingWhitespace(t *testing.T) {
+	golden := loadGoldenJSON(t, "v2-golden.json")
+	for _, gs := range golden.Shares {
+		t.Run(gs.Holder, func(t *testing.T) {
+			lines := strings.Split(strings.TrimSuffix(gs.PEM, "\n"), "\n")
+			data := lines[len(lines)-2]
+			want := mustDecodeHex(t, gs.DataHex)
+
+			mangle := map[string]func(string) string{
+				"leading and trailing": func(s string) string { return "   " + s + " \t" },
+				"internal":             func(s string) string { return s[:8] + " " + s[8:20] + "  " + s[20:] },
+				"every four":           func(s string) string { return spaceEvery(s, 4, " ") },
+				"split lines":          func(s string) string { return s[:10] + "  \n " + s[10:] },
+				"no-break spaces":      func(s string) string { return spaceEvery(s, 11, "\u00a0") },
+			}
+			for name, f := range mangle {
+				t.Run(name, func(t *testing.T) {
+					mangled := strings.Join(lines[:len(lines)-2], "\n") + "\n" + f(data) + "\n" + lines[len(lines)-1] + "\n"
+					share, err := ParseShare([]byte(mangled))
+					if err != nil {
+						t.Fatalf("ParseShare: %v\n%s", err, mangled)
+					}
+					if !bytes.Equal(share.Data, want) {
+						t.Errorf("data: got %x, want %s", share.Data, gs.DataHex)
+					}
+					if err := share.Verify(); err != nil {
+						t.Errorf("Verify: %v", err)
+					}
+				})
+			}
+		})
+	}
+
+	// The markers themselves must still be intact.
+	pem := golden.Shares[0].PEM
+	for _, bad := range []string{
+		strings.Replace(pem, "-----BEGIN REMEMORY SHARE-----", "-----BEGIN REMEMORY  SHARE-----", 1),
+		strings.Replace(pem, "-----END REMEMORY SHARE-----", "----- END REMEMORY SHARE-----", 1),
+	} {
+		if _, err := ParseShare([]byte(bad)); err == nil {
+			t.Errorf("ParseShare accepted a broken marker:\n%s", bad)
+		}
+	}
+}
+
+// spaceEvery inserts sep after every n bytes of s.
+func spaceEvery(s string, n int, sep string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i += n {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(s[i:min(i+n, len(s))])
+	}
+	return b.String()
+}
+
 // TestGoldenCombine combines threshold shares and verifies the passphrase.
 func TestGoldenCombine(t *testing.T) {
 	for _, ver := range goldenVersions {
@@ -562,6 +601,72 @@ func TestGoldenDecrypt(t *testing.T) {
 	}
 }
 
+// TestGoldenRotateManifest rotates each golden manifest to new content under
+// a new passphrase, and checks the new shares unlock it.
+func TestGoldenRotateManifest(t *testing.T) {
+	newPlaintext := createTarGz(t, map[string]string{
+		"manifest/secret.txt": "The new passphrase is: rotated-horse-battery-staple\n",
+	})
+
+	for _, ver := range goldenVersions {
+		t.Run(ver.name, func(t *testing.T) {
+			golden := loadGoldenJSON(t, ver.fixture)
+
+			var shareData [][]byte
+			for _, name := range []string{"alice", "bob", "carol"} {
+				pemData, err := os.ReadFile(filepath.Join("testdata", ver.bundleDir, fmt.Sprintf("SHARE-%s.txt", name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				share, err := ParseShare(pemData)
+				if err != nil {
+					t.Fatalf("ParseShare(%s): %v", name, err)
+				}
+				shareData = append(shareData, share.Data)
+			}
+			manifestAge, err := os.ReadFile(filepath.Join("testdata", ver.bundleDir, "MANIFEST.age"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, newShares, err := RotateManifest(shareData, bytes.NewReader(manifestAge), bytes.NewReader(newPlaintext), 4, 2)
+			if err != nil {
+				t.Fatalf("RotateManifest: %v", err)
+			}
+			if len(newShares) != 4 {
+				t.Fatalf("got %d new shares, want 4", len(newShares))
+			}
+
+			secret, err := Combine(newShares[2:])
+			if err != nil {
+				t.Fatalf("Combine: %v", err)
+			}
+			passphrase := RecoverPassphrase(secret, 2)
+			if passphrase == golden.Passphrase {
+				t.Fatal("the new passphrase is the old one")
+			}
+			var decrypted bytes.Buffer
+			if err := Decrypt(&decrypted, bytes.NewReader(ciphertext), passphrase); err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if !bytes.Equal(decrypted.Bytes(), newPlaintext) {
+				t.Error("the new manifest doesn't decrypt to the new content")
+			}
+
+			// Shares that don't unlock the old manifest make nothing new.
+			bad := [][]byte{shareData[0], bytes.Clone(shareData[1]), shareData[2]}
+			bad[1][len(bad[1])-1] ^= 0xff
+			ciphertext, newShares, err = RotateManifest(bad, bytes.NewReader(manifestAge), bytes.NewReader(newPlaintext), 4, 2)
+			if err == nil || !strings.Contains(err.Error(), "don't unlock the old manifest") {
+				t.Errorf("damaged share: got %v, want an error about the old manifest", err)
+			}
+			if ciphertext != nil || newShares != nil {
+				t.Error("damaged share still produced a new manifest")
+			}
+		})
+	}
+}
+
 // TestGoldenV2WordEncoding tests word encoding round-trips against golden fixtures.
 // Words are 25 words: 24 data words + 1 index word.
 func TestGoldenV2WordEncoding(t *testing.T) {
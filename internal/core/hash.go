@@ -6,6 +6,10 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
 )
 
 // HashString returns the SHA-256 hash of a string, prefixed with "sha256:".
@@ -24,3 +28,69 @@ func HashBytes(b []byte) string {
 func VerifyHash(got, expected string) bool {
 	return subtle.ConstantTimeCompare([]byte(got), []byte(expected)) == 1
 }
+
+// FormatChecksumGrouped formats the first 128 bits of a checksum
+// ("sha256:..." or bare hex) as 8 groups of 4 hex characters, like
+// "3f2a 9c01 ...", short enough to read over the phone and compare with the
+// start of the full checksum. Input that isn't long enough is grouped as is.
+func FormatChecksumGrouped(sum string) string {
+	hexSum := strings.ToLower(strings.TrimPrefix(sum, "sha256:"))
+	if len(hexSum) > 32 {
+		hexSum = hexSum[:32]
+	}
+	groups := make([]string, 0, 8)
+	for len(hexSum) > 4 {
+		groups = append(groups, hexSum[:4])
+		hexSum = hexSum[4:]
+	}
+	if hexSum != "" {
+		groups = append(groups, hexSum)
+	}
+	return strings.Join(groups, " ")
+}
+
+// ChecksumError reports data whose SHA-256 differs from the expected value.
+type ChecksumError struct {
+	Expected string
+	Got      string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: expected %s, got %s", e.Expected, e.Got)
+}
+
+// NewVerifyingReader returns a reader that passes r through unchanged while
+// hashing it, so a file can be checked against its recorded checksum
+// ("sha256:...") without holding all of it in memory. After the returned
+// reader has been read to EOF, check reports a *ChecksumError if the data
+// didn't match. Calling check before EOF is an error too: a partly read
+// file hasn't been verified.
+func NewVerifyingReader(r io.Reader, expected string) (io.Reader, func() error) {
+	v := &verifyingReader{r: r, h: sha256.New()}
+	check := func() error {
+		if !v.eof {
+			return fmt.Errorf("checksum not verified: data was not read to the end")
+		}
+		got := "sha256:" + hex.EncodeToString(v.h.Sum(nil))
+		if !VerifyHash(got, expected) {
+			return &ChecksumError{Expected: expected, Got: got}
+		}
+		return nil
+	}
+	return v, check
+}
+
+type verifyingReader struct {
+	r   io.Reader
+	h   hash.Hash
+	eof bool
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF {
+		v.eof = true
+	}
+	return n, err
+}
@@ -0,0 +1,48 @@
+package core
+
+import "time"
+
+// ShareStaleYears is how many years old a share can be before it counts as
+// stale: the secret it protects has likely changed since it was sealed.
+const ShareStaleYears = 2
+
+// ShareHealth sums up the checks that can be run on a single share, for
+// dashboards and status listings.
+type ShareHealth struct {
+	// ChecksumOK is true when the data matches the share's checksum, or
+	// the share has none (as with shares typed in from words).
+	ChecksumOK bool `json:"checksum_ok"`
+	// Fresh is true when the share is no more than ShareStaleYears old,
+	// or its creation time is unknown.
+	Fresh bool `json:"fresh"`
+	// Score is out of 100; see the HealthScore constants.
+	Score int `json:"score"`
+}
+
+// Values of ShareHealth.Score. A share with a bad checksum won't combine,
+// however new it is, so it scores 0. A stale share still combines; it only
+// suggests sealing again.
+const (
+	HealthScoreBad     = 0
+	HealthScoreStale   = 50
+	HealthScoreHealthy = 100
+)
+
+// CheckShareHealth runs the checks for a single share as of now: its
+// checksum and its age.
+func CheckShareHealth(s *Share, now time.Time) ShareHealth {
+	const year = 365 * 24 * time.Hour
+	h := ShareHealth{
+		ChecksumOK: s.Verify() == nil,
+		Fresh:      ShareAge(s, now) <= ShareStaleYears*year,
+	}
+	switch {
+	case !h.ChecksumOK:
+		h.Score = HealthScoreBad
+	case !h.Fresh:
+		h.Score = HealthScoreStale
+	default:
+		h.Score = HealthScoreHealthy
+	}
+	return h
+}
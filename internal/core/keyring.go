@@ -0,0 +1,56 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Keyring reads secrets the operating system keeps for the user, such as
+// the macOS Keychain or the Secret Service on Linux.
+type Keyring interface {
+	// Get returns the secret stored for service and account. It returns
+	// ErrKeyringNotFound if there is none, and ErrKeyringUnavailable if
+	// the system has no keyring to ask.
+	Get(service, account string) (string, error)
+}
+
+var (
+	// ErrKeyringUnavailable means there is no keyring to read from: the
+	// platform isn't supported or its keyring tool isn't installed.
+	ErrKeyringUnavailable = errors.New("no keyring available on this system")
+
+	// ErrKeyringNotFound means the keyring has no entry for the service and
+	// account asked for.
+	ErrKeyringNotFound = errors.New("no such keyring entry")
+)
+
+// SystemKeyring is the keyring PassphraseFromKeyring reads: the Keychain on
+// macOS (through the security tool), the Secret Service on Linux (through
+// secret-tool), and none elsewhere. Tests can replace it.
+var SystemKeyring Keyring = systemKeyring{}
+
+// PassphraseFromKeyring reads a passphrase stored in the system keyring
+// under service and account. Errors wrap ErrKeyringUnavailable or
+// ErrKeyringNotFound when those are the cause, so callers can fall back to
+// asking for the passphrase some other way.
+func PassphraseFromKeyring(service, account string) (string, error) {
+	return PassphraseFromKeyringWith(SystemKeyring, service, account)
+}
+
+// PassphraseFromKeyringWith is PassphraseFromKeyring reading from k.
+func PassphraseFromKeyringWith(k Keyring, service, account string) (string, error) {
+	if service == "" || account == "" {
+		return "", fmt.Errorf("keyring service and account must not be empty")
+	}
+	secret, err := k.Get(service, account)
+	if err != nil {
+		return "", fmt.Errorf("reading %s/%s from keyring: %w", service, account, err)
+	}
+	// Keyring tools print the secret followed by a newline.
+	passphrase := strings.TrimRight(secret, "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("keyring entry %s/%s: %w", service, account, ErrEmptyPassphrase)
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,36 @@
+//go:build darwin
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemKeyring reads the login Keychain with the security tool.
+type systemKeyring struct{}
+
+// securityItemNotFound is the exit status security gives when no item
+// matches (errSecItemNotFound).
+const securityItemNotFound = 44
+
+func (systemKeyring) Get(service, account string) (string, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return "", ErrKeyringUnavailable
+	}
+	var stderr strings.Builder
+	cmd := exec.Command(path, "find-generic-password", "-s", service, "-a", account, "-w")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityItemNotFound {
+		return "", ErrKeyringNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("security: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,38 @@
+//go:build linux
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemKeyring reads the Secret Service (GNOME Keyring, KWallet) with
+// secret-tool, looking the item up by its service and account attributes.
+type systemKeyring struct{}
+
+func (systemKeyring) Get(service, account string) (string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", ErrKeyringUnavailable
+	}
+	var stderr strings.Builder
+	cmd := exec.Command(path, "lookup", "service", service, "account", account)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// secret-tool exits with 1 and prints nothing when no item matches. With
+	// no Secret Service running it says so on stderr.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(out) == 0 {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", ErrKeyringUnavailable, msg)
+		}
+		return "", ErrKeyringNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("secret-tool: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !linux
+
+package core
+
+// systemKeyring is the keyring of platforms ReMemory can't read one on,
+// including the browser.
+type systemKeyring struct{}
+
+func (systemKeyring) Get(service, account string) (string, error) {
+	return "", ErrKeyringUnavailable
+}
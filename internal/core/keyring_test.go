@@ -0,0 +1,59 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeKeyring is a Keyring holding secrets by "service/account".
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) Get(service, account string) (string, error) {
+	secret, ok := k[service+"/"+account]
+	if !ok {
+		return "", ErrKeyringNotFound
+	}
+	return secret, nil
+}
+
+type unavailableKeyring struct{}
+
+func (unavailableKeyring) Get(service, account string) (string, error) {
+	return "", ErrKeyringUnavailable
+}
+
+func TestPassphraseFromKeyring(t *testing.T) {
+	k := fakeKeyring{
+		"rememory/notes": "correct horse battery staple\n",
+		"rememory/empty": "\n",
+	}
+
+	passphrase, err := PassphraseFromKeyringWith(k, "rememory", "notes")
+	if err != nil {
+		t.Fatalf("PassphraseFromKeyringWith: %v", err)
+	}
+	if passphrase != "correct horse battery staple" {
+		t.Errorf("passphrase = %q, want the trailing newline trimmed", passphrase)
+	}
+
+	if _, err := PassphraseFromKeyringWith(k, "rememory", "missing"); !errors.Is(err, ErrKeyringNotFound) {
+		t.Errorf("missing entry: got %v, want ErrKeyringNotFound", err)
+	}
+	if _, err := PassphraseFromKeyringWith(k, "rememory", "empty"); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("empty entry: got %v, want ErrEmptyPassphrase", err)
+	}
+	if _, err := PassphraseFromKeyringWith(unavailableKeyring{}, "rememory", "notes"); !errors.Is(err, ErrKeyringUnavailable) {
+		t.Errorf("no keyring: got %v, want ErrKeyringUnavailable", err)
+	}
+	if _, err := PassphraseFromKeyringWith(k, "", "notes"); err == nil {
+		t.Error("expected error for an empty service")
+	}
+
+	// PassphraseFromKeyring reads SystemKeyring.
+	saved := SystemKeyring
+	SystemKeyring = k
+	t.Cleanup(func() { SystemKeyring = saved })
+	if passphrase, err := PassphraseFromKeyring("rememory", "notes"); err != nil || passphrase != "correct horse battery staple" {
+		t.Errorf("PassphraseFromKeyring = %q, %v", passphrase, err)
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// v1SecretSize is the size of the random passphrase v1 seals encoded as
+// base64 before splitting; v2 seals split those bytes directly.
+const v1SecretSize = 32
+
+// ErrV1NotMigratable is returned by MigrateV1Shares when the passphrase in
+// the v1 shares isn't one v2 shares can carry, so the project has to be
+// sealed again instead.
+var ErrV1NotMigratable = errors.New("the passphrase in these v1 shares can't be carried by v2 shares; seal the project again instead")
+
+// MigrateV1Shares rebuilds the passphrase from a quorum of v1 shares and
+// splits it again into version 2 shares, which can be written as words. The
+// passphrase stays the same, so the new shares open the same manifest; only
+// the shares change. Total and threshold are kept, and each new share has
+// the index and holder of the old one where that was given (the holders of
+// shares not given are left empty). All new shares get the created time,
+// and carry a commitment to the passphrase as sealing does.
+//
+// Every share must be version 1 and from the same set, and there must be at
+// least the threshold of them; extra shares are checked as in
+// CombineChecked. Returns ErrV1NotMigratable if the passphrase isn't the
+// base64 form of 32 random bytes that v1 seals used.
+func MigrateV1Shares(shares []*Share, created time.Time) ([]*Share, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares provided")
+	}
+	first := shares[0]
+	holders := make([]string, first.Total)
+	data := make([][]byte, len(shares))
+	for i, s := range shares {
+		if s.Version != 1 {
+			return nil, fmt.Errorf("share %d is version %d, not 1", i+1, s.Version)
+		}
+		if s.Total != first.Total || s.Threshold != first.Threshold {
+			return nil, fmt.Errorf("share %d is from a different set (%d of %d, not %d of %d)", i+1, s.Threshold, s.Total, first.Threshold, first.Total)
+		}
+		if err := s.Verify(); err != nil {
+			return nil, fmt.Errorf("share %d: %w", i+1, err)
+		}
+		if s.Index >= 1 && s.Index <= s.Total {
+			holders[s.Index-1] = s.Holder
+		}
+		data[i] = s.Data
+	}
+
+	recovered, err := CombineChecked(data, first.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(recovered)
+
+	raw, err := base64.RawURLEncoding.DecodeString(string(recovered))
+	if err != nil || len(raw) != v1SecretSize {
+		Zeroize(raw)
+		return nil, ErrV1NotMigratable
+	}
+	defer Zeroize(raw)
+	if RecoverPassphrase(raw, 2) != RecoverPassphrase(recovered, 1) {
+		return nil, ErrV1NotMigratable
+	}
+
+	commitment, err := NewCommitment(raw)
+	if err != nil {
+		return nil, err
+	}
+	migrated, err := SplitToShares(raw, first.Total, first.Threshold, holders, created)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range migrated {
+		s.Headers = map[string]string{CommitmentHeader: commitment}
+	}
+	return migrated, nil
+}
@@ -0,0 +1,35 @@
+package core
+
+// ParseErrorKind says what went wrong reading a share or its words.
+type ParseErrorKind string
+
+const (
+	// ParseUnknownWord is a word that isn't in the word list, or a prefix
+	// that matches more than one word.
+	ParseUnknownWord ParseErrorKind = "unknown-word"
+	// ParseBadChecksum is data that doesn't match its checksum: a word
+	// miscopied or out of order, or a damaged compact string.
+	ParseBadChecksum ParseErrorKind = "bad-checksum"
+	// ParseWrongCount is the wrong number of words for a share.
+	ParseWrongCount ParseErrorKind = "wrong-count"
+)
+
+// ParseError is an error from reading a share or its words, with the
+// details a UI needs to point at the problem. Get it with errors.As. Its
+// Error text is the same message the CLI prints.
+type ParseError struct {
+	Kind ParseErrorKind
+	// Position is the 1-based number of the word at fault, or 0 when the
+	// error isn't about one word.
+	Position int
+	// Input is the word or checksum as given, if the error is about one.
+	Input string
+	// Suggestion is the closest word in the list, if there is one.
+	Suggestion string
+
+	msg string
+}
+
+func (e *ParseError) Error() string {
+	return e.msg
+}
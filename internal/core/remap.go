@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RemapIndices gives shares new indices, for example to put holders back in
+// order after a revocation or reissue. mapping takes an old index to a new
+// one; shares whose index isn't a key keep theirs. Everything derived from
+// the index, such as the 25th recovery word and the compact encoding, is
+// computed from Index when encoded, so it follows along.
+//
+// The index must only be a label. In Vault's shares (versions 1 and 2) the
+// Shamir x-coordinate is the last data byte, picked at random and unrelated
+// to the index, so relabelling is safe. A format that derives x from the
+// index can't be relabelled without resharing, and neither can one this
+// code doesn't know, so such shares are refused.
+//
+// RemapIndices checks everything before changing anything: on error no
+// share is modified. It fails if a key matches no share, if a share's
+// index is unknown (0, from words of a share past 15), if a new index is
+// out of range, or if two shares would end up with the same index.
+func RemapIndices(shares []*Share, mapping map[int]int) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	byIndex := make(map[int]*Share, len(shares))
+	for i, s := range shares {
+		if err := checkIndexIsLabel(s); err != nil {
+			return fmt.Errorf("share %d: %w", i+1, err)
+		}
+		if s.Index == 0 {
+			continue
+		}
+		if _, ok := byIndex[s.Index]; ok {
+			return fmt.Errorf("two shares already have index %d", s.Index)
+		}
+		byIndex[s.Index] = s
+	}
+
+	// Walk the mapping in a fixed order so errors are stable.
+	olds := make([]int, 0, len(mapping))
+	for old := range mapping {
+		olds = append(olds, old)
+	}
+	sort.Ints(olds)
+
+	next := make(map[*Share]int, len(shares))
+	for _, old := range olds {
+		s, ok := byIndex[old]
+		if !ok {
+			return fmt.Errorf("no share has index %d", old)
+		}
+		// Shares typed in as words carry no total; 255 is Split's limit.
+		idx, limit := mapping[old], s.Total
+		if limit == 0 {
+			limit = 255
+		}
+		if idx < 1 || idx > limit {
+			return fmt.Errorf("new index %d for share %d is out of range (1-%d)", idx, old, limit)
+		}
+		next[s] = idx
+	}
+
+	taken := make(map[int]int, len(shares))
+	for _, s := range shares {
+		idx, ok := next[s]
+		if !ok {
+			idx = s.Index
+		}
+		if idx == 0 {
+			continue
+		}
+		if old, ok := taken[idx]; ok {
+			return fmt.Errorf("remapping would give index %d to both share %d and share %d", idx, old, s.Index)
+		}
+		taken[idx] = s.Index
+	}
+
+	for s, idx := range next {
+		s.Index = idx
+	}
+	return nil
+}
+
+// checkIndexIsLabel returns an error unless s's index can change without
+// touching its data.
+func checkIndexIsLabel(s *Share) error {
+	switch s.Version {
+	case 1, 2:
+		return nil
+	default:
+		return fmt.Errorf("share version %d may tie its x-coordinate to the index; reshare instead of remapping", s.Version)
+	}
+}
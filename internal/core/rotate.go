@@ -0,0 +1,64 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// rotatedSecretSize is the size of the fresh secret RotateManifest splits,
+// the same as seal's (crypto.DefaultPassphraseBytes).
+const rotatedSecretSize = 32
+
+// RotateManifest moves a manifest to a new passphrase in one call, for key
+// rotation. It combines shares and checks that the result decrypts
+// oldCiphertext to the end, so wrong or damaged shares are caught before
+// anything new is made. It then encrypts newPlaintext under a fresh random
+// passphrase and splits that into total new shares, threshold of which
+// recover it.
+//
+// shares are the Data of the old shares, from a v1 or a v2 seal. The new
+// shares are always version 2: give them to NewShare with version 2, and
+// RecoverPassphrase(secret, 2) turns their combined secret back into the
+// passphrase.
+func RotateManifest(shares [][]byte, oldCiphertext, newPlaintext io.Reader, total, threshold int) (newCiphertext []byte, newShares [][]byte, err error) {
+	if err := ValidateShamirParams(total, threshold); err != nil {
+		return nil, nil, err
+	}
+
+	recovered, err := Combine(shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer Zeroize(recovered)
+
+	old, err := io.ReadAll(oldCiphertext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading old manifest: %w", err)
+	}
+	if err := CanDecrypt(bytes.NewReader(old), RecoverPassphrase(recovered, 2)); err != nil {
+		// v1 shares split the passphrase text itself, which is base64url.
+		if _, b64Err := base64.RawURLEncoding.DecodeString(string(recovered)); b64Err != nil ||
+			CanDecrypt(bytes.NewReader(old), RecoverPassphrase(recovered, 1)) != nil {
+			return nil, nil, fmt.Errorf("the shares don't unlock the old manifest: %w", err)
+		}
+	}
+
+	secret := make([]byte, rotatedSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, fmt.Errorf("generating passphrase: %w", err)
+	}
+	defer Zeroize(secret)
+
+	var out bytes.Buffer
+	if err := Encrypt(&out, newPlaintext, RecoverPassphrase(secret, 2)); err != nil {
+		return nil, nil, err
+	}
+	newShares, err = Split(secret, total, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Bytes(), newShares, nil
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"strings"
 
 	vault "github.com/hashicorp/vault/shamir"
 )
@@ -32,6 +35,9 @@ func Combine(shares [][]byte) ([]byte, error) {
 	if len(shares) < 2 {
 		return nil, fmt.Errorf("need at least 2 shares, got %d", len(shares))
 	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, err
+	}
 
 	secret, err := vault.Combine(shares)
 	if err != nil {
@@ -41,6 +47,302 @@ func Combine(shares [][]byte) ([]byte, error) {
 	return secret, nil
 }
 
+// DuplicateSharesError reports two shares with the same x-coordinate: the
+// same piece given twice. Combining them would silently leave fewer distinct
+// shares than were supplied, possibly fewer than the threshold.
+type DuplicateSharesError struct {
+	// First and Second are the positions (0-based, in the order given) of
+	// the two copies.
+	First, Second int
+}
+
+func (e *DuplicateSharesError) Error() string {
+	return fmt.Sprintf("shares %d and %d are the same share; each one counts only once", e.First+1, e.Second+1)
+}
+
+// InconsistentSharesError reports that the supplied shares don't all lie on
+// one polynomial, so at least one of them is corrupted or forged.
+type InconsistentSharesError struct {
+	// Suspects holds the positions (0-based, in the order given) of shares
+	// that disagree with the rest. It is empty when there are too few extra
+	// shares to tell which one is bad.
+	Suspects []int
+}
+
+func (e *InconsistentSharesError) Error() string {
+	if len(e.Suspects) == 0 {
+		return "shares disagree: at least one is corrupted, but with only one share beyond the threshold there is no telling which; add another share to find it"
+	}
+	positions := make([]int, len(e.Suspects))
+	for i, p := range e.Suspects {
+		positions[i] = p + 1
+	}
+	if len(positions) == 1 {
+		return fmt.Sprintf("shares disagree: share %d does not match the others", positions[0])
+	}
+	return fmt.Sprintf("shares disagree: one of shares %s does not match the others", joinInts(positions))
+}
+
+// CombineChecked reconstructs the secret like Combine, but uses any shares
+// beyond the threshold as a consistency check instead of silently accepting
+// them. With threshold+1 shares it can detect a single bad share; with
+// threshold+2 or more it can also say which one it is.
+// Returns *InconsistentSharesError when the shares disagree.
+func CombineChecked(shares [][]byte, threshold int) ([]byte, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2, got %d", threshold)
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("need at least %d shares, got %d", threshold, len(shares))
+	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, err
+	}
+
+	if !sharesConsistent(shares, threshold) {
+		return nil, &InconsistentSharesError{Suspects: locateBadShares(shares, threshold)}
+	}
+
+	return Combine(shares[:threshold])
+}
+
+// ReconstructShare derives the share that lies at x-coordinate x on the same
+// polynomial as the given shares. It needs at least threshold consistent
+// shares; any extras are checked like in CombineChecked. The result is in
+// Vault's format (y bytes followed by x), so it combines with the originals.
+func ReconstructShare(shares [][]byte, threshold int, x byte) ([]byte, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2, got %d", threshold)
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("need at least %d shares, got %d", threshold, len(shares))
+	}
+	if x == 0 {
+		return nil, fmt.Errorf("x-coordinate 0 is the secret itself")
+	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, err
+	}
+	if !sharesConsistent(shares, threshold) {
+		return nil, &InconsistentSharesError{Suspects: locateBadShares(shares, threshold)}
+	}
+
+	y, err := evaluateAt(shares[:threshold], x)
+	if err != nil {
+		return nil, err
+	}
+	return append(y, x), nil
+}
+
+// CombineVerbose reconstructs the secret like CombineChecked, and also
+// reports how far each share is from the polynomial: residuals[i] is the
+// number of bytes of shares[i] that don't lie on it, so zero for a good
+// share. It is a diagnostic for when recovery gives back the wrong secret.
+//
+// The degree of the polynomial depends on the threshold, which the share
+// data doesn't carry, so it has to be given. Every threshold-sized subset
+// is interpolated, and the polynomial the most shares lie on is used, so a
+// corrupt share stands out wherever it is in the list. With only threshold
+// shares every residual is zero, since there is nothing to check against.
+//
+// Returns *InconsistentSharesError, and no secret, when no polynomial is
+// backed by more shares than any other, as when no extra share agrees with
+// any subset.
+func CombineVerbose(shares [][]byte, threshold int) (secret []byte, residuals []byte, err error) {
+	if threshold < 2 {
+		return nil, nil, fmt.Errorf("threshold must be at least 2, got %d", threshold)
+	}
+	if len(shares) < threshold {
+		return nil, nil, fmt.Errorf("need at least %d shares, got %d", threshold, len(shares))
+	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, nil, err
+	}
+	if n := binomial(len(shares), threshold); n > MaxSubsetTries {
+		return nil, nil, fmt.Errorf("%d shares taken %d at a time make %.0f combinations, more than the %d that can be tried", len(shares), threshold, n, MaxSubsetTries)
+	}
+
+	var best []int
+	bestSupport, tied := -1, false
+	for _, combo := range combinations(len(shares), threshold) {
+		res, err := shareResiduals(shares, combo)
+		if err != nil {
+			return nil, nil, err
+		}
+		support := 0
+		for _, r := range res {
+			if r == 0 {
+				support++
+			}
+		}
+		switch {
+		case support > bestSupport:
+			best, residuals, bestSupport, tied = combo, res, support, false
+		case support == bestSupport && !sameZeros(res, residuals):
+			// Another polynomial, backed by as many shares.
+			tied = true
+		}
+	}
+	if tied || (bestSupport == threshold && len(shares) > threshold) {
+		return nil, nil, &InconsistentSharesError{}
+	}
+
+	subset := make([][]byte, threshold)
+	for i, pos := range best {
+		subset[i] = shares[pos]
+	}
+	secret, err = Combine(subset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret, residuals, nil
+}
+
+// shareResiduals evaluates the polynomial through the shares at positions
+// combo and counts, for every share, the bytes that are off it.
+func shareResiduals(shares [][]byte, combo []int) ([]byte, error) {
+	n := len(shares[0]) - 1
+	subset := make([][]byte, len(combo))
+	for i, pos := range combo {
+		subset[i] = shares[pos]
+	}
+	residuals := make([]byte, len(shares))
+	for i, s := range shares {
+		y, err := evaluateAt(subset, s[n])
+		if err != nil {
+			return nil, err
+		}
+		for b := 0; b < n; b++ {
+			if y[b] != s[b] && residuals[i] < 255 {
+				residuals[i]++
+			}
+		}
+	}
+	return residuals, nil
+}
+
+// sameZeros reports whether a and b are zero at the same positions.
+func sameZeros(a, b []byte) bool {
+	for i := range a {
+		if (a[i] == 0) != (b[i] == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// EvaluateShareAt evaluates the polynomial through the given shares at x
+// and returns the share there, in Vault's format (y bytes followed by x), so
+// it combines with the originals. All the shares are used, so give exactly a
+// quorum: with fewer than the threshold the result lies on a different
+// polynomial and is useless, which can't be detected here. When the
+// threshold is known, ReconstructShare also checks any extra shares.
+//
+// x must be 1-255 and must not be the x-coordinate of any of the given
+// shares. Note that Vault picks x-coordinates at random, so they aren't the
+// share indices; check x against every share of the set, not just these.
+func EvaluateShareAt(shares [][]byte, x int) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("need at least 2 shares, got %d", len(shares))
+	}
+	if x < 1 || x > 255 {
+		return nil, fmt.Errorf("x-coordinate %d is out of range (1-255)", x)
+	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, err
+	}
+	for i, s := range shares {
+		if int(s[len(s)-1]) == x {
+			return nil, fmt.Errorf("share %d is already at x-coordinate %d", i+1, x)
+		}
+	}
+	return ReconstructShare(shares, len(shares), byte(x))
+}
+
+// checkSharePoints makes sure the shares have equal length and distinct
+// x-coordinates (the last byte of each Vault share). A repeated x-coordinate
+// is reported as *DuplicateSharesError.
+func checkSharePoints(shares [][]byte) error {
+	seen := make(map[byte]int)
+	for i, s := range shares {
+		if len(s) < 2 {
+			return fmt.Errorf("share %d is too short", i+1)
+		}
+		if len(s) != len(shares[0]) {
+			return fmt.Errorf("share %d has a different length (%d vs %d bytes)", i+1, len(s), len(shares[0]))
+		}
+		x := s[len(s)-1]
+		if j, ok := seen[x]; ok {
+			return &DuplicateSharesError{First: j, Second: i}
+		}
+		seen[x] = i
+	}
+	return nil
+}
+
+// sharesConsistent reports whether every share lies on the polynomial
+// defined by the first threshold shares.
+func sharesConsistent(shares [][]byte, threshold int) bool {
+	n := len(shares[0]) - 1
+	for _, s := range shares[min(threshold, len(shares)):] {
+		y, err := evaluateAt(shares[:threshold], s[n])
+		if err != nil || !bytes.Equal(y, s[:n]) {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateAt returns the y bytes, at x, of the polynomial through the given
+// shares. Vault's Combine only evaluates at 0, but in GF(2^8) adding x to
+// every x-coordinate moves the polynomial without changing its degree, so
+// combining the moved shares gives the value at x. The shares must have
+// passed checkSharePoints.
+func evaluateAt(shares [][]byte, x byte) ([]byte, error) {
+	n := len(shares[0]) - 1
+	moved := make([][]byte, len(shares))
+	for i, s := range shares {
+		if s[n] == x {
+			return bytes.Clone(s[:n]), nil
+		}
+		moved[i] = append(bytes.Clone(s[:n]), s[n]^x)
+	}
+	y, err := vault.Combine(moved)
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+	return y, nil
+}
+
+// locateBadShares finds shares whose removal leaves a consistent set. This
+// needs at least threshold+1 shares left over, so it returns nil when fewer
+// than threshold+2 shares were supplied or no single share explains the
+// inconsistency.
+func locateBadShares(shares [][]byte, threshold int) []int {
+	if len(shares) < threshold+2 {
+		return nil
+	}
+	var suspects []int
+	rest := make([][]byte, 0, len(shares)-1)
+	for i := range shares {
+		rest = rest[:0]
+		rest = append(rest, shares[:i]...)
+		rest = append(rest, shares[i+1:]...)
+		if sharesConsistent(rest, threshold) {
+			suspects = append(suspects, i)
+		}
+	}
+	return suspects
+}
+
+func joinInts(nums []int) string {
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		parts[i] = fmt.Sprintf("%d", n)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ValidateShamirParams validates the parameters for Shamir's Secret Sharing.
 func ValidateShamirParams(n, k int) error {
 	if k < 2 {
@@ -54,3 +356,57 @@ func ValidateShamirParams(n, k int) error {
 	}
 	return nil
 }
+
+// ThresholdWarning reports a threshold that works but leaves no room for a
+// lost share: with threshold equal to total, every holder is needed, so
+// losing any one share locks the secret away for good.
+type ThresholdWarning struct {
+	Threshold, Total int
+}
+
+func (w *ThresholdWarning) Error() string {
+	return fmt.Sprintf("threshold %d of %d needs every share, so losing any one makes recovery impossible", w.Threshold, w.Total)
+}
+
+// ValidateThreshold checks a threshold for a split into total shares, for
+// setting up a project. Thresholds that can't work, below 2 (any one share
+// would reveal the secret) or above total, are errors like in
+// ValidateShamirParams. A threshold equal to total is returned as
+// *ThresholdWarning, which callers may let the user override.
+func ValidateThreshold(threshold, total int) error {
+	if err := ValidateShamirParams(total, threshold); err != nil {
+		return err
+	}
+	if threshold == total {
+		return &ThresholdWarning{Threshold: threshold, Total: total}
+	}
+	return nil
+}
+
+// RecoveryProbability returns the chance that at least threshold of total
+// holders can still be reached, when each is unreachable with probability
+// lossProb, independently of the others. That is the chance the secret can
+// be recovered at all. It returns 0 when threshold is outside 1..total, and
+// NaN when lossProb is outside 0..1.
+func RecoveryProbability(total, threshold int, lossProb float64) float64 {
+	if !(lossProb >= 0 && lossProb <= 1) {
+		return math.NaN()
+	}
+	if threshold < 1 || threshold > total {
+		return 0
+	}
+	keep := 1 - lossProb
+	// Sum the binomial terms for threshold..total reachable holders,
+	// building each coefficient from the previous one.
+	var p float64
+	coef := 1.0 // C(total, 0)
+	for i := 0; i <= total; i++ {
+		if i > 0 {
+			coef = coef * float64(total-i+1) / float64(i)
+		}
+		if i >= threshold {
+			p += coef * math.Pow(keep, float64(i)) * math.Pow(lossProb, float64(total-i))
+		}
+	}
+	return min(p, 1)
+}
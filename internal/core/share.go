@@ -1,10 +1,13 @@
 package core
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,8 +17,11 @@ import (
 )
 
 const (
-	ShareBegin = "-----BEGIN REMEMORY SHARE-----"
-	ShareEnd   = "-----END REMEMORY SHARE-----"
+	// DefaultShareBlockType is the PEM block type of an encoded share.
+	DefaultShareBlockType = "REMEMORY SHARE"
+
+	ShareBegin = "-----BEGIN " + DefaultShareBlockType + "-----"
+	ShareEnd   = "-----END " + DefaultShareBlockType + "-----"
 
 	// DefaultRecoveryURL is the default base URL for QR codes in PDFs.
 	// Points to the recover.html hosted on GitHub Pages.
@@ -26,12 +32,19 @@ const (
 type Share struct {
 	Version   int       // Format version (1 or 2)
 	Index     int       // Which share (1-indexed for humans)
+	Label     string    // Optional name for the share, such as "A"; see DisplayLabel
 	Total     int       // Total shares (N)
 	Threshold int       // Required shares (K)
 	Holder    string    // Name of the person holding this share
 	Created   time.Time // When the share was created
 	Data      []byte    // The actual share bytes
 	Checksum  string    // SHA-256 of Data
+
+	// Headers holds any other PEM headers, such as a note the holder added
+	// ("X-Note: given to Carol 2024-03, backup in safe"). They are kept as
+	// written and encoded again after the known ones, so annotations
+	// survive a round trip. They are not covered by the checksum.
+	Headers map[string]string
 }
 
 // NewShare creates a Share with the given parameters and computes its checksum.
@@ -48,6 +61,62 @@ func NewShare(version, index, total, threshold int, holder string, data []byte)
 	}
 }
 
+// SplitToShares splits secret with Split and wraps each piece in a version 2
+// Share, checksummed and ready to encode. holders[i] holds share i+1, so
+// there must be exactly one holder per share. Every share gets the same
+// created time, which keeps them in one fingerprint set.
+func SplitToShares(secret []byte, total, threshold int, holders []string, created time.Time) ([]*Share, error) {
+	if len(holders) != total {
+		return nil, fmt.Errorf("need %d holders for %d shares, got %d", total, total, len(holders))
+	}
+	parts, err := Split(secret, total, threshold)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*Share, total)
+	for i, data := range parts {
+		shares[i] = NewShare(2, i+1, total, threshold, holders[i], data)
+		shares[i].Created = created.UTC()
+	}
+	return shares, nil
+}
+
+// DisplayLabel returns the name to show people for the share: its Label,
+// such as "A", or else its Index as a number. The label is only for people;
+// Combine goes by the data, so relabeling a share never affects recovery.
+// Returns "" for a share with neither.
+func (s *Share) DisplayLabel() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	if s.Index > 0 {
+		return strconv.Itoa(s.Index)
+	}
+	return ""
+}
+
+// XCoordinate returns the Shamir x-coordinate the share's data lies at: the
+// last byte, in Vault's format. It is not the Index, since Vault picks
+// x-coordinates at random, but it is what Combine uses, so a share whose
+// Index was lost (words of a piece past 15 without the 26th) still combines.
+// Returns 0 for a share with no data.
+func (s *Share) XCoordinate() byte {
+	if len(s.Data) == 0 {
+		return 0
+	}
+	return s.Data[len(s.Data)-1]
+}
+
+// ShareAge returns how long ago the share was created, as of now.
+// Returns 0 if the creation time is unknown (e.g. compact or word shares)
+// or in the future.
+func ShareAge(s *Share, now time.Time) time.Duration {
+	if s.Created.IsZero() || now.Before(s.Created) {
+		return 0
+	}
+	return now.Sub(s.Created)
+}
+
 // RecoverPassphrase converts raw bytes from Combine() into the age passphrase.
 // V1 shares contain the passphrase string directly; v2+ shares contain raw bytes
 // that must be base64url-encoded.
@@ -60,11 +129,25 @@ func RecoverPassphrase(recovered []byte, version int) string {
 
 // Encode converts the share to a human-readable PEM-like format.
 func (s *Share) Encode() string {
+	return s.EncodeWithType(DefaultShareBlockType)
+}
+
+// EncodeWithType is Encode with blockType in place of "REMEMORY SHARE" in
+// the BEGIN and END lines, for keeping shares alongside other PEM data
+// without clashing with it. Read it back with ParseShareWithTypes. An empty
+// blockType gives the default.
+func (s *Share) EncodeWithType(blockType string) string {
+	if blockType == "" {
+		blockType = DefaultShareBlockType
+	}
 	var sb strings.Builder
 
-	sb.WriteString(ShareBegin + "\n")
+	sb.WriteString(pemBegin(blockType) + "\n")
 	sb.WriteString(fmt.Sprintf("Version: %d\n", s.Version))
 	sb.WriteString(fmt.Sprintf("Index: %d\n", s.Index))
+	if label := strings.Join(strings.Fields(s.Label), " "); label != "" {
+		sb.WriteString(fmt.Sprintf("Label: %s\n", label))
+	}
 	sb.WriteString(fmt.Sprintf("Total: %d\n", s.Total))
 	sb.WriteString(fmt.Sprintf("Threshold: %d\n", s.Threshold))
 	if s.Holder != "" {
@@ -76,30 +159,87 @@ func (s *Share) Encode() string {
 	if s.Version < 2 {
 		timeFormat = time.RFC3339
 	}
-	sb.WriteString(fmt.Sprintf("Created: %s\n", s.Created.Format(timeFormat)))
+	// Shares converted from compact or word form have no creation time.
+	// The time is always written in UTC, whatever zone it was made in: the
+	// v1 format ends in Z, and the v2 format, which has no zone, is read
+	// back as UTC.
+	if !s.Created.IsZero() {
+		sb.WriteString(fmt.Sprintf("Created: %s\n", s.Created.UTC().Format(timeFormat)))
+	}
 	sb.WriteString(fmt.Sprintf("Checksum: %s\n", s.Checksum))
+	for _, key := range s.extraHeaderKeys() {
+		value := strings.Join(strings.Fields(s.Headers[key]), " ")
+		sb.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+	}
 	sb.WriteString("\n")
 	sb.WriteString(base64.StdEncoding.EncodeToString(s.Data))
 	sb.WriteString("\n")
-	sb.WriteString(ShareEnd + "\n")
+	sb.WriteString(pemEnd(blockType) + "\n")
 
 	return sb.String()
 }
 
+func pemBegin(blockType string) string { return "-----BEGIN " + blockType + "-----" }
+func pemEnd(blockType string) string   { return "-----END " + blockType + "-----" }
+
+// SecretHeader is the extra header that names which secret a share unlocks,
+// on shares from a project sealed with per-file keys.
+const SecretHeader = "Secret"
+
+// knownHeaders are the PEM headers Share has typed fields for.
+var knownHeaders = map[string]bool{
+	"Version": true, "Index": true, "Label": true, "Total": true, "Threshold": true,
+	"Holder": true, "Created": true, "Checksum": true,
+}
+
+// extraHeaderKeys returns the keys of s.Headers that Encode writes, sorted
+// so the output is stable. Keys that would collide with a known header or
+// not parse back as a header are left out.
+func (s *Share) extraHeaderKeys() []string {
+	keys := make([]string, 0, len(s.Headers))
+	for key := range s.Headers {
+		if key == "" || knownHeaders[key] || strings.TrimSpace(key) != key ||
+			strings.Contains(key, ": ") || strings.ContainsAny(key, "\r\n") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // ParseShare parses a share from its encoded format.
 // The content can be a full README.txt file - it will find the share block.
+// Spaces around lines and inside the base64 data are ignored, but the
+// BEGIN/END markers must be intact.
 func ParseShare(content []byte) (*Share, error) {
+	return ParseShareWithTypes(content, DefaultShareBlockType)
+}
+
+// ParseShareWithTypes is ParseShare for a share block of any of blockTypes,
+// as written by EncodeWithType. The first such block in content is read, and
+// PEM blocks of other types around it are ignored.
+func ParseShareWithTypes(content []byte, blockTypes ...string) (*Share, error) {
 	text := string(content)
 
 	// Find the PEM block
-	beginIdx := strings.Index(text, ShareBegin)
-	endIdx := strings.Index(text, ShareEnd)
-	if beginIdx == -1 || endIdx == -1 || endIdx <= beginIdx {
+	beginIdx, blockType := -1, ""
+	for _, t := range blockTypes {
+		if i := strings.Index(text, pemBegin(t)); i != -1 && (beginIdx == -1 || i < beginIdx) {
+			beginIdx, blockType = i, t
+		}
+	}
+	if beginIdx == -1 {
+		return nil, fmt.Errorf("invalid share format: missing BEGIN/END markers")
+	}
+	start := beginIdx + len(pemBegin(blockType))
+	endIdx := strings.Index(text[start:], pemEnd(blockType))
+	if endIdx == -1 {
 		return nil, fmt.Errorf("invalid share format: missing BEGIN/END markers")
 	}
 
 	// Extract content between markers
-	inner := text[beginIdx+len(ShareBegin) : endIdx]
+	inner := text[start : start+endIdx]
 	lines := strings.Split(strings.TrimSpace(inner), "\n")
 
 	share := &Share{}
@@ -142,6 +282,8 @@ func ParseShare(content []byte) (*Share, error) {
 				return nil, fmt.Errorf("invalid index: %w", err)
 			}
 			share.Index = v
+		case "Label":
+			share.Label = value
 		case "Total":
 			v, err := strconv.Atoi(value)
 			if err != nil {
@@ -164,14 +306,21 @@ func ParseShare(content []byte) (*Share, error) {
 			if err != nil {
 				return nil, fmt.Errorf("invalid created time: %w", err)
 			}
-			share.Created = t
+			share.Created = t.UTC()
 		case "Checksum":
 			share.Checksum = value
+		default:
+			if share.Headers == nil {
+				share.Headers = make(map[string]string)
+			}
+			share.Headers[key] = value
 		}
 	}
 
-	// Decode base64 data
-	dataStr := strings.Join(dataLines, "")
+	// Decode base64 data. Messaging apps that reflow a pasted block can add
+	// or double up spaces inside the base64 lines; base64 has no spaces of
+	// its own, so drop them all.
+	dataStr := strings.Join(strings.Fields(strings.Join(dataLines, " ")), "")
 	data, err := base64.StdEncoding.DecodeString(dataStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base64 data: %w", err)
@@ -198,6 +347,80 @@ func ParseShare(content []byte) (*Share, error) {
 	return share, nil
 }
 
+// ParseShareAny parses a share in any of the supported text formats,
+// detecting which one it is:
+//   - a PEM-style block (a SHARE-*.txt or README.txt file)
+//   - a compact string (RM2:...)
+//   - a share URI (rememory://share/v2/RM2:...)
+//   - 25 BIP39 words (26 for pieces above 15) in any supported language,
+//     optionally numbered
+//
+// Word-encoded shares carry only the data and index, so Total and Threshold
+// are left at zero and Created is unset.
+func ParseShareAny(content []byte) (*Share, error) {
+	return ParseShareAnyLang(content, "")
+}
+
+// ParseShareAnyLang is ParseShareAny with the word list language for word
+// input given by lang instead of detected. An empty lang detects it.
+func ParseShareAnyLang(content []byte, lang Lang) (*Share, error) {
+	text := strings.TrimSpace(strings.TrimPrefix(string(content), string(byteOrderMark)))
+	if text == "" {
+		return nil, fmt.Errorf("empty share input")
+	}
+
+	if blocks := strings.Count(text, ShareBegin); blocks > 0 {
+		if blocks > 1 {
+			return nil, fmt.Errorf("ambiguous share input: found %d share blocks, expected one", blocks)
+		}
+		return ParseShare(content)
+	}
+
+	fields := TokenizeWords(text)
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "RM") {
+		return ParseCompact(fields[0])
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], ShareURIPrefix) {
+		return ParseURI(fields[0])
+	}
+
+	// Word lists are often written down numbered ("1. apple 2. banana");
+	// drop the numbers and keep the words.
+	var words []string
+	for _, f := range fields {
+		if _, err := strconv.Atoi(strings.TrimRight(f, ".):")); err == nil {
+			continue
+		}
+		words = append(words, f)
+	}
+	if len(words) != 25 && len(words) != 26 {
+		// Recovery words, all but perhaps a misspelled one, just not the
+		// right number of them.
+		if _, known := closestWordListLangBy(words, lookupWordOrPrefix); known >= len(words)-1 && known*2 > len(words) {
+			return nil, checkShareWordCount(len(words))
+		}
+		return nil, fmt.Errorf("unrecognized share format: not a share block, compact share, share URI, or 25 or 26 words")
+	}
+
+	var data []byte
+	var index int
+	var err error
+	if lang == "" {
+		data, index, _, err = DecodeShareWordsAuto(words)
+	} else {
+		data, index, err = DecodeShareWordsLang(words, lang)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Share{
+		Version:  2,
+		Index:    index,
+		Data:     data,
+		Checksum: HashBytes(data),
+	}, nil
+}
+
 // Verify checks that the share's checksum matches its data.
 // Uses constant-time comparison to prevent timing attacks.
 func (s *Share) Verify() error {
@@ -211,6 +434,163 @@ func (s *Share) Verify() error {
 	return nil
 }
 
+// Validate checks that the share's metadata is self-consistent: a known
+// version, a usable threshold, an index within range, and data of a
+// plausible length. It does not check the checksum; use Verify for that.
+// Shares decoded from words carry no total or threshold (both zero), so
+// those checks are skipped for them.
+func (s *Share) Validate() error {
+	if s.Version < 1 || s.Version > 2 {
+		return fmt.Errorf("unsupported share version %d", s.Version)
+	}
+	if s.Total != 0 || s.Threshold != 0 {
+		if err := ValidateShamirParams(s.Total, s.Threshold); err != nil {
+			return fmt.Errorf("invalid share parameters: %w", err)
+		}
+		maxIndex := s.Total
+		if s.IsEscrow() {
+			// The escrow share comes after the friends' shares.
+			maxIndex = s.Total + 1
+		}
+		if s.Index < 1 || s.Index > maxIndex {
+			return fmt.Errorf("share index %d out of range (1-%d)", s.Index, maxIndex)
+		}
+	}
+	// Vault shares carry at least one y byte plus the trailing x-coordinate.
+	if len(s.Data) < 2 {
+		return fmt.Errorf("share data too short (%d bytes)", len(s.Data))
+	}
+	// v2 splits a 32-byte secret, so every share is 33 bytes.
+	if s.Version == 2 && len(s.Data) != 33 {
+		return fmt.Errorf("v2 share data must be 33 bytes, got %d", len(s.Data))
+	}
+	return nil
+}
+
+// Fingerprint returns a short identifier for the set of shares this share
+// belongs to, e.g. "3f2a-91c0". It is derived only from non-secret data:
+// version, total, threshold, creation minute and, when the share has one,
+// its Commitment header. Every share from the same seal has the same
+// fingerprint. The commitment is salted at random on each seal, so two
+// seals with the same settings in the same minute still differ; shares
+// without one, from before commitments or typed in as words, differ only
+// by their metadata. Returns "" when the creation time is unknown, as with
+// compact-encoded shares.
+func (s *Share) Fingerprint() string {
+	if s.Created.IsZero() {
+		return ""
+	}
+	id := fmt.Sprintf("rememory-set:v%d:%d:%d:%s",
+		s.Version, s.Total, s.Threshold, s.Created.UTC().Format("2006-01-02T15:04"))
+	if c := s.Headers[CommitmentHeader]; c != "" {
+		id += ":" + c
+	}
+	h := sha256.Sum256([]byte(id))
+	x := hex.EncodeToString(h[:4])
+	return x[:4] + "-" + x[4:]
+}
+
+// Clone returns a deep copy of the share. The copy owns its own Data buffer,
+// so zeroizing one does not affect the other.
+func (s *Share) Clone() *Share {
+	c := *s
+	if s.Data != nil {
+		c.Data = make([]byte, len(s.Data))
+		copy(c.Data, s.Data)
+	}
+	if s.Headers != nil {
+		c.Headers = make(map[string]string, len(s.Headers))
+		for k, v := range s.Headers {
+			c.Headers[k] = v
+		}
+	}
+	return &c
+}
+
+// String describes the share without its secret data, so shares are safe to
+// print with %v, %+v or %s, e.g.
+// "Share{v2 3/5 holder=Carol idx=3 data=<redacted 33B> chk=ab12…}" for the
+// third of five shares with a threshold of 3. It has a value receiver so
+// that Share values are covered as well as pointers.
+func (s Share) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Share{v%d %d/%d", s.Version, s.Threshold, s.Total)
+	if s.Holder != "" {
+		fmt.Fprintf(&sb, " holder=%s", s.Holder)
+	}
+	fmt.Fprintf(&sb, " idx=%d data=<redacted %dB>", s.Index, len(s.Data))
+	if chk := strings.TrimPrefix(s.Checksum, "sha256:"); len(chk) >= 4 {
+		fmt.Fprintf(&sb, " chk=%s…", chk[:4])
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// GoString is String for %#v, which would otherwise print every field.
+func (s Share) GoString() string {
+	return s.String()
+}
+
+// SecretData returns the share's secret bytes. It is the same slice as Data;
+// going through it makes the places that handle the secret easy to find.
+func (s *Share) SecretData() []byte {
+	return s.Data
+}
+
+// Less reports whether s sorts before other: by Index, which is the order
+// the pieces were handed out in.
+func (s *Share) Less(other *Share) bool {
+	return s.Index < other.Index
+}
+
+// SortShares sorts shares by Index in place. Shares with the same index
+// keep their relative order.
+func SortShares(shares []*Share) {
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].Less(shares[j])
+	})
+}
+
+// DedupeShares returns shares without exact duplicates (the same Index and
+// Data), keeping the first of each. Two shares with the same Index but
+// different Data can't both be right, so that is an error. Shares without
+// an index (typed in as words past 15) are kept as they are.
+func DedupeShares(shares []*Share) ([]*Share, error) {
+	out := make([]*Share, 0, len(shares))
+	seen := make(map[int]*Share)
+	for _, share := range shares {
+		if share.Index == 0 {
+			out = append(out, share)
+			continue
+		}
+		if prev, ok := seen[share.Index]; ok {
+			// Data is secret, so compare it in constant time.
+			if subtle.ConstantTimeCompare(prev.Data, share.Data) != 1 {
+				return nil, fmt.Errorf("two different shares are both numbered %d", share.Index)
+			}
+			continue
+		}
+		seen[share.Index] = share
+		out = append(out, share)
+	}
+	return out, nil
+}
+
+// Zeroize overwrites the share's secret Data with zeros in place.
+// The checksum is kept, so Verify fails afterwards — a wiped share can't be
+// mistaken for a valid one.
+func (s *Share) Zeroize() {
+	Zeroize(s.Data)
+}
+
+// Zeroize overwrites b with zeros. Use it to wipe secret buffers (share data,
+// recovered passphrase bytes) once they are no longer needed.
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 // CompactEncode returns a short string encoding of the share suitable for
 // QR codes and URL fragments. Format: RM{version}:{index}:{total}:{threshold}:{base64url_data}:{short_check}
 // The short_check is the first 4 hex characters of the SHA-256 of the raw share data.
@@ -220,10 +600,69 @@ func (s *Share) CompactEncode() string {
 	return fmt.Sprintf("RM%d:%d:%d:%d:%s:%s", s.Version, s.Index, s.Total, s.Threshold, data, check)
 }
 
+// CompactEncodeHMAC is CompactEncode with a seventh field: a full
+// HMAC-SHA256 tag over the rest of the string, keyed by key. The short
+// checksum only catches slips in copying; anyone can recompute it. The tag
+// can't be forged without key, so a change to any field, deliberate or not,
+// is caught by ParseCompactHMAC. It adds tamper evidence, not secrecy: key
+// can be public project material, such as the manifest checksum, as long
+// as whoever checks the share gets it from somewhere other than the share.
+func (s *Share) CompactEncodeHMAC(key []byte) string {
+	compact := s.CompactEncode()
+	return compact + ":" + compactTag(compact, key)
+}
+
+// ParseCompactHMAC parses a string made by CompactEncodeHMAC, checking its
+// tag against key before anything else. A compact share without a tag is
+// refused. ParseCompact reads the same string without checking the tag.
+func ParseCompactHMAC(s string, key []byte) (*Share, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("integrity key must not be empty")
+	}
+	compact, tag, ok := cutLast(s, ":")
+	if !ok || strings.Count(compact, ":") != 5 {
+		return nil, fmt.Errorf("invalid compact share: no integrity tag")
+	}
+	if !hmac.Equal([]byte(tag), []byte(compactTag(compact, key))) {
+		return nil, fmt.Errorf("invalid compact share: integrity tag doesn't match; the share was changed or the key is wrong")
+	}
+	return ParseCompact(compact)
+}
+
+// compactTag returns the base64url HMAC-SHA256 of compact under key.
+func compactTag(compact string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(compact))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isCompactTag reports whether s has the shape of a compactTag, without
+// checking it.
+func isCompactTag(s string) bool {
+	if len(s) != base64.RawURLEncoding.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil
+}
+
+// cutLast is strings.Cut around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 // ParseCompact parses a compact-encoded share string back into a Share.
 // It validates the format, decodes the data, and verifies the short checksum.
+// The integrity tag of a string from CompactEncodeHMAC is allowed but not
+// checked; use ParseCompactHMAC for that.
 func ParseCompact(s string) (*Share, error) {
 	parts := strings.Split(s, ":")
+	if len(parts) == 7 && isCompactTag(parts[6]) {
+		parts = parts[:6]
+	}
 	if len(parts) != 6 {
 		return nil, fmt.Errorf("invalid compact share: expected 6 colon-separated fields, got %d", len(parts))
 	}
@@ -264,7 +703,8 @@ func ParseCompact(s string) (*Share, error) {
 	// Verify short checksum
 	expectedCheck := shortChecksum(data)
 	if parts[5] != expectedCheck {
-		return nil, fmt.Errorf("invalid compact share: checksum mismatch (got %s, want %s)", parts[5], expectedCheck)
+		return nil, &ParseError{Kind: ParseBadChecksum, Input: parts[5],
+			msg: fmt.Sprintf("invalid compact share: checksum mismatch (got %s, want %s)", parts[5], expectedCheck)}
 	}
 
 	return &Share{
@@ -277,6 +717,43 @@ func ParseCompact(s string) (*Share, error) {
 	}, nil
 }
 
+// ShareURIPrefix starts every share URI; see Share.URI.
+const ShareURIPrefix = "rememory://share/"
+
+// URI returns the share as a URI for NFC tags, QR codes and deep links:
+// rememory://share/v{version}/{compact}, where compact is CompactEncode.
+// Like the compact form it carries no holder name or creation date.
+func (s *Share) URI() string {
+	return fmt.Sprintf("%sv%d/%s", ShareURIPrefix, s.Version, s.CompactEncode())
+}
+
+// ParseURI parses a share URI made by Share.URI. The version in the path
+// must match the one in the compact share.
+func ParseURI(uri string) (*Share, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(uri), ShareURIPrefix)
+	if !ok {
+		return nil, fmt.Errorf("invalid share URI: must start with %q", ShareURIPrefix)
+	}
+
+	versionPart, compact, ok := strings.Cut(rest, "/")
+	if !ok || compact == "" {
+		return nil, fmt.Errorf("invalid share URI: expected %sv{version}/{share}", ShareURIPrefix)
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(versionPart, "v"))
+	if !strings.HasPrefix(versionPart, "v") || err != nil || version < 1 {
+		return nil, fmt.Errorf("invalid share URI: bad version %q", versionPart)
+	}
+
+	share, err := ParseCompact(compact)
+	if err != nil {
+		return nil, err
+	}
+	if share.Version != version {
+		return nil, fmt.Errorf("invalid share URI: path says v%d but the share is v%d", version, share.Version)
+	}
+	return share, nil
+}
+
 // shortChecksum returns the first 4 hex characters of the SHA-256 of data.
 func shortChecksum(data []byte) string {
 	h := sha256.Sum256(data)
@@ -326,3 +803,20 @@ func SanitizeFilename(name string) string {
 
 	return result
 }
+
+// SanitizeNote prepares free-form text (such as a project note) for printing
+// in README.txt, README.pdf and recover.html. Line endings are normalized to
+// "\n", other control characters except tabs are dropped, and surrounding
+// whitespace is trimmed. HTML escaping is left to the recovery tool, which
+// only ever inserts the note as text.
+func SanitizeNote(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ErrWrongSharePassword is returned by ParseEncryptedShare when the local
+// password doesn't open the share.
+var ErrWrongSharePassword = errors.New("wrong password for this share")
+
+// EncodeEncrypted returns the share's PEM block (as from Encode) encrypted
+// with localPassword, for a holder who keeps their share on a laptop and
+// wants it protected at rest. This is a second layer, separate from the
+// manifest's encryption: the password is the holder's own and never goes
+// into recovery. The result is an ASCII-armored age file (scrypt mode), so
+// any age tool can open it too, and ParseEncryptedShare reads it back.
+func (s *Share) EncodeEncrypted(localPassword string) ([]byte, error) {
+	plain := []byte(s.Encode())
+	defer Zeroize(plain)
+
+	var buf bytes.Buffer
+	w := armor.NewWriter(&buf)
+	if err := Encrypt(w, bytes.NewReader(plain), localPassword); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("finalizing armor: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// IsEncryptedShare reports whether content looks like the output of
+// EncodeEncrypted: an age file, armored or not.
+func IsEncryptedShare(content []byte) bool {
+	text := strings.TrimSpace(strings.TrimPrefix(string(content), string(byteOrderMark)))
+	return strings.HasPrefix(text, armor.Header) || strings.HasPrefix(text, "age-encryption.org/")
+}
+
+// ParseEncryptedShare decrypts content, from EncodeEncrypted, with
+// localPassword and parses the share inside. Binary age files are accepted
+// as well as armored ones. It returns ErrWrongSharePassword when the
+// password doesn't match.
+func ParseEncryptedShare(content []byte, localPassword string) (*Share, error) {
+	text := bytes.TrimSpace(bytes.TrimPrefix(content, []byte(string(byteOrderMark))))
+	var src []byte
+	if bytes.HasPrefix(text, []byte(armor.Header)) {
+		var err error
+		src, err = io.ReadAll(armor.NewReader(bytes.NewReader(text)))
+		if err != nil {
+			return nil, fmt.Errorf("reading encrypted share: %w", err)
+		}
+	} else {
+		src = content
+	}
+
+	plain, err := DecryptBytes(src, localPassword)
+	defer Zeroize(plain)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrWrongSharePassword
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseShare(plain)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxSubsetTries caps how many combinations TrySubsets will go through.
+// Each try is one combine and one hash, so this is well under a second.
+const MaxSubsetTries = 100000
+
+// SubsetSearch is the result of TrySubsets.
+type SubsetSearch struct {
+	// Secret is the secret from the first subset that matched the
+	// commitment. The caller should zeroize it once done with it.
+	Secret []byte
+	// Used holds the positions (0-based, in the order given) of the shares
+	// in that subset.
+	Used []int
+	// Tried and Matched count the subsets combined and the ones that gave
+	// back the committed secret.
+	Tried, Matched int
+	// Suspects holds the positions of shares that are in no matching
+	// subset: the odd ones out.
+	Suspects []int
+}
+
+// TrySubsets combines every threshold-sized subset of shares and checks
+// each result against commitment, from NewCommitment. It finds a good
+// quorum among pieces where some are silently wrong: they parse and pass
+// their checksums, but were damaged before the checksum was taken or come
+// from another seal. Shares that are in no matching subset are reported as
+// suspects.
+//
+// It returns ErrSecretMismatch when no subset matches, and an error when
+// there are more than MaxSubsetTries subsets to try.
+func TrySubsets(shares [][]byte, threshold int, commitment string) (*SubsetSearch, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2, got %d", threshold)
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("need at least %d shares, got %d", threshold, len(shares))
+	}
+	if err := checkSharePoints(shares); err != nil {
+		return nil, err
+	}
+	if n := binomial(len(shares), threshold); n > MaxSubsetTries {
+		return nil, fmt.Errorf("%d shares taken %d at a time make %.0f combinations, more than the %d that can be tried", len(shares), threshold, n, MaxSubsetTries)
+	}
+
+	search := &SubsetSearch{}
+	inMatch := make([]bool, len(shares))
+	subset := make([][]byte, threshold)
+	for _, combo := range combinations(len(shares), threshold) {
+		for i, pos := range combo {
+			subset[i] = shares[pos]
+		}
+		secret, err := Combine(subset)
+		if err != nil {
+			return nil, err
+		}
+		search.Tried++
+		if err := VerifyReconstructedSecret(secret, commitment); err != nil {
+			Zeroize(secret)
+			if errors.Is(err, ErrSecretMismatch) {
+				continue
+			}
+			if search.Secret != nil {
+				Zeroize(search.Secret)
+			}
+			return nil, err
+		}
+		search.Matched++
+		for _, pos := range combo {
+			inMatch[pos] = true
+		}
+		if search.Secret == nil {
+			search.Secret, search.Used = secret, combo
+		} else {
+			Zeroize(secret)
+		}
+	}
+
+	if search.Secret == nil {
+		return nil, ErrSecretMismatch
+	}
+	for i, ok := range inMatch {
+		if !ok {
+			search.Suspects = append(search.Suspects, i)
+		}
+	}
+	return search, nil
+}
+
+// combinations returns all k-element subsets of {0, 1, ..., n-1}, in
+// lexicographic order.
+func combinations(n, k int) [][]int {
+	var result [][]int
+	combo := make([]int, k)
+	var gen func(start, depth int)
+	gen = func(start, depth int) {
+		if depth == k {
+			dup := make([]int, k)
+			copy(dup, combo)
+			result = append(result, dup)
+			return
+		}
+		for i := start; i < n; i++ {
+			combo[depth] = i
+			gen(i+1, depth+1)
+		}
+	}
+	gen(0, 0)
+	return result
+}
+
+// binomial returns C(n, k) as a float, so large values don't overflow.
+func binomial(n, k int) float64 {
+	c := 1.0
+	for i := 1; i <= k; i++ {
+		c = c * float64(n-k+i) / float64(i)
+	}
+	return c
+}
@@ -0,0 +1,40 @@
+package core
+
+import "unicode/utf8"
+
+// TextSampleSize is how much of a file IsLikelyText needs to decide; callers
+// reading from disk can stop there.
+const TextSampleSize = 8 << 10
+
+// IsLikelyText reports whether data, or the first TextSampleSize bytes of
+// it, looks like UTF-8 text: no NUL bytes, valid UTF-8 (a character cut off
+// at the end of the sample is allowed), and hardly any control characters
+// other than tabs, line breaks and form feeds. Empty data counts as text.
+//
+// Text in other encodings, such as Latin-1 or UTF-16, is reported as not
+// text, since it only reads correctly with the right encoding chosen.
+func IsLikelyText(data []byte) bool {
+	if len(data) > TextSampleSize {
+		data = data[:TextSampleSize]
+	}
+	control := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			// A multi-byte character cut off by the sample is fine.
+			if len(data) == TextSampleSize && !utf8.FullRune(data[i:]) {
+				break
+			}
+			return false
+		}
+		switch {
+		case r == 0:
+			return false
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' && r != 0x1b, r == 0x7f:
+			control++
+		}
+		i += size
+	}
+	// Allow the odd stray control character, as in old text files.
+	return control*100 <= len(data)
+}
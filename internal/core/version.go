@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RequiresVersionHeader is the extra share header naming the oldest version
+// of rememory that should recover the share's manifest, as in
+// "Requires-Version: v0.0.12". Seal writes it when asked to pin the
+// version; every share from the seal carries the same value.
+const RequiresVersionHeader = "Requires-Version"
+
+// VersionTooOldError is returned by CheckVersionCompat when the running
+// version is older than the one required.
+type VersionTooOldError struct {
+	Required, Current string
+}
+
+func (e *VersionTooOldError) Error() string {
+	return fmt.Sprintf("these pieces were sealed for rememory %s or newer, and this is %s; it may not read the format, so download a newer version", e.Required, e.Current)
+}
+
+// CheckVersionCompat checks that current, the running version, is at least
+// required. Both are release versions like "v1.2.3" (the "v" is optional),
+// compared by semver precedence on major, minor and patch; anything after
+// them, such as "-rc1" or the "-3-gabc1234" of a build between releases,
+// is ignored. An empty required passes.
+//
+// A current version that isn't a release, such as "dev" for a build from
+// source, can't be compared and passes too. A required version that can't
+// be read is an error.
+func CheckVersionCompat(required, current string) error {
+	if required == "" {
+		return nil
+	}
+	want, ok := parseVersion(required)
+	if !ok {
+		return fmt.Errorf("required version %q isn't a version like v1.2.3", required)
+	}
+	have, ok := parseVersion(current)
+	if !ok {
+		return nil
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			if have[i] < want[i] {
+				return &VersionTooOldError{Required: required, Current: current}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// RequiredVersion returns the newest RequiresVersionHeader among shares, or
+// "" if none has one. Shares of one seal all carry the same value; taking
+// the newest keeps a mixed or edited set on the safe side.
+func RequiredVersion(shares []*Share) string {
+	var required string
+	for _, s := range shares {
+		v := s.Headers[RequiresVersionHeader]
+		if v == "" {
+			continue
+		}
+		if !IsReleaseVersion(v) {
+			// Not comparable; return it so the check reports it.
+			return v
+		}
+		// v is newer when required, run as the current version, is too old.
+		if required == "" || CheckVersionCompat(v, required) != nil {
+			required = v
+		}
+	}
+	return required
+}
+
+// IsReleaseVersion reports whether v can be compared by CheckVersionCompat.
+func IsReleaseVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// parseVersion reads the major, minor and patch numbers of v.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || p == "" || (len(p) > 1 && p[0] == '0') {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SplitWeighted divides a secret among holders who don't all count the
+// same, such as a lawyer whose piece should be worth two friends'. Each
+// holder gets as many points on the polynomial as their weight, and any
+// set of holders whose weights add up to threshold can reconstruct it:
+// with a threshold of 3, a weight-2 holder and a weight-1 holder are
+// enough, as are three weight-1 holders.
+//
+// The result maps each holder to their points, which belong together as
+// one logical share. To recover, pass every point of the holders present
+// to Combine; it doesn't care which holder a point came from.
+//
+// Weights must be at least 1 and add up to 255 or less. No holder may
+// reach the threshold alone, since they could then recover without anyone
+// else.
+func SplitWeighted(secret []byte, weights map[string]int, threshold int) (map[string][][]byte, error) {
+	if len(weights) < 2 {
+		return nil, fmt.Errorf("need at least 2 holders, got %d", len(weights))
+	}
+
+	// Hand out points in name order so the split is laid out the same
+	// way every time.
+	holders := make([]string, 0, len(weights))
+	total := 0
+	for holder, w := range weights {
+		if w < 1 {
+			return nil, fmt.Errorf("weight for %s must be at least 1, got %d", holder, w)
+		}
+		holders = append(holders, holder)
+		total += w
+	}
+	sort.Strings(holders)
+
+	if err := ValidateShamirParams(total, threshold); err != nil {
+		return nil, fmt.Errorf("weights add up to %d: %w", total, err)
+	}
+	for _, holder := range holders {
+		if weights[holder] >= threshold {
+			return nil, fmt.Errorf("%s has weight %d and could recover alone with a threshold of %d", holder, weights[holder], threshold)
+		}
+	}
+
+	points, err := Split(secret, total, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][][]byte, len(holders))
+	for _, holder := range holders {
+		w := weights[holder]
+		out[holder] = points[:w:w]
+		points = points[w:]
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// MinPassphraseWords is the fewest words GenerateWordPassphrase accepts.
+// Six BIP39 words carry 66 bits, which scrypt makes costly enough to guess.
+const MinPassphraseWords = 6
+
+// GenerateWordPassphrase returns a passphrase of words random entries from
+// lang's BIP39 word list, separated by spaces, for people who would rather
+// copy words than base64 by hand. Each word carries 11 bits; see
+// WordPassphraseBits. The words are used as listed, accents included, and
+// the whole string goes to age's scrypt mode like any other passphrase.
+func GenerateWordPassphrase(words int, lang Lang) (string, error) {
+	return generateWordPassphrase(rand.Reader, words, lang)
+}
+
+// generateWordPassphrase is GenerateWordPassphrase reading randomness from r.
+func generateWordPassphrase(r io.Reader, words int, lang Lang) (string, error) {
+	if words < MinPassphraseWords {
+		return "", fmt.Errorf("a word passphrase needs at least %d words, got %d", MinPassphraseWords, words)
+	}
+	wl := GetWordList(lang)
+	if wl == nil {
+		return "", fmt.Errorf("unknown word list language %q", lang)
+	}
+
+	// The list has exactly 2^11 words, so the low 11 bits of two random
+	// bytes pick one uniformly.
+	buf := make([]byte, 2*words)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("generating random bytes: %w", err)
+	}
+	defer Zeroize(buf)
+
+	picked := make([]string, words)
+	for i := range picked {
+		idx := (int(buf[2*i])<<8 | int(buf[2*i+1])) & (len(wl.Words) - 1)
+		picked[i] = wl.Words[idx]
+	}
+	return strings.Join(picked, " "), nil
+}
+
+// WordPassphraseBits returns the entropy, in bits, of a passphrase of words
+// random BIP39 words: words × log2(2048).
+func WordPassphraseBits(words int) float64 {
+	return float64(words) * math.Log2(2048)
+}
+
+// EncryptWithWordPassphrase generates a word passphrase (see
+// GenerateWordPassphrase), encrypts src to dst with it, and returns it.
+// Decrypt with Decrypt and the same string.
+func EncryptWithWordPassphrase(dst io.Writer, src io.Reader, words int, lang Lang) (string, error) {
+	passphrase, err := GenerateWordPassphrase(words, lang)
+	if err != nil {
+		return "", err
+	}
+	if err := Encrypt(dst, src, passphrase); err != nil {
+		return "", err
+	}
+	return passphrase, nil
+}
@@ -3,8 +3,56 @@ package core
 import (
 	"crypto/sha256"
 	"fmt"
+	"strings"
+	"unicode"
 )
 
+// byteOrderMark is the UTF-8 BOM that Windows editors put at the start of
+// text files, and that can come along when pasting from them.
+const byteOrderMark = '\uFEFF'
+
+// TokenizeWords splits pasted or typed text into words. A leading byte order
+// mark is dropped, and any run of whitespace (spaces, tabs, CR and LF,
+// non-breaking spaces) or invisible separators (zero-width spaces, stray
+// BOMs) counts as one break, so text copied from Windows or a word
+// processor splits the same as text typed by hand.
+func TokenizeWords(input string) []string {
+	input = strings.TrimPrefix(input, string(byteOrderMark))
+	return strings.FieldsFunc(input, isWordBreak)
+}
+
+// isWordBreak reports whether r separates words for TokenizeWords.
+func isWordBreak(r rune) bool {
+	switch r {
+	case byteOrderMark, '\u200B', '\u2060': // BOM, zero-width space, word joiner
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+// WordCountForBytes returns how many words EncodeWords gives for n bytes:
+// one per 11 bits, rounded up, so the last word may carry zero padding.
+// 32 bytes take 24 words, as do 33.
+func WordCountForBytes(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return (n*8 + 10) / 11
+}
+
+// ByteCountForWords returns how many bytes DecodeWords gives for w words:
+// 11 bits per word, rounded down, so padding bits short of a full byte are
+// dropped. A whole byte of padding is not, so ByteCountForWords of
+// WordCountForBytes(n) can be more than n (24 words decode to 33 bytes,
+// whether they came from 32 or 33). Callers that know the original size
+// truncate to it.
+func ByteCountForWords(w int) int {
+	if w <= 0 {
+		return 0
+	}
+	return w * 11 / 8
+}
+
 // EncodeWords converts bytes to BIP39 English words (11 bits per word).
 // 33 bytes (264 bits) produces exactly 24 words.
 func EncodeWords(data []byte) []string {
@@ -17,8 +65,7 @@ func EncodeWordsLang(data []byte, lang Lang) []string {
 	if wl == nil {
 		wl = GetWordList(LangEN)
 	}
-	totalBits := len(data) * 8
-	numWords := (totalBits + 10) / 11 // ceiling division
+	numWords := WordCountForBytes(len(data))
 
 	words := make([]string, numWords)
 	for i := 0; i < numWords; i++ {
@@ -53,25 +100,19 @@ func DecodeWords(words []string) ([]byte, error) {
 // DecodeWordsLang converts BIP39 words back to bytes using the given language's list.
 func DecodeWordsLang(words []string, lang Lang) ([]byte, error) {
 	if len(words) == 0 {
-		return nil, fmt.Errorf("no words provided")
+		return nil, &ParseError{Kind: ParseWrongCount, msg: "no words provided"}
 	}
 
 	indices := make([]int, len(words))
 	for i, w := range words {
-		idx, ok := LookupWord(lang, w)
+		idx, ok := lookupWordOrPrefix(lang, w)
 		if !ok {
-			suggestion := SuggestWordLang(w, lang)
-			if suggestion != "" {
-				return nil, fmt.Errorf("word %d %q not recognized — did you mean %q?", i+1, w, suggestion)
-			}
-			return nil, fmt.Errorf("word %d %q not recognized", i+1, w)
+			return nil, unrecognizedWordError(i+1, w, lang)
 		}
 		indices[i] = idx
 	}
 
-	totalBits := len(words) * 11
-	numBytes := totalBits / 8
-	result := make([]byte, numBytes)
+	result := make([]byte, ByteCountForWords(len(words)))
 
 	for i, idx := range indices {
 		set11Bits(result, i*11, idx)
@@ -104,10 +145,10 @@ func set11Bits(data []byte, bitOffset int, val int) {
 //
 // Index: share index (1-based) stored in upper 4 bits.
 //   - Shares 1–15: index stored directly.
-//   - Shares 16+:  index set to 0 (sentinel for "unknown").
-//     The system still works — the share data contains the Shamir
-//     x-coordinate needed for Combine(). The UI just can't identify
-//     which specific friend this share belongs to.
+//   - Shares 16+:  index set to 0 (sentinel for "see word 26").
+//     The system still works without word 26 — the share data contains
+//     the Shamir x-coordinate needed for Combine(). The UI just can't
+//     identify which specific friend this share belongs to.
 //
 // Checksum: lower 7 bits of SHA-256(data_bytes)[0].
 //   - Catches transpositions, word ordering mistakes, and typos that
@@ -120,6 +161,45 @@ const (
 	word25CheckMask = (1 << word25CheckBits) - 1 // 0x7F
 )
 
+// Word 26 layout (11 bits total), only written for shares 16+:
+//
+//	┌───────────────┬──────────────────┐
+//	│ index (8 hi)  │  checksum (3 lo) │
+//	│  bits 10-3    │    bits 2-0      │
+//	└───────────────┴──────────────────┘
+//
+// Index: the full share index, up to 255 (the most Shamir allows).
+// Checksum: lower 3 bits of SHA-256(index || data_bytes)[0], so a typo in
+// this word is caught 7 times out of 8 instead of naming the wrong friend.
+//
+// Shares 1–15 keep exactly 25 words, and 25 words of a longer share still
+// decode, with index 0 as before.
+const (
+	word26IndexBits = 8
+	word26CheckBits = 3
+	word26MaxIndex  = (1 << word26IndexBits) - 1 // 255
+	word26CheckMask = (1 << word26CheckBits) - 1 // 0x07
+)
+
+// word26Checksum computes the 3-bit checksum for the 26th word.
+func word26Checksum(shareIndex int, data []byte) int {
+	h := sha256.New()
+	h.Write([]byte{byte(shareIndex)})
+	h.Write(data)
+	return int(h.Sum(nil)[0]) & word26CheckMask
+}
+
+// word26Encode packs a share index above 15 and its checksum into an 11-bit
+// BIP39 word index.
+func word26Encode(shareIndex int, data []byte) int {
+	return (shareIndex << word26CheckBits) | word26Checksum(shareIndex, data)
+}
+
+// word26Decode unpacks the 26th word's 11-bit value into index and checksum.
+func word26Decode(val int) (index int, checksum int) {
+	return val >> word26CheckBits, val & word26CheckMask
+}
+
 // word25Checksum computes the 7-bit checksum for the 25th word.
 // It hashes the raw share data bytes and returns the lower 7 bits of byte 0.
 func word25Checksum(data []byte) int {
@@ -142,15 +222,18 @@ func word25Decode(val int) (index int, checksum int) {
 	return val >> word25CheckBits, val & word25CheckMask
 }
 
-// Words returns this share's data encoded as 25 BIP39 English words.
+// Words returns this share's data encoded as 25 BIP39 English words, or 26
+// when the share index is above 15.
 // The first 24 words encode the share data (33 bytes = 264 bits, 11 bits per word).
-// The 25th word packs 4 bits of share index + 7 bits of checksum (see word25 layout above).
-// Returns an error for v1 shares or if the share index is negative.
+// The 25th word packs 4 bits of share index + 7 bits of checksum (see word25 layout above),
+// and the 26th, when present, the full index (see word26 layout above).
+// Returns an error for v1 shares or if the share index is negative or above 255.
 func (s *Share) Words() ([]string, error) {
 	return s.WordsForLang(LangEN)
 }
 
-// WordsForLang returns this share's data encoded as 25 BIP39 words in the given language.
+// WordsForLang returns this share's data encoded as BIP39 words in the given
+// language, 25 or 26 of them as for Words.
 func (s *Share) WordsForLang(lang Lang) ([]string, error) {
 	if s.Version < 2 {
 		return nil, fmt.Errorf("word encoding requires share version 2 or later (got v%d)", s.Version)
@@ -158,6 +241,9 @@ func (s *Share) WordsForLang(lang Lang) ([]string, error) {
 	if s.Index < 0 {
 		return nil, fmt.Errorf("share index must be non-negative (got %d)", s.Index)
 	}
+	if s.Index > word26MaxIndex {
+		return nil, fmt.Errorf("share index must be at most %d for word encoding (got %d)", word26MaxIndex, s.Index)
+	}
 	wl := GetWordList(lang)
 	if wl == nil {
 		wl = GetWordList(LangEN)
@@ -165,63 +251,265 @@ func (s *Share) WordsForLang(lang Lang) ([]string, error) {
 	words := EncodeWordsLang(s.Data, lang)
 	bip39Idx := word25Encode(s.Index, s.Data)
 	words = append(words, wl.Words[bip39Idx])
+	if s.Index > word25MaxIndex {
+		words = append(words, wl.Words[word26Encode(s.Index, s.Data)])
+	}
 	return words, nil
 }
 
-// DecodeShareWords decodes 25 BIP39 words into share data and index.
+// checkShareWordCount reports whether n words can be a share: 25, or 26
+// when the share index is above 15.
+func checkShareWordCount(n int) error {
+	if msg := ClassifyWordCount(n); msg != "" {
+		return &ParseError{Kind: ParseWrongCount, msg: msg}
+	}
+	return nil
+}
+
+// ClassifyWordCount explains why n words can't be a share, guessing what
+// went wrong in writing them down: 24 is most likely the last word
+// forgotten, more than 26 a stray or doubled word. It returns "" for 25
+// and 26, the two lengths a share comes in.
+func ClassifyWordCount(n int) string {
+	switch {
+	case n == 25 || n == 26:
+		return ""
+	case n <= 0:
+		return "no words entered, expected 25 words"
+	case n == 24:
+		return "you entered 24 words, expected 25 words: did you forget the last one? It holds the piece number and a checksum"
+	case n < 24:
+		return fmt.Sprintf("you entered %d %s, expected 25 words: %d are missing", n, pluralWords(n), 25-n)
+	case n == 27:
+		return fmt.Sprintf("you entered 27 words, expected 25 words (26 for pieces above %d): is there a stray word, or one written twice?", word25MaxIndex)
+	default:
+		return fmt.Sprintf("you entered %d words, expected 25 words (26 for pieces above %d): %d too many", n, word25MaxIndex, n-26)
+	}
+}
+
+func pluralWords(n int) string {
+	if n == 1 {
+		return "word"
+	}
+	return "words"
+}
+
+// WordHints returns one hint per word, for a holder to check their words
+// with someone over the phone without reading them all out: the word's
+// position, its first and last letters with the rest blanked, and its
+// length, as in "12. s____n (6 letters)". Words of one or two letters
+// would be given away whole, so only their length is shown, as in
+// "3. _ (1 letter)"; that is all a word from the Chinese list gets.
+func WordHints(words []string) []string {
+	hints := make([]string, len(words))
+	for i, word := range words {
+		letters := []rune(word)
+		n := len(letters)
+		var pattern string
+		if n <= 2 {
+			pattern = strings.Repeat("_", n)
+		} else {
+			pattern = string(letters[0]) + strings.Repeat("_", n-2) + string(letters[n-1])
+		}
+		unit := "letters"
+		if n == 1 {
+			unit = "letter"
+		}
+		hints[i] = fmt.Sprintf("%d. %s (%d %s)", i+1, pattern, n, unit)
+	}
+	return hints
+}
+
+// DecodeShareWords decodes 25 or 26 BIP39 words into share data and index.
 // Auto-detects the word list language. The first 24 words are decoded to bytes;
-// the 25th word carries index + checksum.
-// Returns index=0 if the share index was > 15 (the sentinel value).
+// the 25th word carries index + checksum, and a 26th the full index of shares above 15.
+// Returns index=0 if the share index was > 15 (the sentinel value) and the 26th word was left off.
+// The data still carries its Shamir x-coordinate, so such a share combines
+// with the others as usual; only its number is unknown.
 // Returns an error if the checksum doesn't match (wrong word order, typos, etc.).
 func DecodeShareWords(words []string) (data []byte, index int, err error) {
 	data, index, _, err = DecodeShareWordsAuto(words)
 	return
 }
 
-// DecodeShareWordsAuto decodes 25 BIP39 words with auto-detected language.
+// DecodeShareWordsAuto decodes 25 or 26 BIP39 words with auto-detected language.
 // Returns the decoded data, share index, detected language, and any error.
 func DecodeShareWordsAuto(words []string) (data []byte, index int, lang Lang, err error) {
-	if len(words) != 25 {
-		return nil, 0, "", fmt.Errorf("expected 25 words, got %d", len(words))
+	if err := checkShareWordCount(len(words)); err != nil {
+		return nil, 0, "", err
+	}
+	lang, err = detectShareWordsLang(words)
+	if err != nil {
+		return nil, 0, "", err
 	}
+	data, index, err = decodeShareWords(words, lang)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return data, index, lang, nil
+}
+
+// DecodeShareWordsLenient is DecodeShareWords for recovering at all costs:
+// when the words decode but a checksum doesn't match, it returns the data
+// anyway with checksumOK false, instead of an error. The operator can then
+// try to combine it with the other pieces. If one of the 24 data words is
+// what's wrong, the passphrase that comes out is wrong too and decrypting
+// fails, so nothing is recovered by mistake; if only the checksum word was
+// miscopied, recovery works.
+//
+// With checksumOK false, index comes from a word that may be the wrong one,
+// so it may be wrong as well; Combine doesn't use it. Words that aren't in
+// any word list, or the wrong number of words, are still errors.
+func DecodeShareWordsLenient(words []string) (data []byte, index int, checksumOK bool, err error) {
+	if err := checkShareWordCount(len(words)); err != nil {
+		return nil, 0, false, err
+	}
+	lang, err := detectShareWordsLang(words)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	data, index, checkErr, err := decodeShareWordsChecked(words, lang)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return data, index, checkErr == nil, nil
+}
 
-	lang = DetectWordListLang(words)
+// detectShareWordsLang finds the word list the words of a share are from,
+// with a suggestion for the first unrecognized word when it can't tell.
+func detectShareWordsLang(words []string) (Lang, error) {
+	lang := DetectWordListLang(words)
 	if lang == "" {
-		// Try to give a helpful suggestion from any language
-		for _, w := range words {
-			if suggestion := SuggestWordAllLangs(w); suggestion != "" {
-				return nil, 0, "", fmt.Errorf("could not identify word list language — word %q not recognized, did you mean %q?", w, suggestion)
+		// The words may have been written down by their first letters only.
+		lang = detectWordListLangByPrefix(words)
+	}
+	if lang != "" {
+		return lang, nil
+	}
+
+	// Too many words are off to be sure, but suggestions still come from
+	// the list most of the recognized words are in, if there is one.
+	closest, _ := closestWordListLang(words)
+	for i, w := range words {
+		if closest != "" {
+			if _, ok := LookupWord(closest, w); ok {
+				continue
+			}
+			if suggestion := SuggestWordLang(w, closest); suggestion != "" {
+				return "", &ParseError{Kind: ParseUnknownWord, Position: i + 1, Input: w, Suggestion: suggestion,
+					msg: fmt.Sprintf("could not identify word list language (closest is %s) — word %d %q not recognized, did you mean %q?", closest, i+1, w, suggestion)}
+			}
+		} else if suggestion := SuggestWordAllLangs(w); suggestion != "" {
+			return "", &ParseError{Kind: ParseUnknownWord, Position: i + 1, Input: w, Suggestion: suggestion,
+				msg: fmt.Sprintf("could not identify word list language — word %d %q not recognized, did you mean %q?", i+1, w, suggestion)}
+		}
+	}
+	return "", fmt.Errorf("could not identify word list language")
+}
+
+// DecodeShareWordsLang decodes 25 or 26 BIP39 words using lang's word list,
+// without detecting the language. Detection can guess wrong when most of the
+// words happen to appear in more than one list; this lets the caller decide.
+func DecodeShareWordsLang(words []string, lang Lang) (data []byte, index int, err error) {
+	if err := checkShareWordCount(len(words)); err != nil {
+		return nil, 0, err
+	}
+	if GetWordList(lang) == nil {
+		return nil, 0, fmt.Errorf("unknown word list language %q", lang)
+	}
+
+	// Words from another list would otherwise fail one at a time with
+	// suggestions from the wrong language, so say what they look like.
+	for _, w := range words {
+		if _, ok := lookupWordOrPrefix(lang, w); !ok {
+			if detected := DetectWordListLang(words); detected != "" && detected != lang {
+				return nil, 0, fmt.Errorf("these words are not from the %s word list (they look like %s)", lang, detected)
 			}
+			break
 		}
-		return nil, 0, "", fmt.Errorf("could not identify word list language")
 	}
 
+	return decodeShareWords(words, lang)
+}
+
+// decodeShareWords decodes 25 or 26 words known to be in lang's word list.
+func decodeShareWords(words []string, lang Lang) (data []byte, index int, err error) {
+	data, index, checkErr, err := decodeShareWordsChecked(words, lang)
+	if err != nil {
+		return nil, 0, err
+	}
+	if checkErr != nil {
+		return nil, 0, checkErr
+	}
+	return data, index, nil
+}
+
+// decodeShareWordsChecked decodes 25 or 26 words known to be in lang's word
+// list. A word that can't be read is returned as err; when the words read
+// but their checksums don't agree with the data, the data is returned
+// along with checkErr saying which check failed.
+func decodeShareWordsChecked(words []string, lang Lang) (data []byte, index int, checkErr, err error) {
 	// Look up the 25th word
-	lastIdx, ok := LookupWord(lang, words[len(words)-1])
+	lastIdx, ok := lookupWordOrPrefix(lang, words[24])
 	if !ok {
-		suggestion := SuggestWordLang(words[len(words)-1], lang)
-		if suggestion != "" {
-			return nil, 0, "", fmt.Errorf("word %d %q not recognized — did you mean %q?", len(words), words[len(words)-1], suggestion)
-		}
-		return nil, 0, "", fmt.Errorf("word %d %q not recognized", len(words), words[len(words)-1])
+		return nil, 0, nil, unrecognizedWordError(25, words[24], lang)
 	}
 
-	// Decode the data words (all but the last)
-	data, err = DecodeWordsLang(words[:len(words)-1], lang)
+	// Decode the data words
+	data, err = DecodeWordsLang(words[:24], lang)
 	if err != nil {
-		return nil, 0, "", err
+		return nil, 0, nil, err
 	}
 
-	// Unpack index and checksum from the 25th word
+	// Unpack index and checksum from the 25th word, and check the data
 	index, expectedCheck := word25Decode(lastIdx)
+	if word25Checksum(data) != expectedCheck {
+		checkErr = &ParseError{Kind: ParseBadChecksum, Position: 25, Input: words[24],
+			msg: "word checksum failed — check word order and spelling"}
+	}
 
-	// Verify checksum against the decoded data
-	actualCheck := word25Checksum(data)
-	if actualCheck != expectedCheck {
-		return nil, 0, "", fmt.Errorf("word checksum failed — check word order and spelling")
+	if len(words) == 26 {
+		if index != 0 && checkErr == nil {
+			return data, index, &ParseError{Kind: ParseWrongCount, Position: 26, Input: words[25],
+				msg: fmt.Sprintf("word 26 is only used for pieces numbered above %d, but this is piece %d", word25MaxIndex, index)}, nil
+		}
+		extraIdx, ok := lookupWordOrPrefix(lang, words[25])
+		if !ok {
+			return nil, 0, nil, unrecognizedWordError(26, words[25], lang)
+		}
+		extraIndex, extraCheck := word26Decode(extraIdx)
+		if extraIndex <= word25MaxIndex || word26Checksum(extraIndex, data) != extraCheck {
+			if checkErr == nil {
+				checkErr = &ParseError{Kind: ParseBadChecksum, Position: 26, Input: words[25],
+					msg: "word 26 checksum failed — check its spelling"}
+			}
+			index = 0
+		} else {
+			index = extraIndex
+		}
 	}
 
-	return data, index, lang, nil
+	return data, index, checkErr, nil
+}
+
+// unrecognizedWordError reports that word n, w, isn't in lang's list, with
+// the closest word from that same list as a suggestion when there is one.
+func unrecognizedWordError(n int, w string, lang Lang) error {
+	if matches := wordsWithPrefix(lang, w); len(matches) > 1 {
+		wl := GetWordList(lang)
+		candidates := make([]string, len(matches))
+		for i, m := range matches {
+			candidates[i] = fmt.Sprintf("%q", wl.Words[m])
+		}
+		return &ParseError{Kind: ParseUnknownWord, Position: n, Input: w,
+			msg: fmt.Sprintf("word %d %q could be %s in the %s word list — type more of it", n, w, strings.Join(candidates, " or "), lang)}
+	}
+	if suggestion := SuggestWordLang(w, lang); suggestion != "" {
+		return &ParseError{Kind: ParseUnknownWord, Position: n, Input: w, Suggestion: suggestion,
+			msg: fmt.Sprintf("word %d %q is not in the %s word list — did you mean %q?", n, w, lang, suggestion)}
+	}
+	return &ParseError{Kind: ParseUnknownWord, Position: n, Input: w,
+		msg: fmt.Sprintf("word %d %q is not in the %s word list", n, w, lang)}
 }
 
 // SuggestWord finds the closest BIP39 English word by Levenshtein distance (max 2).
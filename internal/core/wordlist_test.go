@@ -2,8 +2,12 @@ package core
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestEncodeDecodeRoundTrip(t *testing.T) {
@@ -37,6 +41,9 @@ func TestEncodeDecodeRoundTrip(t *testing.T) {
 
 			// Decoded length is totalBits/8, which may truncate trailing padding bits
 			expectedLen := (len(words) * 11) / 8
+			if got := ByteCountForWords(len(words)); got != expectedLen {
+				t.Errorf("ByteCountForWords(%d) = %d, want %d", len(words), got, expectedLen)
+			}
 			if len(decoded) != expectedLen {
 				t.Fatalf("decoded length: got %d, want %d", len(decoded), expectedLen)
 			}
@@ -49,6 +56,33 @@ func TestEncodeDecodeRoundTrip(t *testing.T) {
 	}
 }
 
+func TestWordCountForBytes(t *testing.T) {
+	tests := []struct{ bytes, words int }{
+		{33, 24},
+		{32, 24},
+		{45, 33},
+		{1, 1},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := WordCountForBytes(tt.bytes); got != tt.words {
+			t.Errorf("WordCountForBytes(%d) = %d, want %d", tt.bytes, got, tt.words)
+		}
+	}
+
+	// Decoding gives back at least as many bytes as were encoded, and less
+	// than one more word's worth.
+	for n := 0; n <= 100; n++ {
+		w := WordCountForBytes(n)
+		if got := len(EncodeWords(make([]byte, n))); got != w {
+			t.Fatalf("EncodeWords of %d bytes gave %d words, WordCountForBytes says %d", n, got, w)
+		}
+		if b := ByteCountForWords(w); b < n || b*8 >= n*8+11 {
+			t.Errorf("ByteCountForWords(%d) = %d for %d bytes", w, b, n)
+		}
+	}
+}
+
 func TestEncodeWords24(t *testing.T) {
 	// 33 bytes = 264 bits = exactly 24 words (no padding needed)
 	data := make([]byte, 33)
@@ -130,6 +164,34 @@ func TestBIP39ListIntegrity(t *testing.T) {
 	}
 }
 
+func TestVerifyWordLists(t *testing.T) {
+	if err := VerifyWordLists(); err != nil {
+		t.Fatalf("embedded word lists: %v", err)
+	}
+
+	// Swap one French word and the check should name French.
+	lists := make(map[Lang]*WordListInfo)
+	for _, lang := range AllLangs() {
+		lists[lang] = GetWordList(lang)
+	}
+	altered := *GetWordList(LangFR)
+	altered.Words[100] = "baguette"
+	lists[LangFR] = &altered
+
+	err := verifyWordLists(lists)
+	if err == nil || !strings.Contains(err.Error(), "word list fr") {
+		t.Errorf("altered list: got %v, want an error naming fr", err)
+	}
+	if GetWordList(LangFR).Words[100] == "baguette" {
+		t.Fatal("test altered the embedded list")
+	}
+
+	delete(lists, LangFR)
+	if err := verifyWordLists(lists); err == nil || !strings.Contains(err.Error(), "fr is missing") {
+		t.Errorf("missing list: got %v", err)
+	}
+}
+
 func TestEncodeWordsDeterministic(t *testing.T) {
 	data := make([]byte, 33)
 	for i := range data {
@@ -173,17 +235,18 @@ func TestShareWords(t *testing.T) {
 
 func TestDecodeShareWordsRoundTrip(t *testing.T) {
 	tests := []struct {
-		name          string
-		index         int
-		expectedIndex int // what DecodeShareWords should return (0 for >15)
+		name  string
+		index int
+		count int // 25 words, or 26 for indices above 15
 	}{
-		{"index 1", 1, 1},
-		{"index 2", 2, 2},
-		{"index 5", 5, 5},
-		{"index 15 (max exact)", 15, 15},
-		{"index 16 (sentinel)", 16, 0},   // above 15 → stored as 0
-		{"index 100 (sentinel)", 100, 0}, // above 15 → stored as 0
-		{"index 255 (sentinel)", 255, 0}, // above 15 → stored as 0
+		{"index 1", 1, 25},
+		{"index 2", 2, 25},
+		{"index 5", 5, 25},
+		{"index 15 (max in word 25)", 15, 25},
+		{"index 16 (word 26)", 16, 26},
+		{"index 20 (word 26)", 20, 26},
+		{"index 100 (word 26)", 100, 26},
+		{"index 255 (word 26)", 255, 26},
 	}
 
 	for _, tt := range tests {
@@ -197,8 +260,8 @@ func TestDecodeShareWordsRoundTrip(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Words() error: %v", err)
 			}
-			if len(words) != 25 {
-				t.Fatalf("expected 25 words, got %d", len(words))
+			if len(words) != tt.count {
+				t.Fatalf("expected %d words, got %d", tt.count, len(words))
 			}
 
 			decoded, index, err := DecodeShareWords(words)
@@ -208,13 +271,170 @@ func TestDecodeShareWordsRoundTrip(t *testing.T) {
 			if !bytes.Equal(decoded, data) {
 				t.Errorf("data mismatch")
 			}
-			if index != tt.expectedIndex {
-				t.Errorf("index: got %d, want %d", index, tt.expectedIndex)
+			if index != tt.index {
+				t.Errorf("index: got %d, want %d", index, tt.index)
+			}
+
+			// Without word 26, the first 25 words still decode, with the
+			// sentinel index as before.
+			if tt.count == 26 {
+				decoded, index, err := DecodeShareWords(words[:25])
+				if err != nil {
+					t.Fatalf("DecodeShareWords(25 words) error: %v", err)
+				}
+				if !bytes.Equal(decoded, data) || index != 0 {
+					t.Errorf("25 words: got index %d, want 0 and the same data", index)
+				}
 			}
 		})
 	}
 }
 
+// TestWord26 checks the 26th word for indices above 15: it works in other
+// word lists, catches typos, and isn't accepted after a piece numbered 1–15.
+func TestWord26(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+
+	for _, index := range []int{20, 100} {
+		share := NewShare(2, index, 120, 3, "Test", data)
+		words, err := share.WordsForLang(LangES)
+		if err != nil {
+			t.Fatalf("WordsForLang error: %v", err)
+		}
+		decoded, got, lang, err := DecodeShareWordsAuto(words)
+		if err != nil {
+			t.Fatalf("index %d: %v", index, err)
+		}
+		if !bytes.Equal(decoded, data) || got != index || lang != LangES {
+			t.Errorf("index %d: got index %d in %s", index, got, lang)
+		}
+
+		// Any other word 26 names another index or fails its checksum; a
+		// 3-bit checksum catches most, and none may decode to this index.
+		wl := GetWordList(LangEN)
+		enWords, _ := share.Words()
+		caught := 0
+		for _, w := range wl.Words {
+			if w == enWords[25] {
+				continue
+			}
+			tampered := append(append([]string{}, enWords[:25]...), w)
+			_, got, err := DecodeShareWords(tampered)
+			if err != nil {
+				caught++
+			} else if got == index {
+				t.Fatalf("index %d: word 26 %q also decodes to index %d", index, w, got)
+			}
+		}
+		if caught < len(wl.Words)*3/4 {
+			t.Errorf("index %d: only %d of %d wrong 26th words caught", index, caught, len(wl.Words)-1)
+		}
+	}
+
+	low := NewShare(2, 5, 20, 3, "Test", data)
+	lowWords, _ := low.Words()
+	high := NewShare(2, 20, 20, 3, "Test", data)
+	highWords, _ := high.Words()
+	if _, _, err := DecodeShareWords(append(lowWords, highWords[25])); err == nil {
+		t.Error("expected error for word 26 after piece 5")
+	}
+
+	if _, err := NewShare(2, 256, 300, 3, "Test", data).Words(); err == nil {
+		t.Error("expected error for index 256")
+	}
+}
+
+func TestDecodeShareWordsLenient(t *testing.T) {
+	secret := []byte("the boat key is in the blue jar.")
+	shares, err := SplitToShares(secret, 20, 2, make([]string, 20), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := shares[2].Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	en := GetWordList(LangEN)
+	// otherWord returns a word in place of w whose 11-bit value differs in
+	// the lowest bit, so a checksum bit in the 25th word.
+	otherWord := func(w string) string {
+		idx, ok := LookupWord(LangEN, w)
+		if !ok {
+			t.Fatalf("%q not in the English list", w)
+		}
+		return en.Words[idx^1]
+	}
+
+	data, index, ok, err := DecodeShareWordsLenient(words)
+	if err != nil || !ok || index != 3 || !bytes.Equal(data, shares[2].Data) {
+		t.Fatalf("intact words: index %d, checksumOK %v, err %v", index, ok, err)
+	}
+
+	// A miscopied 25th word: the strict decoder refuses, the lenient one
+	// returns the data flagged, and it still combines.
+	bad := append([]string{}, words...)
+	bad[24] = otherWord(bad[24])
+	if _, _, err := DecodeShareWords(bad); err == nil {
+		t.Fatal("strict decoding should fail on a wrong checksum")
+	}
+	data, _, ok, err = DecodeShareWordsLenient(bad)
+	if err != nil {
+		t.Fatalf("lenient decoding returned an error: %v", err)
+	}
+	if ok {
+		t.Error("checksumOK should be false")
+	}
+	if !bytes.Equal(data, shares[2].Data) {
+		t.Error("data should be decoded despite the checksum")
+	}
+	got, err := Combine([][]byte{data, shares[7].Data})
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("combine with the flagged piece: %q, %v", got, err)
+	}
+
+	// A wrong data word is flagged the same way, and gives a wrong secret.
+	// The checksum is 7 bits, so about one change in 128 slips past it;
+	// use the first that doesn't.
+	for i := range 24 {
+		bad = append([]string{}, words...)
+		bad[i] = otherWord(bad[i])
+		if _, _, err := DecodeShareWords(bad); err != nil {
+			break
+		}
+	}
+	data, _, ok, err = DecodeShareWordsLenient(bad)
+	if err != nil || ok {
+		t.Fatalf("wrong data word: checksumOK %v, err %v", ok, err)
+	}
+	if got, _ := Combine([][]byte{data, shares[7].Data}); bytes.Equal(got, secret) {
+		t.Error("a wrong data word should not give the secret")
+	}
+
+	// For pieces above 15, an intact 26th word still gives the number.
+	words20, err := shares[19].Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	words20[24] = otherWord(words20[24])
+	_, index, ok, err = DecodeShareWordsLenient(words20)
+	if err != nil || ok || index != 20 {
+		t.Errorf("piece 20 with a wrong 25th word: index %d, checksumOK %v, err %v", index, ok, err)
+	}
+
+	// Words that can't be read are still errors.
+	bad = append([]string{}, words...)
+	bad[3] = "notaword"
+	if _, _, _, err := DecodeShareWordsLenient(bad); err == nil {
+		t.Error("expected error for an unknown word")
+	}
+	if _, _, _, err := DecodeShareWordsLenient(words[:24]); err == nil {
+		t.Error("expected error for 24 words")
+	}
+}
+
 // TestWord25ChecksumDetectsTransposition verifies that swapping two adjacent
 // data words causes the 25th-word checksum to fail.
 func TestWord25ChecksumDetectsTransposition(t *testing.T) {
@@ -357,7 +577,7 @@ func TestDecodeShareWordsWrongCount(t *testing.T) {
 		{"1 word", 1},
 		{"10 words", 10},
 		{"24 words", 24},
-		{"26 words", 26},
+		{"27 words", 27},
 	}
 
 	for _, tt := range tests {
@@ -377,6 +597,49 @@ func TestDecodeShareWordsWrongCount(t *testing.T) {
 	}
 }
 
+func TestClassifyWordCount(t *testing.T) {
+	tests := []struct {
+		count int
+		want  string // "" for a valid count
+	}{
+		{24, "did you forget the last one?"},
+		{25, ""},
+		{26, ""},
+		{23, "you entered 23 words, expected 25 words: 2 are missing"},
+		{27, "is there a stray word, or one written twice?"},
+		{30, "4 too many"},
+		{1, "you entered 1 word,"},
+		{0, "no words entered"},
+	}
+	for _, tt := range tests {
+		got := ClassifyWordCount(tt.count)
+		if tt.want == "" {
+			if got != "" {
+				t.Errorf("ClassifyWordCount(%d) = %q, want valid", tt.count, got)
+			}
+			continue
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("ClassifyWordCount(%d) = %q, want it to contain %q", tt.count, got, tt.want)
+		}
+	}
+
+	// Pasting a piece one word short gets the same explanation.
+	share := NewShare(2, 3, 5, 3, "Carol", bytes.Repeat([]byte{7}, 33))
+	words, err := share.Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseShareAny([]byte(strings.Join(words[:24], " ")))
+	if err == nil || !strings.Contains(err.Error(), "you entered 24 words") {
+		t.Errorf("24 words: got %v", err)
+	}
+	_, err = ParseShareAny([]byte(strings.Join(append(words, "zoo", "zoo"), " ")))
+	if err == nil || !strings.Contains(err.Error(), "you entered 27 words") {
+		t.Errorf("27 words: got %v", err)
+	}
+}
+
 func TestDecodeWordsMixedCase(t *testing.T) {
 	data := make([]byte, 33)
 	for i := range data {
@@ -426,3 +689,106 @@ func TestWordsNegativeIndexReturnsError(t *testing.T) {
 		t.Errorf("expected non-negative error, got: %v", err)
 	}
 }
+
+func TestParseError(t *testing.T) {
+	data := bytes.Repeat([]byte{0x5c}, 33)
+	share := NewShare(2, 4, 5, 3, "", data)
+	words, err := share.Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := func(i int, w string) []string {
+		out := append([]string{}, words...)
+		out[i] = w
+		return out
+	}
+	parseError := func(t *testing.T, err error) *ParseError {
+		t.Helper()
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("expected a *ParseError, got %T: %v", err, err)
+		}
+		return pe
+	}
+
+	t.Run("unknown word", func(t *testing.T) {
+		_, err := ParseShareAny([]byte(strings.Join(with(6, "abandn"), " ")))
+		pe := parseError(t, err)
+		if pe.Kind != ParseUnknownWord || pe.Position != 7 || pe.Input != "abandn" || pe.Suggestion != "abandon" {
+			t.Errorf("got %+v", *pe)
+		}
+		if want := `word 7 "abandn" is not in the en word list — did you mean "abandon"?`; err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("bad checksum", func(t *testing.T) {
+		idx, _ := LookupWord(LangEN, words[24])
+		_, _, err := DecodeShareWords(with(24, GetWordList(LangEN).Words[idx^1]))
+		pe := parseError(t, err)
+		if pe.Kind != ParseBadChecksum || pe.Position != 25 {
+			t.Errorf("got %+v", *pe)
+		}
+		if err.Error() != "word checksum failed — check word order and spelling" {
+			t.Errorf("Error() = %q", err.Error())
+		}
+	})
+
+	t.Run("wrong count", func(t *testing.T) {
+		_, err := ParseShareAny([]byte(strings.Join(words[:24], " ")))
+		pe := parseError(t, err)
+		if pe.Kind != ParseWrongCount || pe.Position != 0 {
+			t.Errorf("got %+v", *pe)
+		}
+		if err.Error() != ClassifyWordCount(24) {
+			t.Errorf("Error() = %q", err.Error())
+		}
+	})
+
+	t.Run("compact checksum", func(t *testing.T) {
+		compact := share.CompactEncode()
+		bad := compact[:len(compact)-4] + "0000"
+		if bad == compact {
+			bad = compact[:len(compact)-4] + "1111"
+		}
+		_, err := ParseCompact(bad)
+		pe := parseError(t, err)
+		if pe.Kind != ParseBadChecksum || pe.Input != bad[len(bad)-4:] {
+			t.Errorf("got %+v", *pe)
+		}
+		if !strings.HasPrefix(err.Error(), "invalid compact share: checksum mismatch (got ") {
+			t.Errorf("Error() = %q", err.Error())
+		}
+	})
+}
+
+func TestWordHints(t *testing.T) {
+	got := WordHints([]string{"abandon", "zoo", "sun", "ñandú", "是", "ab"})
+	want := []string{
+		"1. a_____n (7 letters)",
+		"2. z_o (3 letters)",
+		"3. s_n (3 letters)",
+		"4. ñ___ú (5 letters)",
+		"5. _ (1 letter)",
+		"6. __ (2 letters)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("WordHints =\n%q\nwant\n%q", got, want)
+	}
+
+	// A real piece's words: no hint gives a word away.
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	shares, err := SplitToShares(secret, 3, 2, []string{"", "", ""}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := shares[0].Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, hint := range WordHints(words) {
+		if !strings.HasPrefix(hint, fmt.Sprintf("%d. ", i+1)) || strings.Contains(hint, words[i]) {
+			t.Errorf("hint %q gives away %q", hint, words[i])
+		}
+	}
+}
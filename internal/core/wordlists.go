@@ -5,9 +5,11 @@ import (
 	"embed"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/unicode/norm"
 )
@@ -28,9 +30,24 @@ const (
 	LangZH_TW Lang = "zh-TW"
 )
 
-// AllLangs returns all supported word list languages.
+// AllLangs returns all supported word list languages: the built-in ones,
+// then any added with RegisterWordList, in the order they were added.
 func AllLangs() []Lang {
-	return []Lang{LangEN, LangES, LangFR, LangDE, LangSL, LangPT, LangZH_TW}
+	langs := []Lang{LangEN, LangES, LangFR, LangDE, LangSL, LangPT, LangZH_TW}
+	return append(langs, customLangs...)
+}
+
+// ParseLang returns the supported word list language named s ("en",
+// "zh-TW", ...), ignoring case. The error lists the valid names.
+func ParseLang(s string) (Lang, error) {
+	names := make([]string, 0, len(AllLangs()))
+	for _, l := range AllLangs() {
+		if strings.EqualFold(s, string(l)) {
+			return l, nil
+		}
+		names = append(names, string(l))
+	}
+	return "", fmt.Errorf("unknown word list language %q (valid: %s)", s, strings.Join(names, ", "))
 }
 
 // WordListInfo describes a BIP39 word list: its source, expected hash, and words.
@@ -102,6 +119,62 @@ func GetWordList(lang Lang) *WordListInfo {
 	return wordListRegistry[lang]
 }
 
+// customLangs holds the languages added with RegisterWordList, in order.
+var (
+	customLangs []Lang
+	registerMu  sync.Mutex
+)
+
+// RegisterWordList adds a custom word list under lang, so that words can be
+// encoded, decoded and detected in it like in the built-in lists. words must
+// be exactly 2048 distinct words (ignoring case), each non-empty and without
+// spaces. A built-in language, or one already registered, can't be replaced.
+//
+// Call it from an init function or early in main, before any words are
+// encoded or decoded: lookups don't take a lock. Custom lists are only known
+// to this process; the recovery tool in recover.html doesn't have them.
+func RegisterWordList(lang Lang, words []string) error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	if lang == "" || strings.ContainsFunc(string(lang), unicode.IsSpace) {
+		return fmt.Errorf("word list language %q is not a valid name", lang)
+	}
+	if existing, err := ParseLang(string(lang)); err == nil {
+		if slices.Contains(customLangs, existing) {
+			return fmt.Errorf("word list %s is already registered", existing)
+		}
+		return fmt.Errorf("word list %s is built in and can't be replaced", existing)
+	}
+	if len(words) != 2048 {
+		return fmt.Errorf("word list %s has %d words, expected 2048", lang, len(words))
+	}
+	seen := make(map[string]int, len(words))
+	for i, w := range words {
+		if w == "" {
+			return fmt.Errorf("word list %s: word %d is empty", lang, i+1)
+		}
+		if strings.ContainsFunc(w, isWordBreak) {
+			return fmt.Errorf("word list %s: word %d %q contains a space", lang, i+1, w)
+		}
+		lower := strings.ToLower(w)
+		if j, ok := seen[lower]; ok {
+			return fmt.Errorf("word list %s: word %d %q is the same as word %d", lang, i+1, w, j+1)
+		}
+		seen[lower] = i
+	}
+
+	info := &WordListInfo{Lang: lang}
+	copy(info.Words[:], words)
+	info.ExpectedHash = wordListInfoHash(info)
+
+	initLangIndices()
+	wordListRegistry[lang] = info
+	langIndices[lang] = newLangWordIndex(lang, info)
+	customLangs = append(customLangs, lang)
+	return nil
+}
+
 // GetWordListSpecs returns the spec metadata for all word lists (for testing).
 func GetWordListSpecs() []wordListSpec {
 	return wordListSpecs
@@ -135,9 +208,10 @@ func collapseGermanDigraphs(word string) string {
 
 // langWordIndex maps normalized forms to BIP39 indices for one language.
 type langWordIndex struct {
-	exact    map[string]int // lowercase canonical → index
-	stripped map[string]int // NFD-stripped → index
-	digraph  map[string]int // German digraph collapsed → index (DE only)
+	exact      map[string]int // lowercase canonical → index
+	stripped   map[string]int // NFD-stripped → index
+	digraph    map[string]int // German digraph collapsed → index (DE only)
+	normalized [2048]string   // NFD-stripped form of each word, for prefix lookups
 }
 
 var (
@@ -150,36 +224,42 @@ func initLangIndices() {
 		initRegistry()
 		langIndices = make(map[Lang]*langWordIndex, len(wordListRegistry))
 		for lang, info := range wordListRegistry {
-			idx := &langWordIndex{
-				exact:    make(map[string]int, 2048),
-				stripped: make(map[string]int, 2048),
-			}
-			if lang == LangDE {
-				idx.digraph = make(map[string]int, 2048)
-			}
-			for i, w := range info.Words {
-				lower := strings.ToLower(w)
-				idx.exact[lower] = i
-
-				normalized := NormalizeWord(w)
-				// Only store stripped form if it differs from exact
-				// (avoids redundant lookups for ASCII-only lists like English)
-				if normalized != lower {
-					idx.stripped[normalized] = i
-				}
-
-				if lang == LangDE {
-					collapsed := collapseGermanDigraphs(normalized)
-					if collapsed != normalized {
-						idx.digraph[collapsed] = i
-					}
-				}
-			}
-			langIndices[lang] = idx
+			langIndices[lang] = newLangWordIndex(lang, info)
 		}
 	})
 }
 
+// newLangWordIndex builds the lookup index for one language's word list.
+func newLangWordIndex(lang Lang, info *WordListInfo) *langWordIndex {
+	idx := &langWordIndex{
+		exact:    make(map[string]int, 2048),
+		stripped: make(map[string]int, 2048),
+	}
+	if lang == LangDE {
+		idx.digraph = make(map[string]int, 2048)
+	}
+	for i, w := range info.Words {
+		lower := strings.ToLower(w)
+		idx.exact[lower] = i
+
+		normalized := NormalizeWord(w)
+		idx.normalized[i] = normalized
+		// Only store stripped form if it differs from exact
+		// (avoids redundant lookups for ASCII-only lists like English)
+		if normalized != lower {
+			idx.stripped[normalized] = i
+		}
+
+		if lang == LangDE {
+			collapsed := collapseGermanDigraphs(normalized)
+			if collapsed != normalized {
+				idx.digraph[collapsed] = i
+			}
+		}
+	}
+	return idx
+}
+
 // LookupWord finds a word's BIP39 index in the given language.
 // Tries exact match first, then NFD-stripped, then German digraph expansion.
 // Returns (index, true) if found, (0, false) if not.
@@ -214,19 +294,95 @@ func LookupWord(lang Lang, word string) (int, bool) {
 	return 0, false
 }
 
+// MinWordPrefix is the shortest prefix LookupWordPrefix resolves. BIP39
+// lists are built so that the first four letters pick out one word.
+const MinWordPrefix = 4
+
+// LookupWordPrefix resolves the start of a word, at least MinWordPrefix
+// letters long, to the one word in lang's list that begins with it, and
+// returns that word and its index. A whole word resolves to itself. Case and
+// accents are ignored, as in LookupWord.
+//
+// The official lists never have two words sharing their first four letters,
+// but the unofficial ones may. A prefix shared by more than one word doesn't
+// resolve, nor does one that is too short or matches no word.
+func LookupWordPrefix(lang Lang, prefix string) (string, int, bool) {
+	if i, ok := LookupWord(lang, prefix); ok {
+		return GetWordList(lang).Words[i], i, true
+	}
+	matches := wordsWithPrefix(lang, prefix)
+	if len(matches) != 1 {
+		return "", 0, false
+	}
+	return GetWordList(lang).Words[matches[0]], matches[0], true
+}
+
+// wordsWithPrefix returns the indices of the words in lang's list that start
+// with prefix, or nil if prefix is shorter than MinWordPrefix.
+func wordsWithPrefix(lang Lang, prefix string) []int {
+	initLangIndices()
+	idx := langIndices[lang]
+	normalized := NormalizeWord(prefix)
+	if idx == nil || utf8.RuneCountInString(normalized) < MinWordPrefix {
+		return nil
+	}
+	var matches []int
+	for i, w := range idx.normalized {
+		if strings.HasPrefix(w, normalized) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// lookupWordOrPrefix is LookupWord, falling back to LookupWordPrefix for a
+// word cut short.
+func lookupWordOrPrefix(lang Lang, word string) (int, bool) {
+	if i, ok := LookupWord(lang, word); ok {
+		return i, true
+	}
+	_, i, ok := LookupWordPrefix(lang, word)
+	return i, ok
+}
+
 // --- Language detection ---
 
 // DetectWordListLang identifies which language a set of words belongs to.
 // Returns the language where the most words match. Requires >50% match.
 // Returns empty string if no language matches.
 func DetectWordListLang(words []string) Lang {
+	lang, count := closestWordListLang(words)
+	if count <= len(words)/2 {
+		return ""
+	}
+	return lang
+}
+
+// closestWordListLang returns the language whose list has the most of words,
+// and how many it has. It returns "" and 0 when no word is in any list.
+func closestWordListLang(words []string) (Lang, int) {
+	return closestWordListLangBy(words, LookupWord)
+}
+
+// detectWordListLangByPrefix is DetectWordListLang for words that may be cut
+// short, counting those LookupWordPrefix resolves as well. Short prefixes
+// often start a word in some other list too, so every word must resolve.
+func detectWordListLangByPrefix(words []string) Lang {
+	lang, count := closestWordListLangBy(words, lookupWordOrPrefix)
+	if count < len(words) {
+		return ""
+	}
+	return lang
+}
+
+func closestWordListLangBy(words []string, lookup func(Lang, string) (int, bool)) (Lang, int) {
 	initLangIndices()
 	bestLang := Lang("")
 	bestCount := 0
 	for _, lang := range AllLangs() {
 		count := 0
 		for _, w := range words {
-			if _, ok := LookupWord(lang, w); ok {
+			if _, ok := lookup(lang, w); ok {
 				count++
 			}
 		}
@@ -235,13 +391,10 @@ func DetectWordListLang(words []string) Lang {
 			bestLang = lang
 		}
 	}
-	if bestCount <= len(words)/2 {
-		return ""
-	}
-	return bestLang
+	return bestLang, bestCount
 }
 
-// --- Hash verification (used by tests) ---
+// --- Hash verification ---
 
 // WordListHash computes the SHA-256 hash of a word list's canonical form
 // (words joined by newline, with trailing newline).
@@ -251,7 +404,33 @@ func WordListHash(lang Lang) string {
 	if info == nil {
 		return ""
 	}
+	return wordListInfoHash(info)
+}
+
+func wordListInfoHash(info *WordListInfo) string {
 	joined := strings.Join(info.Words[:], "\n") + "\n"
 	h := sha256.Sum256([]byte(joined))
 	return hex.EncodeToString(h[:])
 }
+
+// VerifyWordLists checks every embedded word list against its pinned
+// SHA-256 hash, so a binary with a corrupted list refuses to run instead of
+// writing or reading words no other copy would agree on. The error names the
+// first language that doesn't match.
+func VerifyWordLists() error {
+	initRegistry()
+	return verifyWordLists(wordListRegistry)
+}
+
+func verifyWordLists(lists map[Lang]*WordListInfo) error {
+	for _, spec := range wordListSpecs {
+		info := lists[spec.Lang]
+		if info == nil {
+			return fmt.Errorf("word list %s is missing", spec.Lang)
+		}
+		if hash := wordListInfoHash(info); hash != spec.ExpectedHash {
+			return fmt.Errorf("word list %s does not match its pinned hash (got %s, want %s); this build is damaged", spec.Lang, hash, spec.ExpectedHash)
+		}
+	}
+	return nil
+}
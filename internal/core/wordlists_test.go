@@ -2,7 +2,10 @@ package core
 
 import (
 	"bytes"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -165,6 +168,117 @@ func TestLookupWordCaseInsensitive(t *testing.T) {
 	}
 }
 
+func TestLookupWordPrefix(t *testing.T) {
+	tests := []struct {
+		lang   Lang
+		prefix string
+		word   string
+		index  int
+		ok     bool
+	}{
+		{LangEN, "aban", "abandon", 0, true},
+		{LangEN, "ABANDO", "abandon", 0, true},
+		{LangEN, "abandon", "abandon", 0, true},
+		{LangEN, "zoo", "zoo", 2047, true}, // a whole word shorter than four letters
+		{LangES, "abac", "ábaco", 0, true},
+		{LangEN, "aba", "", 0, false},      // too short
+		{LangEN, "abandons", "", 0, false}, // longer than the word
+		{LangEN, "qqqq", "", 0, false},
+		{LangSL, "cest", "", 0, false}, // cesta and čestitka
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.lang)+"/"+tt.prefix, func(t *testing.T) {
+			word, index, ok := LookupWordPrefix(tt.lang, tt.prefix)
+			if ok != tt.ok || NormalizeWord(word) != NormalizeWord(tt.word) || index != tt.index {
+				t.Errorf("LookupWordPrefix(%s, %q) = %q, %d, %v; want %q, %d, %v", tt.lang, tt.prefix, word, index, ok, tt.word, tt.index, tt.ok)
+			}
+		})
+	}
+}
+
+// The first four letters pick out each word in every list where no two words
+// share them, and resolve nothing where they do, unless they are a whole word
+// themselves.
+func TestLookupWordPrefixAllLangs(t *testing.T) {
+	for _, lang := range AllLangs() {
+		t.Run(string(lang), func(t *testing.T) {
+			wl := GetWordList(lang)
+			counts := make(map[string]int)
+			for _, w := range wl.Words {
+				counts[firstRunes(NormalizeWord(w), MinWordPrefix)]++
+			}
+			for i, w := range wl.Words {
+				prefix := firstRunes(NormalizeWord(w), MinWordPrefix)
+				if len([]rune(prefix)) < MinWordPrefix || prefix == NormalizeWord(w) {
+					continue
+				}
+				word, index, ok := LookupWordPrefix(lang, prefix)
+				if whole, found := LookupWord(lang, prefix); found {
+					if !ok || index != whole {
+						t.Errorf("whole word %q resolved to %q, %d", prefix, word, index)
+					}
+					continue
+				}
+				if counts[prefix] > 1 {
+					if ok {
+						t.Errorf("shared prefix %q resolved to %q", prefix, word)
+					}
+					continue
+				}
+				if !ok || word != w || index != i {
+					t.Errorf("LookupWordPrefix(%q) = %q, %d, %v; want %q, %d", prefix, word, index, ok, w, i)
+				}
+			}
+		})
+	}
+}
+
+func firstRunes(s string, n int) string {
+	r := []rune(s)
+	return string(r[:min(n, len(r))])
+}
+
+func TestDecodeShareWordsPrefixes(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	share := NewShare(2, 2, 5, 3, "Test", data)
+
+	for _, lang := range []Lang{LangEN, LangES, LangFR} {
+		t.Run(string(lang), func(t *testing.T) {
+			words, err := share.WordsForLang(lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+			short := make([]string, len(words))
+			for i, w := range words {
+				short[i] = firstRunes(NormalizeWord(w), MinWordPrefix)
+			}
+
+			decoded, index, detected, err := DecodeShareWordsAuto(short)
+			if err != nil {
+				t.Fatalf("DecodeShareWordsAuto: %v", err)
+			}
+			if !bytes.Equal(decoded, data) || index != 2 || detected != lang {
+				t.Errorf("got index %d, lang %s, data match %v", index, detected, bytes.Equal(decoded, data))
+			}
+		})
+	}
+
+	// A prefix two words share asks for more letters.
+	words, err := share.WordsForLang(LangSL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words[3] = "cest"
+	_, _, err = DecodeShareWordsLang(words, LangSL)
+	if err == nil || !strings.Contains(err.Error(), "type more of it") {
+		t.Errorf("expected an ambiguous prefix error, got %v", err)
+	}
+}
+
 func TestEncodeDecodeRoundTripAllLangs(t *testing.T) {
 	data := make([]byte, 33)
 	for i := range data {
@@ -246,6 +360,198 @@ func TestDetectWordListLang(t *testing.T) {
 	}
 }
 
+func TestDecodeShareWordsLang(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	share := NewShare(2, 4, 5, 3, "Test", data)
+	words, err := share.WordsForLang(LangFR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, index, err := DecodeShareWordsLang(words, LangFR)
+	if err != nil {
+		t.Fatalf("right language: %v", err)
+	}
+	if !bytes.Equal(decoded, data) || index != 4 {
+		t.Errorf("right language: got index %d, data match %v", index, bytes.Equal(decoded, data))
+	}
+
+	_, _, err = DecodeShareWordsLang(words, LangDE)
+	if err == nil {
+		t.Fatal("wrong language should fail")
+	}
+	if !strings.Contains(err.Error(), "not from the de word list") || !strings.Contains(err.Error(), "look like fr") {
+		t.Errorf("wrong language error should name both languages, got: %v", err)
+	}
+
+	if _, _, err := DecodeShareWordsLang(words, Lang("xx")); err == nil {
+		t.Error("unknown language should fail")
+	}
+}
+
+func TestDecodeShareWordsAutoSuggestsInDetectedLang(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	words, err := NewShare(2, 3, 5, 3, "", data).WordsForLang(LangES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "abusi" is one letter off Spanish "abuso" and English "abuse"; in a
+	// Spanish share the suggestion must be the Spanish word.
+	typo := append([]string(nil), words...)
+	typo[6] = "abusi"
+	_, _, _, err = DecodeShareWordsAuto(typo)
+	if err == nil {
+		t.Fatal("expected an error for the typo")
+	}
+	for _, want := range []string{"word 7", `"abusi"`, "es word list", `did you mean "abuso"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+
+	// With so many typos that the language can't be told for sure, the
+	// suggestion still comes from the list most words are in.
+	for i := 0; i < 13; i++ {
+		typo[i] = "abusi"
+	}
+	_, _, _, err = DecodeShareWordsAuto(typo)
+	if err == nil {
+		t.Fatal("expected an error for the typos")
+	}
+	if !strings.Contains(err.Error(), "closest is es") || !strings.Contains(err.Error(), `did you mean "abuso"`) {
+		t.Errorf("expected a Spanish suggestion, got: %v", err)
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	for _, lang := range AllLangs() {
+		got, err := ParseLang(strings.ToUpper(string(lang)))
+		if err != nil || got != lang {
+			t.Errorf("ParseLang(%q) = %q, %v", strings.ToUpper(string(lang)), got, err)
+		}
+	}
+
+	_, err := ParseLang("klingon")
+	if err == nil {
+		t.Fatal("expected error for unknown language")
+	}
+	if !strings.Contains(err.Error(), "en, es, fr") {
+		t.Errorf("error should list valid languages, got: %v", err)
+	}
+}
+
+// syntheticWords returns 2048 made-up words, "zqaaa" to "zqdat", that are
+// in none of the built-in lists.
+func syntheticWords() []string {
+	words := make([]string, 2048)
+	for i := range words {
+		words[i] = "zq" + string(rune('a'+i/676)) + string(rune('a'+i/26%26)) + string(rune('a'+i%26))
+	}
+	return words
+}
+
+const testLang Lang = "x-test"
+
+// The registry is global, so the list is registered once per test binary
+// (and -count=N runs see the error from the first registration).
+var (
+	registerTestOnce sync.Once
+	registerTestErr  error
+)
+
+func TestRegisterWordList(t *testing.T) {
+	registerTestOnce.Do(func() {
+		registerTestErr = RegisterWordList(testLang, syntheticWords())
+	})
+	if registerTestErr != nil {
+		t.Fatalf("RegisterWordList: %v", registerTestErr)
+	}
+
+	if !slices.Contains(AllLangs(), testLang) {
+		t.Errorf("AllLangs() = %v, should include %s", AllLangs(), testLang)
+	}
+	if got, err := ParseLang("X-TEST"); err != nil || got != testLang {
+		t.Errorf("ParseLang = %q, %v", got, err)
+	}
+	wl := GetWordList(testLang)
+	if wl == nil || !slices.Equal(wl.Words[:], syntheticWords()) {
+		t.Fatal("registered list not returned by GetWordList")
+	}
+	if WordListHash(testLang) != wl.ExpectedHash {
+		t.Error("hash of the registered list should match its ExpectedHash")
+	}
+
+	// A share round-trips through the custom list, including detection.
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i*37 + 11)
+	}
+	share := NewShare(2, 4, 5, 3, "", data)
+	words, err := share.WordsForLang(testLang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(words[0], "zq") {
+		t.Fatalf("words not from the custom list: %v", words)
+	}
+	got, index, lang, err := DecodeShareWordsAuto(words)
+	if err != nil {
+		t.Fatalf("DecodeShareWordsAuto: %v", err)
+	}
+	if lang != testLang || index != 4 || !bytes.Equal(got, data) {
+		t.Errorf("got lang %s, index %d, data %x", lang, index, got)
+	}
+	if _, ok := LookupWord(testLang, "ZQABC"); !ok {
+		t.Error("lookups in the custom list should ignore case")
+	}
+
+	if err := RegisterWordList(testLang, syntheticWords()); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("registering twice: got %v", err)
+	}
+}
+
+func TestRegisterWordListRejects(t *testing.T) {
+	words := syntheticWords()
+	withWord := func(i int, w string) []string {
+		out := slices.Clone(words)
+		out[i] = w
+		return out
+	}
+	tests := []struct {
+		name  string
+		lang  Lang
+		words []string
+		want  string
+	}{
+		{"built-in", LangEN, words, "built in"},
+		{"built-in other case", "ZH-tw", words, "built in"},
+		{"empty name", "", words, "not a valid name"},
+		{"too few", "x-short", words[:2047], "has 2047 words"},
+		{"too many", "x-long", append(slices.Clone(words), "zqextra"), "has 2049 words"},
+		{"empty word", "x-empty", withWord(5, ""), "word 6 is empty"},
+		{"space", "x-space", withWord(5, "zq ab"), "contains a space"},
+		{"duplicate", "x-dup", withWord(9, "ZQAAB"), `word 10 "ZQAAB" is the same as word 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterWordList(tt.lang, tt.words)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+			if tt.lang != LangEN && tt.lang != "ZH-tw" && GetWordList(tt.lang) != nil {
+				t.Errorf("rejected list %s was registered anyway", tt.lang)
+			}
+		})
+	}
+}
+
 func TestAutoDetectWithNormalization(t *testing.T) {
 	data := make([]byte, 33)
 	for i := range data {
@@ -359,3 +665,113 @@ func TestWordListsHaveUniqueWords(t *testing.T) {
 		})
 	}
 }
+
+func TestWordPassphraseBits(t *testing.T) {
+	tests := []struct {
+		words int
+		want  float64
+	}{
+		{6, 66},
+		{12, 132},
+		{24, 264},
+	}
+	for _, tt := range tests {
+		if got := WordPassphraseBits(tt.words); got != tt.want {
+			t.Errorf("WordPassphraseBits(%d) = %v, want %v", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateWordPassphrase(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x12, 0x34, 0xff, 0xff, 0x00, 0x00}, 4)
+
+	// The same random bytes always give the same words: 0x1234 & 0x7ff is
+	// index 564, 0xffff is 2047 and 0x0000 is 0.
+	got, err := generateWordPassphrase(bytes.NewReader(seed), 6, LangEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wl := GetWordList(LangEN)
+	want := strings.Join([]string{wl.Words[564], wl.Words[2047], wl.Words[0], wl.Words[564], wl.Words[2047], wl.Words[0]}, " ")
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	again, _ := generateWordPassphrase(bytes.NewReader(seed), 6, LangEN)
+	if again != got {
+		t.Errorf("same seed gave %q then %q", got, again)
+	}
+
+	es, err := generateWordPassphrase(bytes.NewReader(seed), 6, LangES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := strings.Fields(es)[0]; first != GetWordList(LangES).Words[564] {
+		t.Errorf("Spanish passphrase starts with %q, want %q", first, GetWordList(LangES).Words[564])
+	}
+
+	if _, err := generateWordPassphrase(bytes.NewReader(seed), 5, LangEN); err == nil {
+		t.Error("expected error for fewer than 6 words")
+	}
+	if _, err := generateWordPassphrase(bytes.NewReader(seed[:5]), 6, LangEN); err == nil {
+		t.Error("expected error when the reader runs out")
+	}
+	if _, err := GenerateWordPassphrase(6, Lang("xx")); err == nil {
+		t.Error("expected error for an unknown language")
+	}
+
+	generated, err := GenerateWordPassphrase(8, LangEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range strings.Fields(generated) {
+		if _, ok := LookupWord(LangEN, w); !ok {
+			t.Errorf("%q is not a BIP39 word", w)
+		}
+	}
+}
+
+func TestEncryptWithWordPassphrase(t *testing.T) {
+	plaintext := []byte("written down in words")
+	var encrypted bytes.Buffer
+	passphrase, err := EncryptWithWordPassphrase(&encrypted, bytes.NewReader(plaintext), MinPassphraseWords, LangEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Fields(passphrase)); n != MinPassphraseWords {
+		t.Errorf("passphrase has %d words, want %d", n, MinPassphraseWords)
+	}
+
+	decrypted, err := DecryptBytes(encrypted.Bytes(), passphrase)
+	if err != nil {
+		t.Fatalf("decrypting with the word passphrase: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("decrypted %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestTokenizeWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"spaces", "apple  banana cherry", []string{"apple", "banana", "cherry"}},
+		{"bom", "\uFEFFapple banana", []string{"apple", "banana"}},
+		{"crlf", "apple\r\nbanana\r\n", []string{"apple", "banana"}},
+		{"tabs", "apple\tbanana\t\tcherry", []string{"apple", "banana", "cherry"}},
+		{"non-breaking spaces", "apple\u00a0banana cherry", []string{"apple", "banana", "cherry"}},
+		{"zero-width space", "apple\u200Bbanana", []string{"apple", "banana"}},
+		{"stray bom", "apple\uFEFF banana", []string{"apple", "banana"}},
+		{"accents kept", "ábaco\r\nñandú", []string{"ábaco", "ñandú"}},
+		{"empty", "\uFEFF \r\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokenizeWords(tt.input)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("TokenizeWords(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
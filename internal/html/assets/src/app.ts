@@ -50,6 +50,12 @@ declare const t: TranslationFunction;
     pasteArea: HTMLElement | null;
     pasteInput: HTMLTextAreaElement | null;
     pasteSubmitBtn: HTMLButtonElement | null;
+    wordSuggestions: HTMLElement | null;
+    holderNote: HTMLElement | null;
+    holderNoteText: HTMLElement | null;
+    answerSection: HTMLElement | null;
+    answerQuestion: HTMLElement | null;
+    answerInput: HTMLInputElement | null;
     contactListSection: HTMLElement | null;
     contactList: HTMLElement | null;
     step1Card: HTMLElement | null;
@@ -81,6 +87,12 @@ declare const t: TranslationFunction;
     pasteArea: document.getElementById('paste-area'),
     pasteInput: document.getElementById('paste-input') as HTMLTextAreaElement | null,
     pasteSubmitBtn: document.getElementById('paste-submit-btn') as HTMLButtonElement | null,
+    wordSuggestions: document.getElementById('word-suggestions'),
+    holderNote: document.getElementById('holder-note'),
+    holderNoteText: document.getElementById('holder-note-text'),
+    answerSection: document.getElementById('answer-section'),
+    answerQuestion: document.getElementById('answer-question'),
+    answerInput: document.getElementById('answer-input') as HTMLInputElement | null,
     contactListSection: document.getElementById('contact-list-section'),
     contactList: document.getElementById('contact-list'),
     step1Card: null,
@@ -227,6 +239,19 @@ declare const t: TranslationFunction;
     setupPaste();
     setupScanner();
 
+    // Show the project owner's note immediately. It is untrusted text:
+    // always set via textContent, never as HTML.
+    if (personalization?.note && elements.holderNoteText) {
+      elements.holderNoteText.textContent = personalization.note;
+      elements.holderNote?.classList.remove('hidden');
+    }
+
+    // The recovery question is untrusted text too.
+    if (personalization?.question && elements.answerQuestion) {
+      elements.answerQuestion.textContent = personalization.question;
+      elements.answerSection?.classList.remove('hidden');
+    }
+
     // Render contact list immediately (doesn't need WASM)
     if (personalization?.otherFriends && personalization.otherFriends.length > 0) {
       renderContactList();
@@ -499,6 +524,7 @@ declare const t: TranslationFunction;
 
       await parseAndAddShareFromPaste(content);
       if (elements.pasteInput) elements.pasteInput.value = '';
+      updateWordSuggestions();
       elements.pasteArea?.classList.add('hidden');
     });
 
@@ -508,6 +534,97 @@ declare const t: TranslationFunction;
         elements.pasteSubmitBtn?.click();
       }
     });
+
+    elements.pasteInput?.addEventListener('input', updateWordSuggestions);
+  }
+
+  // ============================================
+  // Word Suggestions (typing the 25 words by hand)
+  // ============================================
+
+  // Word lists are embedded as inert JSON blocks, only for the languages
+  // this group uses. Decoding still happens in WASM.
+  let knownWords: { word: string; key: string }[] | null = null;
+
+  // normalizeWord mirrors core.NormalizeWord: lowercase and strip accents,
+  // so "abaco" matches "ábaco" however either was typed.
+  function normalizeWord(word: string): string {
+    return word.normalize('NFD').replace(/\p{M}/gu, '').toLowerCase();
+  }
+
+  function loadKnownWords(): { word: string; key: string }[] {
+    if (knownWords) return knownWords;
+    const seen = new Map<string, string>();
+    document.querySelectorAll<HTMLScriptElement>('script[data-wordlist]').forEach(el => {
+      try {
+        (JSON.parse(el.textContent || '[]') as string[]).forEach(w => {
+          const word = w.normalize('NFC');
+          if (!seen.has(word)) seen.set(word, normalizeWord(word));
+        });
+      } catch {
+        // A damaged list only disables suggestions, never recovery
+      }
+    });
+    knownWords = Array.from(seen, ([word, key]) => ({ word, key }))
+      .sort((a, b) => a.key.localeCompare(b.key));
+    return knownWords;
+  }
+
+  function updateWordSuggestions(): void {
+    const box = elements.wordSuggestions;
+    const input = elements.pasteInput;
+    if (!box || !input) return;
+
+    box.innerHTML = '';
+    const text = input.value;
+    const words = loadKnownWords();
+    if (words.length === 0 || text.includes('-----BEGIN') || /^\s*RM\d+:/.test(text)) {
+      box.classList.add('hidden');
+      return;
+    }
+
+    // Suggest completions for the word currently being typed
+    const match = /([\p{L}\p{M}]+)$/u.exec(text);
+    const partial = match ? match[1] : '';
+    const partialKey = normalizeWord(partial);
+    if (partialKey.length >= 2) {
+      words
+        .filter(w => w.key.startsWith(partialKey) && w.key !== partialKey)
+        .slice(0, 6)
+        .forEach(({ word }) => {
+          const btn = document.createElement('button');
+          btn.type = 'button';
+          btn.textContent = word;
+          btn.addEventListener('click', () => {
+            input.value = text.slice(0, text.length - partial.length) + word + ' ';
+            input.focus();
+            updateWordSuggestions();
+          });
+          box.appendChild(btn);
+        });
+    }
+
+    // Count recognized words so the person knows how far along they are.
+    // Pieces numbered above 15 have a 26th word, which their 25th word
+    // announces by decoding without a piece number.
+    const known = new Set(words.map(w => w.key));
+    const typedWords = extractWordsFromText(text).filter(w => known.has(normalizeWord(w)));
+    const typed = typedWords.length;
+    if (typed > 0) {
+      let expected = 25;
+      if (typed > 25) {
+        expected = 26;
+      } else if (typed === 25 && state.wasmReady) {
+        const result = window.rememoryDecodeWords(typedWords);
+        if (!result.error && result.index === 0) expected = 26;
+      }
+      const count = document.createElement('span');
+      count.className = 'word-count';
+      count.textContent = `${typed}/${expected}`;
+      box.appendChild(count);
+    }
+
+    box.classList.toggle('hidden', box.childElementCount === 0);
   }
 
   async function parseAndAddShareFromPaste(content: string): Promise<void> {
@@ -1074,11 +1191,21 @@ declare const t: TranslationFunction;
         html: t('manifest_loaded_html'),
       };
       const sourceLabel = sourceLabels[source] || t('loaded');
+
+      state.manifestChecksum = checkManifestChecksum();
+      let checksumLine = '';
+      if (state.manifestChecksum === 'match') {
+        checksumLine = `<div class="manifest-checksum ok">&#10003; ${t('manifest_checksum_ok')}</div>`;
+      } else if (state.manifestChecksum === 'mismatch') {
+        checksumLine = `<div class="manifest-checksum mismatch">&#9888; ${t('manifest_checksum_mismatch_title')}</div>`;
+      }
+
       elements.manifestStatus.innerHTML = `
-        <span class="icon">&#9989;</span>
+        <span class="icon">${state.manifestChecksum === 'mismatch' ? '&#9888;&#65039;' : '&#9989;'}</span>
         <div style="flex: 1;">
           <strong>${escapeHtml(filename)}</strong> ${sourceLabel}
           <div style="font-size: 0.875rem; color: #6c757d;">${formatSize(size)}</div>
+          ${checksumLine}
         </div>
         <button class="clear-manifest" title="${t('remove')}">&times;</button>
       `;
@@ -1090,8 +1217,20 @@ declare const t: TranslationFunction;
     }
   }
 
+  // Compares the loaded MANIFEST.age with the checksum this page was made
+  // with. Returns undefined when there is nothing to compare: a generic
+  // recover.html, an older bundle, or WASM not loaded yet.
+  function checkManifestChecksum(): 'match' | 'mismatch' | undefined {
+    const expected = personalization?.manifestChecksum;
+    if (!expected || !state.manifest || !state.wasmReady) return undefined;
+    const result = window.rememoryHashBytes(state.manifest);
+    if (result.error) return undefined;
+    return result.checksum === expected ? 'match' : 'mismatch';
+  }
+
   function clearManifest(): void {
     state.manifest = null;
+    state.manifestChecksum = undefined;
     elements.manifestStatus?.classList.add('hidden');
     elements.manifestStatus?.classList.remove('loaded');
     elements.manifestDropZone?.classList.remove('hidden');
@@ -1105,19 +1244,35 @@ declare const t: TranslationFunction;
   function setupButtons(): void {
     elements.recoverBtn?.addEventListener('click', startRecovery);
     elements.downloadAllBtn?.addEventListener('click', downloadAll);
+
+    elements.answerInput?.addEventListener('input', () => checkRecoverReady());
+    elements.answerInput?.addEventListener('keydown', (e: KeyboardEvent) => {
+      if (e.key === 'Enter' && elements.recoverBtn && !elements.recoverBtn.disabled) {
+        startRecovery();
+      }
+    });
+  }
+
+  // The answer to the recovery question, or '' when the project has none.
+  function recoveryAnswer(): string {
+    if (!personalization?.question) return '';
+    return elements.answerInput?.value.trim() ?? '';
   }
 
   function checkRecoverReady(): void {
+    const needsAnswer = !!personalization?.question;
     const ready = state.manifest !== null && (
       (state.threshold > 0 && state.shares.length >= state.threshold) ||
       (state.threshold === 0 && state.shares.length >= 2)
-    );
+    ) && (!needsAnswer || recoveryAnswer() !== '');
 
     if (elements.recoverBtn) {
       elements.recoverBtn.disabled = !ready;
     }
 
-    if (ready && !state.recovering && !state.recoveryComplete) {
+    // With a question, recovery waits for the button or Enter, so it
+    // doesn't start on the first letter of the answer.
+    if (ready && !needsAnswer && !state.recovering && !state.recoveryComplete) {
       startRecovery();
     }
   }
@@ -1162,8 +1317,21 @@ declare const t: TranslationFunction;
       const passphrase = combineResult.passphrase;
       setProgress(30);
 
+      // Unlocking still goes ahead: a mismatched file may be from a newer
+      // set of pieces, and age will refuse it if it doesn't fit.
+      if (state.manifestChecksum === undefined) {
+        state.manifestChecksum = checkManifestChecksum();
+      }
+      if (state.manifestChecksum === 'mismatch') {
+        toast.warning(
+          t('manifest_checksum_mismatch_title'),
+          t('manifest_checksum_mismatch_message'),
+          t('manifest_checksum_mismatch_guidance')
+        );
+      }
+
       setStatus(t('decrypting'));
-      const decryptResult = window.rememoryDecryptManifest(state.manifest!, passphrase);
+      const decryptResult = window.rememoryDecryptManifest(state.manifest!, passphrase, recoveryAnswer());
       if (decryptResult.error || !decryptResult.data) {
         throw new Error(decryptResult.error || 'Failed to decrypt');
       }
@@ -1202,7 +1370,13 @@ declare const t: TranslationFunction;
     } catch (err) {
       const errorMsg = (err instanceof Error) ? err.message : String(err);
 
-      if (errorMsg.includes('decrypt') || errorMsg.includes('passphrase') || errorMsg.includes('incorrect')) {
+      if (personalization?.question && (errorMsg.includes('decrypt') || errorMsg.includes('incorrect'))) {
+        // The pieces or the answer may be wrong; the answer is the easier
+        // one to try again, so keep the pieces and point there.
+        toast.error(t('error_answer_title'), t('error_answer_message'), t('error_answer_guidance'));
+        setStatus(t('error_answer_status'), 'error');
+        elements.answerInput?.focus();
+      } else if (errorMsg.includes('decrypt') || errorMsg.includes('passphrase') || errorMsg.includes('incorrect')) {
         errorHandlers.decryptionFailed(err);
         setStatus(t('error_decrypt_status'), 'error');
       } else if (errorMsg.includes('extract') || errorMsg.includes('tar') || errorMsg.includes('gzip')) {
@@ -49,7 +49,7 @@ declare let currentLang: string;
   }
 
   // State
-  const state: CreationState & { anonymous: boolean; numShares: number } = {
+  const state: CreationState & { anonymous: boolean; numShares: number; note: string } = {
     projectName: generateProjectName(),
     friends: [],
     threshold: 2,
@@ -59,7 +59,8 @@ declare let currentLang: string;
     generating: false,
     generationComplete: false,
     anonymous: false,
-    numShares: 5
+    numShares: 5,
+    note: ''
   };
 
   // DOM elements interface
@@ -272,6 +273,7 @@ declare let currentLang: string;
       if (project.name) {
         state.projectName = project.name;
       }
+      state.note = project.note || '';
 
       if (project.friends && project.friends.length > 0) {
         project.friends.forEach((f: any) => {
@@ -753,7 +755,8 @@ declare let currentLang: string;
         version: window.VERSION || 'dev',
         githubURL: window.GITHUB_URL || 'https://github.com/eljojo/rememory',
         anonymous: state.anonymous,
-        defaultLanguage: currentLang || 'en'
+        defaultLanguage: currentLang || 'en',
+        note: state.note
       };
 
       setProgress(10);
@@ -106,6 +106,7 @@ export interface FriendInput {
 export interface ProjectConfig {
   name?: string;
   threshold?: number;
+  note?: string;
   friends?: FriendInfo[];
 }
 
@@ -126,6 +127,10 @@ export interface PersonalizationData {
   total: number;
   language?: string;
   manifestB64?: string; // Base64-encoded MANIFEST.age (when small enough to embed)
+  wordLanguages?: string[]; // Word lists embedded for typing suggestions
+  note?: string; // Message from the project owner (plain text)
+  question?: string; // Recovery question whose answer is needed to unlock (plain text)
+  manifestChecksum?: string; // SHA-256 of MANIFEST.age ("sha256:..."), as in the README
 }
 
 // ============================================
@@ -141,6 +146,7 @@ export interface RecoveryState {
   recovering: boolean;
   recoveryComplete: boolean;
   decryptedArchive?: Uint8Array;
+  manifestChecksum?: 'match' | 'mismatch'; // Unset when there is nothing to compare against
 }
 
 export interface CreationState {
@@ -189,11 +195,12 @@ declare global {
     // Recovery functions (recover.wasm)
     rememoryParseShare(content: string): ShareParseResult;
     rememoryCombineShares(shares: ShareInput[]): CombineResult;
-    rememoryDecryptManifest(manifest: Uint8Array, passphrase: string): DecryptResult;
+    rememoryDecryptManifest(manifest: Uint8Array, passphrase: string, answer?: string): DecryptResult;
     rememoryExtractTarGz(data: Uint8Array): ExtractResult;
     rememoryExtractBundle(zipData: Uint8Array): BundleExtractResult;
     rememoryParseCompactShare(compact: string): ShareParseResult;
     rememoryDecodeWords(words: string[]): { data: Uint8Array; index: number; checksum: string; error?: string };
+    rememoryHashBytes(data: Uint8Array): { checksum: string; error?: string };
 
     // Creation functions (create.wasm)
     rememoryCreateBundles(config: BundleConfig): BundleCreateResult;
@@ -1,7 +1,9 @@
 package html
 
 import (
+	"bytes"
 	_ "embed"
+	"fmt"
 )
 
 // Embedded assets for the recovery HTML
@@ -48,6 +50,24 @@ var dataflowJS string
 // This avoids circular dependency since create.wasm embeds the html package
 var createWASM []byte
 
+// wasmMagic is the header every WebAssembly module starts with: "\0asm"
+// followed by binary format version 1.
+var wasmMagic = []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+
+// ValidateWASM checks that b looks like a WebAssembly module, so a wrong
+// file given in place of recover.wasm is caught before it goes into a
+// bundle. It only checks the header, not that the module is the recovery
+// tool.
+func ValidateWASM(b []byte) error {
+	if len(b) == 0 {
+		return fmt.Errorf("WASM file is empty")
+	}
+	if !bytes.HasPrefix(b, wasmMagic) {
+		return fmt.Errorf("not a WebAssembly module (missing \\0asm header)")
+	}
+	return nil
+}
+
 // GetRecoverWASMBytes returns the embedded recovery-only WASM binary.
 // This smaller WASM is used in recover.html for bundle distribution.
 func GetRecoverWASMBytes() []byte {
@@ -1,16 +1,20 @@
 package html
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"regexp"
 )
 
 // personalizationManifest is a minimal struct for extracting just the manifest
-// from the PERSONALIZATION JSON embedded in recover.html.
+// and recovery question from the PERSONALIZATION JSON embedded in recover.html.
 type personalizationManifest struct {
 	ManifestB64 string `json:"manifestB64"`
+	Question    string `json:"question"`
 }
 
 // personalizationRe matches the PERSONALIZATION JSON in recover.html.
@@ -48,3 +52,102 @@ func ExtractManifestFromHTML(htmlContent []byte) ([]byte, error) {
 
 	return data, nil
 }
+
+// ExtractQuestionFromHTML returns the recovery question recorded in a
+// personalized recover.html, or "" when the project doesn't use one or the
+// file carries no personalization.
+func ExtractQuestionFromHTML(htmlContent []byte) string {
+	matches := personalizationRe.FindSubmatch(htmlContent)
+	if len(matches) < 2 {
+		return ""
+	}
+	var p personalizationManifest
+	if err := json.Unmarshal(matches[1], &p); err != nil {
+		return ""
+	}
+	return p.Question
+}
+
+// ExtractPersonalization returns the PERSONALIZATION data embedded in a
+// personalized recover.html.
+func ExtractPersonalization(htmlContent []byte) (*PersonalizationData, error) {
+	matches := personalizationRe.FindSubmatch(htmlContent)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("no PERSONALIZATION data found in HTML")
+	}
+	var p PersonalizationData
+	if err := json.Unmarshal(matches[1], &p); err != nil {
+		return nil, fmt.Errorf("parsing PERSONALIZATION JSON: %w", err)
+	}
+	return &p, nil
+}
+
+// SetPersonalizationLanguage returns a copy of a personalized recover.html
+// whose default UI language is lang. Only the language in the PERSONALIZATION
+// JSON changes; the recovery tool, the embedded manifest and any fields this
+// version doesn't know about are kept as they are.
+func SetPersonalizationLanguage(htmlContent []byte, lang string) ([]byte, error) {
+	loc := personalizationRe.FindSubmatchIndex(htmlContent)
+	if loc == nil {
+		return nil, fmt.Errorf("no PERSONALIZATION data found in HTML")
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(htmlContent[loc[2]:loc[3]], &fields); err != nil {
+		return nil, fmt.Errorf("parsing PERSONALIZATION JSON: %w", err)
+	}
+	langJSON, err := json.Marshal(lang)
+	if err != nil {
+		return nil, err
+	}
+	fields["language"] = langJSON
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(htmlContent)+len(langJSON))
+	out = append(out, htmlContent[:loc[2]]...)
+	out = append(out, data...)
+	out = append(out, htmlContent[loc[3]:]...)
+	return out, nil
+}
+
+// wasmBinaryRe matches the gzip-compressed, base64-encoded recovery tool in
+// recover.html:
+//
+//	window.WASM_BINARY = "...";
+var wasmBinaryRe = regexp.MustCompile(`window\.WASM_BINARY\s*=\s*"([A-Za-z0-9+/=]*)"\s*;`)
+
+// maxWASMSize caps how much ExtractWASMFromHTML will decompress. The real
+// recover.wasm is under 10 MiB; anything far bigger is a gzip bomb.
+const maxWASMSize = 64 << 20 // 64 MiB
+
+// ExtractWASMFromHTML returns the recover.wasm binary embedded in a
+// recover.html file, decoded and decompressed, so it can be checked against
+// the checksum recorded when the bundle was made.
+func ExtractWASMFromHTML(htmlContent []byte) ([]byte, error) {
+	matches := wasmBinaryRe.FindSubmatch(htmlContent)
+	if len(matches) < 2 || len(matches[1]) == 0 {
+		return nil, fmt.Errorf("no embedded WASM found in HTML")
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(string(matches[1]))
+	if err != nil {
+		return nil, fmt.Errorf("decoding WASM base64: %w", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing WASM: %w", err)
+	}
+	defer gz.Close()
+	data, err := io.ReadAll(io.LimitReader(gz, maxWASMSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing WASM: %w", err)
+	}
+	if len(data) > maxWASMSize {
+		return nil, fmt.Errorf("embedded WASM is larger than %d MiB", maxWASMSize>>20)
+	}
+
+	return data, nil
+}
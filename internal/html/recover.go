@@ -5,8 +5,12 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -31,31 +35,165 @@ type PersonalizationData struct {
 	Total        int          `json:"total"`                 // Total shares (N)
 	Language     string       `json:"language,omitempty"`    // Default UI language for this friend
 	ManifestB64  string       `json:"manifestB64,omitempty"` // Base64-encoded MANIFEST.age (when <= MaxEmbeddedManifestSize)
+	Note         string       `json:"note,omitempty"`        // Message from the project owner, shown as plain text
+	Question     string       `json:"question,omitempty"`    // Recovery question; its answer is needed to unlock
+
+	// Instructions are the project's recovery steps from the README. The
+	// page doesn't show them; they are kept so the README can be rebuilt
+	// from recover.html, as RelocalizeBundle does.
+	Instructions string `json:"instructions,omitempty"`
+
+	// ManifestChecksum is the SHA-256 of MANIFEST.age as written in the
+	// README ("sha256:..."). The page checks whichever MANIFEST.age it is
+	// given against it before unlocking. There is no matching field for
+	// recover.html: its checksum covers this data, so the page can't carry it.
+	ManifestChecksum string `json:"manifestChecksum,omitempty"`
+
+	// WordLanguages lists the word lists to embed for typing suggestions
+	// (see WordLanguages). Empty means English plus Language.
+	WordLanguages []string `json:"wordLanguages,omitempty"`
 }
 
 // GenerateRecoverHTML creates the complete recover.html with all assets embedded.
 // wasmBytes should be the compiled recover.wasm binary.
 // version is the rememory version string.
-// githubURL is the URL to download CLI binaries.
+// githubURL is the URL to download CLI binaries; empty leaves the download
+// link out, for deployments that don't publish them.
 // personalization can be nil for a generic recover.html, or provided to personalize for a specific friend.
-func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personalization *PersonalizationData) string {
+//
+// langs limits the page to those languages (see RecoverLanguages); none
+// gives every language.
+//
+// To generate recover.html for several friends, build a RecoverTemplate once
+// and call Generate for each instead.
+func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personalization *PersonalizationData, langs ...string) string {
+	return NewRecoverTemplate(wasmBytes, version, githubURL, langs...).Generate(personalization)
+}
+
+// Branding re-skins recover.html: a logo, colours or help text of its own,
+// without changing how it recovers. Empty fields keep the defaults.
+type Branding struct {
+	StylesCSS string // Replaces the built-in stylesheet
+	ExtraCSS  string // Added after the stylesheet
+	ExtraJS   string // Run after the recovery app has loaded
+}
+
+// placeholderRe matches the {{NAME}} placeholders of the page templates.
+var placeholderRe = regexp.MustCompile(`\{\{[A-Z0-9_]+\}\}`)
+
+// Validate checks that b can be put in the page as it is: it may not hold a
+// template placeholder, which would be filled in as if it were the page's
+// own, or close the element it goes in.
+func (b *Branding) Validate() error {
+	for _, f := range []struct{ name, value, closer string }{
+		{"styles", b.StylesCSS, "</style"},
+		{"extra CSS", b.ExtraCSS, "</style"},
+		{"extra JavaScript", b.ExtraJS, "</script"},
+	} {
+		if p := placeholderRe.FindString(f.value); p != "" {
+			return fmt.Errorf("%s cannot contain the template placeholder %s", f.name, p)
+		}
+		if strings.Contains(strings.ToLower(f.value), f.closer) {
+			return fmt.Errorf("%s cannot contain %q", f.name, f.closer+">")
+		}
+	}
+	return nil
+}
+
+// GenerateRecoverHTMLWithBranding is GenerateRecoverHTML with the page's
+// look replaced or extended by branding (see Branding). A nil branding gives
+// the default page. The error is from branding.Validate.
+func GenerateRecoverHTMLWithBranding(wasmBytes []byte, version, githubURL string, personalization *PersonalizationData, branding *Branding, langs ...string) (string, error) {
+	if branding != nil {
+		if err := branding.Validate(); err != nil {
+			return "", err
+		}
+	}
+	return newRecoverTemplate(wasmBytes, version, githubURL, branding, langs).Generate(personalization), nil
+}
+
+// RecoverLanguages returns the languages a recover.html limited to langs
+// embeds: those of langs that are supported, plus English, which the page
+// falls back to for any text missing in another language and whose words
+// every README prints. An empty langs means every language.
+func RecoverLanguages(langs []string) []string {
+	if len(langs) == 0 {
+		return translations.Languages
+	}
+	var result []string
+	for _, l := range translations.Languages {
+		if l == "en" || slices.Contains(langs, l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// Placeholders filled in per call by RecoverTemplate.Generate.
+const (
+	slotWordLists       = "{{WORDLISTS}}"
+	slotPersonalization = "{{PERSONALIZATION_DATA}}"
+	slotCSPNonce        = "{{CSP_NONCE}}"
+)
+
+// RecoverTemplate is recover.html with everything that is the same for every
+// friend already filled in: translations, styles, scripts, and the compressed
+// WASM binary, which is the slow part. Generate adds the per-friend parts.
+// A RecoverTemplate is read-only once built and safe for concurrent use.
+type RecoverTemplate struct {
+	// chunks is the page split at the per-call placeholders; slots[i] is
+	// the placeholder that goes between chunks[i] and chunks[i+1].
+	chunks []string
+	slots  []string
+	size   int
+
+	// langs are the languages embedded, from RecoverLanguages.
+	langs []string
+}
+
+// NewRecoverTemplate assembles the shared parts of recover.html. The
+// arguments are as for GenerateRecoverHTML.
+func NewRecoverTemplate(wasmBytes []byte, version, githubURL string, langs ...string) *RecoverTemplate {
+	return newRecoverTemplate(wasmBytes, version, githubURL, nil, langs)
+}
+
+// newRecoverTemplate is NewRecoverTemplate with an optional branding, which
+// must already be valid.
+func newRecoverTemplate(wasmBytes []byte, version, githubURL string, branding *Branding, langs []string) *RecoverTemplate {
+	langs = RecoverLanguages(langs)
 	html := recoverHTMLTemplate
+	if branding == nil {
+		branding = &Branding{}
+	}
 
 	// Embed translations
-	html = strings.Replace(html, "{{TRANSLATIONS}}", translations.GetTranslationsJS("recover"), 1)
+	html = strings.Replace(html, "{{TRANSLATIONS}}", translations.GetTranslationsJSFor("recover", langs), 1)
 
 	// Embed language picker (generated from translations.LangNames)
-	html = strings.Replace(html, "{{LANG_OPTIONS}}", translations.LangSelectOptions(), 1)
-	html = strings.Replace(html, "{{LANG_DETECT}}", translations.LangDetectJS(), 1)
+	html = strings.Replace(html, "{{LANG_OPTIONS}}", translations.LangSelectOptionsFor(langs), 1)
+	html = strings.Replace(html, "{{LANG_DETECT}}", translations.LangDetectJSFor(langs), 1)
 
-	// Embed styles
-	html = strings.Replace(html, "{{STYLES}}", stylesCSS, 1)
+	// Embed styles, and any branding on top. Everything is filled in
+	// before the placeholders are split, so branding can't add any.
+	styles := stylesCSS
+	if branding.StylesCSS != "" {
+		styles = branding.StylesCSS
+	}
+	if branding.ExtraCSS != "" {
+		styles += "\n" + branding.ExtraCSS
+	}
+	html = strings.Replace(html, "{{STYLES}}", styles, 1)
 
 	// Embed wasm_exec.js
 	html = strings.Replace(html, "{{WASM_EXEC}}", wasmExecJS, 1)
 
 	// Embed shared.js + app.js
 	html = strings.Replace(html, "{{APP_JS}}", sharedJS+"\n"+appJS, 1)
+	extraJS := ""
+	if branding.ExtraJS != "" {
+		extraJS = `<script nonce="` + slotCSPNonce + `">` + branding.ExtraJS + `</script>`
+	}
+	html = strings.Replace(html, "{{EXTRA_JS}}", extraJS, 1)
 
 	// Embed WASM as gzip-compressed base64 (reduces size by ~70%)
 	wasmB64 := compressAndEncode(wasmBytes)
@@ -63,22 +201,84 @@ func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personaliz
 
 	// Replace version and GitHub URL
 	html = strings.Replace(html, "{{VERSION}}", version, 1)
-	html = strings.Replace(html, "{{GITHUB_URL}}", githubURL, 1)
+	cliLink := ""
+	if githubURL != "" {
+		cliLink = ` ·
+      <a href="` + githubURL + `" target="_blank" data-i18n="download_cli">Download CLI tool from GitHub</a>`
+	}
+	html = strings.Replace(html, "{{CLI_LINK}}", cliLink, 1)
+
+	// Split at the per-call placeholders: the first word-list and
+	// personalization slots, and every CSP nonce.
+	t := &RecoverTemplate{size: len(html), langs: langs}
+	used := make(map[string]bool)
+	for {
+		slot, at := "", -1
+		for _, s := range []string{slotWordLists, slotPersonalization, slotCSPNonce} {
+			if used[s] {
+				continue
+			}
+			if i := strings.Index(html, s); i >= 0 && (at < 0 || i < at) {
+				slot, at = s, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		t.chunks = append(t.chunks, html[:at])
+		t.slots = append(t.slots, slot)
+		used[slot] = slot != slotCSPNonce
+		html = html[at+len(slot):]
+	}
+	t.chunks = append(t.chunks, html)
+	return t
+}
 
-	// Embed personalization data as JSON (or null if not provided)
-	var personalizationJSON string
+// Generate returns recover.html for one friend, or the generic tool when
+// personalization is nil. Each call gets its own CSP nonce.
+func (t *RecoverTemplate) Generate(personalization *PersonalizationData) string {
+	// Embed word lists for typing suggestions: all of them for the generic
+	// tool, only the ones the group uses for a personalized one, and never
+	// one for a language the page leaves out.
+	var wordLangs []string
+	switch {
+	case personalization == nil:
+		for _, l := range core.AllLangs() {
+			wordLangs = append(wordLangs, string(l))
+		}
+	case len(personalization.WordLanguages) > 0:
+		wordLangs = personalization.WordLanguages
+	default:
+		wordLangs = WordLanguages([]string{personalization.Language})
+	}
+	wordLangs = slices.DeleteFunc(slices.Clone(wordLangs), func(l string) bool {
+		return !slices.Contains(t.langs, l)
+	})
+
+	// Embed personalization data as JSON (or null if not provided). Names
+	// and notes are user-supplied; json.Marshal writes '<', '>', '&', U+2028
+	// and U+2029 as \u escapes, so no value can close the script element.
+	personalizationJSON := "null"
 	if personalization != nil {
 		data, _ := json.Marshal(personalization)
 		personalizationJSON = string(data)
-	} else {
-		personalizationJSON = "null"
 	}
-	html = strings.Replace(html, "{{PERSONALIZATION_DATA}}", personalizationJSON, 1)
 
-	// Apply CSP nonce to all script tags
-	html = applyCSPNonce(html)
+	values := map[string]string{
+		slotWordLists:       wordListScripts(wordLangs),
+		slotPersonalization: personalizationJSON,
+		slotCSPNonce:        generateCSPNonce(),
+	}
 
-	return html
+	var sb strings.Builder
+	sb.Grow(t.size + len(values[slotWordLists]) + len(personalizationJSON))
+	for i, chunk := range t.chunks {
+		sb.WriteString(chunk)
+		if i < len(t.slots) {
+			sb.WriteString(values[t.slots[i]])
+		}
+	}
+	return sb.String()
 }
 
 // compressAndEncode gzip-compresses data and returns base64-encoded result.
@@ -0,0 +1,361 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWordLanguages(t *testing.T) {
+	got := WordLanguages([]string{"es", "", "es", "xx", "de"})
+	want := []string{"en", "es", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGenerateRecoverHTMLWordLists(t *testing.T) {
+	wasm := []byte("fake-wasm-for-testing")
+
+	t.Run("personalized embeds selected languages", func(t *testing.T) {
+		out := GenerateRecoverHTML(wasm, "v-test", "https://example.com", &PersonalizationData{
+			Holder:        "Alice",
+			Threshold:     2,
+			Total:         3,
+			Language:      "es",
+			WordLanguages: []string{"en", "es"},
+		})
+		for _, lang := range []string{"en", "es"} {
+			if !strings.Contains(out, `data-wordlist="`+lang+`"`) {
+				t.Errorf("missing %s word list", lang)
+			}
+		}
+		for _, lang := range []string{"de", "fr", "zh-TW"} {
+			if strings.Contains(out, `data-wordlist="`+lang+`"`) {
+				t.Errorf("%s word list should not be embedded", lang)
+			}
+		}
+		if !strings.Contains(out, `"abandon"`) || !strings.Contains(out, `"zurdo"`) {
+			t.Error("word list contents not embedded")
+		}
+		if strings.Contains(out, "{{WORDLISTS}}") {
+			t.Error("placeholder not replaced")
+		}
+	})
+
+	t.Run("defaults to English plus holder language", func(t *testing.T) {
+		out := GenerateRecoverHTML(wasm, "v-test", "https://example.com", &PersonalizationData{
+			Holder:   "Bob",
+			Language: "fr",
+		})
+		if !strings.Contains(out, `data-wordlist="en"`) || !strings.Contains(out, `data-wordlist="fr"`) {
+			t.Error("expected en and fr word lists")
+		}
+		if strings.Contains(out, `data-wordlist="es"`) {
+			t.Error("es word list should not be embedded")
+		}
+	})
+
+	t.Run("generic embeds every language", func(t *testing.T) {
+		out := GenerateRecoverHTML(wasm, "v-test", "https://example.com", nil)
+		if n := strings.Count(out, "data-wordlist="); n != 7 {
+			t.Errorf("expected 7 word lists, got %d", n)
+		}
+	})
+}
+
+func TestGenerateRecoverHTMLNoteEscaping(t *testing.T) {
+	note := "Call my brother first.\n</script><script>alert(1)</script> & <b>"
+	out := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com", &PersonalizationData{
+		Holder: "Alice",
+		Note:   note,
+	})
+
+	if strings.Contains(out, "<script>alert(1)") || strings.Contains(out, "</script><script>") {
+		t.Fatal("note was embedded as raw markup")
+	}
+	if !strings.Contains(out, `"note":"Call my brother first.\n\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e \u0026 \u003cb\u003e"`) {
+		t.Error("note not JSON-escaped in personalization data")
+	}
+
+	start := strings.Index(out, "window.PERSONALIZATION = ") + len("window.PERSONALIZATION = ")
+	end := strings.Index(out[start:], ";\n")
+	var pd PersonalizationData
+	if err := json.Unmarshal([]byte(out[start:start+end]), &pd); err != nil {
+		t.Fatalf("parsing personalization JSON: %v", err)
+	}
+	if pd.Note != note {
+		t.Errorf("note round-trip = %q, want %q", pd.Note, note)
+	}
+}
+
+func TestGenerateRecoverHTMLPersonalizationInjection(t *testing.T) {
+	holder := "</script><img src=x onerror=alert(1)>"
+	friend := "Bob <!-- & \u2028\u2029"
+	baseline := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com", &PersonalizationData{Holder: "Alice"})
+	out := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com", &PersonalizationData{
+		Holder:       holder,
+		OtherFriends: []FriendInfo{{Name: friend, Contact: "a&b@example.com", ShareIndex: 2}},
+		Threshold:    2,
+		Total:        2,
+	})
+
+	if strings.Contains(out, "<img") {
+		t.Error("holder name was embedded as raw markup")
+	}
+	if got, want := strings.Count(out, "</script>"), strings.Count(baseline, "</script>"); got != want {
+		t.Errorf("found %d </script> tags, want %d", got, want)
+	}
+	for _, raw := range []string{"<!--", "\u2028", "\u2029"} {
+		if got, want := strings.Count(out, raw), strings.Count(baseline, raw); got != want {
+			t.Errorf("found %d unescaped %q, want %d", got, raw, want)
+		}
+	}
+
+	matches := personalizationRe.FindStringSubmatch(out)
+	if len(matches) < 2 {
+		t.Fatal("PERSONALIZATION not found in recover.html")
+	}
+	var pd PersonalizationData
+	if err := json.Unmarshal([]byte(matches[1]), &pd); err != nil {
+		t.Fatalf("parsing personalization JSON: %v", err)
+	}
+	if pd.Holder != holder {
+		t.Errorf("holder = %q, want %q", pd.Holder, holder)
+	}
+	if len(pd.OtherFriends) != 1 || pd.OtherFriends[0].Name != friend || pd.OtherFriends[0].Contact != "a&b@example.com" {
+		t.Errorf("other friends = %+v", pd.OtherFriends)
+	}
+}
+
+func TestGenerateRecoverHTMLManifestChecksum(t *testing.T) {
+	checksum := "sha256:" + strings.Repeat("ab", 32)
+	out := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com", &PersonalizationData{
+		Holder:           "Alice",
+		ManifestChecksum: checksum,
+	})
+
+	matches := personalizationRe.FindStringSubmatch(out)
+	if len(matches) < 2 {
+		t.Fatal("PERSONALIZATION not found in recover.html")
+	}
+	if !strings.Contains(matches[1], `"manifestChecksum":"`+checksum+`"`) {
+		t.Errorf("manifestChecksum not in personalization JSON: %s", matches[1])
+	}
+	var pd PersonalizationData
+	if err := json.Unmarshal([]byte(matches[1]), &pd); err != nil {
+		t.Fatalf("parsing personalization JSON: %v", err)
+	}
+	if pd.ManifestChecksum != checksum {
+		t.Errorf("ManifestChecksum = %q, want %q", pd.ManifestChecksum, checksum)
+	}
+
+	// Without a checksum the key is left out, and the page skips the check.
+	bare := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com", &PersonalizationData{Holder: "Alice"})
+	if strings.Contains(bare, `"manifestChecksum"`) {
+		t.Error("empty manifestChecksum should be omitted")
+	}
+}
+
+func TestGenerateRecoverHTMLWithoutCLI(t *testing.T) {
+	with := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "https://example.com/releases", nil)
+	if !strings.Contains(with, `href="https://example.com/releases"`) || !strings.Contains(with, `data-i18n="download_cli"`) {
+		t.Error("expected the CLI download link when a URL is given")
+	}
+
+	without := GenerateRecoverHTML([]byte("fake-wasm-for-testing"), "v-test", "", nil)
+	if strings.Contains(without, `data-i18n="download_cli"`) || strings.Contains(without, `href=""`) {
+		t.Error("an empty URL should leave the CLI download link out")
+	}
+	if strings.Contains(without, "{{CLI_LINK}}") {
+		t.Error("placeholder left in recover.html")
+	}
+}
+
+func TestGenerateRecoverHTMLLanguages(t *testing.T) {
+	wasm := []byte("fake-wasm-for-testing")
+	full := GenerateRecoverHTML(wasm, "v-test", "", nil)
+	spanish := GenerateRecoverHTML(wasm, "v-test", "", nil, "es")
+
+	if len(spanish) >= len(full) {
+		t.Errorf("Spanish-only build is %d bytes, all languages %d; expected it smaller", len(spanish), len(full))
+	}
+	for _, lang := range []string{"es", "en"} {
+		if !strings.Contains(spanish, `data-wordlist="`+lang+`"`) || !strings.Contains(spanish, `<option value="`+lang+`"`) {
+			t.Errorf("Spanish-only build should include %s", lang)
+		}
+	}
+	for _, lang := range []string{"fr", "de", "ja"} {
+		if strings.Contains(spanish, `data-wordlist="`+lang+`"`) || strings.Contains(spanish, `<option value="`+lang+`"`) {
+			t.Errorf("Spanish-only build should leave out %s", lang)
+		}
+	}
+	if strings.Contains(spanish, `"fr": {`) {
+		t.Error("Spanish-only build should leave out the French translations")
+	}
+
+	// A personalized page never embeds a word list for a language left out.
+	p := &PersonalizationData{Holder: "Alice", Threshold: 2, Total: 3, Language: "es", WordLanguages: []string{"en", "es", "fr"}}
+	out := NewRecoverTemplate(wasm, "v-test", "", "es").Generate(p)
+	if strings.Contains(out, `data-wordlist="fr"`) || !strings.Contains(out, `data-wordlist="es"`) {
+		t.Error("personalized page should embed only the word lists of its languages")
+	}
+
+	if got := RecoverLanguages([]string{"pt", "xx"}); !slices.Equal(got, []string{"en", "pt"}) {
+		t.Errorf("RecoverLanguages = %v, want [en pt]", got)
+	}
+}
+
+func TestGenerateRecoverHTMLWithBranding(t *testing.T) {
+	wasm := []byte("fake-wasm-for-testing")
+
+	plain, err := GenerateRecoverHTMLWithBranding(wasm, "v-test", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plain, stylesCSS) {
+		t.Error("without branding the built-in styles should be used")
+	}
+
+	branding := &Branding{
+		StylesCSS: "body { background: #123456; }",
+		ExtraCSS:  ".logo { width: 80px; }",
+		ExtraJS:   "document.title = 'Acme Recovery';",
+	}
+	out, err := GenerateRecoverHTMLWithBranding(wasm, "v-test", "", nil, branding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "<style>body { background: #123456; }\n.logo { width: 80px; }</style>") {
+		t.Error("custom styles should replace the built-in ones, followed by the extra CSS")
+	}
+	if strings.Contains(out, stylesCSS) {
+		t.Error("the built-in styles should be gone")
+	}
+	// The extra script runs after the app, under the page's CSP nonce.
+	extra := regexp.MustCompile(`<script nonce="([^"]+)">document.title = 'Acme Recovery';</script>`).FindStringSubmatch(out)
+	if extra == nil || strings.Index(out, extra[0]) < strings.Index(out, appJS) {
+		t.Fatal("extra JavaScript should come after app.js in its own script")
+	}
+	if !strings.Contains(out, "script-src 'nonce-"+extra[1]+"'") {
+		t.Error("extra JavaScript should carry the page's nonce")
+	}
+
+	for _, bad := range []Branding{
+		{ExtraCSS: "/* {{WASM_BASE64}} */"},
+		{StylesCSS: "</style><script>alert(1)</script>"},
+		{ExtraJS: "x = '</SCRIPT>'"},
+	} {
+		if _, err := GenerateRecoverHTMLWithBranding(wasm, "v-test", "", nil, &bad); err == nil {
+			t.Errorf("expected %+v to be refused", bad)
+		}
+	}
+}
+
+func TestRecoverTemplateReuse(t *testing.T) {
+	wasm := []byte("fake-wasm-for-testing")
+	tmpl := NewRecoverTemplate(wasm, "v-test", "https://example.com")
+	nonceRe := regexp.MustCompile(`nonce="([^"]+)"`)
+	mask := func(out string) string {
+		m := nonceRe.FindStringSubmatch(out)
+		if m == nil {
+			t.Fatal("no CSP nonce in output")
+		}
+		return strings.ReplaceAll(out, m[1], "NONCE")
+	}
+
+	alice := &PersonalizationData{Holder: "Alice", Threshold: 2, Total: 3, Language: "es"}
+	bob := &PersonalizationData{Holder: "Bob", Threshold: 2, Total: 3, Note: "literal {{CSP_NONCE}} and {{WORDLISTS}}"}
+
+	for _, p := range []*PersonalizationData{alice, bob, nil} {
+		got := tmpl.Generate(p)
+		want := GenerateRecoverHTML(wasm, "v-test", "https://example.com", p)
+		if mask(got) != mask(want) {
+			t.Errorf("template output for %+v differs from GenerateRecoverHTML", p)
+		}
+	}
+
+	a1, a2 := tmpl.Generate(alice), tmpl.Generate(alice)
+	if nonceRe.FindString(a1) == nonceRe.FindString(a2) {
+		t.Error("each generated page should get its own nonce")
+	}
+	if strings.Contains(a1, slotCSPNonce) || strings.Contains(a1, slotPersonalization) || strings.Contains(a1, slotWordLists) {
+		t.Error("placeholder left in output")
+	}
+
+	// Placeholders in user-supplied text are left alone.
+	matches := personalizationRe.FindStringSubmatch(tmpl.Generate(bob))
+	if len(matches) < 2 {
+		t.Fatal("PERSONALIZATION not found in recover.html")
+	}
+	var data PersonalizationData
+	if err := json.Unmarshal([]byte(matches[1]), &data); err != nil {
+		t.Fatalf("parsing personalization JSON: %v", err)
+	}
+	if data.Note != bob.Note {
+		t.Errorf("note = %q, want %q", data.Note, bob.Note)
+	}
+}
+
+// benchWASM is the embedded recover.wasm when built, or a stand-in of similar
+// size, so the benchmarks reflect the cost of compressing the real binary.
+func benchWASM() []byte {
+	if wasm := GetRecoverWASMBytes(); len(wasm) > 0 {
+		return wasm
+	}
+	wasm := make([]byte, 3<<20)
+	for i := range wasm {
+		wasm[i] = byte(i * 7 % 251)
+	}
+	return wasm
+}
+
+const benchFriends = 10
+
+func benchPersonalization(i int) *PersonalizationData {
+	return &PersonalizationData{Holder: fmt.Sprintf("Friend %d", i), Threshold: 3, Total: benchFriends, Language: "en"}
+}
+
+// BenchmarkRecoverHTMLOneOff generates recover.html for every friend from
+// scratch, as bundles did before RecoverTemplate.
+func BenchmarkRecoverHTMLOneOff(b *testing.B) {
+	wasm := benchWASM()
+	for b.Loop() {
+		for i := range benchFriends {
+			GenerateRecoverHTML(wasm, "v-bench", "https://example.com", benchPersonalization(i))
+		}
+	}
+}
+
+// BenchmarkRecoverHTMLBatch builds the template once per group of friends.
+func BenchmarkRecoverHTMLBatch(b *testing.B) {
+	wasm := benchWASM()
+	for b.Loop() {
+		tmpl := NewRecoverTemplate(wasm, "v-bench", "https://example.com")
+		for i := range benchFriends {
+			tmpl.Generate(benchPersonalization(i))
+		}
+	}
+}
+
+func TestExtractWASMFromHTMLLimit(t *testing.T) {
+	page := func(wasm []byte) []byte {
+		return []byte(`<script>window.WASM_BINARY = "` + compressAndEncode(wasm) + `";</script>`)
+	}
+
+	wasm := []byte("\x00asm\x01\x00\x00\x00small module")
+	got, err := ExtractWASMFromHTML(page(wasm))
+	if err != nil || !bytes.Equal(got, wasm) {
+		t.Fatalf("got %q, %v", got, err)
+	}
+
+	// A small page that inflates past the cap is refused.
+	if _, err := ExtractWASMFromHTML(page(make([]byte, maxWASMSize+1))); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected a size error, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package html
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// WordLanguages returns the word list languages to embed in recover.html for
+// a group of friends using the given bundle languages. English is always
+// included, since every README prints the English words as a fallback.
+// Unsupported codes are dropped and duplicates removed.
+func WordLanguages(langs []string) []string {
+	want := map[string]bool{string(core.LangEN): true}
+	for _, l := range langs {
+		want[l] = true
+	}
+	var result []string
+	for _, l := range core.AllLangs() {
+		if want[string(l)] {
+			result = append(result, string(l))
+		}
+	}
+	return result
+}
+
+// wordListScripts renders the given word lists as inert JSON data blocks, one
+// per language, for the typing suggestions in the recovery tool. Decoding
+// itself happens in WASM, which always has every list.
+func wordListScripts(langs []string) string {
+	var sb strings.Builder
+	for _, l := range langs {
+		wl := core.GetWordList(core.Lang(l))
+		if wl == nil {
+			continue
+		}
+		data, _ := json.Marshal(wl.Words[:])
+		sb.WriteString(`<script type="application/json" data-wordlist="` + l + `">`)
+		sb.Write(data)
+		sb.WriteString("</script>\n")
+	}
+	return sb.String()
+}
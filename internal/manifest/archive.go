@@ -2,12 +2,15 @@ package manifest
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/eljojo/rememory/internal/core"
 )
@@ -19,10 +22,63 @@ type ArchiveResult struct {
 }
 
 // Archive creates a tar.gz archive of the given directory.
-// The archive preserves the directory structure relative to the source.
+// The archive preserves the directory structure relative to the source, and
+// ends with a MANIFEST-HASHES.txt in the root directory listing the SHA-256
+// of every file so recovery can check each one individually.
 // Returns warnings about any skipped files (symlinks, special files, etc.)
 func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
-	result := &ArchiveResult{}
+	return ArchiveCompressed(w, sourceDir, core.CompressionGzip)
+}
+
+// ArchiveCompressed is Archive with a choice of compression codec.
+func ArchiveCompressed(w io.Writer, sourceDir string, compression core.Compression) (*ArchiveResult, error) {
+	return ArchiveWithOptions(w, sourceDir, ArchiveOptions{Compression: compression})
+}
+
+// SymlinkMode says what Archive does with symlinks in the source directory.
+type SymlinkMode string
+
+const (
+	// SymlinkSkip leaves symlinks out with a warning. This is the default.
+	SymlinkSkip SymlinkMode = "skip"
+	// SymlinkFollow archives what the link points to under the link's name.
+	// Targets must be inside the source directory.
+	SymlinkFollow SymlinkMode = "follow"
+	// SymlinkStore archives the link itself. Recovery doesn't recreate
+	// links, but other tar tools will.
+	SymlinkStore SymlinkMode = "store"
+)
+
+// ParseSymlinkMode validates a symlink mode name from user input.
+func ParseSymlinkMode(name string) (SymlinkMode, error) {
+	switch m := SymlinkMode(name); m {
+	case SymlinkSkip, SymlinkFollow, SymlinkStore:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown symlink mode %q (use follow, store, or skip)", name)
+	}
+}
+
+// ArchiveOptions configures ArchiveWithOptions.
+type ArchiveOptions struct {
+	// Compression is the codec wrapped around the tar archive (default gzip).
+	Compression core.Compression
+	// Symlinks is what to do with symlinks (default SymlinkSkip).
+	Symlinks SymlinkMode
+	// Only limits the archive to these top-level entries of the source
+	// directory, by name. Empty means everything.
+	Only []string
+}
+
+// ArchiveWithOptions is Archive with a choice of compression codec and
+// symlink handling.
+func ArchiveWithOptions(w io.Writer, sourceDir string, opts ArchiveOptions) (*ArchiveResult, error) {
+	if opts.Compression == "" {
+		opts.Compression = core.CompressionGzip
+	}
+	if opts.Symlinks == "" {
+		opts.Symlinks = SymlinkSkip
+	}
 
 	sourceDir, err := filepath.Abs(sourceDir)
 	if err != nil {
@@ -37,19 +93,99 @@ func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
 		return nil, fmt.Errorf("not a directory: %s", sourceDir)
 	}
 
-	gzw := gzip.NewWriter(w)
-	defer gzw.Close()
+	// Followed links are checked against the real location of the source
+	// directory, in case it sits under a symlink itself.
+	realRoot, err := filepath.EvalSymlinks(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path: %w", err)
+	}
+
+	cw, err := core.NewCompressor(w, opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	defer cw.Close()
 
-	tw := tar.NewWriter(gzw)
+	tw := tar.NewWriter(cw)
 	defer tw.Close()
 
-	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	a := &archiver{
+		tw:         tw,
+		symlinks:   opts.Symlinks,
+		parentDir:  filepath.Dir(sourceDir),
+		rootName:   filepath.Base(sourceDir),
+		realRoot:   realRoot,
+		hashesPath: filepath.Join(sourceDir, core.ManifestHashesFile),
+		hashes:     make(map[string]string),
+		result:     &ArchiveResult{},
+	}
+	if len(opts.Only) > 0 {
+		a.only = make(map[string]bool, len(opts.Only))
+		for _, name := range opts.Only {
+			a.only[filepath.Join(sourceDir, name)] = true
+		}
+	}
+	if err := a.walk(sourceDir, sourceDir); err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+
+	hashList := core.FormatManifestHashes(a.hashes)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     a.rootName + "/" + core.ManifestHashesFile,
+		Mode:     0644,
+		Size:     int64(len(hashList)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return nil, fmt.Errorf("writing header for %s: %w", core.ManifestHashesFile, err)
+	}
+	if _, err := tw.Write(hashList); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", core.ManifestHashesFile, err)
+	}
+
+	return a.result, nil
+}
+
+// archiver holds the state of one ArchiveWithOptions call.
+type archiver struct {
+	tw         *tar.Writer
+	symlinks   SymlinkMode
+	parentDir  string // entries are named relative to this
+	rootName   string
+	realRoot   string
+	hashesPath string
+	hashes     map[string]string
+	result     *ArchiveResult
+	only       map[string]bool // top-level paths to keep; nil keeps all
+	// linkParents are the real directories holding the directory symlinks
+	// being followed, outermost first, for catching cycles.
+	linkParents []string
+}
+
+// walk archives the tree at dir as if it were at name. The two differ only
+// inside a followed directory symlink, where dir is the link's target and
+// name is the link.
+func (a *archiver) walk(dir, name string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("computing relative path: %w", err)
+		}
+		logical := filepath.Join(name, rel)
+
+		if a.only != nil && filepath.Dir(logical) == filepath.Join(a.parentDir, a.rootName) && !a.only[logical] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Compute relative path for display
-		relPath, err := filepath.Rel(filepath.Dir(sourceDir), path)
+		relPath, err := filepath.Rel(a.parentDir, logical)
 		if err != nil {
 			return fmt.Errorf("computing relative path: %w", err)
 		}
@@ -57,57 +193,135 @@ func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
 		// Check for symlinks and other special files
 		mode := info.Mode()
 		if mode&os.ModeSymlink != 0 {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("skipping symlink: %s (symlinks are not preserved for security)", relPath))
-			return nil
+			return a.symlink(path, logical, relPath, info)
 		}
 		if !mode.IsRegular() && !mode.IsDir() {
 			typeName := describeFileType(mode)
-			result.Warnings = append(result.Warnings,
+			a.result.Warnings = append(a.result.Warnings,
 				fmt.Sprintf("skipping %s: %s (only regular files and directories are archived)", typeName, relPath))
 			return nil
 		}
-
-		// Create tar header
-		header, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return fmt.Errorf("creating header for %s: %w", path, err)
+		if logical == a.hashesPath {
+			a.result.Warnings = append(a.result.Warnings,
+				fmt.Sprintf("skipping %s (this name is reserved for the file hash list)", relPath))
+			return nil
 		}
 
-		header.Name = relPath
+		return a.add(path, relPath, info)
+	})
+}
 
-		// Ensure directory entries end with /
-		if info.IsDir() {
-			header.Name += "/"
+// symlink archives the link at path according to the symlink mode.
+func (a *archiver) symlink(path, logical, relPath string, info os.FileInfo) error {
+	switch a.symlinks {
+	case SymlinkStore:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("reading symlink %s: %w", relPath, err)
 		}
-
-		if err := tw.WriteHeader(header); err != nil {
+		header := &tar.Header{
+			Name:     relPath,
+			Linkname: target,
+			Mode:     0777,
+			ModTime:  info.ModTime(),
+			Typeflag: tar.TypeSymlink,
+		}
+		if err := a.tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("writing header for %s: %w", path, err)
 		}
+		return nil
 
-		// Only write content for regular files
-		if !info.Mode().IsRegular() {
+	case SymlinkFollow:
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			a.result.Warnings = append(a.result.Warnings,
+				fmt.Sprintf("skipping broken symlink: %s", relPath))
 			return nil
 		}
-
-		f, err := os.Open(path)
+		if !withinDir(target, a.realRoot) {
+			return fmt.Errorf("symlink %s points outside the manifest directory", relPath)
+		}
+		targetInfo, err := os.Stat(target)
 		if err != nil {
-			return fmt.Errorf("opening %s: %w", path, err)
+			return fmt.Errorf("accessing symlink target of %s: %w", relPath, err)
 		}
-		defer f.Close()
 
-		if _, err := io.Copy(tw, f); err != nil {
-			return fmt.Errorf("copying %s: %w", path, err)
+		switch {
+		case targetInfo.IsDir():
+			// A link to a directory already being walked would never end:
+			// its own parent or further up, or, through other links, a
+			// directory that leads back to it (d1/l -> ../d2, d2/l -> ../d1).
+			realParent, err := filepath.EvalSymlinks(filepath.Dir(path))
+			if err != nil {
+				return fmt.Errorf("resolving path: %w", err)
+			}
+			for _, dir := range append(a.linkParents, realParent) {
+				if withinDir(dir, target) {
+					a.result.Warnings = append(a.result.Warnings,
+						fmt.Sprintf("skipping symlink: %s (it leads back to a directory that contains it)", relPath))
+					return nil
+				}
+			}
+			a.linkParents = append(a.linkParents, realParent)
+			defer func() { a.linkParents = a.linkParents[:len(a.linkParents)-1] }()
+			return a.walk(target, logical)
+		case targetInfo.Mode().IsRegular():
+			return a.add(target, relPath, targetInfo)
+		default:
+			a.result.Warnings = append(a.result.Warnings,
+				fmt.Sprintf("skipping symlink to %s: %s (only regular files and directories are archived)", describeFileType(targetInfo.Mode()), relPath))
+			return nil
 		}
 
+	default:
+		a.result.Warnings = append(a.result.Warnings,
+			fmt.Sprintf("skipping symlink: %s (symlinks are not preserved for security)", relPath))
 		return nil
-	})
+	}
+}
 
+// add archives the regular file or directory at path under relPath.
+func (a *archiver) add(path, relPath string, info os.FileInfo) error {
+	// Create tar header
+	header, err := tar.FileInfoHeader(info, "")
 	if err != nil {
-		return nil, fmt.Errorf("walking directory: %w", err)
+		return fmt.Errorf("creating header for %s: %w", path, err)
 	}
 
-	return result, nil
+	header.Name = relPath
+
+	// Ensure directory entries end with /
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := a.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing header for %s: %w", path, err)
+	}
+
+	// Only write content for regular files
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(a.tw, h), f); err != nil {
+		return fmt.Errorf("copying %s: %w", path, err)
+	}
+	a.hashes[strings.TrimPrefix(filepath.ToSlash(relPath), a.rootName+"/")] = "sha256:" + hex.EncodeToString(h.Sum(nil))
+
+	return nil
+}
+
+// withinDir reports whether path is dir or somewhere beneath it.
+func withinDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
 }
 
 // describeFileType returns a human-readable description of a file type.
@@ -136,10 +350,27 @@ type ExtractResult struct {
 	Path string
 	// Warnings contains messages about files that were skipped (symlinks, etc.)
 	Warnings []string
+	// Files lists the regular files written, in archive order.
+	Files []ExtractedFile
+	// Verified is how many files were checked against the archive's
+	// MANIFEST-HASHES.txt, or 0 when it has none.
+	Verified int
+}
+
+// ExtractedFile is a regular file written by Extract.
+type ExtractedFile struct {
+	Name string // As named in the archive
+	Size int64
+	Hash string // HashBytes of the bytes written
 }
 
-// Extract unpacks a tar.gz archive to the destination directory.
+// Extract unpacks a tar.gz (or tar.zst) archive to the destination directory.
 // Returns the path to the extracted directory and any warnings about skipped files.
+//
+// Each file is hashed as it is written. When the archive has a
+// MANIFEST-HASHES.txt, the files are checked against it at the end, so what
+// is checked is exactly what is on disk; on a mismatch the files written are
+// removed again, and the error wraps core.ErrFileHashMismatch.
 func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 	result := &ExtractResult{}
 
@@ -152,15 +383,18 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 		return nil, fmt.Errorf("creating destination: %w", err)
 	}
 
-	gzr, err := gzip.NewReader(r)
+	dec, err := core.NewDecompressor(r)
 	if err != nil {
-		return nil, fmt.Errorf("creating gzip reader: %w", err)
+		return nil, fmt.Errorf("opening compressed archive: %w", err)
 	}
-	defer gzr.Close()
+	defer dec.Close()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(dec)
 	var rootDir string
 	var totalSize int64
+	entries := 0
+	var writtenPaths []string
+	var hashList *bytes.Buffer
 
 	for {
 		header, err := tr.Next()
@@ -171,6 +405,11 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 			return nil, fmt.Errorf("reading tar: %w", err)
 		}
 
+		entries++
+		if entries > core.MaxEntries {
+			return nil, fmt.Errorf("archive has too many entries (limit %d)", core.MaxEntries)
+		}
+
 		// Track the root directory
 		parts := strings.Split(header.Name, string(filepath.Separator))
 		if len(parts) > 0 && rootDir == "" {
@@ -211,7 +450,14 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 
 			// Use LimitReader to enforce size limit during actual copy
 			limitedReader := io.LimitReader(tr, core.MaxFileSize+1)
-			written, err := io.Copy(f, limitedReader)
+			h := sha256.New()
+			w := io.MultiWriter(f, h)
+			if _, rel, ok := strings.Cut(header.Name, "/"); ok && rel == core.ManifestHashesFile {
+				hashList = new(bytes.Buffer)
+				w = io.MultiWriter(f, h, hashList)
+			}
+			written, err := io.Copy(w, limitedReader)
+			writtenPaths = append(writtenPaths, target)
 			closeErr := f.Close()
 			if err != nil {
 				return nil, fmt.Errorf("writing file %s: %w", target, err)
@@ -222,6 +468,11 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 			if written > core.MaxFileSize {
 				return nil, fmt.Errorf("file exceeds maximum size during extraction")
 			}
+			result.Files = append(result.Files, ExtractedFile{
+				Name: header.Name,
+				Size: written,
+				Hash: "sha256:" + hex.EncodeToString(h.Sum(nil)),
+			})
 
 		case tar.TypeSymlink:
 			result.Warnings = append(result.Warnings,
@@ -242,10 +493,38 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 		return nil, fmt.Errorf("empty archive")
 	}
 
+	if hashList != nil {
+		verified, err := verifyExtracted(result.Files, hashList.Bytes())
+		if err != nil {
+			for _, path := range writtenPaths {
+				os.Remove(path)
+			}
+			return nil, err
+		}
+		result.Verified = verified
+	}
+
 	result.Path = filepath.Join(destDir, rootDir)
 	return result, nil
 }
 
+// verifyExtracted checks the files Extract wrote against the contents of
+// MANIFEST-HASHES.txt, and returns how many it lists.
+func verifyExtracted(files []ExtractedFile, hashList []byte) (int, error) {
+	hashes, err := core.ParseManifestHashes(hashList)
+	if err != nil {
+		return 0, err
+	}
+	hashed := make([]core.FileHash, len(files))
+	for i, f := range files {
+		hashed[i] = core.FileHash{Name: f.Name, Hash: f.Hash}
+	}
+	if err := core.VerifyFileHashes(hashed, hashes); err != nil {
+		return 0, err
+	}
+	return len(hashes), nil
+}
+
 // describeTarType returns a human-readable description of a tar entry type.
 func describeTarType(typeflag byte) string {
 	switch typeflag {
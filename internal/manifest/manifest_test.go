@@ -4,10 +4,15 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 func TestArchiveExtract(t *testing.T) {
@@ -66,6 +71,132 @@ func TestArchiveExtract(t *testing.T) {
 	}
 }
 
+func TestArchiveFileHashes(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	files := map[string]string{
+		"secret.txt":          "super secret data",
+		"subdir/file.txt":     "nested file content",
+		"MANIFEST-HASHES.txt": "user file with the reserved name",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(srcDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	result, err := Archive(&buf, srcDir)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "MANIFEST-HASHES.txt") {
+		t.Errorf("expected a warning about the reserved name, got %v", result.Warnings)
+	}
+
+	extracted, err := core.ExtractTarGz(buf.Bytes())
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	hashes, err := core.FindManifestHashes(extracted)
+	if err != nil {
+		t.Fatalf("reading hash list: %v", err)
+	}
+	if len(hashes) != 2 || hashes["subdir/file.txt"] != core.HashString("nested file content") {
+		t.Fatalf("unexpected hash list: %v", hashes)
+	}
+	if err := core.VerifyExtractedFiles(extracted, hashes); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// Flip one byte of one file
+	for i := range extracted {
+		if extracted[i].Name == "manifest/secret.txt" {
+			extracted[i].Data[0] ^= 0x01
+		}
+	}
+	err = core.VerifyExtractedFiles(extracted, hashes)
+	if !errors.Is(err, core.ErrFileHashMismatch) {
+		t.Fatalf("expected ErrFileHashMismatch, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "manifest/secret.txt") {
+		t.Errorf("error should name the tampered file: %v", err)
+	}
+}
+
+func TestExtractVerifiesHashes(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	if err := os.MkdirAll(filepath.Join(srcDir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{"secret.txt": "super secret data", "subdir/file.txt": "nested"} {
+		if err := os.WriteFile(filepath.Join(srcDir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := Archive(&buf, srcDir); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	result, err := Extract(&buf, t.TempDir())
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if result.Verified != 2 {
+		t.Errorf("verified %d files, want 2", result.Verified)
+	}
+	for _, f := range result.Files {
+		if f.Name == "manifest/secret.txt" && (f.Hash != core.HashString("super secret data") || f.Size != 17) {
+			t.Errorf("secret.txt written as %+v", f)
+		}
+	}
+
+	// A file that differs from the hash list is named, and not left behind.
+	hashList := core.FormatManifestHashes(map[string]string{"secret.txt": core.HashString("super secret data")})
+	data := createTarGzBytes(t, map[string]string{
+		"manifest/secret.txt":                 "tampered data",
+		"manifest/" + core.ManifestHashesFile: string(hashList),
+	})
+	destDir := t.TempDir()
+	_, err = Extract(bytes.NewReader(data), destDir)
+	if !errors.Is(err, core.ErrFileHashMismatch) || !strings.Contains(err.Error(), "manifest/secret.txt") {
+		t.Fatalf("got %v, want ErrFileHashMismatch naming manifest/secret.txt", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "manifest", "secret.txt")); !os.IsNotExist(err) {
+		t.Error("the tampered file was left on disk")
+	}
+}
+
+func TestArchiveZstd(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "secret.txt"), []byte("super secret data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := ArchiveCompressed(&buf, srcDir, core.CompressionZstd); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if c, err := core.DetectCompression(buf.Bytes()); err != nil || c != core.CompressionZstd {
+		t.Fatalf("DetectCompression = %q, %v", c, err)
+	}
+
+	result, err := Extract(&buf, t.TempDir())
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(result.Path, "secret.txt"))
+	if err != nil || string(content) != "super secret data" {
+		t.Errorf("secret.txt = %q, %v", content, err)
+	}
+}
+
 func TestArchiveNotDirectory(t *testing.T) {
 	// Create a temp file
 	f, err := os.CreateTemp("", "test")
@@ -328,6 +459,192 @@ func TestArchiveSymlinkWarning(t *testing.T) {
 	}
 }
 
+// symlinkTree builds a manifest directory with a file link, a directory
+// link, and a link back to its own root.
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	if err := os.MkdirAll(filepath.Join(srcDir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "real.txt"), []byte("real content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "docs", "a.txt"), []byte("doc content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"link.txt":  "real.txt",
+		"docs-link": "docs",
+		"loop":      ".",
+	} {
+		if err := os.Symlink(target, filepath.Join(srcDir, link)); err != nil {
+			t.Skip("symlinks not supported on this platform")
+		}
+	}
+	return srcDir
+}
+
+// tarEntries lists a gzip archive's entries by name.
+func tarEntries(t *testing.T, data []byte) map[string]*tar.Header {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	entries := make(map[string]*tar.Header)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[h.Name] = h
+	}
+}
+
+func TestArchiveSymlinkModes(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		var buf bytes.Buffer
+		result, err := ArchiveWithOptions(&buf, symlinkTree(t), ArchiveOptions{})
+		if err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+		if len(result.Warnings) != 3 {
+			t.Errorf("expected a warning per link, got %v", result.Warnings)
+		}
+		entries := tarEntries(t, buf.Bytes())
+		for _, name := range []string{"manifest/link.txt", "manifest/docs-link/", "manifest/loop/"} {
+			if _, ok := entries[name]; ok {
+				t.Errorf("%s should have been skipped", name)
+			}
+		}
+	})
+
+	t.Run("store", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := ArchiveWithOptions(&buf, symlinkTree(t), ArchiveOptions{Symlinks: SymlinkStore}); err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+		entries := tarEntries(t, buf.Bytes())
+		h, ok := entries["manifest/link.txt"]
+		if !ok {
+			t.Fatal("link.txt should be stored")
+		}
+		if h.Typeflag != tar.TypeSymlink || h.Linkname != "real.txt" {
+			t.Errorf("link.txt: got type %c -> %q, want a symlink to real.txt", h.Typeflag, h.Linkname)
+		}
+		if h := entries["manifest/docs-link"]; h == nil || h.Linkname != "docs" {
+			t.Errorf("docs-link should be stored as a link to docs, got %+v", h)
+		}
+		if _, ok := entries["manifest/docs-link/a.txt"]; ok {
+			t.Error("a stored directory link should not be walked")
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		var buf bytes.Buffer
+		result, err := ArchiveWithOptions(&buf, symlinkTree(t), ArchiveOptions{Symlinks: SymlinkFollow})
+		if err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "loop") {
+			t.Errorf("expected one warning about the loop, got %v", result.Warnings)
+		}
+
+		extracted, err := core.ExtractTarGz(buf.Bytes())
+		if err != nil {
+			t.Fatalf("extract: %v", err)
+		}
+		got := make(map[string]string)
+		for _, f := range extracted {
+			got[f.Name] = string(f.Data)
+		}
+		if got["manifest/link.txt"] != "real content" {
+			t.Errorf("link.txt should hold its target's content, got %q", got["manifest/link.txt"])
+		}
+		if got["manifest/docs-link/a.txt"] != "doc content" {
+			t.Errorf("docs-link should be walked, got %q", got["manifest/docs-link/a.txt"])
+		}
+
+		hashes, err := core.FindManifestHashes(extracted)
+		if err != nil {
+			t.Fatalf("reading hash list: %v", err)
+		}
+		if err := core.VerifyExtractedFiles(extracted, hashes); err != nil {
+			t.Errorf("verify: %v", err)
+		}
+	})
+
+	t.Run("follow mutual links", func(t *testing.T) {
+		srcDir := filepath.Join(t.TempDir(), "manifest")
+		for _, dir := range []string{"d1", "d2"} {
+			if err := os.MkdirAll(filepath.Join(srcDir, dir), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(srcDir, dir, "f.txt"), []byte(dir), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Symlink("../d2", filepath.Join(srcDir, "d1", "l")); err != nil {
+			t.Skip("symlinks not supported on this platform")
+		}
+		if err := os.Symlink("../d1", filepath.Join(srcDir, "d2", "l")); err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan error, 1)
+		var result *ArchiveResult
+		go func() {
+			var err error
+			result, err = ArchiveWithOptions(io.Discard, srcDir, ArchiveOptions{Symlinks: SymlinkFollow})
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("archive: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("archiving links that point at each other didn't finish")
+		}
+		if len(result.Warnings) == 0 || !strings.Contains(strings.Join(result.Warnings, "\n"), "leads back") {
+			t.Errorf("expected a warning about the cycle, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("follow outside root", func(t *testing.T) {
+		srcDir := symlinkTree(t)
+		outside := filepath.Join(t.TempDir(), "outside.txt")
+		if err := os.WriteFile(outside, []byte("not yours"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(outside, filepath.Join(srcDir, "escape.txt")); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		_, err := ArchiveWithOptions(&buf, srcDir, ArchiveOptions{Symlinks: SymlinkFollow})
+		if err == nil || !strings.Contains(err.Error(), "outside the manifest directory") {
+			t.Fatalf("expected an error about the escaping link, got %v", err)
+		}
+	})
+}
+
+func TestParseSymlinkMode(t *testing.T) {
+	for _, name := range []string{"follow", "store", "skip"} {
+		if m, err := ParseSymlinkMode(name); err != nil || string(m) != name {
+			t.Errorf("ParseSymlinkMode(%q) = %q, %v", name, m, err)
+		}
+	}
+	if _, err := ParseSymlinkMode("copy"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
 func TestArchiveEmptyDir(t *testing.T) {
 	dir := t.TempDir()
 	emptyDir := filepath.Join(dir, "empty")
@@ -346,3 +663,176 @@ func TestArchiveEmptyDir(t *testing.T) {
 		t.Fatalf("Extract empty archive: %v", err)
 	}
 }
+
+func TestSealDirectory(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	files := map[string]string{
+		"README.md":         "# Test Manifest",
+		"secret.txt":        "super secret data",
+		"subdir/file.txt":   "nested file content",
+		"subdir/b/deep.txt": "deeper still",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(srcDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seal := func() []string {
+		var sealed bytes.Buffer
+		if _, err := SealDirectory(srcDir, &sealed, "test-passphrase", SealOptions{}); err != nil {
+			t.Fatalf("seal: %v", err)
+		}
+		archive, err := core.DecryptBytes(sealed.Bytes(), "test-passphrase")
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+
+		dstDir := t.TempDir()
+		result, err := Extract(bytes.NewReader(archive), dstDir)
+		if err != nil {
+			t.Fatalf("extract: %v", err)
+		}
+		for path, want := range files {
+			got, err := os.ReadFile(filepath.Join(result.Path, path))
+			if err != nil {
+				t.Errorf("reading %s: %v", path, err)
+				continue
+			}
+			if string(got) != want {
+				t.Errorf("%s: got %q, want %q", path, got, want)
+			}
+		}
+
+		extracted, err := core.ExtractTarGz(archive)
+		if err != nil {
+			t.Fatalf("listing archive: %v", err)
+		}
+		var names []string
+		for _, f := range extracted {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	first, second := seal(), seal()
+	if strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Errorf("entry order changed between runs:\n%v\n%v", first, second)
+	}
+}
+
+func TestSealDirectoryAnswer(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"secret.txt", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte("super secret data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sealed bytes.Buffer
+	opts := SealOptions{ArchiveOptions: ArchiveOptions{Only: []string{"secret.txt"}}, Answer: "Fluffy"}
+	if _, err := SealDirectory(srcDir, &sealed, "test-passphrase", opts); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if _, err := core.DecryptBytes(sealed.Bytes(), "test-passphrase"); err == nil {
+		t.Error("decrypted without the answer")
+	}
+	archive, err := core.DecryptBytesWithAnswer(sealed.Bytes(), "test-passphrase", "fluffy")
+	if err != nil {
+		t.Fatalf("decrypt with the answer: %v", err)
+	}
+	files, err := core.ExtractTarGz(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if list := strings.Join(names, " "); !strings.Contains(list, "secret.txt") || strings.Contains(list, "other.txt") {
+		t.Errorf("archive holds %v, want secret.txt and not other.txt", names)
+	}
+}
+
+func TestSealDirectoryNonexistent(t *testing.T) {
+	var sealed bytes.Buffer
+	_, err := SealDirectory(filepath.Join(t.TempDir(), "missing"), &sealed, "test-passphrase", SealOptions{})
+	if err == nil {
+		t.Fatal("expected an error sealing a missing directory")
+	}
+	if strings.Contains(err.Error(), errSealStopped.Error()) {
+		t.Errorf("error should come from the archive, got %v", err)
+	}
+}
+
+func TestFindShares(t *testing.T) {
+	share, err := os.ReadFile(filepath.Join("..", "core", "testdata", "v2-bundle", "SHARE-alice.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := core.ParseShare(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"SHARE-bob.txt":       "renamed, but the name gives it away",
+		"notes/pem.txt":       "keep this safe:\n" + string(share),
+		"notes/compact.md":    "QR text: " + parsed.CompactEncode() + "\n",
+		"notes/lookalike.txt": "RM2:1:5:3:AAAA:0000 is not a real piece",
+		"passwords.txt":       "bank: hunter2",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := FindShares(dir)
+	if err != nil {
+		t.Fatalf("FindShares: %v", err)
+	}
+	want := []string{"SHARE-bob.txt", filepath.Join("notes", "compact.md"), filepath.Join("notes", "pem.txt")}
+	if strings.Join(found, ",") != strings.Join(want, ",") {
+		t.Errorf("FindShares = %v, want %v", found, want)
+	}
+}
+
+func TestFindNonText(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"passwords.txt":   []byte("bank: hunter2\n"),
+		"notes/readme.md": []byte("# Notas\n\nañadir más\n"),
+		"photos/key.png":  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		"old/latin1.txt":  []byte("contrase\xf1a"),
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nonText, total, err := FindNonText(dir)
+	if err != nil {
+		t.Fatalf("FindNonText: %v", err)
+	}
+	if total != 4 {
+		t.Errorf("total = %d, want 4", total)
+	}
+	want := []string{filepath.Join("old", "latin1.txt"), filepath.Join("photos", "key.png")}
+	if strings.Join(nonText, ",") != strings.Join(want, ",") {
+		t.Errorf("non-text = %v, want %v", nonText, want)
+	}
+}
@@ -0,0 +1,64 @@
+package manifest
+
+import (
+	"errors"
+	"io"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// errSealStopped is what the archiver sees when encryption gives up first.
+var errSealStopped = errors.New("encryption stopped")
+
+// SealOptions configures SealDirectory.
+type SealOptions struct {
+	ArchiveOptions
+	// Answer, if set, is the answer to a recovery question, needed along
+	// with the passphrase to decrypt, as with core.EncryptWithAnswer.
+	Answer string
+}
+
+// SealDirectory archives root like ArchiveWithOptions and encrypts the
+// archive with passphrase like core.Encrypt, or core.EncryptWithAnswer when
+// opts has an answer, writing the result to out.
+// The two run side by side through a pipe, so the archive is never held in
+// memory or written to disk whole, however big root is. Entries are in the
+// same lexical order as ArchiveWithOptions, so the archive lists the same
+// way every time.
+//
+// This lives here rather than in core because the directory walk does: core
+// can't import this package.
+func SealDirectory(root string, out io.Writer, passphrase string, opts SealOptions) (*ArchiveResult, error) {
+	pr, pw := io.Pipe()
+	type archived struct {
+		result *ArchiveResult
+		err    error
+	}
+	done := make(chan archived, 1)
+	go func() {
+		result, err := ArchiveWithOptions(pw, root, opts.ArchiveOptions)
+		// A nil error closes the pipe normally, ending the encryption.
+		pw.CloseWithError(err)
+		done <- archived{result, err}
+	}()
+
+	var encErr error
+	if opts.Answer != "" {
+		encErr = core.EncryptWithAnswer(out, pr, passphrase, opts.Answer)
+	} else {
+		encErr = core.Encrypt(out, pr, passphrase)
+	}
+	// Unblock the archiver if encryption stopped early.
+	pr.CloseWithError(errSealStopped)
+	a := <-done
+
+	// Report whichever side failed first: an archiving error also reaches
+	// the encryption through the pipe, and the reverse.
+	switch {
+	case a.err != nil && !errors.Is(a.err, errSealStopped):
+		return nil, a.err
+	case encErr != nil:
+		return nil, encErr
+	}
+	return a.result, nil
+}
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// shareScanLimit is how much of each file FindShares reads. Pieces are a
+// few hundred bytes, so one hiding in the first megabyte of a file is the
+// case worth catching; bigger files are still checked by name.
+const shareScanLimit = 1 << 20
+
+// shareNameRe matches the names seal gives piece files.
+var shareNameRe = regexp.MustCompile(`(?i)^share-.*\.txt$`)
+
+// compactShareRe finds candidates for the compact form (see
+// core.Share.CompactEncode); each one is confirmed with core.ParseCompact.
+var compactShareRe = regexp.MustCompile(`RM\d+:\d+:\d+:\d+:[A-Za-z0-9_-]+:[0-9a-f]{4}`)
+
+// FindShares lists the files under dir, relative to it, that look like
+// pieces: named like SHARE-alice.txt, or holding a piece in PEM or compact
+// form. Sealing a piece into the manifest it unlocks would defeat the
+// point of splitting it.
+func FindShares(dir string) ([]string, error) {
+	var found []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("computing relative path: %w", err)
+		}
+		if shareNameRe.MatchString(info.Name()) {
+			found = append(found, relPath)
+			return nil
+		}
+
+		looksLike, err := containsShare(path)
+		if err != nil {
+			return err
+		}
+		if looksLike {
+			found = append(found, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning for pieces: %w", err)
+	}
+	return found, nil
+}
+
+// containsShare reports whether the start of the file at path holds a piece.
+func containsShare(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, shareScanLimit))
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if bytes.Contains(data, []byte(core.ShareBegin)) {
+		return true, nil
+	}
+	for _, m := range compactShareRe.FindAll(data, -1) {
+		if _, err := core.ParseCompact(string(m)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,58 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// FindNonText lists the regular files under dir, relative to it, that don't
+// look like UTF-8 text by core.IsLikelyText: images, PDFs and other binary
+// files, and text in another encoding. It also returns how many files were
+// checked. Only the start of each file is read.
+func FindNonText(dir string) (nonText []string, total int, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		total++
+
+		text, err := isTextFile(path)
+		if err != nil {
+			return err
+		}
+		if !text {
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return fmt.Errorf("computing relative path: %w", err)
+			}
+			nonText = append(nonText, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("checking file contents: %w", err)
+	}
+	return nonText, total, nil
+}
+
+// isTextFile reports whether the file at path starts like UTF-8 text.
+func isTextFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, core.TextSampleSize))
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return core.IsLikelyText(data), nil
+}
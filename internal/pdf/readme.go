@@ -28,11 +28,16 @@ type ReadmeData struct {
 	GitHubReleaseURL string
 	ManifestChecksum string
 	RecoverChecksum  string
+	WASMChecksum     string // Checksum of the recovery tool embedded in recover.html
+	AckToken         string // Confirmation code the holder reads back (see bundle.GenerateAckToken)
 	Created          time.Time
 	Anonymous        bool
 	RecoveryURL      string // Base URL for QR code (e.g. "https://example.com/recover.html")
 	Language         string // Bundle language (e.g. "en", "es"); defaults to "en"
 	ManifestEmbedded bool   // true when manifest is embedded in recover.html
+	Note             string // Optional message from the project owner
+	Instructions     string // Optional recovery steps from the project owner
+	Question         string // Optional recovery question whose answer unlocks along with the pieces
 }
 
 // Font sizes
@@ -138,12 +143,24 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	p.CellFormat(0, 8, t("for", data.Holder), "", 1, "C", false, 0, "")
 	p.Ln(12)
 
+	// ── Note from the project owner — before anything else ──
+	if note := core.SanitizeNote(data.Note); note != "" {
+		p.SetFont(fontSans, "B", bodySize)
+		p.CellFormat(0, 6, t("note_title"), "", 1, "L", false, 0, "")
+		p.Ln(1)
+		addBody(p, note)
+		p.Ln(5)
+	}
+
 	// ── What is this? — context first ──
 	p.SetFont(fontSans, "B", bodySize)
 	p.CellFormat(0, 6, t("what_is_this"), "", 1, "L", false, 0, "")
 	p.Ln(1)
 	addBody(p, t("what_bundle_for", data.ProjectName))
 	addBody(p, t("what_one_of", data.Total))
+	if data.Question != "" {
+		addBody(p, t("what_question", data.Question))
+	}
 	p.Ln(5)
 
 	// ── Warning stamp — soft, centered, calm ──
@@ -177,6 +194,13 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	p.SetDrawColor(0, 0, 0)
 	p.SetLineWidth(0.2)
 
+	// ── Recovery steps from the project owner ──
+	if instructions := core.SanitizeNote(data.Instructions); instructions != "" {
+		addSection(p, t("instructions_title"))
+		addBody(p, instructions)
+		p.Ln(5)
+	}
+
 	// ── Other share holders — contact card layout ──
 	if !data.Anonymous {
 		addSection(p, t("other_holders"))
@@ -224,7 +248,11 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 			p.AddPage()
 		}
 	}
-	addSection(p, t("your_share"))
+	if data.Share != nil && data.Share.Label != "" {
+		addSection(p, fmt.Sprintf("%s (%s)", t("your_share"), data.Share.Label))
+	} else {
+		addSection(p, t("your_share"))
+	}
 	p.Ln(2)
 
 	// Generate QR code PNG
@@ -357,14 +385,16 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	p.MultiCell(0, 5, t("recover_offline"), "", "L", false)
 	p.Ln(5)
 
-	// Section: CLI fallback
-	addSection(p, t("recover_cli"))
-	addBody(p, t("recover_cli_hint"))
-	p.SetFont(fontMono, "", monoSize)
-	p.MultiCell(0, 5, data.GitHubReleaseURL, "", "L", false)
-	p.Ln(2)
-	addBody(p, t("recover_cli_usage"))
-	p.Ln(5)
+	// Section: CLI fallback, when there is somewhere to download it
+	if data.GitHubReleaseURL != "" {
+		addSection(p, t("recover_cli"))
+		addBody(p, t("recover_cli_hint"))
+		p.SetFont(fontMono, "", monoSize)
+		p.MultiCell(0, 5, data.GitHubReleaseURL, "", "L", false)
+		p.Ln(2)
+		addBody(p, t("recover_cli_usage"))
+		p.Ln(5)
+	}
 
 	// Footer: Metadata
 	p.SetFont(fontSans, "B", smallMono)
@@ -372,13 +402,24 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	p.SetFont(fontMono, "", smallMono)
 	p.SetFillColor(245, 245, 245)
 	addMeta(p, "rememory-version", data.Version)
+	if data.Share != nil && data.Share.Headers[core.RequiresVersionHeader] != "" {
+		addMeta(p, "requires-version", data.Share.Headers[core.RequiresVersionHeader])
+	}
 	addMeta(p, "created", data.Created.Format(time.RFC3339))
 	addMeta(p, "project", data.ProjectName)
 	addMeta(p, "threshold", fmt.Sprintf("%d", data.Threshold))
 	addMeta(p, "total", fmt.Sprintf("%d", data.Total))
-	addMeta(p, "github-release", data.GitHubReleaseURL)
+	if data.GitHubReleaseURL != "" {
+		addMeta(p, "github-release", data.GitHubReleaseURL)
+	}
 	addMeta(p, "checksum-manifest", data.ManifestChecksum)
 	addMeta(p, "checksum-recover-html", data.RecoverChecksum)
+	if data.WASMChecksum != "" {
+		addMeta(p, "checksum-recover-wasm", data.WASMChecksum)
+	}
+	if data.AckToken != "" {
+		addMeta(p, "confirmation-code", data.AckToken)
+	}
 
 	// Write to buffer
 	var buf bytes.Buffer
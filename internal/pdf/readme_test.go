@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"image/png"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
 )
 
 func testReadmeData() ReadmeData {
@@ -56,6 +59,25 @@ func TestGenerateReadmeAnonymous(t *testing.T) {
 	}
 }
 
+func TestGenerateReadmeWithoutCLI(t *testing.T) {
+	data := testReadmeData()
+	with, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatalf("GenerateReadme: %v", err)
+	}
+	data.GitHubReleaseURL = ""
+	without, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatalf("GenerateReadme (no CLI): %v", err)
+	}
+	if !bytes.HasPrefix(without, []byte("%PDF-")) {
+		t.Error("output does not start with PDF header")
+	}
+	if len(without) >= len(with) {
+		t.Errorf("leaving the CLI section out should shrink the PDF: %d bytes, was %d", len(without), len(with))
+	}
+}
+
 func TestQRContent(t *testing.T) {
 	data := testReadmeData()
 
@@ -181,3 +203,82 @@ func TestQRCodeContentMatchesCompact(t *testing.T) {
 		t.Error("parsed share data mismatch")
 	}
 }
+
+func TestGenerateShareSheet(t *testing.T) {
+	shareData := make([]byte, 33)
+	for i := range shareData {
+		shareData[i] = byte(i * 7)
+	}
+	share := core.NewShare(2, 3, 5, 3, "Carol", shareData)
+	share.Created = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, lang := range []string{"en", "es", "zh-TW"} {
+		t.Run(lang, func(t *testing.T) {
+			data := testReadmeData()
+			data.Share = share
+			data.Holder = "Carol"
+			data.Threshold, data.Total = 3, 5
+			data.Language = lang
+
+			pdfBytes, err := GenerateShareSheet(data)
+			if err != nil {
+				t.Fatalf("GenerateShareSheet: %v", err)
+			}
+			if !bytes.HasPrefix(pdfBytes, []byte("%PDF-")) {
+				t.Error("output does not start with PDF header")
+			}
+			if pages := bytes.Count(pdfBytes, []byte("/Type /Page\n")); pages != 1 {
+				t.Errorf("share sheet has %d pages, want 1", pages)
+			}
+		})
+	}
+
+	if _, err := GenerateShareSheet(ReadmeData{Holder: "Carol"}); err == nil {
+		t.Error("expected error without a share")
+	}
+}
+
+func TestShareSheetQRDecodesToShare(t *testing.T) {
+	// The sheet's QR code holds the same link as README.pdf's, so scanning
+	// it must give back the share. The link doesn't carry the holder or the
+	// date, so those are copied over before comparing.
+	share := core.NewShare(2, 3, 5, 3, "Carol", []byte("share-sheet-qr-round-trip-data!!!"))
+	data := ReadmeData{Share: share, Holder: "Carol", Threshold: 3, Total: 5, RecoveryURL: "https://example.com/recover.html"}
+
+	qrPNG, err := generateQRPNG(data.QRContent())
+	if err != nil {
+		t.Fatalf("generateQRPNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(qrPNG))
+	if err != nil {
+		t.Fatalf("QR code is not valid PNG: %v", err)
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("reading QR image: %v", err)
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bmp, nil)
+	if err != nil {
+		t.Fatalf("decoding QR code: %v", err)
+	}
+
+	u, err := url.Parse(result.GetText())
+	if err != nil {
+		t.Fatalf("parsing QR content: %v", err)
+	}
+	if u.Scheme != "https" || u.Host != "example.com" || u.Path != "/recover.html" {
+		t.Errorf("QR link points to %s://%s%s", u.Scheme, u.Host, u.Path)
+	}
+	compact, ok := strings.CutPrefix(u.Fragment, "share=")
+	if !ok {
+		t.Fatalf("QR fragment %q has no share", u.Fragment)
+	}
+	got, err := core.ParseCompact(compact)
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+	got.Holder, got.Created = share.Holder, share.Created
+	if got.Encode() != share.Encode() {
+		t.Errorf("QR decodes to\n%s\nwant\n%s", got.Encode(), share.Encode())
+	}
+}
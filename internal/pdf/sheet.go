@@ -0,0 +1,191 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// Share sheet layout, in mm on an A4 page (210 × 297).
+const (
+	sheetFoldY     = 148.5 // the sheet folds in half here
+	sheetQRSize    = 55.0
+	sheetCropInset = 8.0 // cut marks sit at the corners of this inset box
+	sheetCropLen   = 5.0
+	sheetGridCols  = 5
+	sheetRowHeight = 7.0
+)
+
+// GenerateShareSheet creates a one-page PDF for keeping a share on paper:
+// the holder, project and fingerprint, the share's QR code and compact
+// string on the top half, and the recovery words in a numbered grid on the
+// bottom half. The sheet folds in half, printed side in, along a dashed
+// line, and has cut marks at the corners. Everything else in
+// README.pdf (contacts, instructions, metadata) is left out.
+//
+// It uses the same ReadmeData as GenerateReadme; OtherFriends, the
+// checksums and the recovery instructions are ignored.
+func GenerateShareSheet(data ReadmeData) ([]byte, error) {
+	if data.Share == nil {
+		return nil, fmt.Errorf("no share to print")
+	}
+	lang := data.Language
+	if lang == "" {
+		lang = "en"
+	}
+	t := func(key string, args ...any) string {
+		return translations.T("readme", lang, key, args...)
+	}
+
+	p := fpdf.New("P", "mm", "A4", "")
+	p.SetMargins(20, 14, 20)
+	// One page, always: nothing may spill onto a second sheet of paper.
+	p.SetAutoPageBreak(false, 0)
+	registerUTF8Fonts(p)
+	p.AddPage()
+
+	pageWidth, pageHeight := p.GetPageSize()
+	leftMargin, _, rightMargin, _ := p.GetMargins()
+	contentWidth := pageWidth - leftMargin - rightMargin
+
+	bc := bundleColors[0]
+	if data.Share.Index > 0 {
+		bc = bundleColors[(data.Share.Index-1)%len(bundleColors)]
+	}
+	p.SetFillColor(bc[0], bc[1], bc[2])
+	p.Rect(0, 0, pageWidth, 4, "F")
+
+	drawCropMarks(p, pageWidth, pageHeight)
+
+	// ── Top half: who, what, and the QR code ──
+	p.SetTextColor(46, 42, 38)
+	p.SetY(14)
+	p.SetFont(fontSans, "B", 18)
+	p.CellFormat(0, 10, t("sheet_title"), "", 1, "C", false, 0, "")
+	p.Ln(2)
+	p.SetFont(fontSans, "", 14)
+	p.CellFormat(0, 7, t("for", data.Holder), "", 1, "C", false, 0, "")
+	p.Ln(2)
+	p.SetFont(fontSans, "", bodySize)
+	p.CellFormat(0, 5, t("what_bundle_for", data.ProjectName), "", 1, "C", false, 0, "")
+	p.Ln(2)
+	p.SetFont(fontSans, "B", 11)
+	piece := t("sheet_piece", data.Share.Index, data.Total)
+	if data.Threshold > 0 {
+		piece += "  ·  " + t("recovery_rule_count", data.Threshold, data.Total)
+	}
+	p.CellFormat(0, 6, piece, "", 1, "C", false, 0, "")
+	if fp := data.Share.Fingerprint(); fp != "" {
+		p.SetFont(fontMono, "", 9)
+		p.CellFormat(0, 5, t("sheet_fingerprint", fp), "", 1, "C", false, 0, "")
+	}
+
+	qrPNG, err := generateQRPNG(data.QRContent())
+	if err != nil {
+		return nil, fmt.Errorf("generating QR code: %w", err)
+	}
+	opts := fpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
+	p.RegisterImageOptionsReader("qrcode", opts, bytes.NewReader(qrPNG))
+	qrY := 58.0
+	p.ImageOptions("qrcode", leftMargin+(contentWidth-sheetQRSize)/2, qrY, sheetQRSize, sheetQRSize, false, opts, 0, "")
+	p.SetY(qrY + sheetQRSize + 2)
+	p.SetFont(fontSans, "I", 9)
+	p.CellFormat(0, 5, t("qr_caption"), "", 1, "C", false, 0, "")
+	p.SetFont(fontMono, "", smallMono)
+	p.SetFillColor(245, 245, 245)
+	p.CellFormat(0, 4, data.Share.CompactEncode(), "", 1, "C", true, 0, "")
+
+	// ── Fold line ──
+	p.SetDrawColor(150, 150, 150)
+	p.SetLineWidth(0.2)
+	p.SetDashPattern([]float64{2, 1.5}, 0)
+	p.Line(leftMargin, sheetFoldY, pageWidth-rightMargin, sheetFoldY)
+	p.SetDashPattern([]float64{}, 0)
+	p.SetFont(fontSans, "", 7)
+	p.SetTextColor(150, 150, 150)
+	label := t("sheet_fold")
+	labelW := p.GetStringWidth(label) + 4
+	p.SetFillColor(255, 255, 255)
+	p.SetXY((pageWidth-labelW)/2, sheetFoldY-2)
+	p.CellFormat(labelW, 4, label, "", 0, "C", true, 0, "")
+	p.SetTextColor(46, 42, 38)
+
+	// ── Bottom half: the recovery words ──
+	p.SetY(sheetFoldY + 10)
+	nativeWords, err := data.Share.WordsForLang(core.Lang(lang))
+	if err != nil {
+		return nil, fmt.Errorf("encoding recovery words: %w", err)
+	}
+	if lang != "en" {
+		renderSheetWordGrid(p, nativeWords, t("recovery_words_title_lang", len(nativeWords), t("lang_"+lang)), leftMargin, contentWidth)
+		p.Ln(4)
+		englishWords, err := data.Share.Words()
+		if err != nil {
+			return nil, fmt.Errorf("encoding recovery words: %w", err)
+		}
+		renderSheetWordGrid(p, englishWords, t("recovery_words_title_english", len(englishWords)), leftMargin, contentWidth)
+		p.SetFont(fontSans, "I", 9)
+		p.MultiCell(0, 4.5, t("recovery_words_dual_hint"), "", "L", false)
+	} else {
+		renderSheetWordGrid(p, nativeWords, t("recovery_words_title", len(nativeWords)), leftMargin, contentWidth)
+		p.Ln(2)
+		p.SetFont(fontSans, "I", 9)
+		p.MultiCell(0, 4.5, t("recovery_words_hint"), "", "L", false)
+	}
+
+	p.SetY(pageHeight - 20)
+	p.SetFont(fontSans, "", 8)
+	p.SetTextColor(110, 110, 110)
+	p.MultiCell(0, 4, t("sheet_keep"), "", "C", false)
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, fmt.Errorf("writing PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSheetWordGrid draws words as a numbered grid read row by row, in a
+// monospaced font so similar letters can't be confused.
+func renderSheetWordGrid(p *fpdf.Fpdf, words []string, title string, leftMargin, contentWidth float64) {
+	addSection(p, title)
+	p.SetFont(fontMono, "", bodySize)
+	p.SetDrawColor(200, 200, 200)
+	p.SetLineWidth(0.2)
+
+	colWidth := contentWidth / sheetGridCols
+	startY := p.GetY()
+	for i, word := range words {
+		row, col := i/sheetGridCols, i%sheetGridCols
+		p.SetXY(leftMargin+float64(col)*colWidth, startY+float64(row)*sheetRowHeight)
+		p.CellFormat(colWidth, sheetRowHeight, fmt.Sprintf("%2d %s", i+1, norm.NFC.String(word)), "1", 0, "L", false, 0, "")
+	}
+	rows := (len(words) + sheetGridCols - 1) / sheetGridCols
+	p.SetY(startY + float64(rows)*sheetRowHeight + 2)
+}
+
+// drawCropMarks draws short lines just outside the corners of the inset box,
+// for trimming the sheet to fit an envelope or a safe deposit box.
+func drawCropMarks(p *fpdf.Fpdf, pageWidth, pageHeight float64) {
+	p.SetDrawColor(120, 120, 120)
+	p.SetLineWidth(0.2)
+	left, right := sheetCropInset, pageWidth-sheetCropInset
+	top, bottom := sheetCropInset, pageHeight-sheetCropInset
+	for _, c := range [][2]float64{{left, top}, {right, top}, {left, bottom}, {right, bottom}} {
+		x, y := c[0], c[1]
+		dx, dy := -sheetCropLen, -sheetCropLen
+		if x == right {
+			dx = sheetCropLen
+		}
+		if y == bottom {
+			dy = sheetCropLen
+		}
+		p.Line(x, y, x+dx, y)
+		p.Line(x, y, x, y+dy)
+	}
+}
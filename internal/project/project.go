@@ -1,11 +1,15 @@
 package project
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/eljojo/rememory/internal/core"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,6 +18,20 @@ const (
 	ManifestDir     = "manifest"
 	OutputDir       = "output"
 	SharesDir       = "shares"
+
+	// MaxNoteLength is the longest note, in characters, a project may carry.
+	MaxNoteLength = 2000
+
+	// MaxQuestionLength is the longest recovery question, in characters.
+	MaxQuestionLength = 200
+
+	// MaxInstructionsLength is the longest recovery instructions, in
+	// characters, a project may carry.
+	MaxInstructionsLength = 4000
+
+	// MaxLabelLength is the longest label, in characters, for a friend's
+	// piece.
+	MaxLabelLength = 16
 )
 
 // Friend represents a person who will hold a share.
@@ -21,6 +39,7 @@ type Friend struct {
 	Name     string `yaml:"name"`
 	Contact  string `yaml:"contact,omitempty"`
 	Language string `yaml:"language,omitempty"` // Bundle language override (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
+	Label    string `yaml:"label,omitempty"`    // Name for the friend's piece instead of its number (e.g. "A")
 }
 
 // ShareInfo stores information about a generated share.
@@ -36,6 +55,57 @@ type Sealed struct {
 	ManifestChecksum string      `yaml:"manifest_checksum"`
 	VerificationHash string      `yaml:"verification_hash"`
 	Shares           []ShareInfo `yaml:"shares"`
+
+	// Commitment is the salted hash of the split secret that every share
+	// also carries (see core.NewCommitment). Empty for older seals.
+	Commitment string `yaml:"commitment,omitempty"`
+
+	// LastRehearsed is when 'rememory rehearse' last confirmed that a quorum
+	// of pieces unlocks the manifest. Sealing again clears it.
+	LastRehearsed time.Time `yaml:"last_rehearsed,omitempty"`
+
+	// Secrets is set instead of ManifestChecksum, VerificationHash and
+	// Shares when the project was sealed with per-file keys.
+	Secrets []SealedSecret `yaml:"secrets,omitempty"`
+}
+
+// SealedSecret stores information about one top-level entry of manifest/
+// sealed under its own passphrase, with its own set of shares.
+type SealedSecret struct {
+	Name             string      `yaml:"name"` // file or directory name in manifest/
+	File             string      `yaml:"file"` // encrypted archive, relative to the project
+	ManifestChecksum string      `yaml:"manifest_checksum"`
+	VerificationHash string      `yaml:"verification_hash"`
+	Shares           []ShareInfo `yaml:"shares"`
+	Commitment       string      `yaml:"commitment,omitempty"`
+}
+
+// ErrPerFileKeys is returned by operations that need the single
+// MANIFEST.age of a project sealed the usual way.
+var ErrPerFileKeys = errors.New("not available for a project sealed with --per-file-keys")
+
+// PerFileKeys reports whether the project was sealed with a separate
+// passphrase for each top-level entry of manifest/.
+func (p *Project) PerFileKeys() bool {
+	return p.Sealed != nil && len(p.Sealed.Secrets) > 0
+}
+
+// RehearsalInterval is how long a sealed project can go without a
+// rehearsal before status suggests one.
+const RehearsalInterval = 365 * 24 * time.Hour
+
+// RehearsalDue reports whether a sealed project's recovery hasn't been
+// rehearsed for over RehearsalInterval as of now, counting from the seal
+// when it never has. It is false for a project that isn't sealed.
+func (p *Project) RehearsalDue(now time.Time) bool {
+	if p.Sealed == nil {
+		return false
+	}
+	last := p.Sealed.LastRehearsed
+	if last.IsZero() {
+		last = p.Sealed.At
+	}
+	return now.Sub(last) > RehearsalInterval
 }
 
 // Project represents a rememory project configuration.
@@ -45,9 +115,16 @@ type Project struct {
 	Threshold int      `yaml:"threshold"`
 	Anonymous bool     `yaml:"anonymous,omitempty"`
 	Language  string   `yaml:"language,omitempty"` // Default bundle language (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
+	Note      string   `yaml:"note,omitempty"`     // Message shown to every friend in README and recover.html
+	Question  string   `yaml:"question,omitempty"` // Recovery question whose answer is needed along with the pieces
 	Friends   []Friend `yaml:"friends"`
 	Sealed    *Sealed  `yaml:"sealed,omitempty"`
 
+	// Instructions are the project's own recovery steps ("call the bank
+	// at...", "the real key is in the safe deposit box"), printed in their
+	// own section of every README. Optional.
+	Instructions string `yaml:"instructions,omitempty"`
+
 	// Path is the directory containing this project (not serialized)
 	Path string `yaml:"-"`
 }
@@ -99,15 +176,99 @@ func (p *Project) Validate() error {
 		return fmt.Errorf("threshold (%d) cannot exceed number of friends (%d)", p.Threshold, len(p.Friends))
 	}
 
+	labels := make(map[string]int)
 	for i, f := range p.Friends {
 		if f.Name == "" {
 			return fmt.Errorf("friend %d: name is required", i+1)
 		}
+		if f.Label == "" {
+			continue
+		}
+		if n := utf8.RuneCountInString(f.Label); n > MaxLabelLength {
+			return fmt.Errorf("friend %d: label is too long (%d characters, max %d)", i+1, n, MaxLabelLength)
+		}
+		if strings.TrimSpace(f.Label) != f.Label || strings.ContainsAny(f.Label, "\r\n") {
+			return fmt.Errorf("friend %d: label must be a single line without surrounding spaces", i+1)
+		}
+		if j, ok := labels[f.Label]; ok {
+			return fmt.Errorf("friend %d: label %q is already used by friend %d", i+1, f.Label, j+1)
+		}
+		labels[f.Label] = i
+	}
+
+	if n := utf8.RuneCountInString(p.Note); n > MaxNoteLength {
+		return fmt.Errorf("note is too long (%d characters, max %d)", n, MaxNoteLength)
+	}
+	if n := utf8.RuneCountInString(p.Question); n > MaxQuestionLength {
+		return fmt.Errorf("question is too long (%d characters, max %d)", n, MaxQuestionLength)
+	}
+	if n := utf8.RuneCountInString(p.Instructions); n > MaxInstructionsLength {
+		return fmt.Errorf("instructions are too long (%d characters, max %d)", n, MaxInstructionsLength)
+	}
+	if strings.ContainsAny(p.Question, "\r\n") {
+		return fmt.Errorf("question must be a single line")
+	}
+	// The note, question and instructions are printed into README.txt,
+	// which is parsed again on recovery.
+	for _, marker := range []string{"-----BEGIN", "METADATA FOOTER"} {
+		if strings.Contains(p.Note, marker) {
+			return fmt.Errorf("note cannot contain %q", marker)
+		}
+		if strings.Contains(p.Question, marker) {
+			return fmt.Errorf("question cannot contain %q", marker)
+		}
+		if strings.Contains(p.Instructions, marker) {
+			return fmt.Errorf("instructions cannot contain %q", marker)
+		}
 	}
 
 	return nil
 }
 
+// MatchesShare checks that s is a piece of the project's current seal: its
+// total, threshold and set fingerprint (see core.Share.Fingerprint) agree
+// with the project. A piece that disagrees usually comes from a stale
+// bundle, made before the project was changed and sealed again. The error
+// names everything that disagrees. Fields the piece doesn't carry, as with
+// pieces decoded from words, aren't checked.
+func (p *Project) MatchesShare(s *core.Share) error {
+	if p.Sealed == nil {
+		return fmt.Errorf("project has not been sealed yet")
+	}
+
+	var problems []string
+	if s.Total != 0 && s.Total != len(p.Friends) {
+		problems = append(problems, fmt.Sprintf("the piece is one of %d, but the project has %d friends", s.Total, len(p.Friends)))
+	}
+	if s.Threshold != 0 && s.Threshold != p.Threshold {
+		problems = append(problems, fmt.Sprintf("the piece needs %d to recover, but the project's threshold is %d", s.Threshold, p.Threshold))
+	}
+	// A different total or threshold already means a different set, so the
+	// fingerprint is only worth comparing when both agree.
+	if len(problems) == 0 && !s.Created.IsZero() && !p.sealedSet(s) {
+		problems = append(problems, fmt.Sprintf("the piece is from set %s, made %s, but the project was sealed %s",
+			s.Fingerprint(), s.Created.UTC().Format("2006-01-02 15:04 UTC"), p.Sealed.At.UTC().Format("2006-01-02 15:04 UTC")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("piece doesn't match this project: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// sealedSet reports whether s has the fingerprint of a piece from the
+// project's seal. Older versions stamped each share separately, so a
+// minute either side of the seal time counts too.
+func (p *Project) sealedSet(s *core.Share) bool {
+	at := p.Sealed.At.UTC().Truncate(time.Minute)
+	for _, d := range []time.Duration{0, -time.Minute, time.Minute} {
+		want := core.Share{Version: s.Version, Total: s.Total, Threshold: s.Threshold, Created: at.Add(d), Headers: s.Headers}
+		if s.Fingerprint() == want.Fingerprint() {
+			return true
+		}
+	}
+	return false
+}
+
 // ManifestPath returns the path to the manifest directory.
 func (p *Project) ManifestPath() string {
 	return filepath.Join(p.Path, ManifestDir)
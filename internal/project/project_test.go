@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 func TestNewAndLoad(t *testing.T) {
@@ -102,6 +106,26 @@ func TestValidate(t *testing.T) {
 			project: Project{Name: "test", Threshold: 5, Friends: []Friend{{Name: "A"}, {Name: "B"}}},
 			wantErr: true,
 		},
+		{
+			name:    "labels",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Label: "A"}, {Name: "B", Label: "B"}, {Name: "C"}}},
+			wantErr: false,
+		},
+		{
+			name:    "duplicate label",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Label: "X"}, {Name: "B", Label: "X"}}},
+			wantErr: true,
+		},
+		{
+			name:    "label too long",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Label: strings.Repeat("x", MaxLabelLength+1)}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "label on two lines",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Label: "A\nB"}, {Name: "B"}}},
+			wantErr: true,
+		},
 		{
 			name:    "friend missing name",
 			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Contact: "a@x.com"}, {Name: "B"}}},
@@ -133,6 +157,35 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "note",
+			project: Project{Name: "test", Threshold: 2, Note: "Call my brother first.", Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+			wantErr: false,
+		},
+		{
+			name:    "note too long",
+			project: Project{Name: "test", Threshold: 2, Note: strings.Repeat("a", MaxNoteLength+1), Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "note with share marker",
+			project: Project{Name: "test", Threshold: 2, Note: "-----BEGIN REMEMORY SHARE-----", Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "question",
+			project: Project{Name: "test", Threshold: 2, Question: "Name of our first cat?", Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+		},
+		{
+			name:    "question on two lines",
+			project: Project{Name: "test", Threshold: 2, Question: "Name of\nour first cat?", Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "question too long",
+			project: Project{Name: "test", Threshold: 2, Question: strings.Repeat("a", MaxQuestionLength+1), Friends: []Friend{{Name: "A"}, {Name: "B"}}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -378,6 +431,59 @@ func TestSaveAndReload(t *testing.T) {
 	}
 }
 
+func TestRehearsalDue(t *testing.T) {
+	sealedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		sealed  *Sealed
+		now     time.Time
+		wantDue bool
+	}{
+		{"not sealed", nil, sealedAt.AddDate(5, 0, 0), false},
+		{"just sealed", &Sealed{At: sealedAt}, sealedAt.Add(time.Hour), false},
+		{"exactly a year after sealing", &Sealed{At: sealedAt}, sealedAt.Add(RehearsalInterval), false},
+		{"never rehearsed, over a year", &Sealed{At: sealedAt}, sealedAt.Add(RehearsalInterval + time.Minute), true},
+		{"rehearsed recently", &Sealed{At: sealedAt, LastRehearsed: sealedAt.AddDate(1, 0, 0)}, sealedAt.AddDate(1, 6, 0), false},
+		{"rehearsed over a year ago", &Sealed{At: sealedAt, LastRehearsed: sealedAt.AddDate(0, 1, 0)}, sealedAt.AddDate(1, 1, 1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Project{Sealed: tt.sealed}
+			if got := p.RehearsalDue(tt.now); got != tt.wantDue {
+				t.Errorf("RehearsalDue = %v, want %v", got, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestLastRehearsedSaved(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Sealed = &Sealed{At: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(p.Path, ProjectFileName))
+	if strings.Contains(string(data), "last_rehearsed") {
+		t.Errorf("unset last_rehearsed should be left out:\n%s", data)
+	}
+
+	rehearsed := time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC)
+	p.Sealed.LastRehearsed = rehearsed
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(p.Path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.Sealed.LastRehearsed.Equal(rehearsed) {
+		t.Errorf("LastRehearsed = %v, want %v", loaded.Sealed.LastRehearsed, rehearsed)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsAt(s, substr, 0))
 }
@@ -390,3 +496,61 @@ func containsAt(s, substr string, start int) bool {
 	}
 	return false
 }
+
+func TestMatchesShare(t *testing.T) {
+	sealedAt := time.Date(2024, 3, 1, 12, 0, 30, 0, time.UTC)
+	p := &Project{
+		Threshold: 2,
+		Friends:   []Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}, {Name: "Dave"}},
+		Sealed:    &Sealed{At: sealedAt},
+	}
+	share := func(total, threshold int, created time.Time) *core.Share {
+		s := core.NewShare(2, 1, total, threshold, "Alice", []byte{1, 2, 3})
+		s.Created = created
+		return s
+	}
+
+	t.Run("matching", func(t *testing.T) {
+		if err := p.MatchesShare(share(4, 2, sealedAt.Truncate(time.Minute))); err != nil {
+			t.Errorf("MatchesShare: %v", err)
+		}
+		// Older versions could stamp a share a minute off the seal time.
+		if err := p.MatchesShare(share(4, 2, sealedAt.Add(time.Minute))); err != nil {
+			t.Errorf("a minute later: %v", err)
+		}
+		// Pieces from words carry no set details.
+		if err := p.MatchesShare(share(0, 0, time.Time{})); err != nil {
+			t.Errorf("word piece: %v", err)
+		}
+	})
+
+	t.Run("mismatching", func(t *testing.T) {
+		err := p.MatchesShare(share(5, 3, sealedAt))
+		if err == nil {
+			t.Fatal("expected a 3-of-5 piece to be refused by a 2-of-4 project")
+		}
+		for _, want := range []string{"one of 5", "4 friends", "needs 3", "threshold is 2"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should mention %q", err, want)
+			}
+		}
+		if strings.Contains(err.Error(), "set") {
+			t.Errorf("error %q shouldn't mention the set when the counts already differ", err)
+		}
+
+		err = p.MatchesShare(share(4, 2, sealedAt.AddDate(0, -2, 0)))
+		if err == nil || !strings.Contains(err.Error(), "made 2024-01-01 12:00 UTC") || !strings.Contains(err.Error(), "sealed 2024-03-01 12:00 UTC") {
+			t.Errorf("expected an older seal to be named, got %v", err)
+		}
+		if strings.Contains(err.Error(), "friends") {
+			t.Errorf("error %q shouldn't mention the counts when they agree", err)
+		}
+	})
+
+	t.Run("not sealed", func(t *testing.T) {
+		unsealed := &Project{Threshold: 2, Friends: p.Friends}
+		if err := unsealed.MatchesShare(share(4, 2, sealedAt)); err == nil {
+			t.Error("expected an error for an unsealed project")
+		}
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 )
 
 //go:embed recover/*.json
@@ -37,9 +39,18 @@ var LangNames = [][2]string{
 // LangSelectOptions returns HTML <option> elements for all languages,
 // suitable for injection into a <select> element.
 func LangSelectOptions() string {
+	return LangSelectOptionsFor(Languages)
+}
+
+// LangSelectOptionsFor is LangSelectOptions for only the languages in langs,
+// still in the order of LangNames.
+func LangSelectOptionsFor(langs []string) string {
 	var b strings.Builder
-	for i, entry := range LangNames {
-		if i > 0 {
+	for _, entry := range LangNames {
+		if !slices.Contains(langs, entry[0]) {
+			continue
+		}
+		if b.Len() > 0 {
 			b.WriteString("\n        ")
 		}
 		b.WriteString(`<option value="`)
@@ -54,9 +65,14 @@ func LangSelectOptions() string {
 // LangDetectJS returns a JavaScript array literal of non-English language codes
 // for use in navigator.languages detection, e.g. ['es','de','fr','sl','pt','zh-TW'].
 func LangDetectJS() string {
-	var codes []string
+	return LangDetectJSFor(Languages)
+}
+
+// LangDetectJSFor is LangDetectJS for only the languages in langs.
+func LangDetectJSFor(langs []string) string {
+	codes := []string{}
 	for _, entry := range LangNames {
-		if entry[0] != "en" {
+		if entry[0] != "en" && slices.Contains(langs, entry[0]) {
 			codes = append(codes, "'"+entry[0]+"'")
 		}
 	}
@@ -67,6 +83,11 @@ func LangDetectJS() string {
 // component must be "recover", "maker", or "readme".
 // Returns a string like: { en: {...}, es: {...}, de: {...}, fr: {...}, sl: {...} }
 func GetTranslationsJS(component string) string {
+	return GetTranslationsJSFor(component, Languages)
+}
+
+// GetTranslationsJSFor is GetTranslationsJS for only the languages in langs.
+func GetTranslationsJSFor(component string, langs []string) string {
 	fs := fsForComponent(component)
 	if fs == nil {
 		return "{}"
@@ -74,6 +95,9 @@ func GetTranslationsJS(component string) string {
 
 	var parts []string
 	for _, lang := range Languages {
+		if !slices.Contains(langs, lang) {
+			continue
+		}
 		data, err := fs.ReadFile(component + "/" + lang + ".json")
 		if err != nil {
 			continue
@@ -134,9 +158,15 @@ func GetString(component, lang, key string) string {
 
 // componentCache caches loaded translations per component.
 // Key is component name, value maps lang -> key -> value.
-var componentCache map[string]map[string]map[string]string
+// Bundles are generated concurrently, so access goes through componentMu.
+var (
+	componentCache map[string]map[string]map[string]string
+	componentMu    sync.Mutex
+)
 
 func loadComponentCache(component string) map[string]map[string]string {
+	componentMu.Lock()
+	defer componentMu.Unlock()
 	if componentCache == nil {
 		componentCache = make(map[string]map[string]map[string]string)
 	}
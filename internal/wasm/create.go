@@ -47,6 +47,7 @@ type CreateBundlesConfig struct {
 	GitHubURL       string
 	Anonymous       bool
 	DefaultLanguage string // Default bundle language for all friends
+	Note            string // Optional message shown to every friend
 }
 
 // BundleOutput represents a generated bundle for JavaScript.
@@ -77,6 +78,9 @@ func createBundlesJS(this js.Value, args []js.Value) any {
 	if defLang := configJS.Get("defaultLanguage"); !defLang.IsUndefined() && !defLang.IsNull() {
 		config.DefaultLanguage = defLang.String()
 	}
+	if note := configJS.Get("note"); !note.IsUndefined() && !note.IsNull() {
+		config.Note = core.SanitizeNote(note.String())
+	}
 
 	// Parse friends array
 	friendsJS := configJS.Get("friends")
@@ -138,30 +142,33 @@ func createBundlesJS(this js.Value, args []js.Value) any {
 
 // createBundles creates bundles for all friends.
 func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
-	// Validate inputs
-	if config.ProjectName == "" {
-		return nil, fmt.Errorf("project name is required")
-	}
-	if len(config.Friends) < 2 {
-		return nil, fmt.Errorf("need at least 2 friends, got %d", len(config.Friends))
+	// Convert friends to project.Friend for validation and bundle generation
+	projectFriends := make([]project.Friend, len(config.Friends))
+	friendLangs := []string{config.DefaultLanguage}
+	for i, f := range config.Friends {
+		projectFriends[i] = project.Friend{
+			Name:     f.Name,
+			Contact:  f.Contact,
+			Language: f.Language,
+		}
+		friendLangs = append(friendLangs, f.Language)
 	}
-	if config.Threshold < 2 {
-		return nil, fmt.Errorf("threshold must be at least 2, got %d", config.Threshold)
+
+	// Validate inputs the same way the CLI validates project.yml, so the
+	// browser can't make bundles the CLI would refuse.
+	p := &project.Project{
+		Name:      config.ProjectName,
+		Threshold: config.Threshold,
+		Friends:   projectFriends,
+		Note:      config.Note,
 	}
-	if config.Threshold > len(config.Friends) {
-		return nil, fmt.Errorf("threshold (%d) cannot exceed number of friends (%d)", config.Threshold, len(config.Friends))
+	if err := p.Validate(); err != nil {
+		return nil, err
 	}
 	if len(config.Files) == 0 {
 		return nil, fmt.Errorf("no files provided")
 	}
 
-	// Validate friends
-	for i, f := range config.Friends {
-		if f.Name == "" {
-			return nil, fmt.Errorf("friend %d: name is required", i+1)
-		}
-	}
-
 	// Create tar.gz archive of files
 	archiveData, err := createTarGz(config.Files)
 	if err != nil {
@@ -190,12 +197,20 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 		return nil, fmt.Errorf("splitting passphrase: %w", err)
 	}
 
+	commitment, err := core.NewCommitment(raw)
+	if err != nil {
+		return nil, fmt.Errorf("committing to passphrase: %w", err)
+	}
+
 	// Current timestamp for all bundles
 	now := time.Now().UTC()
 
 	// Get recovery WASM bytes for embedding in recover.html
 	// Note: In WASM context, we use the embedded recover.wasm (smaller, recovery-only)
-	wasmBytes := html.GetRecoverWASMBytes()
+	// The parts of recover.html that are the same for everyone are built once.
+	recoverWASM := html.GetRecoverWASMBytes()
+	wasmChecksum := core.HashBytes(recoverWASM)
+	recoverTemplate := html.NewRecoverTemplate(recoverWASM, config.Version, config.GitHubURL)
 
 	// Create shares and bundles
 	bundles := make([]BundleOutput, n)
@@ -212,19 +227,13 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			Created:   now,
 			Data:      rawShares[i],
 			Checksum:  core.HashBytes(rawShares[i]),
+			Headers:   map[string]string{core.CommitmentHeader: commitment},
 		}
 		shares[i] = share
 	}
 
-	// Convert friends to project.Friend for bundle generation
-	projectFriends := make([]project.Friend, len(config.Friends))
-	for i, f := range config.Friends {
-		projectFriends[i] = project.Friend{
-			Name:     f.Name,
-			Contact:  f.Contact,
-			Language: f.Language,
-		}
-	}
+	// Any holder may end up typing in someone else's words
+	wordLangs := html.WordLanguages(friendLangs)
 
 	// Generate bundle for each friend
 	for i, friend := range config.Friends {
@@ -265,6 +274,10 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			Threshold:    k,
 			Total:        n,
 			Language:     lang,
+			Note:         config.Note,
+
+			ManifestChecksum: manifestChecksum,
+			WordLanguages:    wordLangs,
 		}
 
 		// Embed manifest in recover.html when small enough
@@ -273,7 +286,7 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			personalization.ManifestB64 = base64.StdEncoding.EncodeToString(manifestData)
 		}
 
-		recoverHTML := html.GenerateRecoverHTML(wasmBytes, config.Version, config.GitHubURL, personalization)
+		recoverHTML := recoverTemplate.Generate(personalization)
 		recoverChecksum := core.HashString(recoverHTML)
 
 		// Generate README.txt
@@ -288,10 +301,12 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			GitHubReleaseURL: config.GitHubURL,
 			ManifestChecksum: manifestChecksum,
 			RecoverChecksum:  recoverChecksum,
+			WASMChecksum:     wasmChecksum,
 			Created:          now,
 			Anonymous:        config.Anonymous,
 			Language:         lang,
 			ManifestEmbedded: manifestEmbedded,
+			Note:             config.Note,
 		}
 		readmeContent := bundle.GenerateReadme(readmeData)
 
@@ -308,10 +323,13 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			GitHubReleaseURL: config.GitHubURL,
 			ManifestChecksum: manifestChecksum,
 			RecoverChecksum:  recoverChecksum,
+			WASMChecksum:     wasmChecksum,
+			AckToken:         bundle.GenerateAckToken(share),
 			Created:          now,
 			Anonymous:        config.Anonymous,
 			Language:         lang,
 			ManifestEmbedded: manifestEmbedded,
+			Note:             config.Note,
 		}
 		pdfContent, err := pdf.GenerateReadme(pdfData)
 		if err != nil {
@@ -366,6 +384,7 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 		return nil, fmt.Errorf("writing directory header: %w", err)
 	}
 
+	hashes := make(map[string]string)
 	for _, f := range files {
 		// Normalize the file path - ensure it's under manifest/
 		name := f.Name
@@ -378,6 +397,10 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 		if len(name) > 9 && name[:9] == "manifest/" {
 			name = name[9:]
 		}
+		// The hash list is generated below; a user file can't take its place
+		if name == core.ManifestHashesFile {
+			continue
+		}
 		// Add the manifest/ prefix
 		fullPath := rootDir + "/" + name
 
@@ -396,6 +419,22 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 		if _, err := tw.Write(f.Data); err != nil {
 			return nil, fmt.Errorf("writing data for %s: %w", f.Name, err)
 		}
+		hashes[name] = core.HashBytes(f.Data)
+	}
+
+	// Per-file hashes, checked during recovery
+	hashList := core.FormatManifestHashes(hashes)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     rootDir + "/" + core.ManifestHashesFile,
+		Mode:     0644,
+		Size:     int64(len(hashList)),
+		ModTime:  time.Now().UTC(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return nil, fmt.Errorf("writing header for %s: %w", core.ManifestHashesFile, err)
+	}
+	if _, err := tw.Write(hashList); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", core.ManifestHashesFile, err)
 	}
 
 	if err := tw.Close(); err != nil {
@@ -475,6 +514,7 @@ func parseProjectYAMLJS(this js.Value, args []js.Value) any {
 			"name":      proj.Name,
 			"threshold": proj.Threshold,
 			"language":  proj.Language,
+			"note":      proj.Note,
 			"friends":   friends,
 		},
 		"error": nil,
@@ -486,6 +526,7 @@ type ProjectYAML struct {
 	Name      string `yaml:"name"`
 	Threshold int    `yaml:"threshold"`
 	Language  string `yaml:"language,omitempty"`
+	Note      string `yaml:"note,omitempty"`
 	Friends   []struct {
 		Name     string `yaml:"name"`
 		Contact  string `yaml:"contact,omitempty"`
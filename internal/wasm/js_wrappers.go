@@ -4,6 +4,8 @@ package main
 
 import (
 	"syscall/js"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 // parseShareJS parses a share from text content.
@@ -60,7 +62,7 @@ func combineSharesJS(this js.Value, args []js.Value) any {
 }
 
 // decryptManifestJS decrypts an age-encrypted manifest.
-// Args: encryptedData (Uint8Array), passphrase (string)
+// Args: encryptedData (Uint8Array), passphrase (string), answer (string, optional)
 // Returns: { data: Uint8Array, error: string|null }
 func decryptManifestJS(this js.Value, args []js.Value) any {
 	if len(args) < 2 {
@@ -74,8 +76,12 @@ func decryptManifestJS(this js.Value, args []js.Value) any {
 	js.CopyBytesToGo(encryptedData, jsData)
 
 	passphrase := args[1].String()
+	var answer string
+	if len(args) > 2 && args[2].Type() == js.TypeString {
+		answer = args[2].String()
+	}
 
-	decrypted, err := decryptManifest(encryptedData, passphrase)
+	decrypted, err := decryptManifest(encryptedData, passphrase, answer)
 	if err != nil {
 		return errorResult(err.Error())
 	}
@@ -182,9 +188,10 @@ func parseCompactShareJS(this js.Value, args []js.Value) any {
 	})
 }
 
-// decodeWordsJS decodes 25 BIP39 words to raw share data bytes and share index.
-// The first 24 words encode the data; the 25th word packs 4 bits of index + 7 bits of checksum.
-// Returns index=0 if the share index was > 15 (sentinel for "unknown — UI should not highlight a specific contact").
+// decodeWordsJS decodes 25 or 26 BIP39 words to raw share data bytes and share index.
+// The first 24 words encode the data; the 25th word packs 4 bits of index + 7 bits of checksum,
+// and a 26th, for shares above 15, the full index.
+// Returns index=0 if the share index was > 15 and word 26 was left off (sentinel for "unknown — UI should not highlight a specific contact").
 // Returns an error if the embedded checksum doesn't match (wrong word order, typos, etc.).
 // Args: words (string array)
 // Returns: { data: Uint8Array, index: number, checksum: string, error: string|null }
@@ -217,6 +224,24 @@ func decodeWordsJS(this js.Value, args []js.Value) any {
 	})
 }
 
+// hashBytesJS returns the SHA-256 checksum of data in the README's format,
+// for checking a MANIFEST.age against the one the bundle was made with.
+// Args: data (Uint8Array)
+// Returns: { checksum: string, error: string|null }
+func hashBytesJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing data argument")
+	}
+
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	return js.ValueOf(map[string]any{
+		"checksum": core.HashBytes(data),
+		"error":    nil,
+	})
+}
+
 // shareInfoToJS converts a ShareInfo to a JS-compatible map.
 func shareInfoToJS(s *ShareInfo) map[string]any {
 	return map[string]any{
@@ -15,6 +15,7 @@ func main() {
 	js.Global().Set("rememoryExtractBundle", js.FuncOf(extractBundleJS))
 	js.Global().Set("rememoryParseCompactShare", js.FuncOf(parseCompactShareJS))
 	js.Global().Set("rememoryDecodeWords", js.FuncOf(decodeWordsJS))
+	js.Global().Set("rememoryHashBytes", js.FuncOf(hashBytesJS))
 
 	// Signal that WASM is ready
 	js.Global().Set("rememoryReady", true)
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/translations"
@@ -115,23 +116,51 @@ func combineShares(shares []ShareData) (string, error) {
 	return core.RecoverPassphrase(secret, shares[0].Version), nil
 }
 
-// decryptManifest decrypts age-encrypted data using a passphrase.
+// decryptManifest decrypts age-encrypted data using a passphrase, and the
+// answer to the recovery question when the project has one.
 // Uses core.DecryptBytes for the actual decryption.
-func decryptManifest(encryptedData []byte, passphrase string) ([]byte, error) {
+func decryptManifest(encryptedData []byte, passphrase, answer string) ([]byte, error) {
+	if answer != "" {
+		return core.DecryptBytesWithAnswer(encryptedData, passphrase, answer)
+	}
 	return core.DecryptBytes(encryptedData, passphrase)
 }
 
 // extractTarGz extracts files from tar.gz data in memory.
-// Uses core.ExtractTarGz for the actual extraction.
+// Uses core.ExtractTarGz for the actual extraction. When the archive carries
+// MANIFEST-HASHES.txt, every file is checked against it and the list itself
+// is left out of the result.
 func extractTarGz(tarGzData []byte) ([]core.ExtractedFile, error) {
-	return core.ExtractTarGz(tarGzData)
+	files, err := core.ExtractTarGz(tarGzData)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := core.FindManifestHashes(files)
+	if err != nil {
+		return nil, err
+	}
+	if hashes == nil {
+		return files, nil
+	}
+	if err := core.VerifyExtractedFiles(files, hashes); err != nil {
+		return nil, err
+	}
+	result := files[:0]
+	for _, f := range files {
+		if _, rel, _ := strings.Cut(f.Name, "/"); rel != core.ManifestHashesFile {
+			result = append(result, f)
+		}
+	}
+	return result, nil
 }
 
-// decodeShareWords converts 25 BIP39 words to raw share data bytes and share index.
+// decodeShareWords converts 25 or 26 BIP39 words to raw share data bytes and share index.
 // Auto-detects the word list language. The first 24 words encode the data;
-// the 25th word packs 4 bits of index + 7 bits of checksum.
-// Returns the decoded bytes, share index (0 if share >15), checksum, detected language, and any error.
+// the 25th word packs 4 bits of index + 7 bits of checksum, and a 26th the full index of shares >15.
+// Returns the decoded bytes, share index (0 if share >15 without word 26), checksum, detected language, and any error.
 func decodeShareWords(words []string) ([]byte, int, string, string, error) {
+	// Words pasted from Windows can carry a BOM or odd spaces inside them.
+	words = core.TokenizeWords(strings.Join(words, " "))
 	data, index, lang, err := core.DecodeShareWordsAuto(words)
 	if err != nil {
 		return nil, 0, "", "", err